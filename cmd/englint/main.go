@@ -1,24 +1,66 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/TT-AIXion/englint/internal/annotate"
+	"github.com/TT-AIXion/englint/internal/badge"
+	"github.com/TT-AIXion/englint/internal/baseline"
+	"github.com/TT-AIXion/englint/internal/codeowners"
 	"github.com/TT-AIXion/englint/internal/config"
+	"github.com/TT-AIXion/englint/internal/diff"
+	"github.com/TT-AIXion/englint/internal/fix"
+	"github.com/TT-AIXion/englint/internal/git"
+	"github.com/TT-AIXion/englint/internal/i18n"
+	"github.com/TT-AIXion/englint/internal/mcp"
 	"github.com/TT-AIXion/englint/internal/output"
+	"github.com/TT-AIXion/englint/internal/plugin"
+	"github.com/TT-AIXion/englint/internal/publish"
 	"github.com/TT-AIXion/englint/internal/scanner"
+	"github.com/TT-AIXion/englint/internal/store"
+	"github.com/TT-AIXion/englint/internal/suggest"
+	"github.com/TT-AIXion/englint/internal/trend"
 )
 
 var Version = "dev"
+
+// exitNoFiles is the scan exit code when --error-on-no-files is set and the
+// include and exclude patterns matched no files, so CI can tell a mistyped
+// glob from findings.
+const exitNoFiles = 2
+
 var exitFunc = os.Exit
+var stdin io.Reader = os.Stdin
+
+// lang is the language of human-readable messages, set by runMain from
+// --lang or the locale environment variables.
+var lang = i18n.English
 
 func main() {
 	exitFunc(runMain(os.Args[1:], os.Stdout, os.Stderr))
 }
 
 func runMain(args []string, stdout, stderr io.Writer) int {
+	args, langFlag, err := extractLang(args)
+	if err == nil {
+		lang, err = i18n.Resolve(langFlag, os.Getenv)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "argument error: %v\n", err)
+		return 1
+	}
 	if len(args) == 0 {
 		printUsage(stdout)
 		return 0
@@ -35,6 +77,28 @@ func runMain(args []string, stdout, stderr io.Writer) int {
 		return runInit(args[1:], stdout, stderr)
 	case "scan":
 		return runScan(args[1:], stdout, stderr)
+	case "mcp":
+		return runMCP(args[1:], stdout, stderr)
+	case "report":
+		return runReport(args[1:], stdout, stderr)
+	case "history":
+		return runHistory(args[1:], stdout, stderr)
+	case "diff":
+		return runDiff(args[1:], stdout, stderr)
+	case "trend":
+		return runTrend(args[1:], stdout, stderr)
+	case "annotate":
+		return runAnnotate(args[1:], stdout, stderr)
+	case "suggest-allow":
+		return runSuggestAllow(args[1:], stdout, stderr)
+	case "badge":
+		return runBadge(args[1:], stdout, stderr)
+	case "fix":
+		return runFix(args[1:], stdout, stderr)
+	case "validate":
+		return runValidate(args[1:], stdout, stderr)
+	case "baseline":
+		return runBaseline(args[1:], stdout, stderr)
 	default:
 		_, _ = fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
 		printUsage(stderr)
@@ -42,16 +106,105 @@ func runMain(args []string, stdout, stderr io.Writer) int {
 	}
 }
 
+// extractLang removes the global --lang flag, which may appear anywhere
+// before "--", and returns the remaining arguments and its value.
+func extractLang(args []string) ([]string, string, error) {
+	out := make([]string, 0, len(args))
+	value := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(out, args[i:]...), value, nil
+		case arg == "--lang":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("flag --lang requires a value")
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "--lang="):
+			value = strings.TrimPrefix(arg, "--lang=")
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out, value, nil
+}
+
 type scanArgs struct {
-	ConfigPath string
-	Include    []string
-	Exclude    []string
-	JSON       bool
-	Fix        bool
-	Severity   string
-	NoColor    bool
-	Verbose    bool
-	Paths      []string
+	// ConfigPaths are the --config files in order; later files override
+	// earlier ones. ConfigPath is the last of them.
+	ConfigPaths []string
+	ConfigPath  string
+	Include     []string
+	Exclude     []string
+	// Format is the --format value; --json is an alias for "json".
+	Format string
+	Fix    bool
+	// FixDryRun prints the changes --fix would make as a diff instead of
+	// writing them.
+	FixDryRun        bool
+	Severity         string
+	NoColor          bool
+	Verbose          bool
+	Why              []string
+	MmapThreshold    string
+	MaxFindings      string
+	Threads          string
+	Excerpts         string
+	NotifyWebhook    string
+	NotifyAll        bool
+	Store            string
+	AllowLatin       bool
+	IgnoreURLs       bool
+	IgnoreBlobs      bool
+	DecodeEscapes    bool
+	CheckEntities    bool
+	IgnoreCodeBlocks bool
+	EditorConfig     bool
+	CheckCharset     bool
+	GroupByOwner     bool
+	Strict           bool
+	ErrorPolicy      string
+	// PathStyle is the --paths value: relative, absolute, or
+	// relative-to=<dir>.
+	PathStyle      string
+	FileURIs       bool
+	MinConfidence  string
+	InvalidUTF8Fix string
+	FixMode        string
+	// Baseline is a baseline file whose findings are not reported.
+	Baseline string
+	Paths    []string
+	// IgnoreComments and IgnoreStrings are nil unless --ignore-comments,
+	// --ignore-strings, or their --no- forms override the config.
+	IgnoreComments *bool
+	IgnoreStrings  *bool
+	// Allow holds the --allow values, added to the config allow list.
+	Allow []string
+	// CategorySeverities holds the CATEGORY=level --severity values.
+	CategorySeverities []string
+	ErrorOnNoFiles     bool
+	// Changed limits the scan to files git reports as changed or untracked.
+	Changed bool
+	// Since limits the scan to the lines added or modified since the merge
+	// base of a git revision and HEAD.
+	Since string
+	// ListSkipped prints only the skipped files, with their reasons.
+	ListSkipped   bool
+	DedupeContent bool
+	// ReadSpecial reads named pipes and devices given as paths.
+	ReadSpecial bool
+}
+
+// severityFlag records a --severity value: a default severity, or a
+// CATEGORY=level override.
+func (a *scanArgs) severityFlag(value string) {
+	if strings.Contains(value, "=") {
+		a.CategorySeverities = append(a.CategorySeverities, value)
+		return
+	}
+	a.Severity = value
 }
 
 func parseScanArgs(args []string) (scanArgs, error) {
@@ -72,21 +225,152 @@ func parseScanArgs(args []string) (scanArgs, error) {
 
 		switch {
 		case arg == "--json":
-			out.JSON = true
+			out.Format = string(output.FormatJSON)
+		case arg == "--problem-matcher":
+			out.Format = string(output.FormatProblemMatcher)
 		case arg == "--fix":
 			out.Fix = true
+		case arg == "--fix-dry-run":
+			out.Fix = true
+			out.FixDryRun = true
 		case arg == "--no-color":
 			out.NoColor = true
 		case arg == "--verbose":
 			out.Verbose = true
+		case arg == "--notify-findings":
+			out.NotifyAll = true
+		case arg == "--allow-latin-extended":
+			out.AllowLatin = true
+		case arg == "--ignore-comments", arg == "--no-ignore-comments":
+			out.IgnoreComments = boolFlag(arg == "--ignore-comments")
+		case arg == "--ignore-strings", arg == "--no-ignore-strings":
+			out.IgnoreStrings = boolFlag(arg == "--ignore-strings")
+		case arg == "--ignore-urls":
+			out.IgnoreURLs = true
+		case arg == "--ignore-blobs":
+			out.IgnoreBlobs = true
+		case arg == "--decode-escapes":
+			out.DecodeEscapes = true
+		case arg == "--check-entities":
+			out.CheckEntities = true
+		case arg == "--ignore-code-blocks":
+			out.IgnoreCodeBlocks = true
+		case arg == "--editorconfig":
+			out.EditorConfig = true
+		case arg == "--check-charset":
+			out.CheckCharset = true
+		case arg == "--dedupe-content":
+			out.DedupeContent = true
+		case arg == "--read-special":
+			out.ReadSpecial = true
+		case arg == "--group-by-owner":
+			out.GroupByOwner = true
+		case arg == "--strict":
+			out.Strict = true
+		case arg == "--file-uris":
+			out.FileURIs = true
+		case arg == "--error-on-no-files":
+			out.ErrorOnNoFiles = true
+		case arg == "--changed":
+			out.Changed = true
+		case arg == "--since":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --since requires a value")
+			}
+			i++
+			out.Since = args[i]
+		case strings.HasPrefix(arg, "--since="):
+			out.Since = strings.TrimPrefix(arg, "--since=")
+		case arg == "--list-skipped":
+			out.ListSkipped = true
+		case arg == "--store":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --store requires a value")
+			}
+			i++
+			out.Store = args[i]
+		case strings.HasPrefix(arg, "--store="):
+			out.Store = strings.TrimPrefix(arg, "--store=")
+		case arg == "--min-confidence":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --min-confidence requires a value")
+			}
+			i++
+			out.MinConfidence = args[i]
+		case strings.HasPrefix(arg, "--min-confidence="):
+			out.MinConfidence = strings.TrimPrefix(arg, "--min-confidence=")
+		case arg == "--format":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --format requires a value")
+			}
+			i++
+			out.Format = args[i]
+		case strings.HasPrefix(arg, "--format="):
+			out.Format = strings.TrimPrefix(arg, "--format=")
+		case arg == "--paths":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --paths requires a value")
+			}
+			i++
+			out.PathStyle = args[i]
+		case strings.HasPrefix(arg, "--paths="):
+			out.PathStyle = strings.TrimPrefix(arg, "--paths=")
+		case arg == "--error-policy":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --error-policy requires a value")
+			}
+			i++
+			out.ErrorPolicy = args[i]
+		case strings.HasPrefix(arg, "--error-policy="):
+			out.ErrorPolicy = strings.TrimPrefix(arg, "--error-policy=")
+		case arg == "--invalid-utf8-fix":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --invalid-utf8-fix requires a value")
+			}
+			i++
+			out.InvalidUTF8Fix = args[i]
+		case strings.HasPrefix(arg, "--invalid-utf8-fix="):
+			out.InvalidUTF8Fix = strings.TrimPrefix(arg, "--invalid-utf8-fix=")
+		case arg == "--baseline":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --baseline requires a value")
+			}
+			i++
+			out.Baseline = args[i]
+		case strings.HasPrefix(arg, "--baseline="):
+			out.Baseline = strings.TrimPrefix(arg, "--baseline=")
+		case arg == "--fix-mode":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --fix-mode requires a value")
+			}
+			i++
+			out.FixMode = args[i]
+		case strings.HasPrefix(arg, "--fix-mode="):
+			out.FixMode = strings.TrimPrefix(arg, "--fix-mode=")
+		case arg == "--notify-webhook":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --notify-webhook requires a value")
+			}
+			i++
+			out.NotifyWebhook = args[i]
+		case strings.HasPrefix(arg, "--notify-webhook="):
+			out.NotifyWebhook = strings.TrimPrefix(arg, "--notify-webhook=")
 		case arg == "--config":
 			if i+1 >= len(args) {
 				return scanArgs{}, fmt.Errorf("flag --config requires a value")
 			}
 			i++
-			out.ConfigPath = args[i]
+			out.ConfigPaths = append(out.ConfigPaths, args[i])
 		case strings.HasPrefix(arg, "--config="):
-			out.ConfigPath = strings.TrimPrefix(arg, "--config=")
+			out.ConfigPaths = append(out.ConfigPaths, strings.TrimPrefix(arg, "--config="))
+		case arg == "--allow":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --allow requires a value")
+			}
+			i++
+			out.Allow = append(out.Allow, args[i])
+		case strings.HasPrefix(arg, "--allow="):
+			out.Allow = append(out.Allow, strings.TrimPrefix(arg, "--allow="))
 		case arg == "--exclude":
 			if i+1 >= len(args) {
 				return scanArgs{}, fmt.Errorf("flag --exclude requires a value")
@@ -103,14 +387,56 @@ func parseScanArgs(args []string) (scanArgs, error) {
 			out.Include = append(out.Include, args[i])
 		case strings.HasPrefix(arg, "--include="):
 			out.Include = append(out.Include, strings.TrimPrefix(arg, "--include="))
+		case arg == "--why":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --why requires a value")
+			}
+			i++
+			out.Why = append(out.Why, args[i])
+		case strings.HasPrefix(arg, "--why="):
+			out.Why = append(out.Why, strings.TrimPrefix(arg, "--why="))
+		case arg == "--excerpts":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --excerpts requires a value")
+			}
+			i++
+			out.Excerpts = args[i]
+		case strings.HasPrefix(arg, "--excerpts="):
+			out.Excerpts = strings.TrimPrefix(arg, "--excerpts=")
+		case arg == "--max-findings-per-file":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --max-findings-per-file requires a value")
+			}
+			i++
+			out.MaxFindings = args[i]
+		case strings.HasPrefix(arg, "--max-findings-per-file="):
+			out.MaxFindings = strings.TrimPrefix(arg, "--max-findings-per-file=")
+		case arg == "--threads" || arg == "--jobs":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag %s requires a value", arg)
+			}
+			i++
+			out.Threads = args[i]
+		case strings.HasPrefix(arg, "--threads="):
+			out.Threads = strings.TrimPrefix(arg, "--threads=")
+		case strings.HasPrefix(arg, "--jobs="):
+			out.Threads = strings.TrimPrefix(arg, "--jobs=")
+		case arg == "--mmap-threshold":
+			if i+1 >= len(args) {
+				return scanArgs{}, fmt.Errorf("flag --mmap-threshold requires a value")
+			}
+			i++
+			out.MmapThreshold = args[i]
+		case strings.HasPrefix(arg, "--mmap-threshold="):
+			out.MmapThreshold = strings.TrimPrefix(arg, "--mmap-threshold=")
 		case arg == "--severity":
 			if i+1 >= len(args) {
 				return scanArgs{}, fmt.Errorf("flag --severity requires a value")
 			}
 			i++
-			out.Severity = args[i]
+			out.severityFlag(args[i])
 		case strings.HasPrefix(arg, "--severity="):
-			out.Severity = strings.TrimPrefix(arg, "--severity=")
+			out.severityFlag(strings.TrimPrefix(arg, "--severity="))
 		default:
 			return scanArgs{}, fmt.Errorf("unknown flag: %s", arg)
 		}
@@ -119,15 +445,53 @@ func parseScanArgs(args []string) (scanArgs, error) {
 	if len(out.Paths) == 0 {
 		out.Paths = []string{"."}
 	}
-	if strings.TrimSpace(out.ConfigPath) == "" {
-		out.ConfigPath = ".englint.yaml"
-	}
+	out.ConfigPaths = defaultConfigPaths(out.ConfigPaths)
+	out.ConfigPath = out.ConfigPaths[len(out.ConfigPaths)-1]
 	out.Severity = strings.ToLower(strings.TrimSpace(out.Severity))
 	return out, nil
 }
 
+// loadConfig loads the config of commands that take a single --config
+// path as scan loads its own: path, else the files in ENGLINT_CONFIG, else
+// .englint.yaml, beneath the ENGLINT_* variables and the per-user config.
+func loadConfig(path string) (config.Config, error) {
+	return config.LoadEnv(os.Getenv, defaultConfigPaths([]string{path}))
+}
+
+// commandOptions returns the scan options of cfg for commands other than
+// scan, with paths relative to the root of the git checkout as scan
+// reports them by default.
+func commandOptions(cfg config.Config) scanner.Options {
+	opts := scanOptions(cfg)
+	opts.DisplayRoot = gitDisplayRoot()
+	return opts
+}
+
+// defaultConfigPaths returns the non-empty --config paths, else the files
+// listed in ENGLINT_CONFIG, else .englint.yaml.
+func defaultConfigPaths(paths []string) []string {
+	paths = slices.DeleteFunc(paths, func(path string) bool {
+		return strings.TrimSpace(path) == ""
+	})
+	if len(paths) == 0 {
+		paths = filepath.SplitList(os.Getenv(config.EnvPrefix + "CONFIG"))
+	}
+	if len(paths) == 0 {
+		paths = []string{".englint.yaml"}
+	}
+	return paths
+}
+
 type initArgs struct {
 	ConfigPath string
+	// Template is the name of the starter config, one of
+	// config.TemplateNames.
+	Template string
+	// Force overwrites an existing config file.
+	Force bool
+	// Interactive builds the config from answers read from stdin instead
+	// of a template.
+	Interactive bool
 }
 
 func parseInitArgs(args []string) (initArgs, error) {
@@ -146,6 +510,18 @@ func parseInitArgs(args []string) (initArgs, error) {
 			out.ConfigPath = args[i]
 		case strings.HasPrefix(arg, "--config="):
 			out.ConfigPath = strings.TrimPrefix(arg, "--config=")
+		case arg == "--template":
+			if i+1 >= len(args) {
+				return initArgs{}, fmt.Errorf("flag --template requires a value")
+			}
+			i++
+			out.Template = strings.TrimSpace(args[i])
+		case strings.HasPrefix(arg, "--template="):
+			out.Template = strings.TrimSpace(strings.TrimPrefix(arg, "--template="))
+		case arg == "--force":
+			out.Force = true
+		case arg == "--interactive":
+			out.Interactive = true
 		default:
 			return initArgs{}, fmt.Errorf("unknown flag for init: %s", arg)
 		}
@@ -153,9 +529,27 @@ func parseInitArgs(args []string) (initArgs, error) {
 	if strings.TrimSpace(out.ConfigPath) == "" {
 		out.ConfigPath = ".englint.yaml"
 	}
+	if out.Interactive {
+		if out.Template != "" {
+			return initArgs{}, fmt.Errorf("--interactive cannot be combined with --template")
+		}
+		return out, nil
+	}
+	if out.Template == "" {
+		out.Template = "default"
+	}
+	if _, err := config.Template(out.Template); err != nil {
+		return initArgs{}, err
+	}
 	return out, nil
 }
 
+// boolFlag returns a pointer to v for flags that override a config value
+// only when given.
+func boolFlag(v bool) *bool {
+	return &v
+}
+
 func runScan(args []string, stdout, stderr io.Writer) int {
 	parsed, err := parseScanArgs(args)
 	if err != nil {
@@ -163,8 +557,26 @@ func runScan(args []string, stdout, stderr io.Writer) int {
 		printScanUsage(stderr)
 		return 1
 	}
+	if parsed.Format == "help" {
+		if err := output.PrintFormats(stdout); err != nil {
+			_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	format, err := output.ParseFormat(parsed.Format)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan argument error: %v\n", err)
+		return 1
+	}
+	switch scanner.ErrorPolicy(parsed.ErrorPolicy) {
+	case "", scanner.ErrorPolicySkip, scanner.ErrorPolicyWarn, scanner.ErrorPolicyAbort:
+	default:
+		_, _ = fmt.Fprintf(stderr, "scan argument error: --error-policy must be abort, skip, or warn\n")
+		return 1
+	}
 
-	cfg, err := config.Load(parsed.ConfigPath)
+	cfg, err := config.LoadEnv(os.Getenv, parsed.ConfigPaths)
 	if err != nil {
 		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
 		return 1
@@ -175,82 +587,2247 @@ func runScan(args []string, stdout, stderr io.Writer) int {
 	if parsed.Severity != "" {
 		cfg.Severity = parsed.Severity
 	}
+	if len(parsed.CategorySeverities) > 0 {
+		for _, entry := range parsed.CategorySeverities {
+			category, level, _ := strings.Cut(entry, "=")
+			switch strings.ToLower(strings.TrimSpace(level)) {
+			case config.SeverityOff, config.SeverityWarning, config.SeverityError:
+			default:
+				_, _ = fmt.Fprintf(stderr, "scan argument error: --severity %s=%s must set off, warning, or error\n", category, level)
+				return 1
+			}
+			if strings.TrimSpace(category) == "" {
+				_, _ = fmt.Fprintf(stderr, "scan argument error: --severity %q requires a category\n", entry)
+				return 1
+			}
+		}
+		// A policy for every file, applied after the config policies, so
+		// the flags win.
+		cfg.Policies = append(cfg.Policies, config.Policy{Paths: []string{"**"}, Categories: parsed.CategorySeverities})
+	}
+	if parsed.Excerpts != "" {
+		cfg.Excerpts = parsed.Excerpts
+	}
+	if parsed.MaxFindings != "" {
+		limit, err := strconv.Atoi(parsed.MaxFindings)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan argument error: --max-findings-per-file must be an integer\n")
+			return 1
+		}
+		cfg.MaxFindingsPerFile = limit
+	}
+	if parsed.Threads != "" {
+		threads, err := strconv.Atoi(parsed.Threads)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan argument error: --threads must be an integer\n")
+			return 1
+		}
+		cfg.Threads = threads
+	}
+	if parsed.MmapThreshold != "" {
+		threshold, err := config.ParseByteSize(parsed.MmapThreshold)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan argument error: --mmap-threshold: %v\n", err)
+			return 1
+		}
+		cfg.MmapThreshold = threshold
+	}
+	if parsed.NotifyWebhook != "" {
+		cfg.NotifyWebhook = parsed.NotifyWebhook
+	}
+	if parsed.NotifyAll {
+		cfg.NotifyIncludeFindings = true
+	}
+	if parsed.AllowLatin {
+		cfg.AllowLatinExtended = true
+	}
+	if parsed.IgnoreComments != nil {
+		cfg.IgnoreComments = *parsed.IgnoreComments
+	}
+	if parsed.IgnoreStrings != nil {
+		cfg.IgnoreStrings = *parsed.IgnoreStrings
+	}
+	if parsed.IgnoreURLs {
+		cfg.IgnoreURLs = true
+	}
+	if parsed.IgnoreBlobs {
+		cfg.IgnoreBlobs = true
+	}
+	if parsed.DecodeEscapes {
+		cfg.DecodeEscapes = true
+	}
+	if parsed.CheckEntities {
+		cfg.CheckEntities = true
+	}
+	if parsed.IgnoreCodeBlocks {
+		cfg.IgnoreCodeBlocks = true
+	}
+	if parsed.EditorConfig {
+		cfg.EditorConfig = true
+	}
+	if parsed.CheckCharset {
+		cfg.CheckCharset = true
+	}
+	if parsed.DedupeContent {
+		cfg.DedupeContent = true
+	}
+	if parsed.MinConfidence != "" {
+		cfg.MinConfidence = parsed.MinConfidence
+	}
+	if parsed.InvalidUTF8Fix != "" {
+		cfg.InvalidUTF8Fix = parsed.InvalidUTF8Fix
+	}
+	if parsed.FixMode != "" {
+		cfg.FixMode = parsed.FixMode
+	}
 	cfg = config.ApplyDefaults(cfg)
 	if err := config.Validate(cfg); err != nil {
 		_, _ = fmt.Fprintf(stderr, "config validation error: %v\n", err)
 		return 1
 	}
 
-	sev := scanner.SeverityError
-	if cfg.Severity == config.SeverityWarning {
-		sev = scanner.SeverityWarning
+	opts := scanOptions(cfg)
+	opts.Strict = parsed.Strict
+	opts.ReadSpecialFiles = parsed.ReadSpecial
+	verbose := parsed.Verbose || cfg.Verbose
+	opts.Timing = verbose
+	opts.ErrorPolicy = scanner.ErrorPolicy(parsed.ErrorPolicy)
+	if err := applyPathStyle(&opts, parsed.PathStyle); err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan argument error: %v\n", err)
+		return 1
+	}
+	for _, value := range parsed.Allow {
+		if err := applyAllow(&opts, value); err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan argument error: %v\n", err)
+			return 1
+		}
 	}
+	noColor := parsed.NoColor || cfg.NoColor || os.Getenv("NO_COLOR") != "" || os.Getenv(config.EnvPrefix+"NO_COLOR") != ""
+	writer := output.New(format, noColor, stdout, stderr)
+	writer.Lang = lang
 
-	result, err := scanner.Scan(parsed.Paths, scanner.Options{
-		Include:           cfg.Include,
-		Exclude:           cfg.Exclude,
-		AllowRunes:        config.AllowedRuneMap(cfg.Allow),
-		Severity:          sev,
-		IgnoreComments:    cfg.IgnoreComments,
-		IgnoreStrings:     cfg.IgnoreStrings,
-		AllowFilePatterns: cfg.AllowFilePatterns,
-	})
+	if len(parsed.Why) > 0 {
+		explanations := make([]scanner.Explanation, 0, len(parsed.Why))
+		for _, path := range parsed.Why {
+			explanation, err := scanner.Explain(path, opts)
+			if err != nil {
+				_, _ = fmt.Fprintf(stderr, "explain error: %v\n", err)
+				return 1
+			}
+			explanations = append(explanations, explanation)
+		}
+		if err := writer.PrintExplanations(explanations); err != nil {
+			_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	paths := parsed.Paths
+	if parsed.Changed && parsed.Since != "" {
+		_, _ = fmt.Fprintf(stderr, "scan argument error: --changed and --since cannot be combined\n")
+		return 1
+	}
+	if parsed.Changed {
+		if paths, err = changedPaths(parsed.Paths); err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+			return 1
+		}
+	}
+	var sinceLines map[string][]git.LineRange
+	if parsed.Since != "" {
+		if paths, sinceLines, err = changedSincePaths(parsed.Since, parsed.Paths); err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+			return 1
+		}
+	}
+	var base baseline.Baseline
+	if parsed.Baseline != "" {
+		if base, err = baseline.Load(parsed.Baseline); err != nil {
+			_, _ = fmt.Fprintf(stderr, "baseline error: %v\n", err)
+			return 1
+		}
+	}
+	// The per-file limit applies to the findings on changed lines that the
+	// baseline leaves.
+	filtered := parsed.Since != "" || parsed.Baseline != ""
+	if filtered {
+		opts.MaxFindingsPerFile = 0
+	}
+	// Scan treats no paths as the working directory, so a clean checkout is
+	// not scanned at all.
+	result := scanner.Result{Findings: []scanner.Finding{}, ScannedFiles: []string{}, SkippedFiles: []scanner.SkippedFile{}}
+	if !parsed.Changed && parsed.Since == "" || len(paths) > 0 {
+		result, err = scan(paths, cfg, opts)
+	}
 	if err != nil {
 		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
 		return 1
 	}
+	if parsed.ListSkipped {
+		if err := writer.PrintSkipped(result.SkippedFiles); err != nil {
+			_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	// --strict fails the scan for files skipped with an error, after the
+	// findings are reported.
+	skipErrors := 0
+	if opts.ErrorPolicy == scanner.ErrorPolicyWarn || parsed.Strict {
+		level := "warning"
+		if parsed.Strict {
+			level = "error"
+		}
+		for _, skipped := range result.SkippedFiles {
+			if skipped.Reason == scanner.SkipError {
+				_, _ = fmt.Fprintf(stderr, "%s: skipped %s: %s\n", level, skipped.Path, skipped.Detail)
+				skipErrors++
+			}
+		}
+	}
+	if parsed.Since != "" {
+		if err := keepChangedLines(&result, sinceLines, opts.DisplayRoot); err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+			return 1
+		}
+	}
+	if parsed.Baseline != "" {
+		base.Apply(&result)
+	}
+	if filtered {
+		result.Limit(cfg.MaxFindingsPerFile)
+	}
+	if expired := expiredAllowFindings(cfg.AllowEntries, time.Now()); len(expired) > 0 {
+		result.Merge(expired)
+	}
+	if parsed.Strict {
+		for i := range result.Findings {
+			result.Findings[i].Severity = scanner.SeverityError
+		}
+		result.Merge(nil)
+	}
+	if err := attachOwners(&result, opts.DisplayRoot); err != nil {
+		_, _ = fmt.Fprintf(stderr, "codeowners error: %v\n", err)
+		return 1
+	}
+	if parsed.Fix {
+		// Keep JSON output parseable by reporting fixes on stderr.
+		report := stdout
+		if format != output.FormatHuman {
+			report = stderr
+		}
+		if err := fixFindings(&result, fix.Strategy(cfg.InvalidUTF8Fix), fix.Mode(cfg.FixMode), opts.DisplayRoot, parsed.FixDryRun, report); err != nil {
+			_, _ = fmt.Fprintf(stderr, "fix error: %v\n", err)
+			return 1
+		}
+	}
 
-	writer := output.New(parsed.JSON, parsed.NoColor || os.Getenv("NO_COLOR") != "", stdout, stderr)
-	if err := writer.PrintScan(result, output.ScanOptions{Verbose: parsed.Verbose, FixRequested: parsed.Fix}); err != nil {
+	if parsed.FileURIs {
+		if err := attachURIs(&result, opts.DisplayRoot); err != nil {
+			_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+			return 1
+		}
+	}
+	if err := writer.PrintScan(result, output.ScanOptions{Verbose: verbose, FixRequested: parsed.Fix, Messages: len(cfg.MessageTemplates) > 0, GroupByOwner: parsed.GroupByOwner, Version: Version}); err != nil {
 		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
 		return 1
 	}
+	if parsed.Store != "" {
+		// Scans outside a git checkout are stored without a commit.
+		sha, _ := git.HeadSHA(".")
+		rec := store.Record{Time: time.Now(), GitSHA: sha, Result: result}
+		if err := (store.Store{Path: parsed.Store}).Append(rec); err != nil {
+			_, _ = fmt.Fprintf(stderr, "store error: %v\n", err)
+			return 1
+		}
+	}
+	if cfg.NotifyWebhook != "" {
+		hook := publish.Webhook{URL: cfg.NotifyWebhook, IncludeFindings: cfg.NotifyIncludeFindings}
+		if err := hook.Notify(result); err != nil {
+			_, _ = fmt.Fprintf(stderr, "notify error: %v\n", err)
+			return 1
+		}
+	}
+	if parsed.Strict && skipErrors > 0 {
+		return 1
+	}
+	if parsed.ErrorOnNoFiles && result.Summary.FilesScanned == 0 {
+		_, _ = fmt.Fprintf(stderr, "scan error: no files matched the include and exclude patterns\n")
+		return exitNoFiles
+	}
 	if result.Summary.Findings > 0 {
 		return 1
 	}
 	return 0
 }
 
-func runInit(args []string, stdout, stderr io.Writer) int {
-	parsed, err := parseInitArgs(args)
+// scanOptions converts a validated config into scanner options.
+func scanOptions(cfg config.Config) scanner.Options {
+	sev := scanner.SeverityError
+	if cfg.Severity == config.SeverityWarning {
+		sev = scanner.SeverityWarning
+	}
+	return scanner.Options{
+		Root:               cfg.PatternRoot(),
+		Include:            cfg.Include,
+		Exclude:            cfg.Exclude,
+		AllowRunes:         config.AllowedRuneMap(cfg.Allow),
+		Severity:           sev,
+		IgnoreComments:     cfg.IgnoreComments,
+		IgnoreStrings:      cfg.IgnoreStrings,
+		AllowLatinExtended: cfg.AllowLatinExtended,
+		IgnoreURLs:         cfg.IgnoreURLs,
+		IgnoreBlobs:        cfg.IgnoreBlobs,
+		DecodeEscapes:      cfg.DecodeEscapes,
+		CheckEntities:      cfg.CheckEntities,
+		IgnoreCodeBlocks:   cfg.IgnoreCodeBlocks,
+		EditorConfig:       cfg.EditorConfig,
+		CheckCharset:       cfg.CheckCharset,
+		DedupeContent:      cfg.DedupeContent,
+		MinConfidence:      scanner.Confidence(cfg.MinConfidence),
+		AllowFilePatterns:  cfg.AllowFilePatterns,
+		IgnoreLinePatterns: scanLinePatterns(cfg.IgnoreLinePatterns),
+		AllowPatterns:      scanAllowPatterns(cfg.AllowPatterns),
+		AllowFiles:         scanAllowFiles(cfg.AllowFiles),
+		MmapThreshold:      cfg.MmapThreshold,
+		MaxFindingsPerFile: cfg.MaxFindingsPerFile,
+		Threads:            cfg.Threads,
+		Excerpts:           scanner.ExcerptMode(cfg.Excerpts),
+		MessageTemplates:   config.MessageTemplateMap(cfg.MessageTemplates),
+		HelpURIs:           config.MessageTemplateMap(cfg.HelpURIs),
+		Categories:         scanCategories(cfg.Categories),
+		Policies:           scanPolicies(cfg.Policies),
+		PathSeverities:     scanPathSeverities(cfg.FixturePaths, cfg.Paths),
+		HeadLimits:         scanHeadLimits(cfg.ScanHeadOnly),
+		LoadNestedConfig:   nestedConfigLoader(cfg),
+	}
+}
+
+// nestedConfigLoader reads the nested config files the scanner finds,
+// skipping one in the directory the config was loaded from. Only their allow
+// and exclude keys apply.
+func nestedConfigLoader(cfg config.Config) func(string) (scanner.NestedConfig, error) {
+	dir := ""
+	if cfg.Dir != "" {
+		dir, _ = filepath.Abs(cfg.Dir)
+	}
+	return func(path string) (scanner.NestedConfig, error) {
+		if filepath.Dir(path) == dir {
+			return scanner.NestedConfig{}, nil
+		}
+		nested, err := config.LoadNested(path)
+		if err != nil {
+			return scanner.NestedConfig{}, err
+		}
+		return scanner.NestedConfig{Exclude: nested.Exclude, AllowRunes: config.AllowedRuneMap(nested.Allow)}, nil
+	}
+}
+
+// scanLinePatterns compiles validated config ignore_line_patterns for the
+// scanner.
+func scanLinePatterns(patterns []string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		out = append(out, regexp.MustCompile(pattern))
+	}
+	return out
+}
+
+// scanAllowPatterns compiles validated config allow_patterns for the
+// scanner.
+func scanAllowPatterns(patterns []config.AllowPattern) []scanner.AllowPattern {
+	out := make([]scanner.AllowPattern, 0, len(patterns))
+	for _, p := range patterns {
+		out = append(out, scanner.AllowPattern{Pattern: regexp.MustCompile(p.Pattern), Categories: p.Categories})
+	}
+	return out
+}
+
+// scanAllowFiles converts validated config allow_files for the scanner.
+func scanAllowFiles(files []config.AllowFile) []scanner.AllowFile {
+	out := make([]scanner.AllowFile, 0, len(files))
+	for _, a := range files {
+		out = append(out, scanner.AllowFile{Path: a.Path, SHA256: a.SHA256})
+	}
+	return out
+}
+
+// scanPathSeverities converts validated config paths for the scanner,
+// after warning entries for the fixture patterns so paths entries win.
+func scanPathSeverities(fixtures []string, paths []config.PathSeverity) []scanner.PathSeverity {
+	out := make([]scanner.PathSeverity, 0, len(fixtures)+len(paths))
+	for _, pattern := range fixtures {
+		out = append(out, scanner.PathSeverity{Pattern: pattern, Severity: scanner.SeverityWarning})
+	}
+	for _, p := range paths {
+		out = append(out, scanner.PathSeverity{Pattern: p.Pattern, Severity: scanner.Severity(p.Severity)})
+	}
+	return out
+}
+
+// scanHeadLimits converts config scan_head_only entries for the scanner.
+func scanHeadLimits(limits []config.HeadLimit) []scanner.HeadLimit {
+	out := make([]scanner.HeadLimit, 0, len(limits))
+	for _, h := range limits {
+		out = append(out, scanner.HeadLimit{Pattern: h.Pattern, Bytes: h.Bytes, Lines: h.Lines})
+	}
+	return out
+}
+
+// scanPolicies converts validated config policies for the scanner.
+func scanPolicies(policies []config.Policy) []scanner.Policy {
+	out := make([]scanner.Policy, 0, len(policies))
+	for _, p := range policies {
+		levels := make(map[string]scanner.Severity)
+		for category, level := range config.PolicyLevels(p.Categories) {
+			levels[category] = scanner.Severity(level)
+		}
+		out = append(out, scanner.Policy{Paths: p.Paths, Regions: p.Regions, Levels: levels})
+	}
+	return out
+}
+
+// scanCategories converts validated config categories for the scanner.
+func scanCategories(categories []config.Category) []scanner.Category {
+	out := make([]scanner.Category, 0, len(categories))
+	for _, c := range categories {
+		category := scanner.Category{Name: c.Name, Severity: scanner.Severity(c.Severity), Fix: c.Fix}
+		for _, value := range c.Ranges {
+			lo, hi, _ := config.ParseRuneRange(value)
+			category.Ranges = append(category.Ranges, scanner.RuneRange{Lo: lo, Hi: hi})
+		}
+		out = append(out, category)
+	}
+	return out
+}
+
+// scan runs the built-in checks and then the plugins configured in cfg.
+func scan(paths []string, cfg config.Config, opts scanner.Options) (scanner.Result, error) {
+	result, err := scanner.Scan(paths, opts)
+	if err != nil || len(cfg.Plugins) == 0 {
+		return result, err
+	}
+	plugins := make([]plugin.Plugin, 0, len(cfg.Plugins))
+	for _, command := range cfg.Plugins {
+		p, err := plugin.Parse(command)
+		if err != nil {
+			return result, err
+		}
+		plugins = append(plugins, p)
+	}
+	if err := plugin.Run(plugins, &result, opts.Severity, opts.DisplayRoot); err != nil {
+		return result, err
+	}
+	for i, f := range result.Findings {
+		if f.HelpURI == "" {
+			result.Findings[i].HelpURI = opts.HelpURI(f.Category)
+		}
+	}
+	return result, nil
+}
+
+// applyPathStyle sets how opts reports paths from a --paths value. Without
+// one, paths are relative to the root of the enclosing git checkout, so they
+// do not depend on where englint runs.
+func applyPathStyle(opts *scanner.Options, style string) error {
+	switch {
+	case style == "":
+		opts.DisplayRoot = gitDisplayRoot()
+	case style == "relative":
+	case style == "absolute":
+		opts.AbsolutePaths = true
+	case strings.HasPrefix(style, "relative-to=") && len(style) > len("relative-to="):
+		opts.DisplayRoot = strings.TrimPrefix(style, "relative-to=")
+	default:
+		return fmt.Errorf("--paths must be relative, absolute, or relative-to=<dir>")
+	}
+	return nil
+}
+
+// gitDisplayRoot returns the top-level directory of the git checkout
+// containing the working directory, or "" outside a checkout. git resolves
+// symlinks in the root, so it is spelled through the working directory's own
+// path to keep the display paths of files below it relative.
+func gitDisplayRoot() string {
+	cwd, err := os.Getwd()
 	if err != nil {
-		_, _ = fmt.Fprintf(stderr, "init argument error: %v\n", err)
-		return 1
+		return ""
 	}
-	if _, err := os.Stat(parsed.ConfigPath); err == nil {
-		_, _ = fmt.Fprintf(stderr, "config file already exists: %s\n", parsed.ConfigPath)
-		return 1
-	} else if !os.IsNotExist(err) {
-		_, _ = fmt.Fprintf(stderr, "failed to check config file: %v\n", err)
-		return 1
+	root, err := git.Root(cwd)
+	if err != nil {
+		return ""
 	}
-	if err := config.WriteDefault(parsed.ConfigPath); err != nil {
-		_, _ = fmt.Fprintf(stderr, "failed to create config: %v\n", err)
-		return 1
+	root = filepath.FromSlash(root)
+	real, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return root
 	}
-	_, _ = fmt.Fprintf(stdout, "Created %s\n", parsed.ConfigPath)
-	return 0
+	rel, err := filepath.Rel(real, root)
+	if err != nil {
+		return root
+	}
+	return filepath.Join(cwd, rel)
 }
 
-func printUsage(w io.Writer) {
-	_, _ = fmt.Fprintln(w, "englint - detect non-English text in source files")
-	_, _ = fmt.Fprintln(w, "")
-	_, _ = fmt.Fprintln(w, "Usage:")
-	_, _ = fmt.Fprintln(w, "  englint scan [paths...] [flags]")
-	_, _ = fmt.Fprintln(w, "  englint init [--config <path>]")
-	_, _ = fmt.Fprintln(w, "  englint version")
+// changedPaths returns the absolute paths of the files git reports as
+// changed from HEAD or untracked, limited to those below one of paths when
+// any are given.
+func changedPaths(paths []string) ([]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	changed, err := git.Changed(cwd)
+	if err != nil {
+		return nil, err
+	}
+	return changedFiles(changed, paths)
+}
+
+// changedSincePaths is changedPaths for the files changed since the merge
+// base of rev and HEAD, returning their added and modified lines by path
+// relative to the repository root as well.
+func changedSincePaths(rev string, paths []string) ([]string, map[string][]git.LineRange, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := git.ChangedSince(cwd, rev)
+	if err != nil {
+		return nil, nil, err
+	}
+	changed := make([]string, 0, len(lines))
+	for name := range lines {
+		changed = append(changed, name)
+	}
+	slices.Sort(changed)
+	files, err := changedFiles(changed, paths)
+	return files, lines, err
+}
+
+// changedFiles returns the changed files, given relative to the repository
+// root, that still exist and are below one of paths, or all of them when
+// there are no paths.
+func changedFiles(changed, paths []string) ([]string, error) {
+	limits := make([]string, 0, len(paths))
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, abs)
+	}
+	root := gitDisplayRoot()
+	var out []string
+	for _, name := range changed {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		// Submodules are reported as changed directories.
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		if len(limits) > 0 && !slices.ContainsFunc(limits, func(limit string) bool {
+			rel, err := filepath.Rel(limit, path)
+			return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+		}) {
+			continue
+		}
+		out = append(out, path)
+	}
+	return out, nil
+}
+
+// applyAllow adds an --allow value to the characters opts allows: a code
+// point or range such as U+00E9 or U+2500..U+257F, a single character, or a
+// Unicode script name such as Greek.
+func applyAllow(opts *scanner.Options, value string) error {
+	if strings.HasPrefix(strings.ToUpper(value), "U+") {
+		lo, hi, err := config.ParseRuneRange(value)
+		if err != nil {
+			return fmt.Errorf("--allow: %w", err)
+		}
+		if lo == hi {
+			opts.AllowRunes[lo] = struct{}{}
+			return nil
+		}
+		// A table keeps wide ranges such as U+0000..U+10FFFF out of the
+		// rune map, which nested configs copy.
+		opts.AllowScripts = append(opts.AllowScripts, &unicode.RangeTable{
+			R32: []unicode.Range32{{Lo: uint32(lo), Hi: uint32(hi), Stride: 1}},
+		})
+		return nil
+	}
+	if r, size := utf8.DecodeRuneInString(value); size > 0 && size == len(value) && r != utf8.RuneError {
+		opts.AllowRunes[r] = struct{}{}
+		return nil
+	}
+	for name, table := range unicode.Scripts {
+		if strings.EqualFold(name, value) {
+			opts.AllowScripts = append(opts.AllowScripts, table)
+			return nil
+		}
+	}
+	return fmt.Errorf("--allow %q must be a character, a code point such as U+00E9, or a Unicode script such as Greek", value)
+}
+
+// jsonFormat returns the output format of commands that only take --json.
+func jsonFormat(json bool) output.Format {
+	if json {
+		return output.FormatJSON
+	}
+	return output.FormatHuman
+}
+
+// fixFindings rewrites the files of result in place: it replaces every
+// character that has a fix, as fixText finds it, and repairs invalid UTF-8
+// using strategy. It drops the findings it fixed and reports each fixed
+// file to w. With dryRun, it writes the changes to w as a diff instead and
+// keeps the findings. Relative finding paths are relative to base or the
+// working directory.
+func fixFindings(result *scanner.Result, strategy fix.Strategy, mode fix.Mode, base string, dryRun bool, w io.Writer) error {
+	if strategy == "" {
+		strategy = fix.Replace
+	}
+	var order []string
+	plans := make(map[string]*fixPlan)
+	for _, f := range result.Findings {
+		invalid := f.Category == "Invalid UTF-8"
+		text, ok := fixText(f, mode)
+		if !ok && !invalid {
+			continue
+		}
+		plan := plans[f.Path]
+		if plan == nil {
+			path := f.Path
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(base, path)
+			}
+			plan = &fixPlan{path: path}
+			plans[f.Path] = plan
+			order = append(order, f.Path)
+		}
+		if invalid {
+			plan.invalidUTF8++
+		} else {
+			plan.replacements = append(plan.replacements, fix.Replacement{Line: f.Line, Column: f.Column, Old: f.Character, New: text})
+		}
+	}
+	type position struct {
+		path         string
+		line, column int
+	}
+	fixed := make(map[position]bool)
+	repaired := make(map[string]bool)
+	for _, name := range order {
+		plan := plans[name]
+		data, content, applied, n, err := applyFixPlan(plan, strategy)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			if _, err := w.Write(fix.Patch(patchPath(plan.path), data, content)); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(applied) == 0 && n == 0 {
+			continue
+		}
+		if err := fix.WriteFile(plan.path, content); err != nil {
+			return err
+		}
+		if n > 0 {
+			repaired[name] = true
+			if _, err := fmt.Fprintf(w, "fixed %s: %d invalid UTF-8 byte(s) (%s)\n", name, n, strategy); err != nil {
+				return err
+			}
+		}
+		if len(applied) > 0 {
+			for _, r := range applied {
+				fixed[position{name, r.Line, r.Column}] = true
+			}
+			if _, err := fmt.Fprintf(w, "fixed %s: %d character(s)\n", name, len(applied)); err != nil {
+				return err
+			}
+		}
+	}
+	if len(fixed) == 0 && len(repaired) == 0 {
+		return nil
+	}
+	kept := result.Findings[:0]
+	for _, f := range result.Findings {
+		if f.Category == "Invalid UTF-8" && repaired[f.Path] || fixed[position{f.Path, f.Line, f.Column}] {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	result.Findings = kept
+	result.Merge(nil)
+	return nil
+}
+
+// fixText returns the text that replaces the character of f: its suggested
+// fix, nothing for a format character, or else its substitute in mode.
+// Characters decoded from escapes and entities have none, since they do not
+// appear literally in the file.
+func fixText(f scanner.Finding, mode fix.Mode) (string, bool) {
+	if f.Escape != "" || f.Category == "Invalid UTF-8" {
+		return "", false
+	}
+	if f.Fix != "" {
+		return f.Fix, true
+	}
+	if r, size := utf8.DecodeRuneInString(f.Character); size == len(f.Character) && scanner.IsFormatCharacter(r) {
+		return "", true
+	}
+	return mode.Substitute(f.Character)
+}
+
+// expiredAllowFindings reports the structured allow entries whose expiry
+// date has passed as warnings at their line in the config file.
+func expiredAllowFindings(entries []config.AllowEntry, now time.Time) []scanner.Finding {
+	var out []scanner.Finding
+	for _, e := range entries {
+		if !e.Expired(now) {
+			continue
+		}
+		message := fmt.Sprintf("Allow entry %q expired on %s", e.Value, e.Expires)
+		if e.Reason != "" {
+			message += " (" + e.Reason + ")"
+		}
+		codePoint := ""
+		if r, size := utf8.DecodeRuneInString(e.Value); size == len(e.Value) {
+			codePoint = fmt.Sprintf("U+%04X", r)
+		}
+		out = append(out, scanner.Finding{
+			Path:      e.Path,
+			Line:      e.Line,
+			Column:    1,
+			Character: e.Value,
+			CodePoint: codePoint,
+			Category:  "Expired Allow",
+			Severity:  scanner.SeverityWarning,
+			Message:   message,
+		})
+	}
+	return out
+}
+
+// attachURIs sets the file URI of every finding, whose relative paths are
+// relative to base or the working directory.
+func attachURIs(result *scanner.Result, base string) error {
+	dir, err := filepath.Abs(base)
+	if err != nil {
+		return err
+	}
+	for i, f := range result.Findings {
+		path := f.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		result.Findings[i].URI = scanner.FileURI(path, f.Line)
+	}
+	return nil
+}
+
+// attachOwners sets the CODEOWNERS owners of every finding, whose relative
+// paths are relative to base or the working directory. CODEOWNERS is looked
+// up at the root of the git checkout, or in the working directory outside
+// one; without it findings are left unchanged.
+func attachOwners(result *scanner.Result, base string) error {
+	if len(result.Findings) == 0 {
+		return nil
+	}
+	root, repoPath, err := repoPaths(base)
+	if err != nil {
+		return err
+	}
+	owners, ok, err := codeowners.Load(root)
+	if err != nil || !ok {
+		return err
+	}
+	for i, f := range result.Findings {
+		if rel, ok := repoPath(f.Path); ok {
+			result.Findings[i].Owners = owners.Owners(rel)
+		}
+	}
+	return nil
+}
+
+// keepChangedLines drops the findings of result that are not on a line
+// that lines, keyed by path relative to the repository root, lists as
+// added or modified.
+func keepChangedLines(result *scanner.Result, lines map[string][]git.LineRange, base string) error {
+	_, repoPath, err := repoPaths(base)
+	if err != nil {
+		return err
+	}
+	kept := result.Findings[:0]
+	for _, f := range result.Findings {
+		rel, ok := repoPath(f.Path)
+		if ok && slices.ContainsFunc(lines[rel], func(r git.LineRange) bool { return r.Contains(f.Line) }) {
+			kept = append(kept, f)
+		}
+	}
+	result.Findings = kept
+	result.Merge(nil)
+	return nil
+}
+
+// repoPaths returns the root of the git checkout containing the working
+// directory, or the working directory outside one, and a function that
+// maps display paths, relative to base or absolute, to slash-separated
+// paths relative to that root. It reports false for paths outside the root.
+func repoPaths(base string) (string, func(path string) (string, bool), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	dir := cwd
+	if base != "" {
+		if dir, err = filepath.Abs(base); err != nil {
+			return "", nil, err
+		}
+	}
+	root, err := git.Root(cwd)
+	if err != nil {
+		root = cwd
+	}
+	// git reports the root with symlinks resolved, so display paths are
+	// resolved against the real directory.
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	return root, func(path string) (string, bool) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}, nil
+}
+
+type mcpArgs struct {
+	ConfigPath string
+}
+
+func parseMCPArgs(args []string) (mcpArgs, error) {
+	var out mcpArgs
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		switch {
+		case arg == "--config":
+			if i+1 >= len(args) {
+				return mcpArgs{}, fmt.Errorf("flag --config requires a value")
+			}
+			i++
+			out.ConfigPath = args[i]
+		case strings.HasPrefix(arg, "--config="):
+			out.ConfigPath = strings.TrimPrefix(arg, "--config=")
+		default:
+			return mcpArgs{}, fmt.Errorf("unknown flag for mcp: %s", arg)
+		}
+	}
+	return out, nil
+}
+
+func runMCP(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseMCPArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "mcp argument error: %v\n", err)
+		return 1
+	}
+	if _, err := loadConfig(parsed.ConfigPath); err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	// allow writes to the config file that takes precedence, loaded on its
+	// own so per-user and environment settings are not copied into it.
+	paths := defaultConfigPaths([]string{parsed.ConfigPath})
+	allowPath := paths[len(paths)-1]
+
+	// The config is reloaded for every call so allow-list updates made
+	// through the server, or by hand, take effect immediately.
+	server := mcp.Server{
+		Name:    "englint",
+		Version: Version,
+		Handlers: mcp.Handlers{
+			Scan: func(paths []string) (scanner.Result, error) {
+				cfg, err := loadConfig(parsed.ConfigPath)
+				if err != nil {
+					return scanner.Result{}, err
+				}
+				if len(paths) == 0 {
+					paths = []string{"."}
+				}
+				return scan(paths, cfg, commandOptions(cfg))
+			},
+			Explain: func(path string) (scanner.Explanation, error) {
+				cfg, err := loadConfig(parsed.ConfigPath)
+				if err != nil {
+					return scanner.Explanation{}, err
+				}
+				return scanner.Explain(path, commandOptions(cfg))
+			},
+			Allow: func(values []string) ([]string, error) {
+				cfg, err := config.Load(allowPath)
+				if err != nil {
+					return nil, err
+				}
+				for _, value := range values {
+					if !containsString(cfg.Allow, value) {
+						cfg.Allow = append(cfg.Allow, value)
+					}
+				}
+				if err := config.Save(allowPath, cfg); err != nil {
+					return nil, err
+				}
+				return cfg.Allow, nil
+			},
+			Fix: func(paths []string, dryRun bool) (string, error) {
+				cfg, err := loadConfig(parsed.ConfigPath)
+				if err != nil {
+					return "", err
+				}
+				if len(paths) == 0 {
+					paths = []string{"."}
+				}
+				opts := commandOptions(cfg)
+				opts.MaxFindingsPerFile = 0
+				result, err := scan(paths, cfg, opts)
+				if err != nil {
+					return "", err
+				}
+				var changes strings.Builder
+				err = fixFindings(&result, fix.Strategy(cfg.InvalidUTF8Fix), fix.Mode(cfg.FixMode), opts.DisplayRoot, dryRun, &changes)
+				return changes.String(), err
+			},
+		},
+	}
+	if err := server.Serve(stdin, stdout); err != nil {
+		_, _ = fmt.Fprintf(stderr, "mcp error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+type reportArgs struct {
+	Target     string
+	ConfigPath string
+	PR         int
+	MR         int
+	Commit     string
+	Limit      int
+	Paths      []string
+}
+
+func parseReportArgs(args []string) (reportArgs, error) {
+	var out reportArgs
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return reportArgs{}, fmt.Errorf("report requires a target (github-pr|gitlab-mr|bitbucket)")
+	}
+	out.Target = args[0]
+	switch out.Target {
+	case "github-pr", "gitlab-mr", "bitbucket":
+	default:
+		return reportArgs{}, fmt.Errorf("unknown report target: %s", out.Target)
+	}
+
+	intValue := func(flag, value string) (int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("flag %s requires a non-negative integer", flag)
+		}
+		return n, nil
+	}
+
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		arg := strings.TrimSpace(rest[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--" {
+			out.Paths = append(out.Paths, rest[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			out.Paths = append(out.Paths, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--config", "--pr", "--mr", "--commit", "--limit":
+		default:
+			return reportArgs{}, fmt.Errorf("unknown flag for report: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(rest) {
+				return reportArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = rest[i]
+		}
+		var err error
+		switch name {
+		case "--config":
+			out.ConfigPath = value
+		case "--pr":
+			out.PR, err = intValue(name, value)
+		case "--mr":
+			out.MR, err = intValue(name, value)
+		case "--commit":
+			out.Commit = value
+		case "--limit":
+			out.Limit, err = intValue(name, value)
+		}
+		if err != nil {
+			return reportArgs{}, err
+		}
+	}
+
+	if out.Target == "github-pr" && out.PR == 0 {
+		return reportArgs{}, fmt.Errorf("github-pr requires --pr <number>")
+	}
+	if len(out.Paths) == 0 {
+		out.Paths = []string{"."}
+	}
+	return out, nil
+}
+
+// changedLines returns the lines each file of the repository in the working
+// directory changed since base.
+func changedLines(base string) (map[string][]git.LineRange, error) {
+	return git.ChangedSince(".", base)
+}
+
+func runReport(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseReportArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "report argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(parsed.ConfigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	result, err := scan(parsed.Paths, cfg, commandOptions(cfg))
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+
+	var publisher publish.Publisher
+	switch parsed.Target {
+	case "gitlab-mr":
+		mr := parsed.MR
+		if mr == 0 {
+			mr, _ = strconv.Atoi(os.Getenv("CI_MERGE_REQUEST_IID"))
+		}
+		publisher = publish.GitLab{
+			APIURL:       os.Getenv("CI_API_V4_URL"),
+			Token:        os.Getenv("GITLAB_TOKEN"),
+			Project:      os.Getenv("CI_PROJECT_ID"),
+			MR:           mr,
+			Limit:        parsed.Limit,
+			ChangedLines: changedLines,
+		}
+	case "bitbucket":
+		commit := parsed.Commit
+		if commit == "" {
+			commit = os.Getenv("BITBUCKET_COMMIT")
+		}
+		if serverURL := os.Getenv("BITBUCKET_SERVER_URL"); serverURL != "" {
+			publisher = publish.Bitbucket{
+				APIURL: serverURL,
+				Server: true,
+				Token:  os.Getenv("BITBUCKET_TOKEN"),
+				Owner:  os.Getenv("BITBUCKET_PROJECT_KEY"),
+				Repo:   os.Getenv("BITBUCKET_REPO_SLUG"),
+				Commit: commit,
+				Limit:  parsed.Limit,
+			}
+			break
+		}
+		publisher = publish.Bitbucket{
+			APIURL: os.Getenv("BITBUCKET_API_URL"),
+			Token:  os.Getenv("BITBUCKET_TOKEN"),
+			Owner:  os.Getenv("BITBUCKET_WORKSPACE"),
+			Repo:   os.Getenv("BITBUCKET_REPO_SLUG"),
+			Commit: commit,
+			Limit:  parsed.Limit,
+		}
+	default:
+		publisher = publish.GitHub{
+			APIURL:       os.Getenv("GITHUB_API_URL"),
+			Token:        os.Getenv("GITHUB_TOKEN"),
+			Repo:         os.Getenv("GITHUB_REPOSITORY"),
+			PR:           parsed.PR,
+			Limit:        parsed.Limit,
+			ChangedLines: changedLines,
+		}
+	}
+	published, err := publisher.Publish(result.Findings)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "report error: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(stdout, "Posted %d comment(s): updated=%d resolved=%d duplicates=%d limited=%d findings=%d\n",
+		published.Posted, published.Updated, published.Resolved, published.Duplicates, published.Limited, result.Summary.Findings)
+	if result.Summary.Findings > 0 {
+		return 1
+	}
+	return 0
+}
+
+type annotateArgs struct {
+	ConfigPath string
+	Reason     string
+	Paths      []string
+}
+
+func parseAnnotateArgs(args []string) (annotateArgs, error) {
+	var out annotateArgs
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--" {
+			out.Paths = append(out.Paths, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			out.Paths = append(out.Paths, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--config" && name != "--reason" {
+			return annotateArgs{}, fmt.Errorf("unknown flag for annotate: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return annotateArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == "--config" {
+			out.ConfigPath = value
+			continue
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return annotateArgs{}, fmt.Errorf("flag --reason must be a single line")
+		}
+		out.Reason = strings.TrimSpace(value)
+	}
+	if len(out.Paths) == 0 {
+		out.Paths = []string{"."}
+	}
+	return out, nil
+}
+
+func runAnnotate(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseAnnotateArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "annotate argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(parsed.ConfigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	opts := commandOptions(cfg)
+	opts.MaxFindingsPerFile = 0
+	result, err := scan(parsed.Paths, cfg, opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+	annotated, err := annotate.Annotate(result.Findings, parsed.Reason, opts.DisplayRoot)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "annotate error: %v\n", err)
+		return 1
+	}
+	for _, path := range annotated.Unsupported {
+		_, _ = fmt.Fprintf(stderr, "annotate: skipped %s: no known comment syntax\n", path)
+	}
+	for _, f := range annotated.NotAnnotatable {
+		_, _ = fmt.Fprintf(stderr, "annotate: skipped %s:%d:%d: line starts inside a string or comment\n", f.Path, f.Line, f.Column)
+	}
+	_, _ = fmt.Fprintf(stdout, "Annotated %d line(s) in %d file(s)\n", annotated.Lines, annotated.Files)
+	if len(annotated.Unsupported) > 0 || len(annotated.NotAnnotatable) > 0 {
+		return 1
+	}
+	return 0
+}
+
+type fixArgs struct {
+	ConfigPath string
+	Strategy   fix.Strategy
+	// Mode substitutes characters without a suggested fix; empty leaves
+	// them to the config's fix_mode.
+	Mode        fix.Mode
+	Categories  []string
+	DryRun      bool
+	Interactive bool
+	// Patch is where to write the fixes as a patch instead of applying
+	// them.
+	Patch string
+	Paths []string
+}
+
+func parseFixArgs(args []string) (fixArgs, error) {
+	var out fixArgs
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--" {
+			out.Paths = append(out.Paths, args[i+1:]...)
+			break
+		}
+		switch arg {
+		case "--dry-run":
+			out.DryRun = true
+			continue
+		case "--interactive", "-i":
+			out.Interactive = true
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			out.Paths = append(out.Paths, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--config", "--strategy", "--mode", "--categories", "--patch":
+		default:
+			return fixArgs{}, fmt.Errorf("unknown flag for fix: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return fixArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--config":
+			out.ConfigPath = value
+		case "--strategy":
+			out.Strategy = fix.Strategy(strings.ToLower(strings.TrimSpace(value)))
+			if !slices.Contains(fix.Strategies, out.Strategy) {
+				return fixArgs{}, fmt.Errorf("--strategy must be replace, strip, or legacy")
+			}
+		case "--mode":
+			out.Mode = fix.Mode(strings.ToLower(strings.TrimSpace(value)))
+			if !slices.Contains(fix.Modes, out.Mode) {
+				return fixArgs{}, fmt.Errorf("--mode must be transliterate, question, or delete")
+			}
+		case "--patch":
+			out.Patch = value
+		case "--categories":
+			for _, category := range strings.Split(value, ",") {
+				if category = strings.TrimSpace(category); category != "" {
+					out.Categories = append(out.Categories, category)
+				}
+			}
+		}
+	}
+	if out.DryRun && out.Interactive {
+		return fixArgs{}, fmt.Errorf("--dry-run and --interactive cannot be combined")
+	}
+	if out.DryRun && out.Patch != "" {
+		return fixArgs{}, fmt.Errorf("--dry-run and --patch cannot be combined")
+	}
+	if len(out.Paths) == 0 {
+		out.Paths = []string{"."}
+	}
+	return out, nil
+}
+
+// fixPlan holds the repairs englint fix or scan --fix makes to one file.
+type fixPlan struct {
+	path         string
+	replacements []fix.Replacement
+	invalidUTF8  int
+}
+
+// runFix rewrites files to apply the suggested replacement of every finding
+// that has one, or its substitute in the fix mode, and repairs invalid
+// UTF-8. Unlike scan --fix, it can limit the fixes to some categories and
+// confirm each change first.
+func runFix(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseFixArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "fix argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(parsed.ConfigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	strategy := parsed.Strategy
+	if strategy == "" {
+		strategy = fix.Strategy(cfg.InvalidUTF8Fix)
+	}
+	if strategy == "" {
+		strategy = fix.Replace
+	}
+	mode := parsed.Mode
+	if mode == "" {
+		mode = fix.Mode(cfg.FixMode)
+	}
+	opts := commandOptions(cfg)
+	opts.MaxFindingsPerFile = 0
+	result, err := scan(parsed.Paths, cfg, opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+
+	selected := func(category string) bool {
+		return len(parsed.Categories) == 0 || slices.ContainsFunc(parsed.Categories, func(c string) bool {
+			return scanner.InCategory(category, c)
+		})
+	}
+	var plans []*fixPlan
+	byPath := make(map[string]*fixPlan)
+	unfixable := 0
+	repairOffered := make(map[string]bool)
+	var answers *bufio.Scanner
+	if parsed.Interactive {
+		answers = bufio.NewScanner(stdin)
+	}
+	acceptAll := false
+	for _, f := range result.Findings {
+		if !selected(f.Category) {
+			continue
+		}
+		invalid := f.Category == "Invalid UTF-8"
+		text, ok := fixText(f, mode)
+		if !ok && !invalid {
+			unfixable++
+			continue
+		}
+		plan := byPath[f.Path]
+		// Invalid UTF-8 is repaired for the whole file at once, so only its
+		// first invalid byte is offered.
+		if invalid && repairOffered[f.Path] {
+			if plan != nil && plan.invalidUTF8 > 0 {
+				plan.invalidUTF8++
+			}
+			continue
+		}
+		if invalid {
+			repairOffered[f.Path] = true
+		}
+		change := fmt.Sprintf("%s:%d:%d: replace %q (%s) with %q", f.Path, f.Line, f.Column, f.Character, f.CodePoint, text)
+		if invalid {
+			change = fmt.Sprintf("%s: repair invalid UTF-8 (%s)", f.Path, strategy)
+		}
+		if answers != nil && !acceptAll {
+			answer, ok := confirm(stdout, answers, change+"? [y,n,a,q] ")
+			if !ok || answer == "q" {
+				break
+			}
+			if answer == "a" {
+				acceptAll = true
+			} else if answer != "y" {
+				continue
+			}
+		}
+		if plan == nil {
+			path := f.Path
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(opts.DisplayRoot, path)
+			}
+			plan = &fixPlan{path: path}
+			byPath[f.Path] = plan
+			plans = append(plans, plan)
+		}
+		if invalid {
+			plan.invalidUTF8++
+		} else {
+			plan.replacements = append(plan.replacements, fix.Replacement{Line: f.Line, Column: f.Column, Old: f.Character, New: text})
+		}
+		if answers == nil {
+			_, _ = fmt.Fprintln(stdout, change)
+		}
+	}
+
+	fixed, files := 0, 0
+	var patch bytes.Buffer
+	for _, plan := range plans {
+		if parsed.DryRun {
+			fixed += len(plan.replacements) + plan.invalidUTF8
+			files++
+			continue
+		}
+		data, content, applied, repaired, err := applyFixPlan(plan, strategy)
+		n := len(applied) + repaired
+		if err == nil && n > 0 {
+			if parsed.Patch != "" {
+				patch.Write(fix.Patch(patchPath(plan.path), data, content))
+			} else {
+				err = fix.WriteFile(plan.path, content)
+			}
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "fix error: %v\n", err)
+			return 1
+		}
+		if skipped := len(plan.replacements) + plan.invalidUTF8 - n; skipped > 0 {
+			_, _ = fmt.Fprintf(stderr, "fix: skipped %d change(s) in %s: the file changed since it was scanned\n", skipped, plan.path)
+		}
+		if n > 0 {
+			fixed += n
+			files++
+		}
+	}
+	verb := "Fixed"
+	switch {
+	case parsed.DryRun:
+		verb = "Would fix"
+	case parsed.Patch != "":
+		if err := os.WriteFile(parsed.Patch, patch.Bytes(), 0o644); err != nil {
+			_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+			return 1
+		}
+		verb = "Wrote a patch to " + parsed.Patch + " fixing"
+	}
+	_, _ = fmt.Fprintf(stdout, "%s %d finding(s) in %d file(s)", verb, fixed, files)
+	if unfixable > 0 {
+		_, _ = fmt.Fprintf(stdout, "; %d finding(s) have no automatic fix", unfixable)
+	}
+	_, _ = fmt.Fprintln(stdout)
+	return 0
+}
+
+// confirm asks prompt on w and returns the first letter of the answer read
+// from answers, lower-cased, or false at the end of input.
+func confirm(w io.Writer, answers *bufio.Scanner, prompt string) (string, bool) {
+	_, _ = fmt.Fprint(w, prompt)
+	if !answers.Scan() {
+		_, _ = fmt.Fprintln(w)
+		return "", false
+	}
+	answer := strings.ToLower(strings.TrimSpace(answers.Text()))
+	if answer == "" {
+		return "n", true
+	}
+	return answer[:1], true
+}
+
+// applyFixPlan reads the file of plan and returns its content before and
+// after the fixes, the replacements whose character was still in place,
+// and the number of invalid UTF-8 bytes repaired.
+func applyFixPlan(plan *fixPlan, strategy fix.Strategy) ([]byte, []byte, []fix.Replacement, int, error) {
+	data, err := os.ReadFile(plan.path)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	fixed, applied := fix.ReplaceCharacters(data, plan.replacements)
+	repaired := 0
+	if plan.invalidUTF8 > 0 {
+		fixed, repaired = fix.InvalidUTF8(fixed, strategy)
+	}
+	return data, fixed, applied, repaired, nil
+}
+
+// patchPath returns path as git apply expects it in a patch: relative to
+// the root of the git checkout containing the working directory, or to the
+// working directory outside a checkout.
+func patchPath(path string) string {
+	if root := gitDisplayRoot(); root != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+type suggestAllowArgs struct {
+	ConfigPath string
+	MinCount   int
+	MinFiles   int
+	JSON       bool
+	Paths      []string
+}
+
+func parseSuggestAllowArgs(args []string) (suggestAllowArgs, error) {
+	out := suggestAllowArgs{MinCount: suggest.DefaultMinCount, MinFiles: suggest.DefaultMinFiles}
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--" {
+			out.Paths = append(out.Paths, args[i+1:]...)
+			break
+		}
+		if arg == "--json" {
+			out.JSON = true
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			out.Paths = append(out.Paths, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--config", "--min-count", "--min-files":
+		default:
+			return suggestAllowArgs{}, fmt.Errorf("unknown flag for suggest-allow: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return suggestAllowArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == "--config" {
+			out.ConfigPath = value
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return suggestAllowArgs{}, fmt.Errorf("flag %s requires a positive integer", name)
+		}
+		if name == "--min-count" {
+			out.MinCount = n
+		} else {
+			out.MinFiles = n
+		}
+	}
+	if len(out.Paths) == 0 {
+		out.Paths = []string{"."}
+	}
+	return out, nil
+}
+
+func runSuggestAllow(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseSuggestAllowArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "suggest-allow argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(parsed.ConfigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	opts := commandOptions(cfg)
+	opts.MaxFindingsPerFile = 0
+	result, err := scan(parsed.Paths, cfg, opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+	suggestions := suggest.Allow(result.Findings, suggest.Options{MinCount: parsed.MinCount, MinFiles: parsed.MinFiles})
+	writer := output.New(jsonFormat(parsed.JSON), true, stdout, stderr)
+	if err := writer.PrintSuggestions(cfg.Allow, cfg.AllowEntries, suggestions); err != nil {
+		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+type badgeArgs struct {
+	ConfigPath string
+	Output     string
+	// Format is svg or json, the shields.io endpoint format.
+	Format string
+	Label  string
+	Paths  []string
+}
+
+func parseBadgeArgs(args []string) (badgeArgs, error) {
+	out := badgeArgs{Label: badge.DefaultLabel}
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--" {
+			out.Paths = append(out.Paths, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			out.Paths = append(out.Paths, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--config", "--output", "--format", "--label":
+		default:
+			return badgeArgs{}, fmt.Errorf("unknown flag for badge: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return badgeArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--config":
+			out.ConfigPath = value
+		case "--output":
+			out.Output = value
+		case "--format":
+			out.Format = value
+		case "--label":
+			out.Label = value
+		}
+	}
+	if out.Format == "" {
+		out.Format = "svg"
+		if strings.EqualFold(filepath.Ext(out.Output), ".json") {
+			out.Format = "json"
+		}
+	}
+	if out.Format != "svg" && out.Format != "json" {
+		return badgeArgs{}, fmt.Errorf("--format must be svg or json")
+	}
+	if len(out.Paths) == 0 {
+		out.Paths = []string{"."}
+	}
+	return out, nil
+}
+
+// runBadge writes a badge for the scan to --output or stdout. It succeeds
+// whether or not there are findings, since the badge reports them.
+func runBadge(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseBadgeArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "badge argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(parsed.ConfigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	result, err := scan(parsed.Paths, cfg, commandOptions(cfg))
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+	b := badge.FromResult(parsed.Label, result)
+	data := b.SVG()
+	if parsed.Format == "json" {
+		if data, err = b.Endpoint(); err != nil {
+			_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+			return 1
+		}
+	}
+	if parsed.Output == "" {
+		_, err = stdout.Write(data)
+	} else {
+		err = os.WriteFile(parsed.Output, data, 0o644)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// baselineArgs holds the flags of englint baseline.
+type baselineArgs struct {
+	// ConfigPaths are the --config files in order, as for scan.
+	ConfigPaths []string
+	Output      string
+	// PathStyle is the --paths value, as for scan, so the recorded paths
+	// match those of the scans the baseline is used with.
+	PathStyle string
+	Paths     []string
+}
+
+func parseBaselineArgs(args []string) (baselineArgs, error) {
+	out := baselineArgs{Output: baseline.DefaultPath}
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--" {
+			out.Paths = append(out.Paths, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			out.Paths = append(out.Paths, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--config", "--output", "--paths":
+		default:
+			return baselineArgs{}, fmt.Errorf("unknown flag for baseline: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return baselineArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if strings.TrimSpace(value) == "" {
+			return baselineArgs{}, fmt.Errorf("flag %s requires a value", name)
+		}
+		switch name {
+		case "--config":
+			out.ConfigPaths = append(out.ConfigPaths, value)
+		case "--output":
+			out.Output = value
+		case "--paths":
+			out.PathStyle = value
+		}
+	}
+	out.ConfigPaths = defaultConfigPaths(out.ConfigPaths)
+	return out, nil
+}
+
+// runBaseline scans like scan and records every finding in a baseline file,
+// replacing any earlier one, for scan --baseline to suppress.
+func runBaseline(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseBaselineArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "baseline argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := config.LoadEnv(os.Getenv, parsed.ConfigPaths)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	opts := scanOptions(cfg)
+	opts.MaxFindingsPerFile = 0
+	if err := applyPathStyle(&opts, parsed.PathStyle); err != nil {
+		_, _ = fmt.Fprintf(stderr, "baseline argument error: %v\n", err)
+		return 1
+	}
+	result, err := scan(parsed.Paths, cfg, opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+	if err := baseline.New(result.Findings).Write(parsed.Output); err != nil {
+		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(stdout, "Wrote %d finding(s) to %s\n", len(result.Findings), parsed.Output)
+	return 0
+}
+
+// validateArgs holds the flags of englint validate.
+type validateArgs struct {
+	// ConfigPaths are the --config files in order, as for scan.
+	ConfigPaths []string
+}
+
+func parseValidateArgs(args []string) (validateArgs, error) {
+	var out validateArgs
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--config" {
+			return validateArgs{}, fmt.Errorf("unknown flag for validate: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return validateArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		out.ConfigPaths = append(out.ConfigPaths, value)
+	}
+	out.ConfigPaths = defaultConfigPaths(out.ConfigPaths)
+	return out, nil
+}
+
+// runValidate loads the config the way scan does, reports whether it is
+// valid, and prints the likely mistakes config.Lint finds as warnings,
+// which do not change the exit status.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseValidateArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "validate argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := config.LoadEnv(os.Getenv, parsed.ConfigPaths)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	cfg = config.ApplyDefaults(cfg)
+	if err := config.Validate(cfg); err != nil {
+		_, _ = fmt.Fprintf(stderr, "config validation error: %v\n", err)
+		return 1
+	}
+	warnings := config.Lint(cfg)
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(stdout, "warning: %s\n", warning)
+	}
+	if len(warnings) == 0 {
+		_, _ = fmt.Fprintln(stdout, "Config is valid")
+		return 0
+	}
+	_, _ = fmt.Fprintf(stdout, "Config is valid with %d warning(s)\n", len(warnings))
+	return 0
+}
+
+type historyArgs struct {
+	Store string
+	Limit int
+	JSON  bool
+}
+
+func parseHistoryArgs(args []string) (historyArgs, error) {
+	out := historyArgs{Limit: 20}
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--json" {
+			out.JSON = true
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--store" && name != "--limit" {
+			return historyArgs{}, fmt.Errorf("unknown flag for history: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return historyArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == "--store" {
+			out.Store = value
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return historyArgs{}, fmt.Errorf("flag --limit requires a non-negative integer")
+		}
+		out.Limit = n
+	}
+	if strings.TrimSpace(out.Store) == "" {
+		return historyArgs{}, fmt.Errorf("history requires --store <path>")
+	}
+	return out, nil
+}
+
+func runHistory(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseHistoryArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "history argument error: %v\n", err)
+		return 1
+	}
+	scans, err := store.Store{Path: parsed.Store}.History(parsed.Limit)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "store error: %v\n", err)
+		return 1
+	}
+	writer := output.New(jsonFormat(parsed.JSON), true, stdout, stderr)
+	if err := writer.PrintHistory(scans); err != nil {
+		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+type diffArgs struct {
+	Old     string
+	New     string
+	JSON    bool
+	NoColor bool
+}
+
+func parseDiffArgs(args []string) (diffArgs, error) {
+	var out diffArgs
+	var files []string
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			out.JSON = true
+		case arg == "--no-color":
+			out.NoColor = true
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			return diffArgs{}, fmt.Errorf("unknown flag for diff: %s", arg)
+		default:
+			files = append(files, arg)
+		}
+	}
+	if len(files) != 2 {
+		return diffArgs{}, fmt.Errorf("diff requires <old.json> and <new.json>")
+	}
+	out.Old, out.New = files[0], files[1]
+	return out, nil
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseDiffArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff argument error: %v\n", err)
+		return 1
+	}
+	previous, err := diff.LoadFindings(parsed.Old)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff error: %v\n", err)
+		return 1
+	}
+	current, err := diff.LoadFindings(parsed.New)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff error: %v\n", err)
+		return 1
+	}
+
+	result := diff.Compare(previous, current)
+	writer := output.New(jsonFormat(parsed.JSON), parsed.NoColor || os.Getenv("NO_COLOR") != "", stdout, stderr)
+	if err := writer.PrintDiff(result); err != nil {
+		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+		return 1
+	}
+	if len(result.Added) > 0 {
+		return 1
+	}
+	return 0
+}
+
+type trendArgs struct {
+	ConfigPath string
+	Since      time.Time
+	Until      time.Time
+	Step       string
+	Rev        string
+	JSON       bool
+}
+
+func parseTrendArgs(args []string, now time.Time) (trendArgs, error) {
+	out := trendArgs{Step: trend.StepMonthly, Rev: "HEAD"}
+	out.Until = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		if arg == "--json" {
+			out.JSON = true
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--config", "--since", "--until", "--step", "--rev":
+		default:
+			return trendArgs{}, fmt.Errorf("unknown flag for trend: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return trendArgs{}, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		var err error
+		switch name {
+		case "--config":
+			out.ConfigPath = value
+		case "--since":
+			out.Since, err = time.Parse(time.DateOnly, value)
+		case "--until":
+			out.Until, err = time.Parse(time.DateOnly, value)
+		case "--step":
+			out.Step = strings.ToLower(value)
+		case "--rev":
+			out.Rev = value
+		}
+		if err != nil {
+			return trendArgs{}, fmt.Errorf("flag %s requires a YYYY-MM-DD date", name)
+		}
+	}
+	if out.Since.IsZero() {
+		return trendArgs{}, fmt.Errorf("trend requires --since <YYYY-MM-DD>")
+	}
+	return out, nil
+}
+
+func runTrend(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseTrendArgs(args, time.Now().UTC())
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "trend argument error: %v\n", err)
+		return 1
+	}
+	cfg, err := loadConfig(parsed.ConfigPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	root, err := git.Run(".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "trend error: %v\n", err)
+		return 1
+	}
+
+	// Blobs are scanned by their path in the repository, so no display
+	// root applies. Every finding counts toward the trend, so none are
+	// omitted.
+	opts := scanOptions(cfg)
+	opts.MaxFindingsPerFile = 0
+	points, err := trend.Run(trend.Options{
+		Dir:   root,
+		Rev:   parsed.Rev,
+		Since: parsed.Since,
+		Until: parsed.Until,
+		Step:  parsed.Step,
+		Scan:  opts,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "trend error: %v\n", err)
+		return 1
+	}
+	writer := output.New(jsonFormat(parsed.JSON), true, stdout, stderr)
+	if err := writer.PrintTrend(points); err != nil {
+		_, _ = fmt.Fprintf(stderr, "output error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runInit(args []string, stdout, stderr io.Writer) int {
+	parsed, err := parseInitArgs(args)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "init argument error: %v\n", err)
+		return 1
+	}
+	if _, err := os.Stat(parsed.ConfigPath); err == nil {
+		if !parsed.Force {
+			_, _ = fmt.Fprintf(stderr, "config file already exists: %s (use --force to overwrite)\n", parsed.ConfigPath)
+			return 1
+		}
+	} else if !os.IsNotExist(err) {
+		_, _ = fmt.Fprintf(stderr, "failed to check config file: %v\n", err)
+		return 1
+	}
+	if parsed.Interactive {
+		return runInitWizard(parsed.ConfigPath, stdout, stderr)
+	}
+	if err := config.WriteTemplate(parsed.ConfigPath, parsed.Template); err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to create config: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(stdout, "Created %s\n", parsed.ConfigPath)
+	return 0
+}
+
+// runInitWizard asks about the repository on stdout, reading answers from
+// stdin, and saves the config config.InitConfig builds from them to path.
+// It then offers a first scan and to allow the characters it finds, so only
+// new findings are reported.
+func runInitWizard(path string, stdout, stderr io.Writer) int {
+	answers := bufio.NewScanner(stdin)
+	dir := filepath.Dir(path)
+	detected := config.DetectLanguages(dir)
+	if len(detected) == 0 {
+		detected = []string{"go", "typescript"}
+	}
+	var picked config.InitAnswers
+	for {
+		answer := ask(stdout, answers, "Languages in this repository ("+strings.Join(config.LanguageNames(), ", ")+")?", strings.Join(detected, ", "))
+		picked.Languages = nil
+		for _, name := range strings.Split(strings.ToLower(answer), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				picked.Languages = append(picked.Languages, name)
+			}
+		}
+		_, err := config.InitConfig(config.InitAnswers{Languages: picked.Languages, Docs: "error"})
+		if err == nil {
+			break
+		}
+		_, _ = fmt.Fprintln(stdout, err)
+	}
+	for {
+		answer := strings.ToLower(ask(stdout, answers, "Check documentation such as Markdown files (error, warning, off)?", "warning"))
+		if i := slices.IndexFunc(config.DocsPolicies, func(p string) bool { return strings.HasPrefix(p, answer) }); i >= 0 {
+			picked.Docs = config.DocsPolicies[i]
+			break
+		}
+		_, _ = fmt.Fprintf(stdout, "docs must be one of %s\n", strings.Join(config.DocsPolicies, ", "))
+	}
+	picked.AllowEmoji = yes(ask(stdout, answers, "Allow emoji?", "n"))
+	cfg, err := config.InitConfig(picked)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "init error: %v\n", err)
+		return 1
+	}
+	if err := config.Save(path, cfg); err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to create config: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(stdout, "Created %s\n", path)
+
+	if !yes(ask(stdout, answers, "Run a first scan now?", "y")) {
+		return 0
+	}
+	if cfg, err = config.Load(path); err != nil {
+		_, _ = fmt.Fprintf(stderr, "config error: %v\n", err)
+		return 1
+	}
+	opts := scanOptions(cfg)
+	opts.MaxFindingsPerFile = 0
+	result, err := scan([]string{dir}, cfg, opts)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "scan error: %v\n", err)
+		return 1
+	}
+	var chars []string
+	for _, f := range result.Findings {
+		if f.Category != "Invalid UTF-8" && utf8.RuneCountInString(f.Character) == 1 && !slices.Contains(chars, f.Character) {
+			chars = append(chars, f.Character)
+		}
+	}
+	_, _ = fmt.Fprintf(stdout, "Found %d finding(s) of %d character(s) in %d file(s)\n", len(result.Findings), len(chars), result.Summary.FilesScanned)
+	if len(chars) == 0 || !yes(ask(stdout, answers, "Allow these characters so only new findings are reported?", "n")) {
+		return 0
+	}
+	for _, char := range chars {
+		cfg.Allow = append(cfg.Allow, char)
+		cfg.AllowEntries = append(cfg.AllowEntries, config.AllowEntry{Value: char, Reason: "found by englint init"})
+	}
+	if err := config.Save(path, cfg); err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to update config: %v\n", err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(stdout, "Allowed %d character(s) in %s\n", len(chars), path)
+	return 0
+}
+
+// ask asks prompt on w, offering def, and returns the trimmed answer read
+// from answers, or def when the answer is empty or input ends.
+func ask(w io.Writer, answers *bufio.Scanner, prompt, def string) string {
+	_, _ = fmt.Fprintf(w, "%s [%s] ", prompt, def)
+	if !answers.Scan() {
+		_, _ = fmt.Fprintln(w)
+		return def
+	}
+	if answer := strings.TrimSpace(answers.Text()); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// yes reports whether answer starts with y or Y.
+func yes(answer string) bool {
+	return strings.HasPrefix(strings.ToLower(answer), "y")
+}
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, lang.T(i18n.Tagline))
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, lang.T(i18n.Usage))
+	_, _ = fmt.Fprintln(w, "  englint scan [paths...] [flags]")
+	_, _ = fmt.Fprintln(w, "  englint init [--template <name> | --interactive] [--force] [--config <path>]")
+	_, _ = fmt.Fprintln(w, "  englint mcp [--config <path>]")
+	_, _ = fmt.Fprintln(w, "  englint report github-pr --pr <n> [--limit <n>] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint report gitlab-mr [--mr <iid>] [--limit <n>] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint report bitbucket [--commit <sha>] [--limit <n>] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint diff <old.json> <new.json> [--json] [--no-color]")
+	_, _ = fmt.Fprintln(w, "  englint trend --since <date> [--until <date>] [--step daily|weekly|monthly] [--json]")
+	_, _ = fmt.Fprintln(w, "  englint history --store <path> [--limit <n>] [--json]")
+	_, _ = fmt.Fprintln(w, "  englint annotate [--reason <text>] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint fix [--strategy <mode>] [--mode <mode>] [--categories <list>] [--dry-run] [--interactive] [--patch <path>] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint suggest-allow [--min-count <n>] [--min-files <n>] [--json] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint badge [--output <path>] [--format svg|json] [--label <text>] [--config <path>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint validate [--config <path>]")
+	_, _ = fmt.Fprintln(w, "  englint baseline [--output <path>] [--config <path>] [--paths <style>] [paths...]")
+	_, _ = fmt.Fprintln(w, "  englint version")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, lang.T(i18n.GlobalFlags))
+	_, _ = fmt.Fprintln(w, "  --lang <code>                Message language (default: LC_ALL, LC_MESSAGES, or LANG)")
 	_, _ = fmt.Fprintln(w, "")
 	printScanUsage(w)
 }
 
 func printScanUsage(w io.Writer) {
-	_, _ = fmt.Fprintln(w, "Scan flags:")
-	_, _ = fmt.Fprintln(w, "  --config <path>          Config file path (default: .englint.yaml)")
-	_, _ = fmt.Fprintln(w, "  --exclude <glob>         Exclude glob pattern (repeatable)")
-	_, _ = fmt.Fprintln(w, "  --include <glob>         Include glob pattern (repeatable)")
-	_, _ = fmt.Fprintln(w, "  --json                   JSON output")
-	_, _ = fmt.Fprintln(w, "  --fix                    Auto-fix placeholder mode")
-	_, _ = fmt.Fprintln(w, "  --severity <level>       Default severity: error|warning")
-	_, _ = fmt.Fprintln(w, "  --no-color               Disable color output")
-	_, _ = fmt.Fprintln(w, "  --verbose                Show all scanned and skipped files")
+	_, _ = fmt.Fprintln(w, lang.T(i18n.ScanFlags))
+	_, _ = fmt.Fprintln(w, "  --config <path>              Config file path, repeatable (default: .englint.yaml)")
+	_, _ = fmt.Fprintln(w, "  --allow <value>              Also allow a character, U+XXXX code point, or script (repeatable)")
+	_, _ = fmt.Fprintln(w, "  --exclude <glob>             Exclude glob pattern (repeatable)")
+	_, _ = fmt.Fprintln(w, "  --include <glob>             Include glob pattern (repeatable)")
+	_, _ = fmt.Fprintln(w, "  --format <name>              Output format: human|json|sarif|problem-matcher (help lists formats)")
+	_, _ = fmt.Fprintln(w, "  --problem-matcher            Same as --format=problem-matcher")
+	_, _ = fmt.Fprintln(w, "  --json                       Same as --format=json (deprecated)")
+	_, _ = fmt.Fprintln(w, "  --fix                        Replace characters that have a fix and repair invalid UTF-8")
+	_, _ = fmt.Fprintln(w, "  --fix-dry-run                Print the changes --fix would make as a diff")
+	_, _ = fmt.Fprintln(w, "  --fix-mode <mode>            Replace other characters with --fix: transliterate|question|delete")
+	_, _ = fmt.Fprintln(w, "  --baseline <path>            Report only findings the baseline file does not record")
+	_, _ = fmt.Fprintln(w, "  --invalid-utf8-fix <mode>    Repair invalid UTF-8 with --fix: replace|strip|legacy")
+	_, _ = fmt.Fprintln(w, "  --severity <level>           Default severity: error|warning")
+	_, _ = fmt.Fprintln(w, "  --severity <CAT=level>       Category level: off|warning|error (repeatable)")
+	_, _ = fmt.Fprintln(w, "  --excerpts <mode>            Line excerpts: full|omit|redact")
+	_, _ = fmt.Fprintln(w, "  --max-findings-per-file <n>  Report at most n findings per file")
+	_, _ = fmt.Fprintln(w, "  --min-confidence <level>     Drop findings below low|medium|high confidence")
+	_, _ = fmt.Fprintln(w, "  --mmap-threshold <size>      Memory-map files at least this large (e.g. 64MB)")
+	_, _ = fmt.Fprintln(w, "  --threads, --jobs <n>        Scan at most n files at once (default: one per CPU, 1 for sequential)")
+	_, _ = fmt.Fprintln(w, "  --store <path>               Append findings and summary to a SQLite database")
+	_, _ = fmt.Fprintln(w, "  --notify-webhook <url>       POST a summary to url when findings are reported")
+	_, _ = fmt.Fprintln(w, "  --notify-findings            Include all findings in the webhook payload")
+	_, _ = fmt.Fprintln(w, "  --no-color                   Disable color output")
+	_, _ = fmt.Fprintln(w, "  --allow-latin-extended       Allow accented Latin letters such as é and ü")
+	_, _ = fmt.Fprintln(w, "  --ignore-comments            Skip comments (--no-ignore-comments to scan them)")
+	_, _ = fmt.Fprintln(w, "  --ignore-strings             Skip string literals (--no-ignore-strings to scan them)")
+	_, _ = fmt.Fprintln(w, "  --ignore-urls                Skip characters inside URLs and email addresses")
+	_, _ = fmt.Fprintln(w, "  --ignore-blobs               Skip characters inside base64/hex blobs and data URIs")
+	_, _ = fmt.Fprintln(w, "  --decode-escapes             Report non-English characters written as escapes in strings")
+	_, _ = fmt.Fprintln(w, "  --check-entities             Report HTML entities such as &nbsp; in HTML and Markdown")
+	_, _ = fmt.Fprintln(w, "  --ignore-code-blocks         Skip code blocks in Markdown, reStructuredText, and AsciiDoc")
+	_, _ = fmt.Fprintln(w, "  --editorconfig               Decode files from their .editorconfig charset")
+	_, _ = fmt.Fprintln(w, "  --check-charset              Report files that disagree with their .editorconfig charset")
+	_, _ = fmt.Fprintln(w, "  --dedupe-content             Report findings in files with identical content once")
+	_, _ = fmt.Fprintln(w, "  --group-by-owner             Report findings per CODEOWNERS owner")
+	_, _ = fmt.Fprintln(w, "  --paths <style>              Report paths relative to the git root (default), relative, absolute, or relative-to=<dir>")
+	_, _ = fmt.Fprintln(w, "  --file-uris                  Report locations as file:///path#L12 URIs")
+	_, _ = fmt.Fprintln(w, "  --error-policy <policy>      Unreadable files: skip (default), warn, or abort")
+	_, _ = fmt.Fprintln(w, "  --changed                    Scan only files changed from HEAD or untracked in git")
+	_, _ = fmt.Fprintln(w, "  --since <ref>                Report only findings on lines changed since the merge base with ref")
+	_, _ = fmt.Fprintln(w, "  --error-on-no-files          Exit 2 when no files match the include and exclude patterns")
+	_, _ = fmt.Fprintln(w, "  --strict                     Report warnings as errors and fail on files skipped with an error")
+	_, _ = fmt.Fprintln(w, "  --verbose                    Show all scanned and skipped files")
+	_, _ = fmt.Fprintln(w, "  --list-skipped               Print only the skipped files with reasons and evidence")
+	_, _ = fmt.Fprintln(w, "  --why <path>                 Explain which rule scans or skips a file (repeatable)")
 }
@@ -4,12 +4,34 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/TT-AIXion/englint/internal/baseline"
+	"github.com/TT-AIXion/englint/internal/config"
+	"github.com/TT-AIXion/englint/internal/output"
+	"github.com/TT-AIXion/englint/internal/scanner"
 )
 
+// TestMain clears the locale and hides the per-user config so output
+// assertions see English messages and default settings regardless of the
+// developer's environment.
+func TestMain(m *testing.M) {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		_ = os.Unsetenv(name)
+	}
+	_ = os.Setenv("XDG_CONFIG_HOME", filepath.Join(os.TempDir(), "englint-test-no-user-config"))
+	os.Exit(m.Run())
+}
+
 type failWriter struct{}
 
 func (failWriter) Write([]byte) (int, error) {
@@ -39,7 +61,7 @@ func TestParseScanArgs(t *testing.T) {
 			name: "flags and paths",
 			args: []string{"src", "--json", "--config", "cfg.yaml", "--exclude", "vendor/**", "--include=**/*.go", "--severity", "warning", "--fix", "--no-color", "--verbose"},
 			check: func(t *testing.T, got scanArgs) {
-				if !got.JSON || !got.Fix || !got.NoColor || !got.Verbose {
+				if got.Format != "json" || !got.Fix || !got.NoColor || !got.Verbose {
 					t.Fatalf("expected bool flags true: %+v", got)
 				}
 				if got.ConfigPath != "cfg.yaml" {
@@ -215,6 +237,73 @@ func TestRunInit(t *testing.T) {
 	if !strings.Contains(errBuf.String(), "already exists") {
 		t.Fatalf("expected existing file error")
 	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"init", "--config", configPath, "--template=security", "--force"}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected forced init success, got %d, err=%s", code, errBuf.String())
+	}
+	sourcePath := filepath.Join(tmp, "auth.py")
+	if err := os.WriteFile(sourcePath, []byte("access = \"user\u202e \u2066# admin\u2069 \u2066\"\nname = \"José\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", tmp}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected bidi controls to fail the scan, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "Bidi Control") || strings.Count(out.String(), "ERROR") != 4 {
+		t.Fatalf("expected bidi control errors, got:\n%s", out.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"init", "--config", configPath, "--template", "python", "--force"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), `unknown template "python"`) {
+		t.Fatalf("expected unknown template error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunInitInteractive(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(filepath.Join(tmp, "a.go"), []byte("// 日本 🎉\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	origStdin := stdin
+	defer func() { stdin = origStdin }()
+	stdin = strings.NewReader("cobol\n\nnever\noff\nyes\n\ny\n")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"init", "--interactive", "--config", configPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected interactive init success, got %d: %s", code, errBuf.String())
+	}
+	for _, want := range []string{
+		"Languages in this repository (go, javascript,",
+		"swift)? [go] ",
+		`unknown language "cobol"`,
+		"docs must be one of error, warning, off",
+		"Created " + configPath,
+		"Found 2 finding(s) of 2 character(s) in 1 file(s)",
+		"Allowed 2 character(s) in " + configPath,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Include, []string{"**/*.go"}) || len(cfg.AllowEntries) != 2 || cfg.AllowEntries[0].Value != "日" || cfg.AllowEntries[0].Reason != "found by englint init" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, tmp}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected a clean scan after allowing the findings, got %d:\n%s", code, out.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"init", "--interactive", "--template=go", "--config", configPath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "cannot be combined") {
+		t.Fatalf("expected flag error, got %d: %s", code, errBuf.String())
+	}
 }
 
 func TestRunInitErrors(t *testing.T) {
@@ -289,7 +378,7 @@ severity: error
 		t.Fatalf("expected scan with findings to return 1, got %d, err=%s", code, errBuf.String())
 	}
 	text := out.String()
-	for _, expected := range []string{"ERROR", "Summary:", "Auto-fix is not implemented yet.", "SCANNED"} {
+	for _, expected := range []string{"ERROR", "Summary:", "Some findings have no automatic fix.", "SCANNED"} {
 		if !strings.Contains(text, expected) {
 			t.Fatalf("expected output to contain %q\nactual:\n%s", expected, text)
 		}
@@ -318,45 +407,2631 @@ severity: error
 	}
 }
 
-func TestRunScanErrors(t *testing.T) {
+func TestRunScanWhy(t *testing.T) {
 	tmp := t.TempDir()
-	configPath := filepath.Join(tmp, "bad.yaml")
-	if err := os.WriteFile(configPath, []byte("severity: invalid\n"), 0o644); err != nil {
-		t.Fatalf("write bad config: %v", err)
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(configPath, []byte("include:\n  - \"**/*.go\"\nexclude:\n  - \"**/sample.go\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
 	}
+
 	var out bytes.Buffer
 	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--why", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected --why to succeed, got %d, err=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `skipped by exclude "**/sample.go"`) {
+		t.Fatalf("unexpected --why output: %s", out.String())
+	}
 
-	if code := runMain([]string{"scan", "--config", configPath, tmp}, &out, &errBuf); code != 1 {
-		t.Fatalf("expected validation error code")
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--why=" + filepath.Join(tmp, "missing.go")}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected --why failure for missing file")
 	}
-	if !strings.Contains(errBuf.String(), "config error") {
-		t.Fatalf("expected validation message, got: %s", errBuf.String())
+	if !strings.Contains(errBuf.String(), "explain error") {
+		t.Fatalf("expected explain error, got %s", errBuf.String())
+	}
+
+	if code := runMain([]string{"scan", "--config", configPath, "--why", sourcePath}, failWriter{}, &errBuf); code != 1 {
+		t.Fatalf("expected output error code")
+	}
+	if _, err := parseScanArgs([]string{"--why"}); err == nil {
+		t.Fatalf("expected missing --why value error")
+	}
+}
+
+func TestRunScanMmapThreshold(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--mmap-threshold", "1B", "--no-color", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings with mmap, got %d, err=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "findings=5") {
+		t.Fatalf("unexpected output: %s", out.String())
 	}
 
 	errBuf.Reset()
-	if code := runMain([]string{"scan", "--bad"}, &out, &errBuf); code != 1 {
-		t.Fatalf("expected argument error")
+	if code := runMain([]string{"scan", "--config", configPath, "--mmap-threshold=big", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected invalid threshold failure")
 	}
-	if !strings.Contains(errBuf.String(), "scan argument error") {
-		t.Fatalf("expected argument error message")
+	if !strings.Contains(errBuf.String(), "--mmap-threshold") {
+		t.Fatalf("expected threshold error, got %s", errBuf.String())
+	}
+	if _, err := parseScanArgs([]string{"--mmap-threshold"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+}
+
+func TestRunScanMaxFindingsPerFile(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--max-findings-per-file", "2", "--no-color", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d, err=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "findings=2 omitted=3") {
+		t.Fatalf("unexpected output: %s", out.String())
 	}
 
 	errBuf.Reset()
-	if code := runMain([]string{"scan", "--config", tmp, tmp}, &out, &errBuf); code != 1 {
-		t.Fatalf("expected config load/read error")
+	if code := runMain([]string{"scan", "--config", configPath, "--max-findings-per-file=lots", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected invalid limit failure")
 	}
-	if !strings.Contains(errBuf.String(), "config error") {
-		t.Fatalf("expected config load error")
+	if !strings.Contains(errBuf.String(), "--max-findings-per-file") {
+		t.Fatalf("expected limit error, got %s", errBuf.String())
+	}
+	if _, err := parseScanArgs([]string{"--max-findings-per-file"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+}
+
+func TestRunScanThreads(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"é\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--threads", "1", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d, err=%s", code, errBuf.String())
+	}
+	for _, threads := range []string{"--threads=many", "--threads=-2"} {
+		errBuf.Reset()
+		if code := runMain([]string{"scan", "--config", configPath, threads, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "threads") {
+			t.Fatalf("expected %s to fail, got %d: %s", threads, code, errBuf.String())
+		}
+	}
+	if _, err := parseScanArgs([]string{"--threads"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+	if parsed, err := parseScanArgs([]string{"--jobs", "8"}); err != nil || parsed.Threads != "8" {
+		t.Fatalf("expected --jobs to set threads, got %+v, %v", parsed, err)
+	}
+	if parsed, err := parseScanArgs([]string{"--jobs=3"}); err != nil || parsed.Threads != "3" {
+		t.Fatalf("expected --jobs= to set threads, got %+v, %v", parsed, err)
+	}
+}
+
+func TestRunLang(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"--lang", "ja", "scan", "--config", configPath, sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, errBuf.String())
+	}
+	if out.String() != "英語以外のテキストは見つかりませんでした。\n概要: スキャン=1 スキップ=0 検出=0\n" {
+		t.Fatalf("unexpected Japanese output: %q", out.String())
+	}
+
+	t.Setenv("LANG", "de_DE.UTF-8")
+	out.Reset()
+	if code := runMain([]string{"help"}, &out, &errBuf); code != 0 || !strings.HasPrefix(out.String(), "englint - findet nicht-englischen Text") {
+		t.Fatalf("expected German usage from LANG, got %d: %s", code, out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath, "--lang=en"}, &out, &errBuf); code != 0 || !strings.Contains(out.String(), "Summary: scanned=1") {
+		t.Fatalf("expected --lang to override LANG, got %d: %s", code, out.String())
 	}
 
 	errBuf.Reset()
-	if code := runMain([]string{"scan", "--config", filepath.Join(tmp, "missing.yaml"), filepath.Join(tmp, "does-not-exist")}, &out, &errBuf); code != 1 {
-		t.Fatalf("expected scan failure")
+	if code := runMain([]string{"--lang", "xx", "version"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "unsupported language \"xx\"") {
+		t.Fatalf("expected unsupported language error, got %d: %s", code, errBuf.String())
 	}
-	if !strings.Contains(errBuf.String(), "scan error") {
-		t.Fatalf("expected scan error")
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--lang"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "flag --lang requires a value") {
+		t.Fatalf("expected missing value error, got %d: %s", code, errBuf.String())
+	}
+	if args, value, err := extractLang([]string{"scan", "--", "--lang", "x"}); err != nil || value != "" || len(args) != 4 {
+		t.Fatalf("expected arguments after -- to be kept, got %v %q %v", args, value, err)
+	}
+}
+
+func TestRunScanPlugins(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	tmp := t.TempDir()
+	script := filepath.Join(tmp, "banned-terms")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncase \"$(cat)\" in *whitelist*) echo '{\"findings\":[{\"line\":2,\"column\":5,\"message\":\"use allowlist\"}]}' ;; *) echo '{\"findings\":[]}' ;; esac\n"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar whitelist = \"é\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("plugins:\n  - \""+script+"\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	var payload struct {
+		Summary struct {
+			Findings           int            `json:"findings"`
+			FindingsByCategory map[string]int `json:"findingsByCategory"`
+		} `json:"summary"`
+		Findings []struct {
+			Column   int    `json:"column"`
+			Category string `json:"category"`
+			Message  string `json:"message"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode scan: %v", err)
+	}
+	if payload.Summary.Findings != 2 || payload.Summary.FindingsByCategory["banned-terms"] != 1 || payload.Findings[0].Category != "banned-terms" || payload.Findings[0].Message != "use allowlist" || payload.Findings[1].Category != "Latin Extended" {
+		t.Fatalf("unexpected merged findings: %s", out.String())
+	}
+
+	if err := os.WriteFile(configPath, []byte("plugins:\n  - \"englint-missing-plugin\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "scan error: plugin englint-missing-plugin") {
+		t.Fatalf("expected plugin error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanAllowLatinExtended(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\n// café 中文\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--allow-latin-extended", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if strings.Contains(out.String(), "U+00E9") || !strings.Contains(out.String(), "findings=2") {
+		t.Fatalf("expected only CJK findings: %s", out.String())
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, ".englint.yaml"), []byte("allow_latin_extended: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", filepath.Join(tmp, ".englint.yaml"), sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(out.String(), "findings=2") {
+		t.Fatalf("expected config to allow Latin letters, got %d: %s", code, out.String())
+	}
+}
+
+func TestRunScanIgnoreURLs(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "README.md")
+	if err := os.WriteFile(sourcePath, []byte("See https://例え.jp or 用户@例子.广告 for 中文\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--ignore-urls", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "findings=2") {
+		t.Fatalf("expected only prose findings: %s", out.String())
+	}
+}
+
+func TestRunScanIgnoreBlobs(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "index.html")
+	if err := os.WriteFile(sourcePath, []byte("<img src=\"data:image/svg+xml,<svg>日本</svg>\"> 中\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--include", "**/*.html", "--ignore-blobs", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "findings=1") {
+		t.Fatalf("expected only the prose finding: %s", out.String())
+	}
+}
+
+func TestRunScanDecodeEscapes(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.ts")
+	if err := os.WriteFile(sourcePath, []byte(`const s = "\u4e2d";`+"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
 	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected escapes to pass by default, got %d: %s", code, out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--decode-escapes", "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"escape": "\\u4e2d"`) || !strings.Contains(out.String(), "U+4E2D") {
+		t.Fatalf("expected decoded escape finding: %s", out.String())
+	}
+}
+
+func TestRunScanCheckEntities(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "README.md")
+	if err := os.WriteFile(sourcePath, []byte("Ready&nbsp;to go &mdash; now\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected entities to pass by default, got %d: %s", code, out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--check-entities", "--fix", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `fix: replace with " "`) || !strings.Contains(out.String(), `fix: replace with "--"`) {
+		t.Fatalf("expected ASCII fixes: %s", out.String())
+	}
+}
+
+func TestRunScanIgnoreCodeBlocks(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "index.rst")
+	if err := os.WriteFile(sourcePath, []byte("Usage::\n\n    echo 日本\n\nDone 中\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--include", "**/*.rst", "--ignore-code-blocks", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "findings=1") || !strings.Contains(out.String(), ":5:6") {
+		t.Fatalf("expected only the prose finding: %s", out.String())
+	}
+}
+
+func TestRunScanEditorConfig(t *testing.T) {
+	tmp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmp, ".editorconfig"), []byte("root = true\n\n[*.go]\ncharset = latin1\n\n[*.md]\ncharset = utf-8-bom\n"), 0o644); err != nil {
+		t.Fatalf("write editorconfig: %v", err)
+	}
+	latin1Path := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(latin1Path, []byte("// caf\xe9\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	bomPath := filepath.Join(tmp, "README.md")
+	if err := os.WriteFile(bomPath, []byte("plain\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--editorconfig", "--json", latin1Path, bomPath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"character": "é"`) || strings.Contains(out.String(), "Charset Mismatch") {
+		t.Fatalf("expected decoded latin1 only: %s", out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--check-charset", "--json", bomPath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected charset mismatch, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "has no UTF-8 byte order mark but .editorconfig declares charset utf-8-bom") {
+		t.Fatalf("expected charset mismatch: %s", out.String())
+	}
+}
+
+func TestRunScanPathStyle(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "src", "main.go")
+	if err := os.MkdirAll(filepath.Dir(sourcePath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("// caf\xe9\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--paths=absolute", "--format=json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"path": "`+filepath.ToSlash(sourcePath)+`"`) {
+		t.Fatalf("expected absolute path: %s", out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--paths", "relative-to=" + tmp, "--fix", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected repaired file, got %d: %s %s", code, out.String(), errBuf.String())
+	}
+	if !strings.Contains(out.String(), "fixed src/main.go: 1 invalid UTF-8 byte(s)") {
+		t.Fatalf("expected path relative to %s: %s", tmp, out.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--paths=relative-to=", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "--paths must be relative, absolute, or relative-to=<dir>") {
+		t.Fatalf("expected invalid style error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanGitRootPaths(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	sub := filepath.Join(tmp, "pkg", "sub")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "a.go"), []byte("// 日本\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if output, err := exec.Command("git", "init", "-q", tmp).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.Chdir(sub); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"scan", "--format=json", "."}, want: `"path": "pkg/sub/a.go"`},
+		{args: []string{"scan", "--format=json", "--paths=relative", "."}, want: `"path": "a.go"`},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		if code := runMain(tt.args, &out, &errBuf); code != 1 {
+			t.Fatalf("%v: expected findings, got %d: %s", tt.args, code, errBuf.String())
+		}
+		if !strings.Contains(out.String(), tt.want) {
+			t.Fatalf("%v: expected %s: %s", tt.args, tt.want, out.String())
+		}
+	}
+}
+
+func TestRunCommandsSubdirectory(t *testing.T) {
+	for _, tool := range []string{"git", "sh"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed", tool)
+		}
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	sub := filepath.Join(tmp, "sub")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	script := filepath.Join(tmp, "check")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat >/dev/null; echo '{\"findings\":[{\"line\":1,\"message\":\"checked\"}]}'\n"), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, ".englint.yaml"), []byte("plugins:\n  - \""+script+"\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	sourcePath := filepath.Join(sub, "a.go")
+	if err := os.WriteFile(sourcePath, []byte("// é\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if output, err := exec.Command("git", "init", "-q", tmp).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.Chdir(sub); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{args: []string{"scan", "--problem-matcher", "."}, code: 1, want: "sub/a.go:1:1: error: checked"},
+		{args: []string{"fix", "--dry-run", "--mode", "transliterate", "."}, code: 0, want: `sub/a.go:1:4: replace "é" (U+00E9) with "e"`},
+		{args: []string{"annotate", "."}, code: 0, want: "Annotated 1 line(s) in 1 file(s)"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		if code := runMain(tt.args, &out, &errBuf); code != tt.code || !strings.Contains(out.String(), tt.want) {
+			t.Fatalf("%v: got %d, want %d and %q:\n%s%s", tt.args, code, tt.code, tt.want, out.String(), errBuf.String())
+		}
+	}
+	if data, _ := os.ReadFile(sourcePath); !strings.HasPrefix(string(data), "// englint:ignore") {
+		t.Fatalf("expected the annotation in %s: %q", sourcePath, data)
+	}
+}
+
+func TestRunScanChanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write("clean.go", "// 日本\n")
+	write("edited.go", "package main\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--changed", "--error-on-no-files"}, &out, &errBuf); code != exitNoFiles {
+		t.Fatalf("expected no changed files, got %d: %s%s", code, out.String(), errBuf.String())
+	}
+
+	write("edited.go", "// 中\n")
+	write("sub/new.go", "// 文\n")
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"scan", "--changed", "--format=json", "--verbose"}, want: []string{"edited.go", "sub/new.go"}},
+		{args: []string{"scan", "--changed", "--format=json", "--verbose", "sub"}, want: []string{"sub/new.go"}},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		errBuf.Reset()
+		if code := runMain(tt.args, &out, &errBuf); code != 1 {
+			t.Fatalf("%v: expected findings, got %d: %s", tt.args, code, errBuf.String())
+		}
+		var result scanner.Result
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if !reflect.DeepEqual(result.ScannedFiles, tt.want) {
+			t.Fatalf("%v: scanned %q, want %q", tt.args, result.ScannedFiles, tt.want)
+		}
+	}
+}
+
+func TestRunScanSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write("legacy.go", "// 日本\n// ok\n")
+	write("clean.go", "// 中\n")
+	git("init", "-q", "-b", "main")
+	git("add", "-A")
+	git("-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init")
+	git("checkout", "-q", "-b", "feature")
+	write("legacy.go", "// 日本\n// é\n")
+	git("add", "-A")
+	git("-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "edit")
+	write("sub/new.go", "// 文\n")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--since", "main", "--format=json", "--verbose"}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	var result scanner.Result
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if want := []string{"legacy.go", "sub/new.go"}; !reflect.DeepEqual(result.ScannedFiles, want) {
+		t.Fatalf("scanned %q, want %q", result.ScannedFiles, want)
+	}
+	var got []string
+	for _, f := range result.Findings {
+		got = append(got, fmt.Sprintf("%s:%d %s", f.Path, f.Line, f.Character))
+	}
+	if want := []string{"legacy.go:2 é", "sub/new.go:1 文"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("findings %q, want %q", got, want)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--since=main", "--changed"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "cannot be combined") {
+		t.Fatalf("expected conflict error, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--since", "no-such-branch"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "scan error: git merge-base") {
+		t.Fatalf("expected unknown revision error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanErrorOnNoFiles(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("include:\n  - \"**/*.og\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no files without flag", args: []string{"scan", "--config", configPath, tmp}, want: 0},
+		{name: "no files", args: []string{"scan", "--config", configPath, "--error-on-no-files", tmp}, want: exitNoFiles},
+		{name: "files", args: []string{"scan", "--config", configPath, "--include", "**/*.go", "--error-on-no-files", tmp}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var errBuf bytes.Buffer
+			if code := runMain(tt.args, &out, &errBuf); code != tt.want {
+				t.Fatalf("expected exit %d, got %d: %s", tt.want, code, errBuf.String())
+			}
+			if tt.want == exitNoFiles && !strings.Contains(errBuf.String(), "no files matched the include and exclude patterns") {
+				t.Fatalf("expected no files error: %s", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestRunScanFileURIs(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(sourcePath, []byte("package main\n// é\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	uri := "file://" + filepath.ToSlash(sourcePath) + "#L2"
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", "--file-uris", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "ERROR "+uri+" [Latin Extended]") {
+		t.Fatalf("expected URI in human output: %s", out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--file-uris", "--format=json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"uri": "`+uri+`"`) {
+		t.Fatalf("expected URI in JSON output: %s", out.String())
+	}
+}
+
+func TestRunScanErrorPolicy(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	blocked := filepath.Join(tmp, "blocked.go")
+	if err := os.WriteFile(blocked, []byte("package p\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.Chmod(blocked, 0o000); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	defer os.Chmod(blocked, 0o644)
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, tmp}, &out, &errBuf); code != 0 || errBuf.Len() != 0 {
+		t.Fatalf("expected unreadable file to be skipped, got %d: %s", code, errBuf.String())
+	}
+	if code := runMain([]string{"scan", "--config", configPath, "--error-policy=warn", tmp}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected warning only, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "warning: skipped "+blocked+": read error: permission denied") {
+		t.Fatalf("expected skip warning: %s", errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--strict", tmp}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected strict failure, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "error: skipped "+blocked+": read error: permission denied") {
+		t.Fatalf("expected skip error: %s", errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--error-policy", "abort", tmp}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "scan error: read ") {
+		t.Fatalf("expected scan error, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--error-policy=ignore", tmp}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "--error-policy must be") {
+		t.Fatalf("expected invalid policy error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanStrictSeverity(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(sourcePath, []byte("// é\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--severity", "warning", "--strict", "--format=json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"severity": "error"`) || strings.Contains(out.String(), `"severity": "warning"`) {
+		t.Fatalf("expected warnings reported as errors: %s", out.String())
+	}
+}
+
+func TestRunScanFixturePaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   string
+	}{
+		{
+			name:   "default",
+			config: "include:\n  - \"**/*.go\"\n",
+			want:   "main.go:1:4: error\ntestdata/a.go:1:4: warning\nx/__fixtures__/b.go:1:4: warning\n",
+		},
+		{
+			name:   "strict",
+			config: "include:\n  - \"**/*.go\"\n",
+			args:   []string{"--strict"},
+			want:   "main.go:1:4: error\ntestdata/a.go:1:4: error\nx/__fixtures__/b.go:1:4: error\n",
+		},
+		{
+			name:   "off",
+			config: "include:\n  - \"**/*.go\"\nfixture_paths: []\n",
+			want:   "main.go:1:4: error\ntestdata/a.go:1:4: error\nx/__fixtures__/b.go:1:4: error\n",
+		},
+		{
+			name:   "custom",
+			config: "include:\n  - \"**/*.go\"\nfixture_paths: [\"x/**\"]\n",
+			want:   "main.go:1:4: error\ntestdata/a.go:1:4: error\nx/__fixtures__/b.go:1:4: warning\n",
+		},
+		{
+			name:   "paths win",
+			config: "include:\n  - \"**/*.go\"\npaths: {\"testdata/**\": {severity: error}}\n",
+			want:   "main.go:1:4: error\ntestdata/a.go:1:4: error\nx/__fixtures__/b.go:1:4: warning\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			files := map[string]string{
+				".englint.yaml":       tc.config,
+				"main.go":             "// é\n",
+				"testdata/a.go":       "// é\n",
+				"x/__fixtures__/b.go": "// é\n",
+			}
+			for name, content := range files {
+				path := filepath.Join(tmp, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatalf("mkdir: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+			}
+
+			var out bytes.Buffer
+			var errBuf bytes.Buffer
+			args := append([]string{"scan", "--config", filepath.Join(tmp, ".englint.yaml"), "--paths=relative-to=" + tmp, "--problem-matcher"}, tc.args...)
+			if code := runMain(append(args, tmp), &out, &errBuf); code != 1 {
+				t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+			}
+			var got strings.Builder
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				location, _, _ := strings.Cut(line, ": Detected")
+				got.WriteString(filepath.ToSlash(location) + "\n")
+			}
+			if got.String() != tc.want {
+				t.Fatalf("unexpected severities:\n%s\nwant:\n%s", got.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestRunScanListSkipped(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	files := map[string]string{
+		"text.go":  "// 日本\n",
+		"image.go": "GIF89a\x00\x01",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--paths=relative-to=" + tmp, "--list-skipped", tmp}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected audit to succeed, got %d: %s", code, errBuf.String())
+	}
+	want := "PATH      REASON  DETAIL\nimage.go  binary  NUL byte at offset 6\n"
+	if out.String() != want {
+		t.Fatalf("unexpected skipped table:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
+
+func TestRunScanDedupeContent(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	for _, name := range []string{"vendor/a/x.go", "vendor/b/x.go", "main.go"} {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("// 日\n"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "other.go"), []byte("// 本\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--paths=relative-to=" + tmp, "--dedupe-content", tmp}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "main.go:1:4 [Han]") || !strings.Contains(out.String(), "  also in: vendor/a/x.go, vendor/b/x.go\n") {
+		t.Fatalf("expected one finding with duplicates:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "vendor/a/x.go:1") || !strings.Contains(out.String(), "other.go:1:4") {
+		t.Fatalf("expected duplicate findings to be folded:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "scanned=4 skipped=0 findings=2") {
+		t.Fatalf("expected duplicates to count as scanned:\n%s", out.String())
+	}
+}
+
+func TestRunScanFormat(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(sourcePath, []byte("// é\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--format=help"}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected format list, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "human ") || !strings.Contains(out.String(), "json ") {
+		t.Fatalf("unexpected format list: %s", out.String())
+	}
+	for _, args := range [][]string{{"--format", "JSON"}, {"--format=json"}, {"--json"}} {
+		out.Reset()
+		args = append([]string{"scan", "--config", configPath}, append(args, sourcePath)...)
+		if code := runMain(args, &out, &errBuf); code != 1 || !strings.Contains(out.String(), `"category": "Latin Extended"`) {
+			t.Fatalf("%v: expected JSON findings, got %d: %s", args, code, out.String())
+		}
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--format", "sarif", sourcePath}, &out, &errBuf); code != 1 ||
+		!strings.Contains(out.String(), `"version": "2.1.0"`) || !strings.Contains(out.String(), `"ruleId": "latin-extended"`) || !strings.Contains(out.String(), `"version": "`+Version+`"`) {
+		t.Fatalf("expected SARIF findings, got %d: %s", code, out.String())
+	}
+	for _, args := range [][]string{{"--format=problem-matcher"}, {"--problem-matcher"}} {
+		out.Reset()
+		args = append([]string{"scan", "--config", configPath}, append(args, sourcePath)...)
+		want := filepath.ToSlash(sourcePath) + ":1:4: error: Detected Latin Extended character \"é\" (U+00E9)\n"
+		if code := runMain(args, &out, &errBuf); code != 1 || out.String() != want {
+			t.Fatalf("%v: expected problem matcher line, got %d: %q, want %q", args, code, out.String(), want)
+		}
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--format=xml", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), `unknown format "xml" (see --format=help)`) {
+		t.Fatalf("expected unknown format error, got %d: %s", code, errBuf.String())
+	}
+	if code := runMain([]string{"scan", "--format"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "flag --format requires a value") {
+		t.Fatalf("expected missing value error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanFixInvalidUTF8(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(sourcePath, []byte("// caf\xe9 \x93ok\x94\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--fix", "--invalid-utf8-fix", "legacy", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected repaired file to pass, got %d: %s %s", code, out.String(), errBuf.String())
+	}
+	if !strings.Contains(out.String(), "fixed "+sourcePath+": 3 invalid UTF-8 byte(s) (legacy)") {
+		t.Fatalf("expected repair report: %s", out.String())
+	}
+	data, _ := os.ReadFile(sourcePath)
+	if string(data) != "// café “ok”\n" {
+		t.Fatalf("unexpected repaired file: %q", data)
+	}
+
+	if err := os.WriteFile(sourcePath, []byte("x := 1 // \xff\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	out.Reset()
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--fix", "--json", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected repaired file to pass, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "(replace)") || strings.Contains(out.String(), "Invalid UTF-8") {
+		t.Fatalf("unexpected output: %s %s", out.String(), errBuf.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--json", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(out.String(), "U+FFFD") {
+		t.Fatalf("expected replacement character finding, got %d: %s", code, out.String())
+	}
+	if code := runMain([]string{"scan", "--config", configPath, "--invalid-utf8-fix=latin9", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "invalid_utf8_fix must be") {
+		t.Fatalf("expected invalid strategy error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanExpiredAllowEntries(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	cfg := "include:\n  - \"**/*.md\"\nallow:\n  - {value: \"©\", expires: 2000-01-01, reason: \"legacy header\"}\n  - {value: \"™\", expires: 2999-12-31}\n"
+	if err := os.WriteFile(configPath, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	sourcePath := filepath.Join(tmp, "README.md")
+	if err := os.WriteFile(sourcePath, []byte("© ™\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected expired entry finding, got %d: %s", code, errBuf.String())
+	}
+	var result scanner.Result
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("expected one finding: %+v", result.Findings)
+	}
+	got := result.Findings[0]
+	if got.Path != configPath || got.Line != 4 || got.Category != "Expired Allow" || got.Severity != scanner.SeverityWarning || got.Message != `Allow entry "©" expired on 2000-01-01 (legacy header)` {
+		t.Fatalf("unexpected finding: %+v", got)
+	}
+}
+
+func TestRunScanIgnoreCommentsFlags(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(configPath, []byte("ignore_strings: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("package main\n// é\nvar s = \"ü\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	tests := []struct {
+		flags []string
+		want  []string
+	}{
+		{flags: nil, want: []string{"é"}},
+		{flags: []string{"--no-ignore-strings"}, want: []string{"é", "ü"}},
+		{flags: []string{"--ignore-comments"}, want: nil},
+		{flags: []string{"--ignore-comments", "--no-ignore-comments", "--no-ignore-strings"}, want: []string{"é", "ü"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		args := append([]string{"scan", "--config", configPath, "--format=json"}, tt.flags...)
+		runMain(append(args, sourcePath), &out, &errBuf)
+		var result scanner.Result
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s%s", err, out.String(), errBuf.String())
+		}
+		var got []string
+		for _, f := range result.Findings {
+			got = append(got, f.Character)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%v: findings = %q, want %q", tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestRunScanAllowFlag(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "notes.md")
+	if err := os.WriteFile(sourcePath, []byte("é α β ™\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	tests := []struct {
+		allow []string
+		want  []string
+	}{
+		{allow: nil, want: []string{"é", "α", "β", "™"}},
+		{allow: []string{"é"}, want: []string{"α", "β", "™"}},
+		{allow: []string{"U+00E9", "--allow=u+2122"}, want: []string{"α", "β"}},
+		{allow: []string{"greek"}, want: []string{"é", "™"}},
+		{allow: []string{"U+03B1..U+03B1"}, want: []string{"é", "β", "™"}},
+		{allow: []string{"U+03B1..U+03B2"}, want: []string{"é", "™"}},
+		{allow: []string{"U+0000..U+10FFFF"}, want: nil},
+	}
+	for _, tt := range tests {
+		args := []string{"scan", "--config", configPath, "--format=json"}
+		for _, value := range tt.allow {
+			if strings.HasPrefix(value, "--") {
+				args = append(args, value)
+				continue
+			}
+			args = append(args, "--allow", value)
+		}
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		runMain(append(args, sourcePath), &out, &errBuf)
+		var result scanner.Result
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s%s", err, out.String(), errBuf.String())
+		}
+		var got []string
+		for _, f := range result.Findings {
+			got = append(got, f.Character)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%v: findings = %q, want %q", tt.allow, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"Grek", "U+ZZZZ", "ab"} {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		if code := runMain([]string{"scan", "--config", configPath, "--allow", bad, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "scan argument error: --allow") {
+			t.Fatalf("expected --allow %q to fail, got %d: %s", bad, code, errBuf.String())
+		}
+	}
+	if _, err := parseScanArgs([]string{"--allow"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+}
+
+func TestRunScanCategorySeverity(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "notes.md")
+	if err := os.WriteFile(configPath, []byte("policies:\n  - paths: [\"**/*.md\"]\n    categories: [\"CJK=warning\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("日 é\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	tests := []struct {
+		flags []string
+		want  []string
+	}{
+		{flags: nil, want: []string{"日=warning", "é=error"}},
+		{flags: []string{"--severity", "cjk=error"}, want: []string{"日=error", "é=error"}},
+		{flags: []string{"--severity=Latin Extended=off", "--severity", "warning"}, want: []string{"日=warning"}},
+		{flags: []string{"--severity", "*=warning", "--severity", "CJK=off"}, want: []string{"é=warning"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		args := append([]string{"scan", "--config", configPath, "--format=json"}, tt.flags...)
+		runMain(append(args, sourcePath), &out, &errBuf)
+		var result scanner.Result
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s%s", err, out.String(), errBuf.String())
+		}
+		var got []string
+		for _, f := range result.Findings {
+			got = append(got, f.Character+"="+string(f.Severity))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%v: findings = %q, want %q", tt.flags, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"CJK=loud", "=off"} {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		if code := runMain([]string{"scan", "--config", configPath, "--severity", bad, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "scan argument error: --severity") {
+			t.Fatalf("expected --severity %q to fail, got %d: %s", bad, code, errBuf.String())
+		}
+	}
+}
+
+func TestRunScanEnvironment(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "ci.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(configPath, []byte("allow:\n  - \"é\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("package main\n// é ü\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	t.Setenv("ENGLINT_CONFIG", configPath)
+	t.Setenv("ENGLINT_SEVERITY", "warning")
+	t.Setenv("ENGLINT_NO_COLOR", "1")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "WARNING "+sourcePath) || strings.Contains(out.String(), "é (U+00E9)") || strings.Contains(out.String(), "\x1b[") {
+		t.Fatalf("expected settings from the environment: %q", out.String())
+	}
+
+	t.Setenv("ENGLINT_THREADS", "many")
+	errBuf.Reset()
+	if code := runMain([]string{"scan", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "config error: invalid ENGLINT_THREADS") {
+		t.Fatalf("expected environment error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanUserConfig(t *testing.T) {
+	tmp := t.TempDir()
+	userPath := filepath.Join(tmp, "xdg", "englint", "config.yaml")
+	projectPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	if err := os.MkdirAll(filepath.Dir(userPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		userPath:    "no_color: true\nverbose: true\nseverity: warning\nallow:\n  - \"é\"\n",
+		projectPath: "severity: error\nallow:\n  - \"ü\"\n",
+		sourcePath:  "package main\n// é ü ß\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "xdg"))
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", projectPath, sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	got := out.String()
+	if !strings.Contains(got, "ERROR "+sourcePath) || !strings.Contains(got, "SCANNED ") || strings.Contains(got, "\x1b[") {
+		t.Fatalf("expected project severity with user color and verbosity: %q", got)
+	}
+	if strings.Contains(got, "U+00E9") || strings.Contains(got, "U+00FC") || !strings.Contains(got, "U+00DF") {
+		t.Fatalf("expected user and project allow entries combined: %q", got)
+	}
+}
+
+func TestRunScanLayeredConfigs(t *testing.T) {
+	tmp := t.TempDir()
+	orgPath := filepath.Join(tmp, "org.yaml")
+	repoPath := filepath.Join(tmp, "repo.yaml")
+	sourcePath := filepath.Join(tmp, "main.go")
+	files := map[string]string{
+		orgPath:    "severity: warning\nallow:\n  - \"é\"\n",
+		repoPath:   "allow:\n  - \"ü\"\n",
+		sourcePath: "package main\n// é ü\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	code := runMain([]string{"scan", "--config", orgPath, "--config=" + repoPath, "--no-color", sourcePath}, &out, &errBuf)
+	if code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "WARNING") || !strings.Contains(out.String(), "é (U+00E9 LATIN SMALL LETTER E WITH ACUTE)") || strings.Contains(out.String(), "ü (U+00FC") {
+		t.Fatalf("expected the repo allow list to replace the org one: %s", out.String())
+	}
+
+	parsed, err := parseScanArgs([]string{"--config", orgPath, "--config=", "--config", repoPath})
+	if err != nil || !reflect.DeepEqual(parsed.ConfigPaths, []string{orgPath, repoPath}) || parsed.ConfigPath != repoPath {
+		t.Fatalf("unexpected config paths: %q, %q, %v", parsed.ConfigPaths, parsed.ConfigPath, err)
+	}
+}
+
+func TestRunScanPatternsRelativeToConfig(t *testing.T) {
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	files := map[string]string{
+		".englint.yaml":           "include:\n  - \"src/**\"\nexclude:\n  - \"src/gen/**\"\nallow_file_patterns:\n  - \"src/legacy.go\"\n",
+		"sub/.englint.yaml":       "root: \"..\"\ninclude:\n  - \"src/app.go\"\n",
+		"src/app.go":              "// 日本\n",
+		"src/legacy.go":           "// 中\n",
+		"src/gen/generated.go":    "// 中\n",
+		"src/nested/src/other.go": "// 中\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := os.Chdir(filepath.Join(tmp, "src")); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	runMain([]string{"scan", "--config", "../.englint.yaml", "--format=json", "--verbose", "."}, &out, &errBuf)
+	var result scanner.Result
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s%s", err, out.String(), errBuf.String())
+	}
+	if !reflect.DeepEqual(result.ScannedFiles, []string{"app.go", "nested/src/other.go"}) {
+		t.Fatalf("expected patterns relative to the config file, got %v", result.ScannedFiles)
+	}
+	if len(result.SkippedFiles) != 1 || result.SkippedFiles[0].Path != "legacy.go" {
+		t.Fatalf("expected legacy.go to be allowed, got %+v", result.SkippedFiles)
+	}
+
+	out.Reset()
+	runMain([]string{"scan", "--config", "../sub/.englint.yaml", "--format=json", "--verbose", "."}, &out, &errBuf)
+	result = scanner.Result{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(result.ScannedFiles, []string{"app.go"}) {
+		t.Fatalf("expected patterns relative to root, got %v", result.ScannedFiles)
+	}
+}
+
+func TestRunScanNestedConfigs(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		".englint.yaml":          "include:\n  - \"**/*.go\"\n",
+		"team/.englint.yaml":     "allow:\n  - \"é\"\nexclude:\n  - \"gen/**\"\n",
+		"team/a.go":              "// é ü\n",
+		"team/gen/generated.go":  "// 中\n",
+		"other/b.go":             "// é\n",
+		"broken/.englint.yaml":   "allow: [\n",
+		"team/deep/nested.go":    "// é\n",
+		"team/deep/.englint.yml": "not a nested config\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	runMain([]string{"scan", "--config", configPath, "--format=json", filepath.Join(tmp, "team"), filepath.Join(tmp, "other")}, &out, &errBuf)
+	var result scanner.Result
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s%s", err, out.String(), errBuf.String())
+	}
+	var got []string
+	for _, f := range result.Findings {
+		got = append(got, filepath.Base(f.Path)+":"+f.Character)
+	}
+	if want := []string{"b.go:é", "a.go:ü"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("findings = %q, want %q", got, want)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, tmp}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "nested config "+filepath.Join(tmp, "broken", ".englint.yaml")) {
+		t.Fatalf("expected nested config error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanGroupByOwner(t *testing.T) {
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	files := map[string]string{
+		".github/CODEOWNERS": "*.go @org/core\ndocs/ @org/docs @alice\n",
+		"main.go":            "// 日本\n",
+		"docs/guide.md":      "中\n",
+		"README.md":          "文\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", "missing.yaml", "--json", "--group-by-owner"}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	var payload struct {
+		Findings []scanner.Finding     `json:"findings"`
+		Owners   []output.OwnerSummary `json:"owners"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	owners := map[string][]string{}
+	for _, f := range payload.Findings {
+		owners[f.Path] = f.Owners
+	}
+	wantOwners := map[string][]string{"README.md": nil, "docs/guide.md": {"@org/docs", "@alice"}, "main.go": {"@org/core"}}
+	if !reflect.DeepEqual(owners, wantOwners) {
+		t.Fatalf("owners = %v, want %v", owners, wantOwners)
+	}
+	wantSummary := []output.OwnerSummary{
+		{Owner: "@org/core", Files: 1, Findings: 2},
+		{Owner: "(unowned)", Files: 1, Findings: 1},
+		{Owner: "@alice", Files: 1, Findings: 1},
+		{Owner: "@org/docs", Files: 1, Findings: 1},
+	}
+	if !reflect.DeepEqual(payload.Owners, wantSummary) {
+		t.Fatalf("owner summary = %+v, want %+v", payload.Owners, wantSummary)
+	}
+
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", "missing.yaml", "--group-by-owner", "--no-color"}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "OWNER      FILES  FINDINGS\n@org/core  1      2\n") {
+		t.Fatalf("expected owner table: %s", out.String())
+	}
+}
+
+func TestRunScanMinConfidence(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar s = \"™\" // 日\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--json", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(out.String(), `"confidence": "low"`) {
+		t.Fatalf("expected low confidence finding, got %d: %s", code, out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--min-confidence", "medium", "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if strings.Contains(out.String(), "U+2122") || !strings.Contains(out.String(), `"findings": 1`) {
+		t.Fatalf("expected only the medium confidence finding: %s", out.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--min-confidence=sure", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "min_confidence must be") {
+		t.Fatalf("expected validation error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanPolicies(t *testing.T) {
+	tmp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmp, "README.md"), []byte("™ café 日\n"), 0o644); err != nil {
+		t.Fatalf("write markdown: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), []byte("package p\n// ™ café\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	policies := "policies:\n  - paths: [\"**/*.md\"]\n    categories: [\"Unicode Symbol=off\", \"Latin Extended=off\", \"CJK=warning\"]\n"
+	if err := os.WriteFile(configPath, []byte(policies), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", tmp}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	got := out.String()
+	if !strings.Contains(got, "WARNING "+filepath.Join(tmp, "README.md")+":1:8 [Han]") || strings.Contains(got, "README.md:1:1") ||
+		!strings.Contains(got, "main.go:2:4 [Unicode Symbol]") || !strings.Contains(got, "findings=3") {
+		t.Fatalf("unexpected policy output: %s", got)
+	}
+}
+
+func TestRunScanCustomCategories(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\n// ├── a\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("categories:\n  - name: Box Drawing\n    ranges: [\"U+2500..U+257F\"]\n    severity: warning\n    fix: \"-\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", "--fix-dry-run", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "WARNING "+sourcePath+":2:4 [Box Drawing] ├ (U+251C BOX DRAWINGS LIGHT VERTICAL AND RIGHT)\n  fix: replace with \"-\"\n") ||
+		!strings.Contains(out.String(), "-// ├── a\n+// --- a\n") {
+		t.Fatalf("unexpected custom category output: %s", out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", "--fix", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected --fix to fix every finding, got %d: %s", code, out.String())
+	}
+	if data, _ := os.ReadFile(sourcePath); string(data) != "package p\n// --- a\n" || !strings.Contains(out.String(), "fixed "+sourcePath+": 3 character(s)") {
+		t.Fatalf("unexpected fix: %q\n%s", data, out.String())
+	}
+
+	if err := os.WriteFile(configPath, []byte("categories:\n  - name: Box Drawing\n    ranges: [\"2500\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "must start with U+") {
+		t.Fatalf("expected range validation error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanMessageTemplates(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"秘\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("message_templates:\n  - \"CJK={codepoint} is not allowed, see https://wiki.example.com/english#cjk\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d, err=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "\n  U+79D8 is not allowed, see https://wiki.example.com/english#cjk\n") {
+		t.Fatalf("expected templated message: %s", out.String())
+	}
+
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d, err=%s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `"message": "U+79D8 is not allowed, see https://wiki.example.com/english#cjk"`) {
+		t.Fatalf("expected templated json message: %s", out.String())
+	}
+}
+
+func TestRunScanExcerpts(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"秘\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, "missing.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--excerpts", "omit", "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d, err=%s", code, errBuf.String())
+	}
+	if strings.Contains(out.String(), "excerpt") {
+		t.Fatalf("expected excerpts to be omitted: %s", out.String())
+	}
+
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--excerpts=redact", "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings, got %d, err=%s", code, errBuf.String())
+	}
+	if strings.Contains(out.String(), "秘") || !strings.Contains(out.String(), "<U+79D8>") {
+		t.Fatalf("expected redacted output: %s", out.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--excerpts", "some", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected invalid excerpts failure")
+	}
+	if !strings.Contains(errBuf.String(), "excerpts must be") {
+		t.Fatalf("expected excerpts validation error, got %s", errBuf.String())
+	}
+	if _, err := parseScanArgs([]string{"--excerpts"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+}
+
+func TestRunScanErrors(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "bad.yaml")
+	if err := os.WriteFile(configPath, []byte("severity: invalid\n"), 0o644); err != nil {
+		t.Fatalf("write bad config: %v", err)
+	}
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+
+	if code := runMain([]string{"scan", "--config", configPath, tmp}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected validation error code")
+	}
+	if !strings.Contains(errBuf.String(), "config error") {
+		t.Fatalf("expected validation message, got: %s", errBuf.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--bad"}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected argument error")
+	}
+	if !strings.Contains(errBuf.String(), "scan argument error") {
+		t.Fatalf("expected argument error message")
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", tmp, tmp}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected config load/read error")
+	}
+	if !strings.Contains(errBuf.String(), "config error") {
+		t.Fatalf("expected config load error")
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", filepath.Join(tmp, "missing.yaml"), filepath.Join(tmp, "does-not-exist")}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected scan failure")
+	}
+	if !strings.Contains(errBuf.String(), "scan error") {
+		t.Fatalf("expected scan error")
+	}
+}
+
+func TestRunFix(t *testing.T) {
+	origStdin := stdin
+	defer func() { stdin = origStdin }()
+
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, "missing.yaml")
+	sourcePath := filepath.Join(tmp, "sample.go")
+	source := "// 𝐚 𝐛 é\n// caf\xe9 \xff\n"
+	if err := os.WriteFile(sourcePath, []byte(source), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	read := func() string {
+		data, _ := os.ReadFile(sourcePath)
+		return string(data)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"fix", "--config", configPath, "--dry-run", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected dry run to succeed, got %d: %s", code, errBuf.String())
+	}
+	for _, want := range []string{`:1:4: replace "𝐚" (U+1D41A) with "a"`, ": repair invalid UTF-8 (replace)", "Would fix 4 finding(s) in 1 file(s); 1 finding(s) have no automatic fix"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in dry run output:\n%s", want, out.String())
+		}
+	}
+	if read() != source {
+		t.Fatalf("dry run changed the file: %q", read())
+	}
+
+	stdin = strings.NewReader("n\ny\n")
+	out.Reset()
+	if code := runMain([]string{"fix", "--config", configPath, "--interactive", "--categories", "confusable latin, Other", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected interactive fix to succeed, got %d: %s", code, errBuf.String())
+	}
+	if want := "// 𝐚 b é\n// caf\xe9 \xff\n"; read() != want || !strings.Contains(out.String(), "? [y,n,a,q] ") || !strings.Contains(out.String(), "Fixed 1 finding(s) in 1 file(s)") {
+		t.Fatalf("unexpected interactive fix: %q\n%s", read(), out.String())
+	}
+
+	out.Reset()
+	if code := runMain([]string{"fix", "--config", configPath, "--strategy=legacy", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected fix to succeed, got %d: %s", code, errBuf.String())
+	}
+	info, _ := os.Stat(sourcePath)
+	if want := "// a b é\n// café ÿ\n"; read() != want || info.Mode().Perm() != 0o600 {
+		t.Fatalf("fixed source = %q (%v), want %q", read(), info.Mode(), want)
+	}
+
+	out.Reset()
+	if code := runMain([]string{"fix", "--config", configPath, "--mode", "transliterate", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected transliteration to succeed, got %d: %s", code, errBuf.String())
+	}
+	if want := "// a b e\n// cafe y\n"; read() != want || !strings.Contains(out.String(), `replace "é" (U+00E9) with "e"`) {
+		t.Fatalf("transliterated source = %q, want %q\n%s", read(), want, out.String())
+	}
+
+	for _, args := range [][]string{{"--strategy", "ascii"}, {"--mode", "ascii"}, {"--dry-run", "--interactive"}, {"--dry-run", "--patch=x.patch"}, {"--bogus"}} {
+		errBuf.Reset()
+		if code := runMain(append([]string{"fix"}, args...), &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "fix argument error") {
+			t.Fatalf("%v: expected argument error, got %d: %s", args, code, errBuf.String())
+		}
+	}
+}
+
+func TestRunScanFixMode(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "sample.go")
+	source := "// naïve 日本 — done\n"
+	if err := os.WriteFile(sourcePath, []byte(source), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", "--fix", "--fix-mode", "transliterate", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected untransliterated findings, got %d: %s", code, errBuf.String())
+	}
+	data, _ := os.ReadFile(sourcePath)
+	if want := "// naive 日本 -- done\n"; string(data) != want {
+		t.Fatalf("fixed source = %q, want %q", data, want)
+	}
+	for _, want := range []string{"fixed " + sourcePath + ": 2 character(s)", "findings=2", "Some findings have no automatic fix."} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	if err := os.WriteFile(configPath, []byte("fix_mode: question\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--fix-dry-run", "--json", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected dry run to keep findings, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(errBuf.String(), "-// naive 日本 -- done\n+// naive ?? -- done\n") || !json.Valid(out.Bytes()) {
+		t.Fatalf("expected a diff on stderr and JSON on stdout:\n%s\n%s", errBuf.String(), out.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--fix", "--fix-mode=delete", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected every finding to be fixed, got %d: %s", code, out.String())
+	}
+	if data, _ := os.ReadFile(sourcePath); string(data) != "// naive  -- done\n" {
+		t.Fatalf("unexpected deletion: %q", data)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--fix-mode", "ascii", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "fix_mode must be") {
+		t.Fatalf("expected fix mode validation error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunScanFixFormatCharacters(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("x := \"co\u00adop\u200b\" // caf\u00e9\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--no-color", "--fix", "--fix-mode", "question", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected every finding to be fixed, got %d: %s%s", code, out.String(), errBuf.String())
+	}
+	if data, _ := os.ReadFile(sourcePath); string(data) != "x := \"coop\" // caf?\n" {
+		t.Fatalf("fixed source = %q", data)
+	}
+}
+
+func TestRunFixPatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", tmp).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	sub := filepath.Join(tmp, "sub")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	source := "// 𝐚\n1\n2\n3\n4\n5\n6\n7\n8\n// 𝐛"
+	if err := os.WriteFile(filepath.Join(sub, "a.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chdir(sub); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"fix", "--config", "missing.yaml", "--patch", "../fix.patch", "."}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected patch to be written, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "Wrote a patch to ../fix.patch fixing 2 finding(s) in 1 file(s)") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+	if data, _ := os.ReadFile("a.go"); string(data) != source {
+		t.Fatalf("expected a.go to be left alone, got %q", data)
+	}
+	patch, _ := os.ReadFile("../fix.patch")
+	want := "diff --git a/sub/a.go b/sub/a.go\n--- a/sub/a.go\n+++ b/sub/a.go\n" +
+		"@@ -1,4 +1,4 @@\n-// 𝐚\n+// a\n 1\n 2\n 3\n" +
+		"@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-// 𝐛\n\\ No newline at end of file\n+// b\n\\ No newline at end of file\n"
+	if string(patch) != want {
+		t.Fatalf("patch = %q, want %q", patch, want)
+	}
+	if output, err := exec.Command("git", "-C", tmp, "apply", "fix.patch").CombinedOutput(); err != nil {
+		t.Fatalf("git apply: %v: %s", err, output)
+	}
+	if data, _ := os.ReadFile("a.go"); string(data) != strings.NewReplacer("𝐚", "a", "𝐛", "b").Replace(source) {
+		t.Fatalf("unexpected patched file %q", data)
+	}
+}
+
+func TestRunMCP(t *testing.T) {
+	origStdin := stdin
+	defer func() { stdin = origStdin }()
+
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"café\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	fixPath := filepath.Join(tmp, "fix.go")
+	if err := os.WriteFile(fixPath, []byte("// a\u200bb\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	stdin = strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"scan","arguments":{"paths":[` + jsonQuote(sourcePath) + `]}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"allow","arguments":{"values":["é"]}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"scan","arguments":{"paths":[` + jsonQuote(sourcePath) + `]}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"explain","arguments":{"path":` + jsonQuote(sourcePath) + `}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"fix","arguments":{"paths":[` + jsonQuote(fixPath) + `]}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"fix","arguments":{"paths":[` + jsonQuote(fixPath) + `],"dry_run":false}}}`,
+	}, "\n"))
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"mcp", "--config", configPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected mcp to succeed, got %d, err=%s", code, errBuf.String())
+	}
+
+	var texts []string
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp struct {
+			Result struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"result"`
+		}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		texts = append(texts, resp.Result.Content[0].Text)
+	}
+	if len(texts) != 6 {
+		t.Fatalf("expected 6 responses, got %d", len(texts))
+	}
+	if !strings.Contains(texts[0], `"findings": 1`) {
+		t.Fatalf("expected one finding before allow, got %s", texts[0])
+	}
+	if !strings.Contains(texts[2], `"findings": 0`) {
+		t.Fatalf("expected no findings after allow, got %s", texts[2])
+	}
+	if !strings.Contains(texts[3], `"scanned": true`) {
+		t.Fatalf("unexpected explain response: %s", texts[3])
+	}
+	if !strings.Contains(texts[4], `"dry_run": true`) || !strings.Contains(texts[4], `+// ab\n`) {
+		t.Fatalf("expected a diff from the default dry run, got %s", texts[4])
+	}
+	if !strings.Contains(texts[5], `"dry_run": false`) || !strings.Contains(texts[5], `1 character(s)`) {
+		t.Fatalf("unexpected fix response: %s", texts[5])
+	}
+	if data, _ := os.ReadFile(fixPath); string(data) != "// ab\n" {
+		t.Fatalf("fix.go = %q, want the fixed text", data)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || !strings.Contains(string(data), `"é"`) {
+		t.Fatalf("expected allow list to be saved, got %q (%v)", data, err)
+	}
+
+	if code := runMain([]string{"mcp", "--bogus"}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected mcp argument error")
+	}
+	if _, err := parseMCPArgs([]string{"--config"}); err == nil {
+		t.Fatalf("expected missing --config value error")
+	}
+	t.Setenv("ENGLINT_CONFIG", "")
+	if parsed, err := parseMCPArgs([]string{"--config="}); err != nil || !reflect.DeepEqual(defaultConfigPaths([]string{parsed.ConfigPath}), []string{".englint.yaml"}) {
+		t.Fatalf("expected default config path, got %+v (%v)", parsed, err)
+	}
+	if err := os.WriteFile(configPath, []byte("unknown: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"mcp", "--config=" + configPath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "config error") {
+		t.Fatalf("expected config error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestParseReportArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    reportArgs
+		wantErr string
+	}{
+		{name: "defaults", args: []string{"github-pr", "--pr", "3"}, want: reportArgs{Target: "github-pr", PR: 3, Paths: []string{"."}}},
+		{name: "equals form", args: []string{"github-pr", "--pr=4", "--limit=10", "--config=c.yaml", "src", "--", "-x"}, want: reportArgs{Target: "github-pr", ConfigPath: "c.yaml", PR: 4, Limit: 10, Paths: []string{"src", "-x"}}},
+		{name: "gitlab", args: []string{"gitlab-mr", "--mr", "8"}, want: reportArgs{Target: "gitlab-mr", MR: 8, Paths: []string{"."}}},
+		{name: "gitlab from env", args: []string{"gitlab-mr"}, want: reportArgs{Target: "gitlab-mr", Paths: []string{"."}}},
+		{name: "bitbucket", args: []string{"bitbucket", "--commit=abc", "--limit", "5"}, want: reportArgs{Target: "bitbucket", Commit: "abc", Limit: 5, Paths: []string{"."}}},
+		{name: "no target", args: []string{"--pr", "1"}, wantErr: "requires a target"},
+		{name: "unknown target", args: []string{"svn"}, wantErr: "unknown report target"},
+		{name: "missing pr", args: []string{"github-pr"}, wantErr: "requires --pr"},
+		{name: "bad pr", args: []string{"github-pr", "--pr", "x"}, wantErr: "non-negative integer"},
+		{name: "missing value", args: []string{"github-pr", "--limit"}, wantErr: "requires a value"},
+		{name: "unknown flag", args: []string{"github-pr", "--json"}, wantErr: "unknown flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReportArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunReportGitHubPR(t *testing.T) {
+	var posted int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/o/r/pulls/5":
+			_, _ = w.Write([]byte(`{"head":{"sha":"abc"}}`))
+		case r.URL.Path == "/repos/o/r/pulls/5/comments":
+			_, _ = w.Write([]byte(`[]`))
+		case r.URL.Path == "/repos/o/r/pulls/5/reviews":
+			posted++
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("GITHUB_API_URL", srv.URL)
+	t.Setenv("GITHUB_TOKEN", "tok")
+	t.Setenv("GITHUB_REPOSITORY", "o/r")
+
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"report", "github-pr", "--pr", "5", "--config", configPath, sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings exit code 1, got %d, err=%s", code, errBuf.String())
+	}
+	if posted != 1 || !strings.Contains(out.String(), "Posted 1 comment(s)") {
+		t.Fatalf("unexpected report output: %s (posted=%d)", out.String(), posted)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"report", "github-pr", "--pr", "6", "--config", configPath, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "report error") {
+		t.Fatalf("expected report error, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"report"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "report argument error") {
+		t.Fatalf("expected report argument error, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"report", "github-pr", "--pr", "5", "--config", configPath, filepath.Join(tmp, "missing")}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "scan error") {
+		t.Fatalf("expected scan error, got %d: %s", code, errBuf.String())
+	}
+	if err := os.WriteFile(configPath, []byte("unknown: true\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"report", "github-pr", "--pr", "5", "--config", configPath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "config error") {
+		t.Fatalf("expected config error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunReportGitLabMR(t *testing.T) {
+	var created int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/42/merge_requests/3":
+			_, _ = w.Write([]byte(`{"diff_refs":{"base_sha":"HEAD","head_sha":"h"}}`))
+		case "/projects/42/merge_requests/3/discussions":
+			if r.Method == http.MethodPost {
+				created++
+			}
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("CI_API_V4_URL", srv.URL)
+	t.Setenv("CI_PROJECT_ID", "42")
+	t.Setenv("CI_MERGE_REQUEST_IID", "3")
+	t.Setenv("GITLAB_TOKEN", "tok")
+
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"日本\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init")
+	// Only the line added since the base commit gets a discussion.
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"日本\"\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"report", "gitlab-mr", "--config", filepath.Join(tmp, ".englint.yaml"), sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings exit code 1, got %d, err=%s", code, errBuf.String())
+	}
+	if created != 1 || !strings.Contains(out.String(), "Posted 1 comment(s)") {
+		t.Fatalf("unexpected report output: %s (created=%d)", out.String(), created)
+	}
+}
+
+func TestRunReportBitbucket(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	args := []string{"report", "bitbucket", "--config", filepath.Join(tmp, ".englint.yaml"), sourcePath}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "cloud",
+			env:  map[string]string{"BITBUCKET_API_URL": srv.URL, "BITBUCKET_WORKSPACE": "ws", "BITBUCKET_REPO_SLUG": "repo", "BITBUCKET_COMMIT": "abc"},
+			want: "POST /repositories/ws/repo/commit/abc/reports/englint/annotations",
+		},
+		{
+			name: "server",
+			env:  map[string]string{"BITBUCKET_SERVER_URL": srv.URL, "BITBUCKET_TOKEN": "tok", "BITBUCKET_PROJECT_KEY": "P", "BITBUCKET_REPO_SLUG": "repo", "BITBUCKET_COMMIT": "def"},
+			want: "POST /rest/insights/1.0/projects/P/repos/repo/commits/def/reports/englint/annotations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"BITBUCKET_API_URL", "BITBUCKET_SERVER_URL", "BITBUCKET_TOKEN", "BITBUCKET_WORKSPACE", "BITBUCKET_PROJECT_KEY", "BITBUCKET_REPO_SLUG", "BITBUCKET_COMMIT"} {
+				t.Setenv(key, tt.env[key])
+			}
+			paths = nil
+			var out bytes.Buffer
+			var errBuf bytes.Buffer
+			if code := runMain(args, &out, &errBuf); code != 1 || errBuf.Len() != 0 {
+				t.Fatalf("expected findings exit code 1, got %d, err=%s", code, errBuf.String())
+			}
+			if len(paths) != 3 || paths[2] != tt.want || !strings.Contains(out.String(), "Posted 5 comment(s)") {
+				t.Fatalf("unexpected requests %v, output %s", paths, out.String())
+			}
+		})
+	}
+}
+
+func TestRunScanNotifyWebhook(t *testing.T) {
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+	}))
+	defer srv.Close()
+
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	cleanPath := filepath.Join(tmp, "clean.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.WriteFile(cleanPath, []byte("package p\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	configPath := filepath.Join(tmp, ".englint.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"scan", "--config", configPath, "--notify-webhook", srv.URL, "--notify-findings", sourcePath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected findings exit code, got %d", code)
+	}
+	if len(bodies) != 1 || bodies[0]["findings"] == nil {
+		t.Fatalf("expected one payload with findings, got %v", bodies)
+	}
+	if code := runMain([]string{"scan", "--config", configPath, "--notify-webhook=" + srv.URL, cleanPath}, &out, &errBuf); code != 0 || len(bodies) != 1 {
+		t.Fatalf("expected no notification for clean scan, got %d (%d payloads)", code, len(bodies))
+	}
+
+	if err := os.WriteFile(configPath, []byte("notify_webhook: \""+srv.URL+"/x\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath}, &out, &errBuf); code != 1 || len(bodies) != 2 || bodies[1]["findings"] != nil {
+		t.Fatalf("expected summary-only notification from config, got %d: %v", code, bodies)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--notify-webhook", "http://127.0.0.1:1/hook", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "notify error") {
+		t.Fatalf("expected notify error, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--notify-webhook", "not-a-url", sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "config validation error") {
+		t.Fatalf("expected validation error, got %d: %s", code, errBuf.String())
+	}
+	if _, err := parseScanArgs([]string{"--notify-webhook"}); err == nil {
+		t.Fatalf("expected missing --notify-webhook value error")
+	}
+}
+
+func TestParseHistoryArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    historyArgs
+		wantErr string
+	}{
+		{name: "defaults", args: []string{"--store", "r.db"}, want: historyArgs{Store: "r.db", Limit: 20}},
+		{name: "all flags", args: []string{"--store=r.db", "--limit=0", "--json"}, want: historyArgs{Store: "r.db", JSON: true}},
+		{name: "missing store", args: []string{"--json"}, wantErr: "requires --store"},
+		{name: "bad limit", args: []string{"--store", "r.db", "--limit", "-1"}, wantErr: "non-negative"},
+		{name: "missing value", args: []string{"--store"}, wantErr: "requires a value"},
+		{name: "unknown", args: []string{"--verbose"}, wantErr: "unknown flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHistoryArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got %+v (%v), want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunScanStoreAndHistory(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	if err := os.WriteFile(sourcePath, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	dbPath := filepath.Join(tmp, "results.db")
+	configPath := filepath.Join(tmp, ".englint.yaml")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"history", "--store", dbPath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "store error") {
+		t.Fatalf("expected store error for missing database, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"history"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "history argument error") {
+		t.Fatalf("expected history argument error, got %d: %s", code, errBuf.String())
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--store", tmp, sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "store error") {
+		t.Fatalf("expected store error for directory path, got %d: %s", code, errBuf.String())
+	}
+	if _, err := parseScanArgs([]string{"--store"}); err == nil {
+		t.Fatalf("expected missing --store value error")
+	}
+
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--config", configPath, "--store=" + dbPath, sourcePath}, &out, &errBuf); code != 1 || errBuf.Len() != 0 {
+		t.Fatalf("expected findings exit code without errors, got %d: %s", code, errBuf.String())
+	}
+	out.Reset()
+	if code := runMain([]string{"history", "--store", dbPath, "--json"}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected history to succeed, got %d: %s", code, errBuf.String())
+	}
+	var payload struct {
+		Scans []struct {
+			Findings   int            `json:"findings"`
+			ByCategory map[string]int `json:"byCategory"`
+		} `json:"scans"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(payload.Scans) != 1 || payload.Scans[0].Findings != 5 || payload.Scans[0].ByCategory["Kana"] != 5 {
+		t.Fatalf("unexpected history: %+v", payload)
+	}
+	if code := runMain([]string{"history", "--store", dbPath}, failWriter{}, &errBuf); code != 1 {
+		t.Fatalf("expected output error code")
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	scanTo := func(name, content string) string {
+		if err := os.WriteFile(sourcePath, []byte(content), 0o644); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+		var out bytes.Buffer
+		runMain([]string{"scan", "--json", "--config", configPath, sourcePath}, &out, &bytes.Buffer{})
+		path := filepath.Join(tmp, name)
+		if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+			t.Fatalf("write result: %v", err)
+		}
+		return path
+	}
+	oldPath := scanTo("old.json", "package p\nvar a = \"é\"\nvar b = \"ж\"\n")
+	samePath := scanTo("same.json", "package p\n\nvar a = \"é\"\nvar b = \"ж\"\n")
+	newPath := scanTo("new.json", "package p\nvar a = \"é\"\nvar c = \"日\"\n")
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"diff", "--no-color", oldPath, samePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected no new findings after moving lines, got %d: %s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "Summary: added=0 removed=0 unchanged=2") {
+		t.Fatalf("unexpected diff output: %s", out.String())
+	}
+
+	out.Reset()
+	if code := runMain([]string{"diff", "--json", oldPath, newPath}, &out, &errBuf); code != 1 {
+		t.Fatalf("expected exit code 1 for new findings, got %d", code)
+	}
+	var payload struct {
+		Summary map[string]int `json:"summary"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	if payload.Summary["added"] != 1 || payload.Summary["removed"] != 1 || payload.Summary["unchanged"] != 1 {
+		t.Fatalf("unexpected diff summary: %v", payload.Summary)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "one file", args: []string{"diff", oldPath}, want: "diff argument error"},
+		{name: "unknown flag", args: []string{"diff", "--verbose", oldPath, newPath}, want: "unknown flag"},
+		{name: "missing old", args: []string{"diff", filepath.Join(tmp, "missing.json"), newPath}, want: "diff error"},
+		{name: "bad new", args: []string{"diff", oldPath, sourcePath}, want: "diff error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errBuf.Reset()
+			if code := runMain(tt.args, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), tt.want) {
+				t.Fatalf("expected %q, got %d: %s", tt.want, code, errBuf.String())
+			}
+		})
+	}
+	if code := runMain([]string{"diff", oldPath, newPath}, failWriter{}, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "output error") {
+		t.Fatalf("expected output error")
+	}
+}
+
+func TestRunAnnotate(t *testing.T) {
+	tmp := t.TempDir()
+	sourcePath := filepath.Join(tmp, "sample.go")
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("include:\n  - \"**/*\"\nmax_findings_per_file: 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("package p\n\nfunc f() {\n\ta := \"é\"\n\tb := \"ж\"\n}\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"annotate", "--config", configPath, "--reason", "product name", sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), "Annotated 2 line(s) in 1 file(s)") {
+		t.Fatalf("unexpected annotate output: %s", out.String())
+	}
+	got, _ := os.ReadFile(sourcePath)
+	want := "package p\n\nfunc f() {\n\t// englint:ignore TODO: product name\n\ta := \"é\"\n\t// englint:ignore TODO: product name\n\tb := \"ж\"\n}\n"
+	if string(got) != want {
+		t.Fatalf("annotated source = %q, want %q", got, want)
+	}
+	if code := runMain([]string{"scan", "--config", configPath, sourcePath}, &bytes.Buffer{}, &errBuf); code != 0 {
+		t.Fatalf("expected annotated file to scan clean, got %d", code)
+	}
+
+	textPath := filepath.Join(tmp, "notes.txt")
+	if err := os.WriteFile(textPath, []byte("é\n"), 0o644); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"annotate", "--config", configPath, textPath}, &bytes.Buffer{}, &errBuf); code != 1 {
+		t.Fatalf("expected exit code 1 for unsupported file, got %d", code)
+	}
+	if !strings.Contains(errBuf.String(), "no known comment syntax") {
+		t.Fatalf("unexpected annotate stderr: %s", errBuf.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"annotate", "--reason"}, &bytes.Buffer{}, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "annotate argument error") {
+		t.Fatalf("expected argument error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunSuggestAllow(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("include:\n  - \"**/*.go\"\nallow:\n  - \"→\"\nmax_findings_per_file: 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte("package p\n\n// © © → 日\n"), 0o644); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"suggest-allow", "--config", configPath, "--min-count=4", tmp}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, errBuf.String())
+	}
+	want := "allow:\n  - \"→\"\n  # U+00A9 (Unicode Symbol) appears 4 times across 2 files — consider allowing\n  - \"©\"\n"
+	if out.String() != want {
+		t.Fatalf("unexpected suggest-allow output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	pasted := filepath.Join(tmp, "pasted.yaml")
+	if err := os.WriteFile(pasted, out.Bytes(), 0o644); err != nil {
+		t.Fatalf("write pasted config: %v", err)
+	}
+	if cfg, err := config.Load(pasted); err != nil || len(cfg.Allow) != 2 {
+		t.Fatalf("expected suggestions to load as config, got %+v (%v)", cfg.Allow, err)
+	}
+
+	out.Reset()
+	if code := runMain([]string{"suggest-allow", "--config", configPath, "--json", "--min-files", "3", tmp}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(out.String(), `"suggestions": []`) {
+		t.Fatalf("unexpected json output: %s", out.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"suggest-allow", "--min-count", "0"}, &bytes.Buffer{}, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "positive integer") {
+		t.Fatalf("expected argument error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunBadge(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "a.go")
+	if err := os.WriteFile(configPath, []byte("severity: warning\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("// 日本\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"badge", "--config", configPath, tmp}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected badge, got %d: %s", code, errBuf.String())
+	}
+	if !strings.Contains(out.String(), `aria-label="English-only: 2 findings"`) || !strings.Contains(out.String(), "#dfb317") {
+		t.Fatalf("unexpected svg: %s", out.String())
+	}
+
+	badgePath := filepath.Join(tmp, "badge.json")
+	if code := runMain([]string{"badge", "--config", configPath, "--label=i18n", "--output", badgePath, filepath.Join(tmp, ".englint.yaml")}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected badge, got %d: %s", code, errBuf.String())
+	}
+	data, err := os.ReadFile(badgePath)
+	if err != nil {
+		t.Fatalf("read badge: %v", err)
+	}
+	if !strings.Contains(string(data), `"label": "i18n"`) || !strings.Contains(string(data), `"message": "passing"`) {
+		t.Fatalf("unexpected endpoint json: %s", data)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"badge", "--format", "png"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "--format must be svg or json") {
+		t.Fatalf("expected format error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunBaseline(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	sourcePath := filepath.Join(tmp, "a.go")
+	baselinePath := filepath.Join(tmp, "baseline.json")
+	if err := os.WriteFile(configPath, []byte("max_findings_per_file: 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("// 日本\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"baseline", "--config", configPath, "--output", baselinePath, sourcePath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected baseline, got %d: %s", code, errBuf.String())
+	}
+	if want := "Wrote 2 finding(s) to " + baselinePath + "\n"; out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+
+	// Lines added above the recorded ones keep them suppressed; the new
+	// character is reported.
+	if err := os.WriteFile(sourcePath, []byte("package a\n\n// 日本\n// é\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	out.Reset()
+	errBuf.Reset()
+	code := runMain([]string{"scan", "--config", configPath, "--baseline", baselinePath, "--format=json", sourcePath}, &out, &errBuf)
+	if code != 1 {
+		t.Fatalf("expected new finding, got %d: %s", code, errBuf.String())
+	}
+	var result scanner.Result
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].CodePoint != "U+00E9" || result.Findings[0].Line != 4 {
+		t.Fatalf("unexpected findings: %+v", result.Findings)
+	}
+	if result.Summary.FindingsBaselined != 2 {
+		t.Fatalf("findingsBaselined = %d, want 2", result.Summary.FindingsBaselined)
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"scan", "--baseline", filepath.Join(tmp, "missing.json"), sourcePath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "baseline error:") {
+		t.Fatalf("expected baseline error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestRunBaselineSubdirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+	tmp := t.TempDir()
+	sub := filepath.Join(tmp, "sub")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "a.go"), []byte("// 日本\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if output, err := exec.Command("git", "init", "-q", tmp).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	if err := os.Chdir(sub); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	for _, style := range [][]string{nil, {"--paths", "relative"}} {
+		var out bytes.Buffer
+		var errBuf bytes.Buffer
+		if code := runMain(append([]string{"baseline"}, style...), &out, &errBuf); code != 0 {
+			t.Fatalf("%v: expected baseline, got %d: %s", style, code, errBuf.String())
+		}
+		out.Reset()
+		args := append([]string{"scan", "--baseline", baseline.DefaultPath}, style...)
+		if code := runMain(args, &out, &errBuf); code != 0 {
+			t.Fatalf("%v: expected baselined findings to pass, got %d: %s%s", style, code, out.String(), errBuf.String())
+		}
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	tmp := t.TempDir()
+	configPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(configPath, []byte("severity: warning\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"validate", "--config", configPath}, &out, &errBuf); code != 0 || out.String() != "Config is valid\n" {
+		t.Fatalf("expected valid config, got %d: %s%s", code, out.String(), errBuf.String())
+	}
+
+	if err := os.WriteFile(configPath, []byte("exclude:\n  - vendor/**\n  - vendor/gen/**\nallow:\n  - \"é\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out.Reset()
+	if code := runMain([]string{"validate", "--config=" + configPath}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected warnings only, got %d: %s", code, errBuf.String())
+	}
+	want := "warning: exclude pattern \"vendor/gen/**\" is shadowed by the earlier exclude pattern \"vendor/**\"\nConfig is valid with 1 warning(s)\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+
+	if err := os.WriteFile(configPath, []byte("severity: fatal\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	errBuf.Reset()
+	if code := runMain([]string{"validate", "--config", configPath}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "severity must be") {
+		t.Fatalf("expected severity error, got %d: %s", code, errBuf.String())
+	}
+
+	errBuf.Reset()
+	if code := runMain([]string{"validate", "--json"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "unknown flag for validate") {
+		t.Fatalf("expected flag error, got %d: %s", code, errBuf.String())
+	}
+}
+
+func TestParseTrendArgs(t *testing.T) {
+	now := time.Date(2024, 5, 6, 15, 4, 5, 0, time.UTC)
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    trendArgs
+		wantErr string
+	}{
+		{name: "defaults", args: []string{"--since", "2024-01-01"}, want: trendArgs{Since: day("2024-01-01"), Until: day("2024-05-06"), Step: "monthly", Rev: "HEAD"}},
+		{name: "all flags", args: []string{"--since=2024-01-01", "--until=2024-02-01", "--step=Weekly", "--rev", "main", "--config=c.yaml", "--json"}, want: trendArgs{ConfigPath: "c.yaml", Since: day("2024-01-01"), Until: day("2024-02-01"), Step: "weekly", Rev: "main", JSON: true}},
+		{name: "missing since", args: []string{"--step", "daily"}, wantErr: "requires --since"},
+		{name: "bad date", args: []string{"--since", "01/02/2024"}, wantErr: "YYYY-MM-DD"},
+		{name: "missing value", args: []string{"--since"}, wantErr: "requires a value"},
+		{name: "unknown", args: []string{"--verbose"}, wantErr: "unknown flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTrendArgs(tt.args, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got %+v (%v), want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTrend(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+
+	tmp := t.TempDir()
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	if code := runMain([]string{"trend", "--since", "2024-01-01"}, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), "trend error") {
+		t.Fatalf("expected trend error outside a repository, got %d: %s", code, errBuf.String())
+	}
+
+	if err := os.WriteFile("a.go", []byte("package a\nvar s = \"日本\"\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2024-01-15T12:00:00Z", "GIT_COMMITTER_DATE=2024-01-15T12:00:00Z")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	// The per-file cap of output does not apply to the counts.
+	if err := os.WriteFile(".englint.yaml", []byte("max_findings_per_file: 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	out.Reset()
+	errBuf.Reset()
+	if code := runMain([]string{"trend", "--since", "2024-01-01", "--until", "2024-03-01", "--json"}, &out, &errBuf); code != 0 {
+		t.Fatalf("expected trend to succeed, got %d: %s", code, errBuf.String())
+	}
+	var payload struct {
+		Points []struct {
+			Date     string `json:"date"`
+			Findings int    `json:"findings"`
+		} `json:"points"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(payload.Points) != 2 || payload.Points[0].Date != "2024-02-01" || payload.Points[0].Findings != 2 {
+		t.Fatalf("unexpected points: %+v", payload.Points)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "args", args: []string{"trend"}, want: "trend argument error"},
+		{name: "step", args: []string{"trend", "--since", "2024-01-01", "--step", "hourly"}, want: "trend error"},
+		{name: "config", args: []string{"trend", "--since", "2024-01-01", "--config", "a.go"}, want: "config error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errBuf.Reset()
+			if code := runMain(tt.args, &out, &errBuf); code != 1 || !strings.Contains(errBuf.String(), tt.want) {
+				t.Fatalf("expected %q, got %d: %s", tt.want, code, errBuf.String())
+			}
+		})
+	}
+	if code := runMain([]string{"trend", "--since", "2024-01-01"}, failWriter{}, &errBuf); code != 1 {
+		t.Fatalf("expected output error")
+	}
+}
+
+func jsonQuote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
 }
 
 func TestRunScanOutputError(t *testing.T) {
@@ -4,21 +4,178 @@ _englint_complete() {
   prev="${COMP_WORDS[COMP_CWORD-1]}"
 
   if [[ ${COMP_CWORD} -eq 1 ]]; then
-    COMPREPLY=( $(compgen -W "help scan init version" -- "$cur") )
+    COMPREPLY=( $(compgen -W "help scan init mcp report history diff trend annotate fix suggest-allow badge validate baseline version" -- "$cur") )
     return 0
   fi
 
   if [[ "${COMP_WORDS[1]}" == "scan" ]]; then
     case "$prev" in
-      --config|--include|--exclude|--severity)
+      --min-confidence)
+        COMPREPLY=( $(compgen -W "low medium high" -- "$cur") )
+        return 0
+        ;;
+      --format)
+        COMPREPLY=( $(compgen -W "human json sarif problem-matcher help" -- "$cur") )
+        return 0
+        ;;
+      --paths)
+        COMPREPLY=( $(compgen -W "relative absolute relative-to=" -- "$cur") )
+        return 0
+        ;;
+      --error-policy)
+        COMPREPLY=( $(compgen -W "skip warn abort" -- "$cur") )
+        return 0
+        ;;
+      --invalid-utf8-fix)
+        COMPREPLY=( $(compgen -W "replace strip legacy" -- "$cur") )
+        return 0
+        ;;
+      --fix-mode)
+        COMPREPLY=( $(compgen -W "transliterate question delete" -- "$cur") )
+        return 0
+        ;;
+      --lang)
+        COMPREPLY=( $(compgen -W "en de es fr ja ko pt zh" -- "$cur") )
+        return 0
+        ;;
+      --baseline)
+        COMPREPLY=( $(compgen -f -- "$cur") )
+        return 0
+        ;;
+      --since)
+        COMPREPLY=( $(compgen -W "$(git for-each-ref --format='%(refname:short)' 2>/dev/null)" -- "$cur") )
+        return 0
+        ;;
+      --config|--include|--exclude|--allow|--severity|--why|--mmap-threshold|--max-findings-per-file|--threads|--jobs|--excerpts|--notify-webhook|--store)
+        return 0
+        ;;
+    esac
+    COMPREPLY=( $(compgen -W "--config --exclude --allow --include --format --problem-matcher --json --fix --fix-dry-run --fix-mode --baseline --severity --no-color --verbose --list-skipped --why --mmap-threshold --max-findings-per-file --threads --jobs --excerpts --notify-webhook --notify-findings --store --lang --allow-latin-extended --ignore-comments --no-ignore-comments --ignore-strings --no-ignore-strings --ignore-urls --ignore-blobs --decode-escapes --check-entities --ignore-code-blocks --editorconfig --check-charset --dedupe-content --read-special --group-by-owner --changed --since --error-on-no-files --strict --error-policy --paths --file-uris --min-confidence --invalid-utf8-fix" -- "$cur") )
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "report" ]]; then
+    if [[ ${COMP_CWORD} -eq 2 ]]; then
+      COMPREPLY=( $(compgen -W "github-pr gitlab-mr bitbucket" -- "$cur") )
+      return 0
+    fi
+    case "$prev" in
+      --config|--pr|--mr|--commit|--limit)
+        return 0
+        ;;
+    esac
+    COMPREPLY=( $(compgen -W "--config --pr --mr --commit --limit" -- "$cur") )
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "diff" ]]; then
+    if [[ "$cur" == -* ]]; then
+      COMPREPLY=( $(compgen -W "--json --no-color" -- "$cur") )
+    else
+      COMPREPLY=( $(compgen -f -- "$cur") )
+    fi
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "trend" ]]; then
+    case "$prev" in
+      --step)
+        COMPREPLY=( $(compgen -W "daily weekly monthly" -- "$cur") )
+        return 0
+        ;;
+      --config|--since|--until|--rev)
         return 0
         ;;
     esac
-    COMPREPLY=( $(compgen -W "--config --exclude --include --json --fix --severity --no-color --verbose" -- "$cur") )
+    COMPREPLY=( $(compgen -W "--config --since --until --step --rev --json" -- "$cur") )
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "annotate" ]]; then
+    case "$prev" in
+      --config|--reason)
+        return 0
+        ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+      COMPREPLY=( $(compgen -W "--config --reason" -- "$cur") )
+    else
+      COMPREPLY=( $(compgen -f -- "$cur") )
+    fi
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "fix" ]]; then
+    case "$prev" in
+      --strategy)
+        COMPREPLY=( $(compgen -W "replace strip legacy" -- "$cur") )
+        return 0
+        ;;
+      --mode)
+        COMPREPLY=( $(compgen -W "transliterate question delete" -- "$cur") )
+        return 0
+        ;;
+      --config|--categories|--patch)
+        return 0
+        ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+      COMPREPLY=( $(compgen -W "--config --strategy --mode --categories --dry-run --interactive --patch" -- "$cur") )
+    else
+      COMPREPLY=( $(compgen -f -- "$cur") )
+    fi
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "suggest-allow" ]]; then
+    case "$prev" in
+      --config|--min-count|--min-files)
+        return 0
+        ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+      COMPREPLY=( $(compgen -W "--config --min-count --min-files --json" -- "$cur") )
+    else
+      COMPREPLY=( $(compgen -f -- "$cur") )
+    fi
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "baseline" ]]; then
+    case "$prev" in
+      --config|--output)
+        COMPREPLY=( $(compgen -f -- "$cur") )
+        return 0
+        ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+      COMPREPLY=( $(compgen -W "--config --output --paths" -- "$cur") )
+    else
+      COMPREPLY=( $(compgen -f -- "$cur") )
+    fi
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "history" ]]; then
+    case "$prev" in
+      --store|--limit)
+        return 0
+        ;;
+    esac
+    COMPREPLY=( $(compgen -W "--store --limit --json" -- "$cur") )
     return 0
   fi
 
   if [[ "${COMP_WORDS[1]}" == "init" ]]; then
+    if [[ "$prev" == "--template" ]]; then
+      COMPREPLY=( $(compgen -W "default go node docs security monorepo" -- "$cur") )
+      return 0
+    fi
+    COMPREPLY=( $(compgen -W "--config --template --force --interactive" -- "$cur") )
+    return 0
+  fi
+
+  if [[ "${COMP_WORDS[1]}" == "mcp" ]]; then
     COMPREPLY=( $(compgen -W "--config" -- "$cur") )
     return 0
   fi
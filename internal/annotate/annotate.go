@@ -0,0 +1,141 @@
+// Package annotate inserts englint:ignore comments above findings so that
+// suppressions live next to the code they apply to.
+package annotate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// DefaultReason is the TODO text written after the directive when no reason
+// is given.
+const DefaultReason = "explain why this text is allowed"
+
+// Result counts the comments written and lists files whose type has no
+// known comment syntax and findings on lines that cannot be annotated.
+type Result struct {
+	Files          int
+	Lines          int
+	Unsupported    []string
+	NotAnnotatable []scanner.Finding
+}
+
+// Annotate inserts one "englint:ignore TODO: <reason>" comment above every
+// line that has findings. Comments copy the indentation and line ending of
+// the line they suppress. Lines that start inside a string, comment, or
+// code block are left alone, since a comment inserted above them would
+// become part of that text; their findings are listed in NotAnnotatable.
+// Relative finding paths are relative to base, or to the working directory
+// when base is empty.
+func Annotate(findings []scanner.Finding, reason, base string) (Result, error) {
+	if reason == "" {
+		reason = DefaultReason
+	}
+	byPath := make(map[string][]int)
+	var paths []string
+	for _, f := range findings {
+		lines, ok := byPath[f.Path]
+		if !ok {
+			paths = append(paths, f.Path)
+		}
+		if len(lines) == 0 || lines[len(lines)-1] != f.Line {
+			byPath[f.Path] = append(lines, f.Line)
+		}
+	}
+	sort.Strings(paths)
+
+	var res Result
+	for _, path := range paths {
+		start, end, ok := scanner.CommentSyntax(path)
+		if !ok {
+			res.Unsupported = append(res.Unsupported, path)
+			continue
+		}
+		comment := start + scanner.IgnoreDirective + " TODO: " + reason + end
+		file := path
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(base, file)
+		}
+		n, skipped, err := annotateFile(file, byPath[path], comment)
+		if err != nil {
+			return res, err
+		}
+		for _, f := range findings {
+			if f.Path == path && skipped[f.Line] {
+				res.NotAnnotatable = append(res.NotAnnotatable, f)
+			}
+		}
+		if n > 0 {
+			res.Files++
+			res.Lines += n
+		}
+	}
+	return res, nil
+}
+
+// annotateFile inserts comment above the lines of the file at path that
+// start in code and returns how many it annotated and the lines it skipped.
+func annotateFile(path string, lines []int, comment string) (int, map[int]bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	code := scanner.CodeLines(path, data)
+	skipped := make(map[int]bool)
+	safe := lines[:0:0]
+	for _, line := range lines {
+		if line < len(code) && !code[line] {
+			skipped[line] = true
+			continue
+		}
+		safe = append(safe, line)
+	}
+	out, n := insertComments(data, safe, comment)
+	if n == 0 {
+		return 0, skipped, nil
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return 0, nil, fmt.Errorf("write %s: %w", path, err)
+	}
+	return n, skipped, nil
+}
+
+// insertComments returns data with comment inserted above each of the
+// 1-based lines, skipping lines past the end of the data.
+func insertComments(data []byte, lines []int, comment string) ([]byte, int) {
+	parts := bytes.SplitAfter(data, []byte("\n"))
+	targets := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		if line >= 1 && line <= len(parts) {
+			targets[line-1] = true
+		}
+	}
+	if len(targets) == 0 {
+		return data, 0
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data) + len(targets)*(len(comment)+8))
+	for i, part := range parts {
+		if targets[i] {
+			out.Write(part[:len(part)-len(bytes.TrimLeft(part, " \t"))])
+			out.WriteString(comment)
+			if bytes.HasSuffix(part, []byte("\r\n")) {
+				out.WriteString("\r\n")
+			} else {
+				out.WriteString("\n")
+			}
+		}
+		out.Write(part)
+	}
+	return out.Bytes(), len(targets)
+}
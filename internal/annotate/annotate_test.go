@@ -0,0 +1,121 @@
+package annotate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestInsertComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		lines []int
+		want  string
+		n     int
+	}{
+		{name: "indentation", data: "func f() {\n\tx := \"日\"\n}\n", lines: []int{2}, want: "func f() {\n\t// c\n\tx := \"日\"\n}\n", n: 1},
+		{name: "crlf", data: "a\r\nb\r\n", lines: []int{2}, want: "a\r\n// c\r\nb\r\n", n: 1},
+		{name: "first and last line without newline", data: "é\nb\né", lines: []int{1, 3}, want: "// c\né\nb\n// c\né", n: 2},
+		{name: "duplicate lines", data: "é é\n", lines: []int{1, 1}, want: "// c\né é\n", n: 1},
+		{name: "out of range", data: "a\n", lines: []int{0, 9}, want: "a\n", n: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n := insertComments([]byte(tt.data), tt.lines, "// c")
+			if string(got) != tt.want || n != tt.n {
+				t.Fatalf("insertComments() = %q, %d; want %q, %d", got, n, tt.want, tt.n)
+			}
+		})
+	}
+}
+
+func TestAnnotateSuppressesFindings(t *testing.T) {
+	dir := t.TempDir()
+	goFile := filepath.Join(dir, "a.go")
+	mdFile := filepath.Join(dir, "a.md")
+	rstFile := filepath.Join(dir, "a.rst")
+	txtFile := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(goFile, []byte("package a\n\nvar s = \"日本\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mdFile, []byte("# Title\n\nCafé\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rstFile, []byte("Title\n=====\n\nCafé\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(txtFile, []byte("é\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := scanner.Options{Include: []string{"**/*"}}
+	before, err := scanner.Scan([]string{dir}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Annotate(before.Findings, "legacy copy", "")
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	if res.Files != 3 || res.Lines != 3 || !reflect.DeepEqual(res.Unsupported, []string{txtFile}) {
+		t.Fatalf("Annotate() = %+v", res)
+	}
+	got, _ := os.ReadFile(goFile)
+	if want := "package a\n\n// englint:ignore TODO: legacy copy\nvar s = \"日本\"\n"; string(got) != want {
+		t.Fatalf("a.go = %q, want %q", got, want)
+	}
+	got, _ = os.ReadFile(mdFile)
+	if want := "# Title\n\n<!-- englint:ignore TODO: legacy copy -->\nCafé\n"; string(got) != want {
+		t.Fatalf("a.md = %q, want %q", got, want)
+	}
+	got, _ = os.ReadFile(rstFile)
+	if want := "Title\n=====\n\n.. englint:ignore TODO: legacy copy\nCafé\n"; string(got) != want {
+		t.Fatalf("a.rst = %q, want %q", got, want)
+	}
+	if info, _ := os.Stat(goFile); info.Mode().Perm() != 0o600 {
+		t.Fatalf("a.go mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	after, err := scanner.Scan([]string{dir}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after.Findings) != 1 || after.Findings[0].Path != txtFile {
+		t.Fatalf("findings after annotate = %+v, want only %s", after.Findings, txtFile)
+	}
+}
+
+func TestAnnotateSkipsLinesInsideStrings(t *testing.T) {
+	dir := t.TempDir()
+	goFile := filepath.Join(dir, "a.go")
+	data := "package a\n\nvar s = `raw\n日本`\n\n/*\né\n*/\nvar r = \"é\"\n"
+	if err := os.WriteFile(goFile, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := scanner.Options{Include: []string{"**/*"}}
+	before, err := scanner.Scan([]string{dir}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Annotate(before.Findings, "", "")
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	var skipped []int
+	for _, f := range res.NotAnnotatable {
+		skipped = append(skipped, f.Line)
+	}
+	if res.Files != 1 || res.Lines != 1 || !reflect.DeepEqual(skipped, []int{4, 4, 7}) {
+		t.Fatalf("Annotate() = %+v, skipped lines %v", res, skipped)
+	}
+	got, _ := os.ReadFile(goFile)
+	want := "package a\n\nvar s = `raw\n日本`\n\n/*\né\n*/\n// englint:ignore TODO: " + DefaultReason + "\nvar r = \"é\"\n"
+	if string(got) != want {
+		t.Fatalf("a.go = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+// Package badge renders scan results as status badges.
+package badge
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"unicode/utf8"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// DefaultLabel is the left-hand text of a badge.
+const DefaultLabel = "English-only"
+
+// Badge colors, as used by shields.io.
+const (
+	ColorPassing  = "brightgreen"
+	ColorWarnings = "yellow"
+	ColorErrors   = "red"
+)
+
+// colorHex maps badge colors to their SVG fill.
+var colorHex = map[string]string{
+	ColorPassing:  "#4c1",
+	ColorWarnings: "#dfb317",
+	ColorErrors:   "#e05d44",
+}
+
+// Badge is a two-part status badge.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// FromResult returns the badge for a scan: "passing" without findings,
+// otherwise the number of findings, red when any is an error.
+func FromResult(label string, res scanner.Result) Badge {
+	b := Badge{Label: label, Message: "passing", Color: ColorPassing}
+	n := res.Summary.Findings + res.Summary.FindingsOmitted
+	if n == 0 {
+		return b
+	}
+	b.Message = fmt.Sprintf("%d findings", n)
+	if n == 1 {
+		b.Message = "1 finding"
+	}
+	b.Color = ColorWarnings
+	for _, f := range res.Findings {
+		if f.Severity == scanner.SeverityError {
+			b.Color = ColorErrors
+			break
+		}
+	}
+	return b
+}
+
+// Endpoint returns the badge as a shields.io endpoint JSON document.
+func (b Badge) Endpoint() ([]byte, error) {
+	data, err := json.MarshalIndent(struct {
+		SchemaVersion int    `json:"schemaVersion"`
+		Label         string `json:"label"`
+		Message       string `json:"message"`
+		Color         string `json:"color"`
+	}{1, b.Label, b.Message, b.Color}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// charWidth approximates the width of a character in 11px Verdana, which is
+// close enough to size badges without font metrics.
+const charWidth = 7
+
+// SVG returns the badge as a flat SVG image.
+func (b Badge) SVG() []byte {
+	labelWidth := utf8.RuneCountInString(b.Label)*charWidth + 10
+	messageWidth := utf8.RuneCountInString(b.Message)*charWidth + 10
+	width := labelWidth + messageWidth
+	fill, ok := colorHex[b.Color]
+	if !ok {
+		fill = colorHex[ColorErrors]
+	}
+	label := html.EscapeString(b.Label)
+	message := html.EscapeString(b.Message)
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">
+<title>%[2]s: %[3]s</title>
+<rect width="%[4]d" height="20" fill="#555"/>
+<rect x="%[4]d" width="%[5]d" height="20" fill="%[6]s"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="14">%[2]s</text>
+<text x="%[8]d" y="14">%[3]s</text>
+</g>
+</svg>
+`, width, label, message, labelWidth, messageWidth, fill, labelWidth/2, labelWidth+messageWidth/2))
+}
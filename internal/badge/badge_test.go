@@ -0,0 +1,59 @@
+package badge
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestFromResult(t *testing.T) {
+	tests := []struct {
+		name string
+		res  scanner.Result
+		want Badge
+	}{
+		{name: "passing", want: Badge{Label: DefaultLabel, Message: "passing", Color: ColorPassing}},
+		{
+			name: "one warning",
+			res:  scanner.Result{Findings: []scanner.Finding{{Severity: scanner.SeverityWarning}}, Summary: scanner.Summary{Findings: 1}},
+			want: Badge{Label: DefaultLabel, Message: "1 finding", Color: ColorWarnings},
+		},
+		{
+			name: "errors and omitted findings",
+			res:  scanner.Result{Findings: []scanner.Finding{{Severity: scanner.SeverityWarning}, {Severity: scanner.SeverityError}}, Summary: scanner.Summary{Findings: 2, FindingsOmitted: 3}},
+			want: Badge{Label: DefaultLabel, Message: "5 findings", Color: ColorErrors},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromResult(DefaultLabel, tt.res); got != tt.want {
+				t.Fatalf("FromResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	data, err := Badge{Label: "i18n", Message: "passing", Color: ColorPassing}.Endpoint()
+	if err != nil {
+		t.Fatalf("Endpoint() error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got["schemaVersion"] != float64(1) || got["label"] != "i18n" || got["message"] != "passing" || got["color"] != "brightgreen" {
+		t.Fatalf("unexpected endpoint: %s", data)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	svg := string(Badge{Label: "a<b", Message: "2 findings", Color: ColorErrors}.SVG())
+	for _, want := range []string{`<svg xmlns="http://www.w3.org/2000/svg" width="111"`, `aria-label="a&lt;b: 2 findings"`, `fill="#e05d44"`, `>2 findings</text>`} {
+		if !strings.Contains(svg, want) {
+			t.Fatalf("expected %q in:\n%s", want, svg)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package baseline records the findings of a scan so later scans report
+// only new ones, which lets a legacy repository adopt englint before its
+// existing findings are fixed.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// DefaultPath is where englint baseline writes the baseline.
+const DefaultPath = ".englint-baseline.json"
+
+// version is the format version of baseline files.
+const version = 1
+
+// Entry is one recorded finding. Findings match an entry by path, code
+// point, and fingerprint, which hashes the text of the finding's line but
+// not its number, so entries survive lines added or removed above them.
+// Line and Category only help people reading the file.
+type Entry struct {
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+	CodePoint   string `json:"codePoint"`
+	Category    string `json:"category"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Baseline is the content of a baseline file.
+type Baseline struct {
+	Version  int     `json:"version"`
+	Findings []Entry `json:"findings"`
+}
+
+// New returns the baseline of findings.
+func New(findings []scanner.Finding) Baseline {
+	b := Baseline{Version: version, Findings: make([]Entry, 0, len(findings))}
+	for _, f := range findings {
+		b.Findings = append(b.Findings, Entry{
+			Path:        filepath.ToSlash(f.Path),
+			Line:        f.Line,
+			CodePoint:   f.CodePoint,
+			Category:    f.Category,
+			Fingerprint: f.Fingerprint,
+		})
+	}
+	return b
+}
+
+// Load reads the baseline file at path.
+func Load(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Baseline{}, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if b.Version != version {
+		return Baseline{}, fmt.Errorf("%s is not an englint baseline (version %d, want %d)", path, b.Version, version)
+	}
+	return b, nil
+}
+
+// Write writes b to path.
+func (b Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Apply drops the findings of result that b records, counting them in
+// result.Baselined. Each entry suppresses one finding, so a second copy of
+// a recorded character on an identical line is still reported.
+func (b Baseline) Apply(result *scanner.Result) {
+	remaining := make(map[string]int, len(b.Findings))
+	for _, e := range b.Findings {
+		remaining[key(e.Path, e.CodePoint, e.Fingerprint, e.Line)]++
+	}
+	kept := result.Findings[:0]
+	for _, f := range result.Findings {
+		k := key(filepath.ToSlash(f.Path), f.CodePoint, f.Fingerprint, f.Line)
+		if remaining[k] > 0 {
+			remaining[k]--
+			result.Baselined++
+			continue
+		}
+		kept = append(kept, f)
+	}
+	result.Findings = kept
+	result.Merge(nil)
+}
+
+// key identifies a finding by path, code point, and fingerprint, or by
+// line for findings without a fingerprint.
+func key(path, codePoint, fingerprint string, line int) string {
+	if fingerprint == "" {
+		return fmt.Sprintf("%s\x00%s\x00%d", path, codePoint, line)
+	}
+	return path + "\x00" + codePoint + "\x00" + fingerprint
+}
@@ -0,0 +1,129 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	b := New([]scanner.Finding{{Path: "a.go", Line: 3, CodePoint: "U+65E5", Category: "Han", Fingerprint: "abc"}})
+	if err := b.Write(path); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Version != version || len(got.Findings) != 1 || got.Findings[0] != b.Findings[0] {
+		t.Fatalf("loaded %+v, want %+v", got, b)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "invalid json", content: "{", want: "invalid JSON"},
+		{name: "wrong version", content: `{"version": 2, "findings": []}`, want: "not an englint baseline"},
+		{name: "no version", content: `{"findings": []}`, want: "not an englint baseline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	recorded := []scanner.Finding{
+		{Path: "a.go", Line: 1, CodePoint: "U+65E5", Fingerprint: "f1"},
+		{Path: "a.go", Line: 2, CodePoint: "U+00E9", Fingerprint: "f2"},
+		{Path: "b.go", Line: 4, CodePoint: "U+00E9"},
+	}
+	b := New(recorded)
+
+	tests := []struct {
+		name      string
+		findings  []scanner.Finding
+		wantLines []int
+		baselined int
+	}{
+		{
+			name: "lines shifted",
+			findings: []scanner.Finding{
+				{Path: "a.go", Line: 5, CodePoint: "U+65E5", Fingerprint: "f1"},
+				{Path: "a.go", Line: 6, CodePoint: "U+00E9", Fingerprint: "f2"},
+			},
+			baselined: 2,
+		},
+		{
+			name: "new finding",
+			findings: []scanner.Finding{
+				{Path: "a.go", Line: 1, CodePoint: "U+65E5", Fingerprint: "f1"},
+				{Path: "a.go", Line: 2, CodePoint: "U+65E5", Fingerprint: "f3"},
+			},
+			wantLines: []int{2},
+			baselined: 1,
+		},
+		{
+			name: "duplicate of a recorded finding",
+			findings: []scanner.Finding{
+				{Path: "a.go", Line: 1, CodePoint: "U+65E5", Fingerprint: "f1"},
+				{Path: "a.go", Line: 9, CodePoint: "U+65E5", Fingerprint: "f1"},
+			},
+			wantLines: []int{9},
+			baselined: 1,
+		},
+		{
+			name: "without fingerprint by line",
+			findings: []scanner.Finding{
+				{Path: "b.go", Line: 4, CodePoint: "U+00E9"},
+				{Path: "b.go", Line: 5, CodePoint: "U+00E9"},
+			},
+			wantLines: []int{5},
+			baselined: 1,
+		},
+		{
+			name: "other file",
+			findings: []scanner.Finding{
+				{Path: "c.go", Line: 1, CodePoint: "U+65E5", Fingerprint: "f1"},
+			},
+			wantLines: []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scanner.Result{Findings: tt.findings}
+			b.Apply(&result)
+			var lines []int
+			for _, f := range result.Findings {
+				lines = append(lines, f.Line)
+			}
+			if len(lines) != len(tt.wantLines) {
+				t.Fatalf("lines = %v, want %v", lines, tt.wantLines)
+			}
+			for i := range lines {
+				if lines[i] != tt.wantLines[i] {
+					t.Fatalf("lines = %v, want %v", lines, tt.wantLines)
+				}
+			}
+			if result.Baselined != tt.baselined || result.Summary.FindingsBaselined != tt.baselined {
+				t.Fatalf("baselined = %d (summary %d), want %d", result.Baselined, result.Summary.FindingsBaselined, tt.baselined)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package codeowners matches paths against CODEOWNERS files.
+package codeowners
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are the CODEOWNERS paths checked by Load, relative to the
+// repository root, in the order GitHub and GitLab use them.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Rule assigns Owners to the paths matching Pattern. A rule without owners
+// leaves its paths unowned.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	Rules []Rule
+}
+
+// Load reads the first CODEOWNERS file found under root. ok is false when
+// there is none.
+func Load(root string) (File, bool, error) {
+	for _, location := range Locations {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(location)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return File{}, false, err
+		}
+		return Parse(string(data)), true, nil
+	}
+	return File{}, false, nil
+}
+
+// Parse reads CODEOWNERS rules, skipping comments, GitLab section headers,
+// and patterns that cannot be compiled.
+func Parse(data string) File {
+	var f File
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '[' || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		re, err := compile(fields[0])
+		if err != nil {
+			continue
+		}
+		rule := Rule{Pattern: fields[0], re: re}
+		if len(fields) > 1 {
+			rule.Owners = fields[1:]
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+	return f
+}
+
+// Owners returns the owners of path, a slash-separated path relative to the
+// repository root. The last matching rule wins.
+func (f File) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(path) {
+			return f.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// compile turns a gitignore-style CODEOWNERS pattern into a regular
+// expression. Patterns with a leading or inner slash are anchored to the
+// root, others match at any depth, and a pattern matching a directory also
+// matches everything below it.
+func compile(pattern string) (*regexp.Regexp, error) {
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if dir {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
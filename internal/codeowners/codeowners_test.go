@@ -0,0 +1,65 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOwners(t *testing.T) {
+	f := Parse(`# Default owners
+*       @org/core
+
+[Docs]
+docs/           @org/docs  # trailing comment
+*.md            @org/writers
+/build/logs/    @org/ops
+apps/**/i18n    @org/l10n @alice
+src/generated/
+`)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "main.go", want: []string{"@org/core"}},
+		{path: "README.md", want: []string{"@org/writers"}},
+		{path: "docs/guide.txt", want: []string{"@org/docs"}},
+		{path: "docs/guide.md", want: []string{"@org/writers"}},
+		{path: "build/logs/a.log", want: []string{"@org/ops"}},
+		{path: "src/build/logs/a.log", want: []string{"@org/core"}},
+		{path: "apps/web/i18n/ja.json", want: []string{"@org/l10n", "@alice"}},
+		{path: "apps/i18n/ja.json", want: []string{"@org/l10n", "@alice"}},
+		{path: "./src/generated/api.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := f.Owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Owners(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tmp := t.TempDir()
+	if _, ok, err := Load(tmp); ok || err != nil {
+		t.Fatalf("expected no CODEOWNERS, got %v, %v", ok, err)
+	}
+	for name, content := range map[string]string{"docs/CODEOWNERS": "* @docs\n", ".github/CODEOWNERS": "* @github\n"} {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	f, ok, err := Load(tmp)
+	if err != nil || !ok {
+		t.Fatalf("Load: %v, %v", ok, err)
+	}
+	if got := f.Owners("a.go"); !reflect.DeepEqual(got, []string{"@github"}) {
+		t.Fatalf("expected .github/CODEOWNERS to win, got %q", got)
+	}
+}
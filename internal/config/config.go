@@ -3,10 +3,14 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -15,6 +19,16 @@ const (
 	SeverityWarning = "warning"
 )
 
+// SeverityOff drops findings; it is only valid as a policy level or a
+// category severity.
+const SeverityOff = "off"
+
+const (
+	ExcerptsFull   = "full"
+	ExcerptsOmit   = "omit"
+	ExcerptsRedact = "redact"
+)
+
 const DefaultTemplate = `include:
   - "**/*.ts"
   - "**/*.tsx"
@@ -26,25 +40,271 @@ exclude:
   - "vendor/**"
   - "*.lock"
 allow:
+  # - {value: "™", expires: 2025-12-31, reason: "legacy header", added_by: "alice"}
   - "©"  # copyright symbol
   - "→"  # arrow
+  - "€"  # euro, pound, and yen signs
+  - "£"
+  - "¥"
+  - "°"  # degree sign
 severity: error
+# root: "."  # directory that patterns are relative to (default: this file's directory)
 # ignore_comments: false
 # ignore_strings: false
+# allow_latin_extended: false  # allow é, ü, ß, and other Latin letters
+# ignore_urls: false  # skip URLs and email addresses such as https://例え.jp
+# ignore_blobs: false  # skip long base64/hex tokens and data: URIs
+# decode_escapes: false  # report "\u4e2d" and "&#x4e2d;" in strings like 中
+# check_entities: false  # report &nbsp; and &#x4e2d; in HTML and Markdown
+# ignore_code_blocks: false  # report only prose in .md, .rst, and .adoc files
+# editorconfig: false  # decode files from their .editorconfig charset, such as latin1
+# check_charset: false  # report files that disagree with their .editorconfig charset
+# dedupe_content: false  # report findings in identical copies of a file once
+# invalid_utf8_fix: replace  # replace|strip|legacy, applied by --fix
+# fix_mode: transliterate  # transliterate|question|delete characters without a suggested fix
 # allow_file_patterns:
 #   - "docs/**"
+# allow_files:  # allow a file while its content is unchanged; any edit is reported again
+#   - {path: "docs/intro_ja.md", sha256: "<output of sha256sum docs/intro_ja.md>"}
+# ignore_line_patterns: ["https?://\\S+", "Co-authored-by:.*"]  # regexes; findings on matching lines are dropped
+# fixture_paths: ["testdata/**", "**/testdata/**", "__fixtures__/**", "**/__fixtures__/**"]  # findings are warnings; [] for none
+# excerpts: full  # full|omit|redact
+# max_findings_per_file: 100
+# min_confidence: low  # low|medium|high
+# mmap_threshold: 64MB
+# threads: 4  # files scanned at once; 0 for one per CPU
+# notify_webhook: "https://hooks.slack.com/services/..."
+# notify_include_findings: false
+# no_color: false
+# verbose: false  # list every scanned and skipped file
+# message_templates:  # CATEGORY=template, * for all categories
+#   - "CJK={message}. See https://wiki.example.com/english-only"
+# help_uris:  # CATEGORY=URL linked from each finding, * for all categories
+#   - "CJK=https://wiki.example.com/english-only#cjk"
+# plugins:  # run on every scanned file, JSON over stdin/stdout
+#   - "./tools/banned-terms"
+# categories:  # checked before the built-in categories
+#   - name: "Box Drawing"
+#     ranges: ["U+2500..U+257F"]
+#     severity: warning  # error|warning|off
+#     fix: "-"
+# paths:  # default severity of matching files; later entries win
+#   "docs/**": {severity: warning}
+# scan_head_only:  # scan only the start of matching files; later entries win
+#   "db/seeds/**/*.sql": {bytes: 1MB}  # bytes and/or lines
+# policies:  # CATEGORY=off|warning|error for matching files; later entries win
+#   - paths: ["**/*.md"]
+#     categories: ["Unicode Symbol=off", "Latin Extended=off"]
+#   - paths: ["**/*.vue"]
+#     regions: ["template"]  # template|script|style|text|expression|rune
+#     categories: ["*=off"]
+# allow_patterns:  # allow findings inside regex matches, such as translation calls
+#   - pattern: 't\("[^"]*"\)'
+#     categories: ["CJK"]  # optional; every category when omitted
 `
 
 type Config struct {
-	Include           []string
-	Exclude           []string
-	Allow             []string
-	Severity          string
-	IgnoreComments    bool
-	IgnoreStrings     bool
+	Include []string
+	Exclude []string
+	// Allow holds every allowed value, including those of AllowEntries.
+	Allow []string
+	// AllowEntries are the structured allow entries, which may expire.
+	AllowEntries   []AllowEntry
+	Severity       string
+	IgnoreComments bool
+	IgnoreStrings  bool
+	// AllowLatinExtended permits accented and other non-ASCII Latin letters.
+	AllowLatinExtended bool
+	// IgnoreURLs skips characters inside URLs and email addresses.
+	IgnoreURLs bool
+	// IgnoreBlobs skips characters inside base64 or hex blobs and data URIs.
+	IgnoreBlobs bool
+	// DecodeEscapes reports non-English characters written as escapes inside
+	// string literals.
+	DecodeEscapes bool
+	// CheckEntities reports HTML entities such as &nbsp; in HTML and Markdown.
+	CheckEntities bool
+	// IgnoreCodeBlocks skips code blocks in Markdown, reStructuredText, and AsciiDoc.
+	IgnoreCodeBlocks bool
+	// EditorConfig decodes files from their .editorconfig charset.
+	EditorConfig bool
+	// CheckCharset reports files whose content disagrees with their .editorconfig charset.
+	CheckCharset bool
+	// DedupeContent reports the findings of files with identical content
+	// once, listing the duplicate paths.
+	DedupeContent bool
+	// InvalidUTF8Fix is how --fix repairs invalid UTF-8: replace, strip, or
+	// legacy.
+	InvalidUTF8Fix string
+	// FixMode is what --fix and englint fix replace characters without a
+	// suggested fix with: transliterate, question, or delete. Empty leaves
+	// them unfixed.
+	FixMode           string
 	AllowFilePatterns []string
+	// IgnoreLinePatterns are regular expressions; findings on lines they
+	// match are suppressed.
+	IgnoreLinePatterns []string
+	MmapThreshold      int64
+	MaxFindingsPerFile int
+	Excerpts           string
+	// Threads caps how many files are scanned at once. Zero means one per
+	// CPU.
+	Threads int
+	// NotifyWebhook receives a JSON summary when a scan reports findings.
+	NotifyWebhook         string
+	NotifyIncludeFindings bool
+	// NoColor and Verbose set the scan --no-color and --verbose flags,
+	// mostly from the per-user config.
+	NoColor bool
+	Verbose bool
+	// MessageTemplates holds CATEGORY=template entries that replace the
+	// finding message; see MessageTemplateMap.
+	MessageTemplates []string
+	// HelpURIs holds CATEGORY=URL entries that link findings to guidance
+	// on resolving them; see MessageTemplateMap.
+	HelpURIs []string
+	// Plugins are external checker commands run on every scanned file.
+	Plugins []string
+	// MinConfidence is low, medium, or high; findings below it are dropped.
+	MinConfidence string
+	// Categories are checked before the built-in categories.
+	Categories []Category
+	// Policies set category levels for files matching their paths.
+	Policies []Policy
+	// AllowPatterns allow findings inside regular expression matches.
+	AllowPatterns []AllowPattern
+	// AllowFiles allow whole files while their content is unchanged.
+	AllowFiles []AllowFile
+	// FixturePaths are test fixture patterns whose files default to warning
+	// severity, beneath Paths. Nil means DefaultFixturePaths; empty turns
+	// the convention off.
+	FixturePaths []string
+	// Paths replace Severity for the files matching their patterns.
+	Paths []PathSeverity
+	// ScanHeadOnly limits how much of the files matching their patterns is
+	// scanned.
+	ScanHeadOnly []HeadLimit
+	// Root overrides the directory that patterns are relative to. A relative
+	// Root is relative to the config file.
+	Root string
+	// Dir is the directory of the loaded config file, empty when no file
+	// was loaded. It is not saved.
+	Dir string
+}
+
+// PatternRoot returns the directory that include, exclude,
+// allow_file_patterns, policies, paths, and scan_head_only patterns are
+// relative to: Root,
+// else the config file's directory, else "" for the working directory.
+func (c Config) PatternRoot() string {
+	switch {
+	case c.Root == "":
+		return c.Dir
+	case filepath.IsAbs(c.Root):
+		return c.Root
+	default:
+		return filepath.Join(c.Dir, c.Root)
+	}
+}
+
+// AllowEntry is a structured allow entry such as
+// {value: "©", expires: 2025-12-31, reason: "legacy header", added_by: "alice"}.
+// Value is allowed like a plain entry; once the Expires date has passed, the
+// entry is reported so temporary exceptions do not become permanent. Reason
+// and AddedBy only document the entry and are kept when the config is saved.
+type AllowEntry struct {
+	Value   string
+	Expires string
+	Reason  string
+	AddedBy string
+	// Line is the config line the entry starts on.
+	Line int
+	// Path is the config file the entry was loaded from.
+	Path string
+}
+
+// allowDateLayout is the layout of AllowEntry.Expires.
+const allowDateLayout = "2006-01-02"
+
+// Expired reports whether the entry's expiry date is before now's date.
+func (e AllowEntry) Expired(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+	expires, err := time.Parse(allowDateLayout, e.Expires)
+	if err != nil {
+		return false
+	}
+	return now.Format(allowDateLayout) > expires.Format(allowDateLayout)
+}
+
+// Policy sets the level of categories in files matching any of Paths.
+// Categories holds CATEGORY=level entries, where level is off, warning, or
+// error and CATEGORY may be * for every category; see PolicyLevels. Regions
+// optionally limits the policy to some PolicyRegions of Vue and Svelte
+// components, template files, or Go source.
+type Policy struct {
+	Paths      []string
+	Regions    []string
+	Categories []string
+}
+
+// AllowPattern allows findings inside the matches of Pattern, a regular
+// expression matched against each line, such as the strings passed to an
+// i18n framework's translation function. Categories optionally limits it to
+// some categories.
+type AllowPattern struct {
+	Pattern    string
+	Categories []string
+}
+
+// AllowFile allows the file at Path, relative to the pattern root, while
+// its content has the SHA-256 digest SHA256, in lower-case hex, so an
+// intentionally localized file is exempt but any edit to it is reported
+// again.
+type AllowFile struct {
+	Path   string
+	SHA256 string
+}
+
+// PathSeverity sets the default severity, error or warning, of the files
+// matching Pattern, so production code can be treated more strictly than
+// documentation in one run. Later entries win.
+type PathSeverity struct {
+	Pattern  string
+	Severity string
+}
+
+// HeadLimit scans only the first Bytes bytes or Lines lines of the files
+// matching Pattern, such as giant generated SQL seeds, so they are sampled
+// rather than scanned in full or excluded. Zero means no limit, and later
+// entries win.
+type HeadLimit struct {
+	Pattern string
+	Bytes   int64
+	Lines   int
+}
+
+// PolicyRegions are the valid policy regions: the regions of single-file
+// components and of template files, and Go rune literals.
+var PolicyRegions = []string{"template", "script", "style", "text", "expression", "rune"}
+
+// Category is a custom finding category for the code points in Ranges,
+// written as "U+2500..U+257F" or a single "U+00A0". Severity overrides the
+// default severity when set, and Fix is the replacement suggested for its
+// characters.
+type Category struct {
+	Name     string
+	Ranges   []string
+	Severity string
+	Fix      string
 }
 
+// DefaultFixturePaths match the test fixture directories that findings are
+// only warnings in unless fixture_paths says otherwise: testdata, which the
+// go tool ignores, and __fixtures__, the Jest convention.
+var DefaultFixturePaths = []string{"testdata/**", "**/testdata/**", "__fixtures__/**", "**/__fixtures__/**"}
+
 var parseYAML = parseConfigYAML
 var renderYAML = renderConfigYAML
 
@@ -52,11 +312,12 @@ func DefaultConfig() Config {
 	return Config{
 		Include:           []string{"**/*.ts", "**/*.tsx", "**/*.go", "**/*.md"},
 		Exclude:           []string{"node_modules/**", ".git/**", "vendor/**", "*.lock"},
-		Allow:             []string{"©", "→"},
+		Allow:             []string{"©", "→", "€", "£", "¥", "°"},
 		Severity:          SeverityError,
 		IgnoreComments:    false,
 		IgnoreStrings:     false,
 		AllowFilePatterns: nil,
+		FixturePaths:      slices.Clone(DefaultFixturePaths),
 	}
 }
 
@@ -71,10 +332,17 @@ func ApplyDefaults(cfg Config) Config {
 	if cfg.Allow == nil {
 		cfg.Allow = defaults.Allow
 	}
+	if cfg.FixturePaths == nil {
+		cfg.FixturePaths = defaults.FixturePaths
+	}
 	if strings.TrimSpace(cfg.Severity) == "" {
 		cfg.Severity = defaults.Severity
 	}
 	cfg.Severity = strings.ToLower(strings.TrimSpace(cfg.Severity))
+	cfg.Excerpts = strings.ToLower(strings.TrimSpace(cfg.Excerpts))
+	cfg.MinConfidence = strings.ToLower(strings.TrimSpace(cfg.MinConfidence))
+	cfg.InvalidUTF8Fix = strings.ToLower(strings.TrimSpace(cfg.InvalidUTF8Fix))
+	cfg.FixMode = strings.ToLower(strings.TrimSpace(cfg.FixMode))
 	return cfg
 }
 
@@ -82,6 +350,160 @@ func Validate(cfg Config) error {
 	if cfg.Severity != SeverityError && cfg.Severity != SeverityWarning {
 		return fmt.Errorf("severity must be %q or %q", SeverityError, SeverityWarning)
 	}
+	switch cfg.Excerpts {
+	case "", ExcerptsFull, ExcerptsOmit, ExcerptsRedact:
+	default:
+		return fmt.Errorf("excerpts must be %q, %q, or %q", ExcerptsFull, ExcerptsOmit, ExcerptsRedact)
+	}
+	switch cfg.MinConfidence {
+	case "", "low", "medium", "high":
+	default:
+		return errors.New(`min_confidence must be "low", "medium", or "high"`)
+	}
+	switch cfg.InvalidUTF8Fix {
+	case "", "replace", "strip", "legacy":
+	default:
+		return errors.New(`invalid_utf8_fix must be "replace", "strip", or "legacy"`)
+	}
+	switch cfg.FixMode {
+	case "", "transliterate", "question", "delete":
+	default:
+		return errors.New(`fix_mode must be "transliterate", "question", or "delete"`)
+	}
+	if cfg.MaxFindingsPerFile < 0 {
+		return errors.New("max_findings_per_file must not be negative")
+	}
+	if cfg.MmapThreshold < 0 {
+		return errors.New("mmap_threshold must not be negative")
+	}
+	for _, pattern := range cfg.IgnoreLinePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("ignore_line_patterns: %w", err)
+		}
+	}
+	if cfg.Threads < 0 {
+		return errors.New("threads must not be negative")
+	}
+	if cfg.NotifyWebhook != "" {
+		u, err := url.Parse(cfg.NotifyWebhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("notify_webhook must be an http or https URL")
+		}
+	}
+	for _, v := range cfg.MessageTemplates {
+		category, template, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(category) == "" || strings.TrimSpace(template) == "" {
+			return fmt.Errorf("message_templates entry %q must be CATEGORY=template", v)
+		}
+	}
+	for _, v := range cfg.HelpURIs {
+		category, uri, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(category) == "" {
+			return fmt.Errorf("help_uris entry %q must be CATEGORY=URL", v)
+		}
+		if u, err := url.Parse(strings.TrimSpace(uri)); err != nil || !u.IsAbs() {
+			return fmt.Errorf("help_uris entry %q must have an absolute URL", v)
+		}
+	}
+	names := make(map[string]bool, len(cfg.Categories))
+	for _, c := range cfg.Categories {
+		if strings.TrimSpace(c.Name) == "" {
+			return errors.New("categories entries require a name")
+		}
+		if names[strings.ToLower(c.Name)] {
+			return fmt.Errorf("category %q is defined more than once", c.Name)
+		}
+		names[strings.ToLower(c.Name)] = true
+		if len(c.Ranges) == 0 {
+			return fmt.Errorf("category %q requires ranges", c.Name)
+		}
+		for _, r := range c.Ranges {
+			if _, _, err := ParseRuneRange(r); err != nil {
+				return fmt.Errorf("category %q: %w", c.Name, err)
+			}
+		}
+		switch c.Severity {
+		case "", SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return fmt.Errorf("category %q: severity must be %q, %q, or %q", c.Name, SeverityError, SeverityWarning, SeverityOff)
+		}
+	}
+	for i, p := range cfg.Policies {
+		if len(p.Paths) == 0 {
+			return fmt.Errorf("policies entry %d requires paths", i+1)
+		}
+		if len(p.Categories) == 0 {
+			return fmt.Errorf("policies entry %d requires categories", i+1)
+		}
+		for _, region := range p.Regions {
+			if !slices.Contains(PolicyRegions, region) {
+				return fmt.Errorf("policies entry %d: region %q must be one of %s", i+1, region, strings.Join(PolicyRegions, ", "))
+			}
+		}
+		for _, entry := range p.Categories {
+			category, level, ok := strings.Cut(entry, "=")
+			if !ok || strings.TrimSpace(category) == "" {
+				return fmt.Errorf("policies entry %d: %q must be CATEGORY=level", i+1, entry)
+			}
+			switch strings.ToLower(strings.TrimSpace(level)) {
+			case SeverityOff, SeverityWarning, SeverityError:
+			default:
+				return fmt.Errorf("policies entry %d: level of %q must be %q, %q, or %q", i+1, category, SeverityOff, SeverityWarning, SeverityError)
+			}
+		}
+	}
+	for i, p := range cfg.AllowPatterns {
+		if p.Pattern == "" {
+			return fmt.Errorf("allow_patterns entry %d requires a pattern", i+1)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("allow_patterns entry %d: %w", i+1, err)
+		}
+	}
+	for i, a := range cfg.AllowFiles {
+		if strings.TrimSpace(a.Path) == "" {
+			return fmt.Errorf("allow_files entry %d requires a path", i+1)
+		}
+		if len(a.SHA256) != 64 || strings.Trim(a.SHA256, "0123456789abcdef") != "" {
+			return fmt.Errorf("allow_files entry %q: sha256 must be 64 hex digits", a.Path)
+		}
+	}
+	for _, p := range cfg.Paths {
+		if strings.TrimSpace(p.Pattern) == "" {
+			return errors.New("paths entries require a pattern")
+		}
+		if p.Severity != SeverityError && p.Severity != SeverityWarning {
+			return fmt.Errorf("paths entry %q: severity must be %q or %q", p.Pattern, SeverityError, SeverityWarning)
+		}
+	}
+	for _, pattern := range cfg.FixturePaths {
+		if strings.TrimSpace(pattern) == "" {
+			return errors.New("fixture_paths entries must not be empty")
+		}
+	}
+	for _, h := range cfg.ScanHeadOnly {
+		if strings.TrimSpace(h.Pattern) == "" {
+			return errors.New("scan_head_only entries require a pattern")
+		}
+		if h.Bytes < 0 || h.Lines < 0 || (h.Bytes == 0 && h.Lines == 0) {
+			return fmt.Errorf("scan_head_only entry %q requires a positive bytes or lines limit", h.Pattern)
+		}
+	}
+	for _, v := range cfg.Plugins {
+		if strings.TrimSpace(v) == "" {
+			return errors.New("plugins entries must not be empty")
+		}
+	}
+	for _, e := range cfg.AllowEntries {
+		if strings.TrimSpace(e.Value) == "" {
+			return fmt.Errorf("allow entry on line %d requires a value", e.Line)
+		}
+		if e.Expires != "" {
+			if _, err := time.Parse(allowDateLayout, e.Expires); err != nil {
+				return fmt.Errorf("allow entry %q: expires must be a YYYY-MM-DD date", e.Value)
+			}
+		}
+	}
 	for _, v := range cfg.Allow {
 		if strings.TrimSpace(v) == "" {
 			return errors.New("allow values must not be empty")
@@ -94,29 +516,259 @@ func Validate(cfg Config) error {
 }
 
 func Load(path string) (Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			cfg := ApplyDefaults(Config{})
-			if err := Validate(cfg); err != nil {
+	return LoadLayers([]string{path})
+}
+
+// LoadLayers loads the config files at paths and merges them in order: a
+// key set in a later file replaces the value of earlier files, and keys it
+// leaves out keep their earlier values. Missing files are skipped. Patterns
+// are relative to the directory of the last file found, or to the last root
+// set.
+func LoadLayers(paths []string) (Config, error) {
+	return LoadEnv(nil, paths)
+}
+
+// EnvPrefix starts the environment variables that set config keys, such as
+// ENGLINT_SEVERITY for severity; see LoadEnv.
+const EnvPrefix = "ENGLINT_"
+
+// envScalarKeys and envListKeys are the config keys LoadEnv reads from the
+// environment. List values are comma-separated, so keys whose values may
+// contain commas, such as regular expressions, are left out. ENGLINT_NO_COLOR
+// follows the NO_COLOR convention instead, so callers check it themselves.
+var (
+	envScalarKeys = []string{
+		"severity", "ignore_comments", "ignore_strings", "allow_latin_extended",
+		"ignore_urls", "ignore_blobs", "decode_escapes", "check_entities",
+		"ignore_code_blocks", "editorconfig", "check_charset", "dedupe_content", "invalid_utf8_fix", "fix_mode",
+		"excerpts", "min_confidence", "max_findings_per_file", "threads",
+		"mmap_threshold", "notify_webhook", "notify_include_findings",
+		"verbose",
+	}
+	envListKeys = []string{"include", "exclude", "allow", "allow_file_patterns", "fixture_paths", "plugins"}
+)
+
+// UserConfigPath returns the per-user config file,
+// $XDG_CONFIG_HOME/englint/config.yaml or else
+// $HOME/.config/englint/config.yaml, or "" when neither variable is set.
+func UserConfigPath(getenv func(string) string) string {
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "englint", "config.yaml")
+	}
+	if home := getenv("HOME"); home != "" {
+		return filepath.Join(home, ".config", "englint", "config.yaml")
+	}
+	return ""
+}
+
+// LoadEnv is LoadLayers with two layers beneath the files read through
+// getenv. The lowest is the per-user config at UserConfigPath, whose allow
+// entries are added to those of every other layer instead of being
+// replaced. Above it, ENGLINT_<KEY> sets the config key <key>, so
+// ENGLINT_SEVERITY=warning applies unless a config file sets severity, and
+// list keys such as ENGLINT_EXCLUDE take comma-separated values. A nil
+// getenv reads nothing.
+func LoadEnv(getenv func(string) string, paths []string) (Config, error) {
+	var cfg Config
+	var user Config
+	if getenv != nil {
+		if path := UserConfigPath(getenv); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
 				return Config{}, err
 			}
-			return cfg, nil
+			if err == nil {
+				user, err = parseYAML(string(data))
+				if err != nil {
+					return Config{}, fmt.Errorf("invalid YAML in %s: %w", path, err)
+				}
+				for i := range user.AllowEntries {
+					user.AllowEntries[i].Path = path
+				}
+				keys := slices.DeleteFunc(topLevelKeys(string(data)), func(key string) bool {
+					return key == "allow"
+				})
+				cfg.overlay(user, keys)
+			}
+		}
+		for _, key := range append(slices.Clone(envScalarKeys), envListKeys...) {
+			name := EnvPrefix + strings.ToUpper(key)
+			value := getenv(name)
+			if value == "" {
+				continue
+			}
+			var text string
+			if slices.Contains(envListKeys, key) {
+				var items []string
+				for _, item := range strings.Split(value, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+				var b strings.Builder
+				writeList(&b, key, items)
+				text = b.String()
+			} else {
+				text = key + ": " + strconv.Quote(value) + "\n"
+			}
+			layer, err := parseYAML(text)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			cfg.overlay(layer, []string{key})
 		}
-		return Config{}, err
 	}
-
-	cfg, err := parseYAML(string(data))
-	if err != nil {
-		return Config{}, fmt.Errorf("invalid YAML in %s: %w", path, err)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, err
+		}
+		layer, err := parseYAML(string(data))
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid YAML in %s: %w", path, err)
+		}
+		dir := filepath.Dir(path)
+		for i := range layer.AllowEntries {
+			layer.AllowEntries[i].Path = path
+		}
+		// A root from an earlier file stays relative to that file.
+		if cfg.Root != "" && !filepath.IsAbs(cfg.Root) && cfg.Dir != dir {
+			cfg.Root = filepath.Join(cfg.Dir, cfg.Root)
+		}
+		cfg.overlay(layer, topLevelKeys(string(data)))
+		cfg.Dir = dir
 	}
 	cfg = ApplyDefaults(cfg)
+	for _, value := range user.Allow {
+		if !slices.Contains(cfg.Allow, value) {
+			cfg.Allow = append(cfg.Allow, value)
+		}
+	}
+	cfg.AllowEntries = append(cfg.AllowEntries, user.AllowEntries...)
 	if err := Validate(cfg); err != nil {
 		return Config{}, err
 	}
 	return cfg, nil
 }
 
+// LoadNested reads a config file found in a subdirectory while scanning.
+// Defaults are not applied, so only the keys the file sets are filled in.
+func LoadNested(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := parseYAML(string(data))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return cfg, nil
+}
+
+// topLevelKeys returns the unindented keys of a config file.
+func topLevelKeys(input string) []string {
+	var keys []string
+	for _, line := range strings.Split(input, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, ":"); ok {
+			keys = append(keys, strings.TrimSpace(key))
+		}
+	}
+	return keys
+}
+
+// overlay replaces the values of c for the keys set in layer.
+func (c *Config) overlay(layer Config, keys []string) {
+	for _, key := range keys {
+		switch key {
+		case "include":
+			c.Include = layer.Include
+		case "exclude":
+			c.Exclude = layer.Exclude
+		case "allow":
+			c.Allow = layer.Allow
+			c.AllowEntries = layer.AllowEntries
+		case "severity":
+			c.Severity = layer.Severity
+		case "ignore_comments":
+			c.IgnoreComments = layer.IgnoreComments
+		case "ignore_strings":
+			c.IgnoreStrings = layer.IgnoreStrings
+		case "allow_latin_extended":
+			c.AllowLatinExtended = layer.AllowLatinExtended
+		case "ignore_urls":
+			c.IgnoreURLs = layer.IgnoreURLs
+		case "ignore_blobs":
+			c.IgnoreBlobs = layer.IgnoreBlobs
+		case "decode_escapes":
+			c.DecodeEscapes = layer.DecodeEscapes
+		case "check_entities":
+			c.CheckEntities = layer.CheckEntities
+		case "ignore_code_blocks":
+			c.IgnoreCodeBlocks = layer.IgnoreCodeBlocks
+		case "editorconfig":
+			c.EditorConfig = layer.EditorConfig
+		case "check_charset":
+			c.CheckCharset = layer.CheckCharset
+		case "dedupe_content":
+			c.DedupeContent = layer.DedupeContent
+		case "invalid_utf8_fix":
+			c.InvalidUTF8Fix = layer.InvalidUTF8Fix
+		case "fix_mode":
+			c.FixMode = layer.FixMode
+		case "allow_file_patterns":
+			c.AllowFilePatterns = layer.AllowFilePatterns
+		case "ignore_line_patterns":
+			c.IgnoreLinePatterns = layer.IgnoreLinePatterns
+		case "mmap_threshold":
+			c.MmapThreshold = layer.MmapThreshold
+		case "max_findings_per_file":
+			c.MaxFindingsPerFile = layer.MaxFindingsPerFile
+		case "excerpts":
+			c.Excerpts = layer.Excerpts
+		case "threads":
+			c.Threads = layer.Threads
+		case "notify_webhook":
+			c.NotifyWebhook = layer.NotifyWebhook
+		case "notify_include_findings":
+			c.NotifyIncludeFindings = layer.NotifyIncludeFindings
+		case "no_color":
+			c.NoColor = layer.NoColor
+		case "verbose":
+			c.Verbose = layer.Verbose
+		case "message_templates":
+			c.MessageTemplates = layer.MessageTemplates
+		case "help_uris":
+			c.HelpURIs = layer.HelpURIs
+		case "plugins":
+			c.Plugins = layer.Plugins
+		case "min_confidence":
+			c.MinConfidence = layer.MinConfidence
+		case "categories":
+			c.Categories = layer.Categories
+		case "policies":
+			c.Policies = layer.Policies
+		case "allow_patterns":
+			c.AllowPatterns = layer.AllowPatterns
+		case "allow_files":
+			c.AllowFiles = layer.AllowFiles
+		case "fixture_paths":
+			c.FixturePaths = layer.FixturePaths
+		case "paths":
+			c.Paths = layer.Paths
+		case "scan_head_only":
+			c.ScanHeadOnly = layer.ScanHeadOnly
+		case "root":
+			c.Root = layer.Root
+		}
+	}
+}
+
 func Save(path string, cfg Config) error {
 	cfg = ApplyDefaults(cfg)
 	if err := Validate(cfg); err != nil {
@@ -142,6 +794,9 @@ func WriteDefault(path string) error {
 	return os.WriteFile(path, []byte(DefaultTemplate), 0o644)
 }
 
+// AllowedRuneMap returns the characters of the allow values. Config.Allow
+// holds the values of plain and structured entries alike, so passing it
+// covers both forms.
 func AllowedRuneMap(allow []string) map[rune]struct{} {
 	out := make(map[rune]struct{})
 	for _, item := range allow {
@@ -152,9 +807,78 @@ func AllowedRuneMap(allow []string) map[rune]struct{} {
 	return out
 }
 
+// ParseRuneRange parses "U+2500..U+257F", "U+2500-U+257F", or a single
+// code point such as "U+00A0" into an inclusive range.
+func ParseRuneRange(value string) (lo, hi rune, err error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(value), "..")
+	if !isRange {
+		first, last, isRange = strings.Cut(first, "-")
+	}
+	if lo, err = parseCodePoint(first); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", value, err)
+	}
+	hi = lo
+	if isRange {
+		if hi, err = parseCodePoint(last); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q: %w", value, err)
+		}
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("invalid range %q: start is after end", value)
+	}
+	return lo, hi, nil
+}
+
+func parseCodePoint(value string) (rune, error) {
+	value = strings.TrimSpace(value)
+	hex, ok := strings.CutPrefix(strings.ToUpper(value), "U+")
+	if !ok {
+		return 0, fmt.Errorf("code point %q must start with U+", value)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || n > utf8.MaxRune {
+		return 0, fmt.Errorf("invalid code point %q", value)
+	}
+	return rune(n), nil
+}
+
+// PolicyLevels turns CATEGORY=level entries into a map keyed by lower-case
+// category with lower-case levels. Later entries for the same category win.
+func PolicyLevels(entries []string) map[string]string {
+	out := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		category, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		out[strings.ToLower(strings.TrimSpace(category))] = strings.ToLower(strings.TrimSpace(level))
+	}
+	return out
+}
+
+// MessageTemplateMap turns CATEGORY=template entries, or the CATEGORY=URL
+// entries of help_uris, into a map keyed by lower-case category. Later entries for the same category win.
+func MessageTemplateMap(entries []string) map[string]string {
+	out := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		category, template, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		out[strings.ToLower(strings.TrimSpace(category))] = strings.TrimSpace(template)
+	}
+	return out
+}
+
 func parseConfigYAML(input string) (Config, error) {
 	cfg := Config{}
 	currentList := ""
+	// allowEntry is set while indented fields continue a block-style
+	// structured allow entry.
+	allowEntry := false
+	// pathsIndent is the indentation of the patterns under paths; deeper
+	// lines are fields of the pattern above them.
+	pathsIndent := 0
 	lines := strings.Split(input, "\n")
 
 	for i, raw := range lines {
@@ -163,10 +887,121 @@ func parseConfigYAML(input string) (Config, error) {
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		if allowEntry && (raw[0] == ' ' || raw[0] == '\t') && !strings.HasPrefix(line, "- ") {
+			if err := parseAllowField(&cfg.AllowEntries[len(cfg.AllowEntries)-1], line); err != nil {
+				return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		allowEntry = false
+		// paths is a mapping of patterns to {severity: level} mappings in
+		// flow or block style.
+		if currentList == "paths" {
+			indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+			switch {
+			case indent == 0:
+				currentList = ""
+			case len(cfg.Paths) > 0 && indent > pathsIndent:
+				if err := parsePathField(&cfg.Paths[len(cfg.Paths)-1], line); err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				continue
+			default:
+				pathsIndent = indent
+				entry, err := parsePathEntry(line)
+				if err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				cfg.Paths = append(cfg.Paths, entry)
+				continue
+			}
+		}
+		// scan_head_only is a mapping of patterns to {bytes: size, lines: n}
+		// mappings, in the same styles as paths.
+		if currentList == "scan_head_only" {
+			indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+			switch {
+			case indent == 0:
+				currentList = ""
+			case len(cfg.ScanHeadOnly) > 0 && indent > pathsIndent:
+				if err := parseHeadLimitField(&cfg.ScanHeadOnly[len(cfg.ScanHeadOnly)-1], line); err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				continue
+			default:
+				pathsIndent = indent
+				entry, err := parseHeadLimitEntry(line)
+				if err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				cfg.ScanHeadOnly = append(cfg.ScanHeadOnly, entry)
+				continue
+			}
+		}
+		// categories, policies, allow_patterns, and allow_files are lists
+		// of mappings: "- key: value" starts an item and indented
+		// "key: value" lines continue it.
+		if currentList == "categories" || currentList == "policies" || currentList == "allow_patterns" || currentList == "allow_files" {
+			items := len(cfg.Categories)
+			switch currentList {
+			case "policies":
+				items = len(cfg.Policies)
+			case "allow_patterns":
+				items = len(cfg.AllowPatterns)
+			case "allow_files":
+				items = len(cfg.AllowFiles)
+			}
+			switch {
+			case strings.HasPrefix(line, "- "):
+				switch currentList {
+				case "categories":
+					cfg.Categories = append(cfg.Categories, Category{})
+				case "policies":
+					cfg.Policies = append(cfg.Policies, Policy{})
+				case "allow_files":
+					cfg.AllowFiles = append(cfg.AllowFiles, AllowFile{})
+				default:
+					cfg.AllowPatterns = append(cfg.AllowPatterns, AllowPattern{})
+				}
+				items++
+				line = strings.TrimSpace(strings.TrimPrefix(line, "- "))
+			case raw[0] != ' ' && raw[0] != '\t':
+				currentList = ""
+			case items == 0:
+				return Config{}, fmt.Errorf("line %d: %s field without list item", lineNo, currentList)
+			}
+			if currentList != "" {
+				var err error
+				switch currentList {
+				case "categories":
+					err = parseCategoryField(&cfg.Categories[items-1], line)
+				case "policies":
+					err = parsePolicyField(&cfg.Policies[items-1], line)
+				case "allow_files":
+					err = parseAllowFileItem(&cfg.AllowFiles[items-1], line)
+				default:
+					err = parseAllowPatternField(&cfg.AllowPatterns[items-1], line)
+				}
+				if err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				continue
+			}
+		}
 		if strings.HasPrefix(line, "- ") {
 			if currentList == "" {
 				return Config{}, fmt.Errorf("line %d: list item without key", lineNo)
 			}
+			if item := strings.TrimSpace(strings.TrimPrefix(line, "- ")); currentList == "allow" && isAllowEntry(item) {
+				entry, err := parseAllowEntry(item)
+				if err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				entry.Line = lineNo
+				cfg.AllowEntries = append(cfg.AllowEntries, entry)
+				allowEntry = !strings.HasPrefix(item, "{")
+				continue
+			}
 			value, err := parseScalar(strings.TrimSpace(strings.TrimPrefix(line, "- ")))
 			if err != nil {
 				return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
@@ -180,6 +1015,16 @@ func parseConfigYAML(input string) (Config, error) {
 				cfg.Allow = append(cfg.Allow, value)
 			case "allow_file_patterns":
 				cfg.AllowFilePatterns = append(cfg.AllowFilePatterns, value)
+			case "ignore_line_patterns":
+				cfg.IgnoreLinePatterns = append(cfg.IgnoreLinePatterns, value)
+			case "fixture_paths":
+				cfg.FixturePaths = append(cfg.FixturePaths, value)
+			case "message_templates":
+				cfg.MessageTemplates = append(cfg.MessageTemplates, value)
+			case "help_uris":
+				cfg.HelpURIs = append(cfg.HelpURIs, value)
+			case "plugins":
+				cfg.Plugins = append(cfg.Plugins, value)
 			default:
 				return Config{}, fmt.Errorf("line %d: key %q does not support list values", lineNo, currentList)
 			}
@@ -216,16 +1061,578 @@ func parseConfigYAML(input string) (Config, error) {
 			if err != nil {
 				return Config{}, fmt.Errorf("line %d: ignore_strings must be true or false", lineNo)
 			}
-		case "include", "exclude", "allow", "allow_file_patterns":
+		case "allow_latin_extended":
+			cfg.AllowLatinExtended, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: allow_latin_extended must be true or false", lineNo)
+			}
+		case "ignore_urls":
+			cfg.IgnoreURLs, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: ignore_urls must be true or false", lineNo)
+			}
+		case "ignore_blobs":
+			cfg.IgnoreBlobs, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: ignore_blobs must be true or false", lineNo)
+			}
+		case "decode_escapes":
+			cfg.DecodeEscapes, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: decode_escapes must be true or false", lineNo)
+			}
+		case "check_entities":
+			cfg.CheckEntities, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: check_entities must be true or false", lineNo)
+			}
+		case "ignore_code_blocks":
+			cfg.IgnoreCodeBlocks, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: ignore_code_blocks must be true or false", lineNo)
+			}
+		case "editorconfig":
+			cfg.EditorConfig, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: editorconfig must be true or false", lineNo)
+			}
+		case "check_charset":
+			cfg.CheckCharset, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: check_charset must be true or false", lineNo)
+			}
+		case "dedupe_content":
+			cfg.DedupeContent, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: dedupe_content must be true or false", lineNo)
+			}
+		case "excerpts":
+			cfg.Excerpts = value
+		case "min_confidence":
+			cfg.MinConfidence = value
+		case "root":
+			cfg.Root = value
+		case "invalid_utf8_fix":
+			cfg.InvalidUTF8Fix = value
+		case "fix_mode":
+			cfg.FixMode = value
+		case "max_findings_per_file":
+			cfg.MaxFindingsPerFile, err = strconv.Atoi(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: max_findings_per_file must be an integer", lineNo)
+			}
+		case "ignore_line_patterns":
+			patterns, err := parseFlowList(valueRaw)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: ignore_line_patterns: %w", lineNo, err)
+			}
+			cfg.IgnoreLinePatterns = append(cfg.IgnoreLinePatterns, patterns...)
+		case "fixture_paths":
+			patterns, err := parseFlowList(valueRaw)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: fixture_paths: %w", lineNo, err)
+			}
+			// fixture_paths: [] turns the convention off, so keep it
+			// distinct from an unset key.
+			cfg.FixturePaths = append(cfg.FixturePaths, patterns...)
+			if cfg.FixturePaths == nil {
+				cfg.FixturePaths = []string{}
+			}
+		case "threads":
+			cfg.Threads, err = strconv.Atoi(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: threads must be an integer", lineNo)
+			}
+		case "mmap_threshold":
+			cfg.MmapThreshold, err = ParseByteSize(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: mmap_threshold: %w", lineNo, err)
+			}
+		case "notify_webhook":
+			cfg.NotifyWebhook = value
+		case "notify_include_findings":
+			cfg.NotifyIncludeFindings, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: notify_include_findings must be true or false", lineNo)
+			}
+		case "no_color":
+			cfg.NoColor, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: no_color must be true or false", lineNo)
+			}
+		case "verbose":
+			cfg.Verbose, err = strconv.ParseBool(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("line %d: verbose must be true or false", lineNo)
+			}
+		case "paths":
+			inner, ok := strings.CutPrefix(stripInlineComment(valueRaw), "{")
+			inner, closed := strings.CutSuffix(inner, "}")
+			if !ok || !closed {
+				return Config{}, fmt.Errorf("line %d: paths must be a mapping of patterns", lineNo)
+			}
+			for _, field := range splitFlowFields(inner) {
+				entry, err := parsePathEntry(strings.TrimSpace(field))
+				if err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				cfg.Paths = append(cfg.Paths, entry)
+			}
+		case "scan_head_only":
+			inner, ok := strings.CutPrefix(stripInlineComment(valueRaw), "{")
+			inner, closed := strings.CutSuffix(inner, "}")
+			if !ok || !closed {
+				return Config{}, fmt.Errorf("line %d: scan_head_only must be a mapping of patterns", lineNo)
+			}
+			for _, field := range splitFlowFields(inner) {
+				entry, err := parseHeadLimitEntry(strings.TrimSpace(field))
+				if err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				cfg.ScanHeadOnly = append(cfg.ScanHeadOnly, entry)
+			}
+		case "allow_files":
+			inner, ok := strings.CutPrefix(strings.TrimSpace(stripInlineComment(valueRaw)), "[")
+			inner, closed := strings.CutSuffix(inner, "]")
+			if !ok || !closed {
+				return Config{}, fmt.Errorf("line %d: allow_files must be a list of {path: ..., sha256: ...} mappings", lineNo)
+			}
+			for _, item := range splitFlowFields(inner) {
+				if strings.TrimSpace(item) == "" {
+					continue
+				}
+				var entry AllowFile
+				if err := parseAllowFileItem(&entry, strings.TrimSpace(item)); err != nil {
+					return Config{}, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				cfg.AllowFiles = append(cfg.AllowFiles, entry)
+			}
+		case "include", "exclude", "allow", "allow_file_patterns", "message_templates", "help_uris", "plugins", "categories", "policies", "allow_patterns":
 			return Config{}, fmt.Errorf("line %d: key %q requires list values", lineNo, key)
 		default:
 			return Config{}, fmt.Errorf("line %d: unknown key %q", lineNo, key)
 		}
 	}
 
+	for _, e := range cfg.AllowEntries {
+		cfg.Allow = append(cfg.Allow, e.Value)
+	}
 	return cfg, nil
 }
 
+// isAllowEntry reports whether an allow list item is a structured entry:
+// a {key: value, ...} flow mapping or the first "key: value" field of a
+// block mapping.
+func isAllowEntry(item string) bool {
+	if strings.HasPrefix(item, "{") {
+		return true
+	}
+	key, _, ok := strings.Cut(item, ":")
+	switch strings.TrimSpace(key) {
+	case "value", "expires", "reason", "added_by":
+		return ok
+	}
+	return false
+}
+
+// parseAllowEntry parses a structured allow entry in flow or block style.
+func parseAllowEntry(item string) (AllowEntry, error) {
+	var entry AllowEntry
+	inner, ok := strings.CutPrefix(stripInlineComment(item), "{")
+	if !ok {
+		return entry, parseAllowField(&entry, item)
+	}
+	inner, ok = strings.CutSuffix(inner, "}")
+	if !ok {
+		return entry, fmt.Errorf("unterminated allow entry %q", item)
+	}
+	for _, field := range splitFlowFields(inner) {
+		if err := parseAllowField(&entry, field); err != nil {
+			return entry, err
+		}
+	}
+	return entry, nil
+}
+
+// parseAllowField parses one "key: value" field of a structured allow entry.
+func parseAllowField(e *AllowEntry, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in allow entry")
+	}
+	value, err := parseScalar(valueRaw)
+	if err != nil {
+		return err
+	}
+	switch strings.TrimSpace(key) {
+	case "value":
+		e.Value = value
+	case "expires":
+		e.Expires = value
+	case "reason":
+		e.Reason = value
+	case "added_by":
+		e.AddedBy = value
+	default:
+		return fmt.Errorf("unknown allow key %q", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// parsePathEntry parses a "pattern: {severity: level}" entry of paths, or
+// the "pattern:" line starting a block-style entry.
+func parsePathEntry(line string) (PathSeverity, error) {
+	keyRaw, valueRaw, ok := cutMappingKey(stripInlineComment(line))
+	if !ok {
+		return PathSeverity{}, errors.New("expected pattern: {severity: level} in paths")
+	}
+	pattern, err := parseScalar(keyRaw)
+	if err != nil {
+		return PathSeverity{}, err
+	}
+	entry := PathSeverity{Pattern: pattern}
+	valueRaw = strings.TrimSpace(valueRaw)
+	if valueRaw == "" {
+		return entry, nil
+	}
+	inner, ok := strings.CutPrefix(valueRaw, "{")
+	inner, closed := strings.CutSuffix(inner, "}")
+	if !ok || !closed {
+		return PathSeverity{}, fmt.Errorf("paths entry %q must be a mapping such as {severity: warning}", pattern)
+	}
+	for _, field := range splitFlowFields(inner) {
+		if err := parsePathField(&entry, field); err != nil {
+			return PathSeverity{}, err
+		}
+	}
+	return entry, nil
+}
+
+// parsePathField parses one "key: value" field of a paths entry.
+func parsePathField(p *PathSeverity, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in paths entry")
+	}
+	value, err := parseScalar(valueRaw)
+	if err != nil {
+		return err
+	}
+	switch strings.TrimSpace(key) {
+	case "severity":
+		p.Severity = strings.ToLower(value)
+	default:
+		return fmt.Errorf("unknown paths key %q", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// parseHeadLimitEntry parses a "pattern: {bytes: size, lines: n}" entry of
+// scan_head_only, or a block-style "pattern:" whose fields follow.
+func parseHeadLimitEntry(line string) (HeadLimit, error) {
+	keyRaw, valueRaw, ok := cutMappingKey(stripInlineComment(line))
+	if !ok {
+		return HeadLimit{}, errors.New("expected pattern: {bytes: size} in scan_head_only")
+	}
+	pattern, err := parseScalar(keyRaw)
+	if err != nil {
+		return HeadLimit{}, err
+	}
+	entry := HeadLimit{Pattern: pattern}
+	valueRaw = strings.TrimSpace(valueRaw)
+	if valueRaw == "" {
+		return entry, nil
+	}
+	inner, ok := strings.CutPrefix(valueRaw, "{")
+	inner, closed := strings.CutSuffix(inner, "}")
+	if !ok || !closed {
+		return HeadLimit{}, fmt.Errorf("scan_head_only entry %q must be a mapping such as {bytes: 1MB}", pattern)
+	}
+	for _, field := range splitFlowFields(inner) {
+		if err := parseHeadLimitField(&entry, field); err != nil {
+			return HeadLimit{}, err
+		}
+	}
+	return entry, nil
+}
+
+// parseHeadLimitField parses one "key: value" field of a scan_head_only
+// entry.
+func parseHeadLimitField(h *HeadLimit, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in scan_head_only entry")
+	}
+	value, err := parseScalar(valueRaw)
+	if err != nil {
+		return err
+	}
+	switch strings.TrimSpace(key) {
+	case "bytes":
+		if h.Bytes, err = ParseByteSize(value); err != nil {
+			return fmt.Errorf("scan_head_only bytes: %w", err)
+		}
+	case "lines":
+		if h.Lines, err = strconv.Atoi(value); err != nil {
+			return errors.New("scan_head_only lines must be an integer")
+		}
+	default:
+		return fmt.Errorf("unknown scan_head_only key %q", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// cutMappingKey splits "key: value" at the colon ending the key, which may
+// be quoted and contain colons itself.
+func cutMappingKey(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	start := 0
+	if line != "" && (line[0] == '"' || line[0] == '\'') {
+		end := strings.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return "", "", false
+		}
+		start = end + 2
+	}
+	i := strings.IndexByte(line[start:], ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return line[:start+i], line[start+i+1:], true
+}
+
+// splitFlowFields splits the inside of a flow mapping at commas outside
+// quoted strings and nested mappings.
+func splitFlowFields(inner string) []string {
+	var fields []string
+	var quote rune
+	depth := 0
+	start := 0
+	for i, r := range inner {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			fields = append(fields, inner[start:i])
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(inner[start:]) != "" {
+		fields = append(fields, inner[start:])
+	}
+	return fields
+}
+
+// parseCategoryField parses one "key: value" field of a categories item.
+func parseCategoryField(c *Category, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in categories item")
+	}
+	key = strings.TrimSpace(key)
+	if key == "ranges" {
+		ranges, err := parseFlowList(valueRaw)
+		if err != nil {
+			return err
+		}
+		c.Ranges = append(c.Ranges, ranges...)
+		return nil
+	}
+	value, err := parseScalar(valueRaw)
+	if err != nil {
+		return err
+	}
+	switch key {
+	case "name":
+		c.Name = value
+	case "severity":
+		c.Severity = strings.ToLower(value)
+	case "fix":
+		c.Fix = value
+	default:
+		return fmt.Errorf("unknown category key %q", key)
+	}
+	return nil
+}
+
+// parsePolicyField parses one "key: value" field of a policies item.
+func parsePolicyField(p *Policy, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in policies item")
+	}
+	values, err := parseFlowList(valueRaw)
+	if err != nil {
+		return err
+	}
+	switch strings.TrimSpace(key) {
+	case "paths":
+		p.Paths = append(p.Paths, values...)
+	case "regions":
+		p.Regions = append(p.Regions, values...)
+	case "categories":
+		p.Categories = append(p.Categories, values...)
+	default:
+		return fmt.Errorf("unknown policy key %q", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// parseAllowPatternField parses one "key: value" field of an allow_patterns
+// item.
+func parseAllowPatternField(p *AllowPattern, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in allow_patterns item")
+	}
+	switch strings.TrimSpace(key) {
+	case "pattern":
+		value, err := parseScalar(valueRaw)
+		if err != nil {
+			return err
+		}
+		p.Pattern = value
+	case "categories":
+		values, err := parseFlowList(valueRaw)
+		if err != nil {
+			return err
+		}
+		p.Categories = append(p.Categories, values...)
+	default:
+		return fmt.Errorf("unknown allow_patterns key %q", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// parseAllowFileItem parses an allow_files item: a {path: ..., sha256: ...}
+// flow mapping or one "key: value" field of a block mapping.
+func parseAllowFileItem(a *AllowFile, item string) error {
+	inner, ok := strings.CutPrefix(strings.TrimSpace(stripInlineComment(item)), "{")
+	if !ok {
+		return parseAllowFileField(a, item)
+	}
+	inner, ok = strings.CutSuffix(inner, "}")
+	if !ok {
+		return fmt.Errorf("unterminated allow_files item %q", item)
+	}
+	for _, field := range splitFlowFields(inner) {
+		if err := parseAllowFileField(a, strings.TrimSpace(field)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAllowFileField parses one "key: value" field of an allow_files item.
+func parseAllowFileField(a *AllowFile, field string) error {
+	key, valueRaw, ok := strings.Cut(field, ":")
+	if !ok {
+		return errors.New("expected key: value in allow_files item")
+	}
+	value, err := parseScalar(valueRaw)
+	if err != nil {
+		return err
+	}
+	switch strings.TrimSpace(key) {
+	case "path":
+		a.Path = value
+	case "sha256":
+		a.SHA256 = strings.ToLower(value)
+	default:
+		return fmt.Errorf("unknown allow_files key %q", strings.TrimSpace(key))
+	}
+	return nil
+}
+
+// parseFlowList parses a flow sequence such as ["a", "b"] or a single
+// scalar. Unquoted items must not contain commas.
+func parseFlowList(value string) ([]string, error) {
+	value = strings.TrimSpace(stripInlineComment(value))
+	inner, ok := strings.CutPrefix(value, "[")
+	if !ok {
+		item, err := parseScalar(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{item}, nil
+	}
+	inner, ok = strings.CutSuffix(inner, "]")
+	if !ok {
+		return nil, fmt.Errorf("unterminated list %q", value)
+	}
+	var out []string
+	for _, part := range splitFlowItems(inner) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		item, err := parseScalar(part)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// splitFlowItems splits the inside of a flow sequence at commas outside
+// quoted items.
+func splitFlowItems(inner string) []string {
+	var parts []string
+	start := 0
+	var quote byte
+	for i := 0; i < len(inner); i++ {
+		switch b := inner[i]; {
+		case quote == '"' && b == '\\':
+			i++
+		case quote != 0:
+			if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case b == ',':
+			parts = append(parts, inner[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, inner[start:])
+}
+
+// ParseByteSize parses sizes such as "4096", "512KB", or "64MB" using
+// 1024-based units.
+func ParseByteSize(value string) (int64, error) {
+	raw := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(raw, unit.suffix) {
+			raw = strings.TrimSpace(strings.TrimSuffix(raw, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * multiplier, nil
+}
+
 func parseScalar(value string) (string, error) {
 	value = strings.TrimSpace(stripInlineComment(value))
 	if value == "" {
@@ -288,7 +1695,7 @@ func renderConfigYAML(cfg Config) (string, error) {
 	var b strings.Builder
 	writeList(&b, "include", cfg.Include)
 	writeList(&b, "exclude", cfg.Exclude)
-	writeList(&b, "allow", cfg.Allow)
+	writeAllowList(&b, cfg.Allow, cfg.AllowEntries)
 	b.WriteString("severity: ")
 	b.WriteString(cfg.Severity)
 	b.WriteByte('\n')
@@ -298,12 +1705,250 @@ func renderConfigYAML(cfg Config) (string, error) {
 	if cfg.IgnoreStrings {
 		b.WriteString("ignore_strings: true\n")
 	}
+	if cfg.AllowLatinExtended {
+		b.WriteString("allow_latin_extended: true\n")
+	}
+	if cfg.IgnoreURLs {
+		b.WriteString("ignore_urls: true\n")
+	}
+	if cfg.IgnoreBlobs {
+		b.WriteString("ignore_blobs: true\n")
+	}
+	if cfg.DecodeEscapes {
+		b.WriteString("decode_escapes: true\n")
+	}
+	if cfg.CheckEntities {
+		b.WriteString("check_entities: true\n")
+	}
+	if cfg.IgnoreCodeBlocks {
+		b.WriteString("ignore_code_blocks: true\n")
+	}
+	if cfg.EditorConfig {
+		b.WriteString("editorconfig: true\n")
+	}
+	if cfg.CheckCharset {
+		b.WriteString("check_charset: true\n")
+	}
+	if cfg.DedupeContent {
+		b.WriteString("dedupe_content: true\n")
+	}
+	if cfg.Root != "" {
+		b.WriteString("root: ")
+		b.WriteString(strconv.Quote(cfg.Root))
+		b.WriteByte('\n')
+	}
+	if cfg.InvalidUTF8Fix != "" {
+		b.WriteString("invalid_utf8_fix: ")
+		b.WriteString(cfg.InvalidUTF8Fix)
+		b.WriteByte('\n')
+	}
+	if cfg.FixMode != "" {
+		b.WriteString("fix_mode: ")
+		b.WriteString(cfg.FixMode)
+		b.WriteByte('\n')
+	}
 	if len(cfg.AllowFilePatterns) > 0 {
 		writeList(&b, "allow_file_patterns", cfg.AllowFilePatterns)
 	}
+	if len(cfg.IgnoreLinePatterns) > 0 {
+		writeList(&b, "ignore_line_patterns", cfg.IgnoreLinePatterns)
+	}
+	switch {
+	case cfg.FixturePaths == nil, slices.Equal(cfg.FixturePaths, DefaultFixturePaths):
+	case len(cfg.FixturePaths) == 0:
+		b.WriteString("fixture_paths: []\n")
+	default:
+		writeList(&b, "fixture_paths", cfg.FixturePaths)
+	}
+	if cfg.Excerpts != "" && cfg.Excerpts != ExcerptsFull {
+		b.WriteString("excerpts: ")
+		b.WriteString(cfg.Excerpts)
+		b.WriteByte('\n')
+	}
+	if cfg.MinConfidence != "" {
+		b.WriteString("min_confidence: ")
+		b.WriteString(cfg.MinConfidence)
+		b.WriteByte('\n')
+	}
+	if cfg.MaxFindingsPerFile > 0 {
+		b.WriteString("max_findings_per_file: ")
+		b.WriteString(strconv.Itoa(cfg.MaxFindingsPerFile))
+		b.WriteByte('\n')
+	}
+	if cfg.MmapThreshold > 0 {
+		b.WriteString("mmap_threshold: ")
+		b.WriteString(strconv.FormatInt(cfg.MmapThreshold, 10))
+		b.WriteByte('\n')
+	}
+	if cfg.Threads > 0 {
+		b.WriteString("threads: ")
+		b.WriteString(strconv.Itoa(cfg.Threads))
+		b.WriteByte('\n')
+	}
+	if cfg.NotifyWebhook != "" {
+		b.WriteString("notify_webhook: ")
+		b.WriteString(strconv.Quote(cfg.NotifyWebhook))
+		b.WriteByte('\n')
+	}
+	if cfg.NotifyIncludeFindings {
+		b.WriteString("notify_include_findings: true\n")
+	}
+	if cfg.NoColor {
+		b.WriteString("no_color: true\n")
+	}
+	if cfg.Verbose {
+		b.WriteString("verbose: true\n")
+	}
+	if len(cfg.MessageTemplates) > 0 {
+		writeList(&b, "message_templates", cfg.MessageTemplates)
+	}
+	if len(cfg.HelpURIs) > 0 {
+		writeList(&b, "help_uris", cfg.HelpURIs)
+	}
+	if len(cfg.Plugins) > 0 {
+		writeList(&b, "plugins", cfg.Plugins)
+	}
+	if len(cfg.Policies) > 0 {
+		b.WriteString("policies:\n")
+		for _, p := range cfg.Policies {
+			b.WriteString("  - paths: ")
+			writeFlowList(&b, p.Paths)
+			if len(p.Regions) > 0 {
+				b.WriteString("\n    regions: ")
+				writeFlowList(&b, p.Regions)
+			}
+			b.WriteString("\n    categories: ")
+			writeFlowList(&b, p.Categories)
+			b.WriteByte('\n')
+		}
+	}
+	if len(cfg.AllowPatterns) > 0 {
+		b.WriteString("allow_patterns:\n")
+		for _, p := range cfg.AllowPatterns {
+			b.WriteString("  - pattern: ")
+			b.WriteString(strconv.Quote(p.Pattern))
+			if len(p.Categories) > 0 {
+				b.WriteString("\n    categories: ")
+				writeFlowList(&b, p.Categories)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	if len(cfg.AllowFiles) > 0 {
+		b.WriteString("allow_files:\n")
+		for _, a := range cfg.AllowFiles {
+			b.WriteString("  - {path: ")
+			b.WriteString(strconv.Quote(a.Path))
+			b.WriteString(", sha256: ")
+			b.WriteString(a.SHA256)
+			b.WriteString("}\n")
+		}
+	}
+	if len(cfg.Paths) > 0 {
+		b.WriteString("paths:\n")
+		for _, p := range cfg.Paths {
+			b.WriteString("  ")
+			b.WriteString(strconv.Quote(p.Pattern))
+			b.WriteString(": {severity: ")
+			b.WriteString(p.Severity)
+			b.WriteString("}\n")
+		}
+	}
+	if len(cfg.ScanHeadOnly) > 0 {
+		b.WriteString("scan_head_only:\n")
+		for _, h := range cfg.ScanHeadOnly {
+			var fields []string
+			if h.Bytes > 0 {
+				fields = append(fields, "bytes: "+strconv.FormatInt(h.Bytes, 10))
+			}
+			if h.Lines > 0 {
+				fields = append(fields, "lines: "+strconv.Itoa(h.Lines))
+			}
+			b.WriteString("  ")
+			b.WriteString(strconv.Quote(h.Pattern))
+			b.WriteString(": {")
+			b.WriteString(strings.Join(fields, ", "))
+			b.WriteString("}\n")
+		}
+	}
+	if len(cfg.Categories) > 0 {
+		b.WriteString("categories:\n")
+		for _, c := range cfg.Categories {
+			b.WriteString("  - name: ")
+			b.WriteString(strconv.Quote(c.Name))
+			b.WriteString("\n    ranges: ")
+			writeFlowList(&b, c.Ranges)
+			b.WriteByte('\n')
+			if c.Severity != "" {
+				b.WriteString("    severity: ")
+				b.WriteString(c.Severity)
+				b.WriteByte('\n')
+			}
+			if c.Fix != "" {
+				b.WriteString("    fix: ")
+				b.WriteString(strconv.Quote(c.Fix))
+				b.WriteByte('\n')
+			}
+		}
+	}
 	return b.String(), nil
 }
 
+func writeFlowList(b *strings.Builder, values []string) {
+	b.WriteByte('[')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(value))
+	}
+	b.WriteByte(']')
+}
+
+// AllowListYAML renders the allow key the way Save writes it, including
+// the expiry, reason, and author of structured entries.
+func AllowListYAML(allow []string, entries []AllowEntry) string {
+	var b strings.Builder
+	writeAllowList(&b, allow, entries)
+	return b.String()
+}
+
+// writeAllowList writes allow with the plain values first and then the
+// structured entries as flow mappings. allow holds the values of entries
+// too, so each entry's value is written once, with the entry.
+func writeAllowList(b *strings.Builder, allow []string, entries []AllowEntry) {
+	structured := make(map[string]int)
+	for _, e := range entries {
+		structured[e.Value]++
+	}
+	var plain []string
+	for _, value := range allow {
+		if structured[value] > 0 {
+			structured[value]--
+			continue
+		}
+		plain = append(plain, value)
+	}
+	writeList(b, "allow", plain)
+	for _, e := range entries {
+		b.WriteString("  - {value: ")
+		b.WriteString(strconv.Quote(e.Value))
+		if e.Expires != "" {
+			b.WriteString(", expires: ")
+			b.WriteString(e.Expires)
+		}
+		if e.Reason != "" {
+			b.WriteString(", reason: ")
+			b.WriteString(strconv.Quote(e.Reason))
+		}
+		if e.AddedBy != "" {
+			b.WriteString(", added_by: ")
+			b.WriteString(strconv.Quote(e.AddedBy))
+		}
+		b.WriteString("}\n")
+	}
+}
+
 func writeList(b *strings.Builder, key string, values []string) {
 	b.WriteString(key)
 	b.WriteString(":\n")
@@ -5,8 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -17,7 +19,7 @@ func TestDefaultConfig(t *testing.T) {
 	if len(cfg.Include) == 0 || len(cfg.Exclude) == 0 {
 		t.Fatalf("expected default include/exclude")
 	}
-	if got := cfg.Allow; !reflect.DeepEqual(got, []string{"©", "→"}) {
+	if got := cfg.Allow; !reflect.DeepEqual(got, []string{"©", "→", "€", "£", "¥", "°"}) {
 		t.Fatalf("unexpected allow list: %v", got)
 	}
 }
@@ -202,6 +204,34 @@ func TestSave(t *testing.T) {
 		}
 	})
 
+	t.Run("structured allow entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".englint.yaml")
+		entries := []AllowEntry{
+			{Value: "©", Reason: "license headers", AddedBy: "alice"},
+			{Value: "™", Expires: "2026-06-30", Reason: `the "old" brand, for now`},
+		}
+		cfg := Config{Allow: []string{"→", "©", "™"}, AllowEntries: entries}
+		if err := Save(path, cfg); err != nil {
+			t.Fatalf("Save returned error: %v", err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if !reflect.DeepEqual(loaded.Allow, cfg.Allow) {
+			t.Fatalf("allow = %q, want %q", loaded.Allow, cfg.Allow)
+		}
+		for i := range loaded.AllowEntries {
+			loaded.AllowEntries[i].Line, loaded.AllowEntries[i].Path = 0, ""
+		}
+		if !reflect.DeepEqual(loaded.AllowEntries, entries) {
+			t.Fatalf("allow entries = %+v, want %+v", loaded.AllowEntries, entries)
+		}
+		if runes := AllowedRuneMap(loaded.Allow); len(runes) != 3 {
+			t.Fatalf("allowed runes = %v, want 3", runes)
+		}
+	})
+
 	t.Run("invalid config", func(t *testing.T) {
 		path := filepath.Join(t.TempDir(), ".englint.yaml")
 		if err := Save(path, Config{Severity: "bad"}); err == nil {
@@ -266,6 +296,93 @@ func TestWriteDefault(t *testing.T) {
 	}
 }
 
+func TestTemplates(t *testing.T) {
+	for _, name := range TemplateNames {
+		t.Run(name, func(t *testing.T) {
+			template, err := Template(name)
+			if err != nil {
+				t.Fatalf("Template(%q) error = %v", name, err)
+			}
+			cfg, err := parseConfigYAML(template)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			cfg = ApplyDefaults(cfg)
+			if err := Validate(cfg); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if warnings := Lint(cfg); len(warnings) > 0 {
+				t.Fatalf("lint warnings: %q", warnings)
+			}
+		})
+	}
+	if _, err := Template("python"); err == nil || !strings.Contains(err.Error(), "want one of default, go") {
+		t.Fatalf("expected unknown template error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "sub", ".englint.yaml")
+	if err := WriteTemplate(path, "go"); err != nil {
+		t.Fatalf("WriteTemplate returned error: %v", err)
+	}
+	if err := WriteTemplate(path, "docs"); err != nil {
+		t.Fatalf("WriteTemplate over an existing file returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "allow_latin_extended: true") {
+		t.Fatalf("expected docs template, got %q (%v)", data, err)
+	}
+}
+
+func TestInitConfig(t *testing.T) {
+	cfg, err := InitConfig(InitAnswers{Languages: []string{"go", "rust"}, Docs: "warning", AllowEmoji: true})
+	if err != nil {
+		t.Fatalf("InitConfig returned error: %v", err)
+	}
+	if want := []string{"**/*.go", "**/*.rs", "**/*.md", "**/*.mdx", "**/*.rst", "**/*.adoc"}; !reflect.DeepEqual(cfg.Include, want) {
+		t.Fatalf("include = %q, want %q", cfg.Include, want)
+	}
+	if !slices.Contains(cfg.Exclude, "target/**") || len(cfg.Paths) != 4 || cfg.Paths[0].Severity != SeverityWarning {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.Categories) != 1 || cfg.Categories[0].Name != "Emoji" || cfg.Categories[0].Severity != "off" {
+		t.Fatalf("expected an Emoji category that is off, got %+v", cfg.Categories)
+	}
+	if err := Validate(ApplyDefaults(cfg)); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	cfg, err = InitConfig(InitAnswers{Docs: "error"})
+	if err != nil || !reflect.DeepEqual(cfg.Include, docsPatterns) || len(cfg.Paths) != 0 || len(cfg.Categories) != 0 {
+		t.Fatalf("expected docs only, got %+v (%v)", cfg, err)
+	}
+
+	for _, bad := range []InitAnswers{
+		{Languages: []string{"cobol"}, Docs: "off"},
+		{Languages: []string{"go"}, Docs: "sometimes"},
+		{Docs: "off"},
+	} {
+		if _, err := InitConfig(bad); err == nil {
+			t.Fatalf("expected error for %+v", bad)
+		}
+	}
+}
+
+func TestDetectLanguages(t *testing.T) {
+	tmp := t.TempDir()
+	for _, name := range []string{"main.go", "web/app.TSX", "lib/x.h", "node_modules/dep/index.js", ".cache/y.py"} {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if got, want := DetectLanguages(tmp), []string{"go", "typescript", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("DetectLanguages() = %q, want %q", got, want)
+	}
+}
+
 func TestAllowedRuneMap(t *testing.T) {
 	allow := []string{"©", "→", "ab"}
 	m := AllowedRuneMap(allow)
@@ -359,3 +476,936 @@ func TestParseConfigYAMLAndHelpers(t *testing.T) {
 		}
 	})
 }
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "4096", want: 4096},
+		{in: "512KB", want: 512 << 10},
+		{in: "64mb", want: 64 << 20},
+		{in: "1 GiB", want: 1 << 30},
+		{in: "10B", want: 10},
+		{in: "2M", want: 2 << 20},
+		{in: "", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "lots", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %q", tt.in)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("ParseByteSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	redacted, err := parseConfigYAML("excerpts: Redact\n")
+	if err != nil || ApplyDefaults(redacted).Excerpts != ExcerptsRedact {
+		t.Fatalf("unexpected excerpts parse: %q, %v", redacted.Excerpts, err)
+	}
+	if err := Validate(Config{Severity: SeverityError, Excerpts: "partial"}); err == nil {
+		t.Fatalf("expected invalid excerpts error")
+	}
+	if rendered, _ := renderConfigYAML(Config{Severity: SeverityError, Excerpts: ExcerptsOmit}); !strings.Contains(rendered, "excerpts: omit") {
+		t.Fatalf("expected rendered excerpts, got %q", rendered)
+	}
+
+	limited, err := parseConfigYAML("max_findings_per_file: 25\n")
+	if err != nil || limited.MaxFindingsPerFile != 25 {
+		t.Fatalf("unexpected max_findings_per_file parse: %d, %v", limited.MaxFindingsPerFile, err)
+	}
+	if _, err := parseConfigYAML("max_findings_per_file: many\n"); err == nil {
+		t.Fatalf("expected invalid max_findings_per_file error")
+	}
+	if err := Validate(Config{Severity: SeverityError, MaxFindingsPerFile: -1}); err == nil {
+		t.Fatalf("expected negative max_findings_per_file error")
+	}
+	if rendered, _ := renderConfigYAML(Config{Severity: SeverityError, MaxFindingsPerFile: 3}); !strings.Contains(rendered, "max_findings_per_file: 3") {
+		t.Fatalf("expected rendered max_findings_per_file, got %q", rendered)
+	}
+
+	threads, err := parseConfigYAML("threads: 2\n")
+	if err != nil || threads.Threads != 2 {
+		t.Fatalf("unexpected threads parse: %d, %v", threads.Threads, err)
+	}
+	if _, err := parseConfigYAML("threads: all\n"); err == nil {
+		t.Fatalf("expected invalid threads error")
+	}
+	if err := Validate(Config{Severity: SeverityError, Threads: -1}); err == nil {
+		t.Fatalf("expected negative threads error")
+	}
+	if rendered, _ := renderConfigYAML(Config{Severity: SeverityError, Threads: 1}); !strings.Contains(rendered, "threads: 1") {
+		t.Fatalf("expected rendered threads, got %q", rendered)
+	}
+
+	cfg, err := parseConfigYAML("mmap_threshold: 16MB\n")
+	if err != nil || cfg.MmapThreshold != 16<<20 {
+		t.Fatalf("unexpected mmap_threshold parse: %d, %v", cfg.MmapThreshold, err)
+	}
+	if _, err := parseConfigYAML("mmap_threshold: huge\n"); err == nil {
+		t.Fatalf("expected invalid mmap_threshold error")
+	}
+	if err := Validate(Config{Severity: SeverityError, MmapThreshold: -1}); err == nil {
+		t.Fatalf("expected negative mmap_threshold error")
+	}
+	rendered, err := renderConfigYAML(Config{Severity: SeverityError, MmapThreshold: 1024})
+	if err != nil || !strings.Contains(rendered, "mmap_threshold: 1024") {
+		t.Fatalf("expected rendered mmap_threshold, got %q", rendered)
+	}
+}
+
+func TestIgnoreLinePatternsConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("ignore_line_patterns: [\"https?://\\\\S+\", 'x{1,2}']\n")
+	if err != nil || !reflect.DeepEqual(cfg.IgnoreLinePatterns, []string{`https?://\S+`, "x{1,2}"}) {
+		t.Fatalf("unexpected ignore_line_patterns parse: %q, %v", cfg.IgnoreLinePatterns, err)
+	}
+	cfg, err = parseConfigYAML("ignore_line_patterns:\n  - \"Co-authored-by:.*\"\n")
+	if err != nil || !reflect.DeepEqual(cfg.IgnoreLinePatterns, []string{"Co-authored-by:.*"}) {
+		t.Fatalf("unexpected ignore_line_patterns list parse: %q, %v", cfg.IgnoreLinePatterns, err)
+	}
+	if err := Validate(Config{Severity: SeverityError, IgnoreLinePatterns: []string{"("}}); err == nil || !strings.Contains(err.Error(), "ignore_line_patterns") {
+		t.Fatalf("expected invalid ignore_line_patterns error, got %v", err)
+	}
+	if rendered, _ := renderConfigYAML(Config{Severity: SeverityError, IgnoreLinePatterns: []string{`\d+`}}); !strings.Contains(rendered, "ignore_line_patterns:\n  - \"\\\\d+\"") {
+		t.Fatalf("expected rendered ignore_line_patterns, got %q", rendered)
+	}
+}
+
+func TestAllowFilesConfig(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	want := []AllowFile{{Path: "docs/intro_ja.md", SHA256: sum}, {Path: "docs/b.md", SHA256: sum}}
+	inputs := []string{
+		"allow_files: [{path: \"docs/intro_ja.md\", sha256: \"" + sum + "\"}, {path: docs/b.md, sha256: " + strings.ToUpper(sum) + "}]\n",
+		"allow_files:\n  - {path: \"docs/intro_ja.md\", sha256: " + sum + "}\n  - path: docs/b.md  # localized\n    sha256: \"" + sum + "\"\n",
+	}
+	for _, input := range inputs {
+		cfg, err := parseConfigYAML(input)
+		if err != nil || !reflect.DeepEqual(cfg.AllowFiles, want) {
+			t.Fatalf("unexpected allow_files parse of %q: %+v, %v", input, cfg.AllowFiles, err)
+		}
+		rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+		if err != nil || !strings.Contains(rendered, "allow_files:\n  - {path: \"docs/intro_ja.md\", sha256: "+sum+"}\n  - {path: \"docs/b.md\", sha256: "+sum+"}\n") {
+			t.Fatalf("unexpected rendered allow_files %q, %v", rendered, err)
+		}
+		reparsed, err := parseConfigYAML(rendered)
+		if err != nil || !reflect.DeepEqual(reparsed.AllowFiles, want) {
+			t.Fatalf("rendered allow_files do not round-trip: %+v, %v", reparsed.AllowFiles, err)
+		}
+	}
+
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{input: "allow_files: docs/a.md\n", want: "list of {path: ..., sha256: ...} mappings"},
+		{input: "allow_files:\n  - {path: a.md, hash: x}\n", want: `unknown allow_files key "hash"`},
+		{input: "allow_files:\n  - {path: a.md\n", want: "unterminated allow_files item"},
+	} {
+		if _, err := parseConfigYAML(tt.input); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("parse %q: expected %q error, got %v", tt.input, tt.want, err)
+		}
+	}
+	for _, tt := range []struct {
+		file AllowFile
+		want string
+	}{
+		{file: AllowFile{SHA256: sum}, want: "allow_files entry 1 requires a path"},
+		{file: AllowFile{Path: "a.md", SHA256: "abc"}, want: `allow_files entry "a.md": sha256 must be 64 hex digits`},
+		{file: AllowFile{Path: "a.md", SHA256: strings.Repeat("g", 64)}, want: `allow_files entry "a.md": sha256 must be 64 hex digits`},
+	} {
+		if err := Validate(Config{Severity: SeverityError, AllowFiles: []AllowFile{tt.file}}); err == nil || err.Error() != tt.want {
+			t.Fatalf("Validate(%+v) = %v, want %q", tt.file, err, tt.want)
+		}
+	}
+}
+
+func TestFixturePathsConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []string
+		render string
+	}{
+		{name: "unset", input: "severity: error\n", want: DefaultFixturePaths},
+		{name: "flow", input: "fixture_paths: [\"spec/fixtures/**\"]\n", want: []string{"spec/fixtures/**"}, render: "fixture_paths:\n  - \"spec/fixtures/**\"\n"},
+		{name: "block", input: "fixture_paths:\n  - \"golden/**\"\n", want: []string{"golden/**"}, render: "fixture_paths:\n  - \"golden/**\"\n"},
+		{name: "off", input: "fixture_paths: []\n", want: []string{}, render: "fixture_paths: []\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := parseConfigYAML(tc.input)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			cfg = ApplyDefaults(cfg)
+			if !reflect.DeepEqual(cfg.FixturePaths, tc.want) {
+				t.Fatalf("fixture_paths = %q, want %q", cfg.FixturePaths, tc.want)
+			}
+			rendered, err := renderConfigYAML(cfg)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if got := strings.Contains(rendered, "fixture_paths"); got != (tc.render != "") || !strings.Contains(rendered, tc.render) {
+				t.Fatalf("rendered config %q, want %q", rendered, tc.render)
+			}
+		})
+	}
+	if err := Validate(Config{Severity: SeverityError, FixturePaths: []string{" "}}); err == nil || !strings.Contains(err.Error(), "fixture_paths") {
+		t.Fatalf("expected empty fixture_paths entry error, got %v", err)
+	}
+}
+
+func TestAllowPatternsConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("allow_patterns:\n  - pattern: 't\\(\"[^\"]*\"\\)'\n    categories: [\"CJK\"]\n  - pattern: \"_\\\\(.*\\\\)\"\n")
+	wantPatterns := []AllowPattern{{Pattern: `t\("[^"]*"\)`, Categories: []string{"CJK"}}, {Pattern: `_\(.*\)`}}
+	if err != nil || !reflect.DeepEqual(cfg.AllowPatterns, wantPatterns) {
+		t.Fatalf("unexpected allow_patterns parse: %+v, %v", cfg.AllowPatterns, err)
+	}
+	if err := Validate(ApplyDefaults(cfg)); err != nil {
+		t.Fatalf("expected valid allow_patterns, got %v", err)
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reparsed, err := parseConfigYAML(rendered); err != nil || !reflect.DeepEqual(reparsed.AllowPatterns, wantPatterns) {
+		t.Fatalf("allow_patterns did not round-trip: %q, %v", rendered, err)
+	}
+	for _, bad := range []AllowPattern{{}, {Pattern: "t("}} {
+		if err := Validate(Config{Severity: SeverityError, AllowPatterns: []AllowPattern{bad}}); err == nil || !strings.Contains(err.Error(), "allow_patterns") {
+			t.Fatalf("expected allow_patterns error for %+v, got %v", bad, err)
+		}
+	}
+	if _, err := parseConfigYAML("allow_patterns:\n  - regex: x\n"); err == nil {
+		t.Fatalf("expected unknown allow_patterns key error")
+	}
+}
+
+func TestNotifyWebhookConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("notify_webhook: \"https://hooks.example.com/x\"\nnotify_include_findings: true\n")
+	if err != nil || cfg.NotifyWebhook != "https://hooks.example.com/x" || !cfg.NotifyIncludeFindings {
+		t.Fatalf("unexpected notify parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("notify_include_findings: maybe\n"); err == nil {
+		t.Fatalf("expected invalid notify_include_findings error")
+	}
+	for _, bad := range []string{"ftp://example.com", "hooks.example.com", "https://"} {
+		if err := Validate(Config{Severity: SeverityError, NotifyWebhook: bad}); err == nil {
+			t.Fatalf("expected invalid notify_webhook error for %q", bad)
+		}
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "notify_webhook: \"https://hooks.example.com/x\"") || !strings.Contains(rendered, "notify_include_findings: true") {
+		t.Fatalf("expected rendered notify keys, got %q", rendered)
+	}
+}
+
+func TestMessageTemplatesConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("message_templates:\n  - \"CJK=See https://wiki.example.com/i18n#cjk ({codepoint})\"\n  - \"*={message}\"\n")
+	if err != nil || len(cfg.MessageTemplates) != 2 {
+		t.Fatalf("unexpected message_templates parse: %+v, %v", cfg, err)
+	}
+	want := map[string]string{"cjk": "See https://wiki.example.com/i18n#cjk ({codepoint})", "*": "{message}"}
+	if got := MessageTemplateMap(cfg.MessageTemplates); !reflect.DeepEqual(got, want) {
+		t.Fatalf("MessageTemplateMap() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"CJK", "=text", "CJK= "} {
+		if err := Validate(Config{Severity: SeverityError, MessageTemplates: []string{bad}}); err == nil {
+			t.Fatalf("expected invalid message_templates error for %q", bad)
+		}
+	}
+	if _, err := parseConfigYAML("message_templates: \"CJK=x\"\n"); err == nil {
+		t.Fatalf("expected list value error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "message_templates:\n  - \"CJK=See https://wiki.example.com/i18n#cjk ({codepoint})\"\n") {
+		t.Fatalf("expected rendered message_templates, got %q", rendered)
+	}
+}
+
+func TestHelpURIsConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("help_uris:\n  - \"CJK=https://wiki.example.com/i18n#cjk\"\n  - \"*=https://wiki.example.com/i18n\"\n")
+	if err != nil || len(cfg.HelpURIs) != 2 || Validate(ApplyDefaults(cfg)) != nil {
+		t.Fatalf("unexpected help_uris parse: %+v, %v", cfg, err)
+	}
+	want := map[string]string{"cjk": "https://wiki.example.com/i18n#cjk", "*": "https://wiki.example.com/i18n"}
+	if got := MessageTemplateMap(cfg.HelpURIs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("MessageTemplateMap() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"CJK", "=https://wiki.example.com", "CJK=", "CJK=wiki/i18n"} {
+		if err := Validate(Config{Severity: SeverityError, HelpURIs: []string{bad}}); err == nil {
+			t.Fatalf("expected invalid help_uris error for %q", bad)
+		}
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "help_uris:\n  - \"CJK=https://wiki.example.com/i18n#cjk\"\n") {
+		t.Fatalf("expected rendered help_uris, got %q", rendered)
+	}
+}
+
+func TestPluginsConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("plugins:\n  - \"./tools/banned-terms --strict\"\n")
+	if err != nil || !reflect.DeepEqual(cfg.Plugins, []string{"./tools/banned-terms --strict"}) {
+		t.Fatalf("unexpected plugins parse: %+v, %v", cfg, err)
+	}
+	if err := Validate(Config{Severity: SeverityError, Plugins: []string{" "}}); err == nil {
+		t.Fatalf("expected empty plugin error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "plugins:\n  - \"./tools/banned-terms --strict\"\n") {
+		t.Fatalf("expected rendered plugins, got %q", rendered)
+	}
+}
+
+func TestCategoriesConfig(t *testing.T) {
+	input := `categories:
+  - name: "Box Drawing"
+    ranges: ["U+2500..U+257F", "U+2580-U+259F"]  # blocks
+    severity: Warning
+    fix: "-"
+  - name: NBSP
+    ranges: U+00A0
+severity: error
+`
+	cfg, err := parseConfigYAML(input)
+	if err != nil {
+		t.Fatalf("parseConfigYAML() error = %v", err)
+	}
+	want := []Category{
+		{Name: "Box Drawing", Ranges: []string{"U+2500..U+257F", "U+2580-U+259F"}, Severity: SeverityWarning, Fix: "-"},
+		{Name: "NBSP", Ranges: []string{"U+00A0"}},
+	}
+	if !reflect.DeepEqual(cfg.Categories, want) || cfg.Severity != SeverityError {
+		t.Fatalf("unexpected categories: %+v (severity %q)", cfg.Categories, cfg.Severity)
+	}
+	if err := Validate(ApplyDefaults(cfg)); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil {
+		t.Fatalf("renderConfigYAML() error = %v", err)
+	}
+	reparsed, err := parseConfigYAML(rendered)
+	if err != nil || !reflect.DeepEqual(reparsed.Categories, want) {
+		t.Fatalf("round trip = %+v, %v\n%s", reparsed.Categories, err, rendered)
+	}
+
+	for _, bad := range []string{
+		"categories:\n    ranges: [\"U+00A0\"]\n",
+		"categories:\n  - name: x\n    color: red\n",
+		"categories:\n  - name: x\n    ranges: [\"U+00A0\"\n",
+		"categories: x\n",
+	} {
+		if _, err := parseConfigYAML(bad); err == nil {
+			t.Fatalf("expected parse error for %q", bad)
+		}
+	}
+	for _, bad := range []Category{
+		{Ranges: []string{"U+00A0"}},
+		{Name: "x"},
+		{Name: "x", Ranges: []string{"00A0"}},
+		{Name: "x", Ranges: []string{"U+257F..U+2500"}},
+		{Name: "x", Ranges: []string{"U+110000"}},
+		{Name: "x", Ranges: []string{"U+00A0"}, Severity: "fatal"},
+	} {
+		if err := Validate(Config{Severity: SeverityError, Categories: []Category{bad}}); err == nil {
+			t.Fatalf("expected validation error for %+v", bad)
+		}
+	}
+	muted := []Category{{Name: "Box Drawing", Ranges: []string{"U+2500..U+257F"}, Severity: SeverityOff}}
+	if err := Validate(Config{Severity: SeverityError, Categories: muted}); err != nil {
+		t.Fatalf("expected off category severity to validate, got %v", err)
+	}
+	dup := []Category{{Name: "X", Ranges: []string{"U+00A0"}}, {Name: "x", Ranges: []string{"U+00A1"}}}
+	if err := Validate(Config{Severity: SeverityError, Categories: dup}); err == nil {
+		t.Fatalf("expected duplicate category error")
+	}
+}
+
+func TestParseRuneRange(t *testing.T) {
+	tests := []struct {
+		value  string
+		lo, hi rune
+	}{
+		{"U+2500..U+257F", 0x2500, 0x257F},
+		{"u+2500 - u+257f", 0x2500, 0x257F},
+		{"U+00A0", 0xA0, 0xA0},
+	}
+	for _, tt := range tests {
+		lo, hi, err := ParseRuneRange(tt.value)
+		if err != nil || lo != tt.lo || hi != tt.hi {
+			t.Fatalf("ParseRuneRange(%q) = %X, %X, %v", tt.value, lo, hi, err)
+		}
+	}
+}
+
+func TestAllowLatinExtendedConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("allow_latin_extended: true\n")
+	if err != nil || !cfg.AllowLatinExtended {
+		t.Fatalf("unexpected allow_latin_extended parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("allow_latin_extended: sometimes\n"); err == nil {
+		t.Fatalf("expected invalid allow_latin_extended error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "allow_latin_extended: true\n") {
+		t.Fatalf("expected rendered allow_latin_extended, got %q", rendered)
+	}
+}
+
+func TestIgnoreURLsConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("ignore_urls: true\n")
+	if err != nil || !cfg.IgnoreURLs {
+		t.Fatalf("unexpected ignore_urls parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("ignore_urls: maybe\n"); err == nil {
+		t.Fatalf("expected invalid ignore_urls error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "ignore_urls: true\n") {
+		t.Fatalf("expected rendered ignore_urls, got %q", rendered)
+	}
+}
+
+func TestIgnoreBlobsConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("ignore_blobs: true\n")
+	if err != nil || !cfg.IgnoreBlobs {
+		t.Fatalf("unexpected ignore_blobs parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("ignore_blobs: maybe\n"); err == nil {
+		t.Fatalf("expected invalid ignore_blobs error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "ignore_blobs: true\n") {
+		t.Fatalf("expected rendered ignore_blobs, got %q", rendered)
+	}
+}
+
+func TestDecodeEscapesConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("decode_escapes: true\n")
+	if err != nil || !cfg.DecodeEscapes {
+		t.Fatalf("unexpected decode_escapes parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("decode_escapes: maybe\n"); err == nil {
+		t.Fatalf("expected invalid decode_escapes error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "decode_escapes: true\n") {
+		t.Fatalf("expected rendered decode_escapes, got %q", rendered)
+	}
+}
+
+func TestCheckEntitiesConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("check_entities: true\n")
+	if err != nil || !cfg.CheckEntities {
+		t.Fatalf("unexpected check_entities parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("check_entities: maybe\n"); err == nil {
+		t.Fatalf("expected invalid check_entities error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "check_entities: true\n") {
+		t.Fatalf("expected rendered check_entities, got %q", rendered)
+	}
+}
+
+func TestIgnoreCodeBlocksConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("ignore_code_blocks: true\n")
+	if err != nil || !cfg.IgnoreCodeBlocks {
+		t.Fatalf("unexpected ignore_code_blocks parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("ignore_code_blocks: maybe\n"); err == nil {
+		t.Fatalf("expected invalid ignore_code_blocks error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "ignore_code_blocks: true\n") {
+		t.Fatalf("expected rendered ignore_code_blocks, got %q", rendered)
+	}
+}
+
+func TestEditorConfigCharsetConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("editorconfig: true\ncheck_charset: true\n")
+	if err != nil || !cfg.EditorConfig || !cfg.CheckCharset {
+		t.Fatalf("unexpected editorconfig parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("check_charset: maybe\n"); err == nil {
+		t.Fatalf("expected invalid check_charset error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "editorconfig: true\ncheck_charset: true\n") {
+		t.Fatalf("expected rendered editorconfig keys, got %q", rendered)
+	}
+}
+
+func TestDedupeContentConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("dedupe_content: true\n")
+	if err != nil || !cfg.DedupeContent {
+		t.Fatalf("unexpected dedupe_content parse: %+v, %v", cfg, err)
+	}
+	if _, err := parseConfigYAML("dedupe_content: maybe\n"); err == nil {
+		t.Fatalf("expected invalid dedupe_content error")
+	}
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil || !strings.Contains(rendered, "dedupe_content: true\n") {
+		t.Fatalf("expected rendered dedupe_content, got %q", rendered)
+	}
+}
+
+func TestAllowEntriesConfig(t *testing.T) {
+	input := `allow:
+  - "→"
+  - {value: "©", expires: 2025-12-31, reason: "legacy, header"}  # temporary
+  - value: "™"
+    reason: 'until the rebrand'
+    added_by: alice
+severity: warning
+`
+	cfg, err := parseConfigYAML(input)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	want := []AllowEntry{
+		{Value: "©", Expires: "2025-12-31", Reason: "legacy, header", Line: 3},
+		{Value: "™", Reason: "until the rebrand", AddedBy: "alice", Line: 4},
+	}
+	if !reflect.DeepEqual(cfg.AllowEntries, want) {
+		t.Fatalf("allow entries = %+v, want %+v", cfg.AllowEntries, want)
+	}
+	if !reflect.DeepEqual(cfg.Allow, []string{"→", "©", "™"}) || cfg.Severity != "warning" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	for _, bad := range []string{
+		"allow:\n  - {value: \"©\", until: 2025-12-31}\n",
+		"allow:\n  - {value: \"©\"\n",
+		"allow:\n  - value: \"©\"\n    expires\n",
+	} {
+		if _, err := parseConfigYAML(bad); err == nil {
+			t.Fatalf("expected parse error for %q", bad)
+		}
+	}
+	for _, entry := range []AllowEntry{{Expires: "2025-12-31"}, {Value: "©", Expires: "12/31/2025"}} {
+		cfg := ApplyDefaults(Config{AllowEntries: []AllowEntry{entry}})
+		if err := Validate(cfg); err == nil {
+			t.Fatalf("expected validation error for %+v", entry)
+		}
+	}
+}
+
+func TestAllowEntryExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expires string
+		want    bool
+	}{
+		{expires: ""},
+		{expires: "2026-01-01"},
+		{expires: "2026-06-30"},
+		{expires: "2025-12-31", want: true},
+		{expires: "soon"},
+	}
+	for _, tt := range tests {
+		if got := (AllowEntry{Value: "©", Expires: tt.expires}).Expired(now); got != tt.want {
+			t.Fatalf("Expired(%q) = %v, want %v", tt.expires, got, tt.want)
+		}
+	}
+}
+
+func TestPathsConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "flow", input: `paths: {"src/**": {severity: error}, "docs/**": {severity: Warning}}  # stricter code
+severity: warning
+`},
+		{name: "block", input: `paths:
+  "src/**": {severity: error}
+  docs/**:
+    severity: Warning  # prose
+severity: warning
+`},
+	}
+	want := []PathSeverity{{Pattern: "src/**", Severity: "error"}, {Pattern: "docs/**", Severity: "warning"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseConfigYAML(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg.Paths, want) || cfg.Severity != "warning" {
+				t.Fatalf("unexpected config: %+v", cfg)
+			}
+			rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			reparsed, err := parseConfigYAML(rendered)
+			if err != nil || !reflect.DeepEqual(reparsed.Paths, want) {
+				t.Fatalf("round trip = %+v, %v from %q", reparsed.Paths, err, rendered)
+			}
+		})
+	}
+
+	for _, bad := range []string{
+		"paths: [\"src/**\"]\n",
+		"paths:\n  src/**: warning\n",
+		"paths:\n  src/**: {level: warning}\n",
+		"paths:\n  \"src/**: {severity: warning}\n",
+	} {
+		if _, err := parseConfigYAML(bad); err == nil {
+			t.Fatalf("expected parse error for %q", bad)
+		}
+	}
+	for _, p := range []PathSeverity{{Severity: "error"}, {Pattern: "a/**", Severity: "off"}, {Pattern: "a/**"}} {
+		if err := Validate(ApplyDefaults(Config{Paths: []PathSeverity{p}})); err == nil {
+			t.Fatalf("expected validation error for %+v", p)
+		}
+	}
+}
+
+func TestScanHeadOnlyConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "flow", input: `scan_head_only: {"db/seeds/**": {bytes: 1MB}, "*.log": {bytes: 2KB, lines: 100}}
+`},
+		{name: "block", input: `scan_head_only:
+  "db/seeds/**": {bytes: 1MB}  # sample
+  "*.log":
+    bytes: 2KB
+    lines: 100
+`},
+	}
+	want := []HeadLimit{{Pattern: "db/seeds/**", Bytes: 1 << 20}, {Pattern: "*.log", Bytes: 2 << 10, Lines: 100}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseConfigYAML(tt.input)
+			if err != nil || !reflect.DeepEqual(cfg.ScanHeadOnly, want) || Validate(ApplyDefaults(cfg)) != nil {
+				t.Fatalf("unexpected config: %+v, %v", cfg.ScanHeadOnly, err)
+			}
+			rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			reparsed, err := parseConfigYAML(rendered)
+			if err != nil || !reflect.DeepEqual(reparsed.ScanHeadOnly, want) {
+				t.Fatalf("round trip = %+v, %v from %q", reparsed.ScanHeadOnly, err, rendered)
+			}
+		})
+	}
+
+	for _, bad := range []string{
+		"scan_head_only: [\"db/**\"]\n",
+		"scan_head_only:\n  db/**: 1MB\n",
+		"scan_head_only:\n  db/**: {size: 1MB}\n",
+		"scan_head_only:\n  db/**: {bytes: lots}\n",
+		"scan_head_only:\n  db/**: {lines: many}\n",
+	} {
+		if _, err := parseConfigYAML(bad); err == nil {
+			t.Fatalf("expected parse error for %q", bad)
+		}
+	}
+	for _, h := range []HeadLimit{{Bytes: 1}, {Pattern: "db/**"}, {Pattern: "db/**", Lines: -1}} {
+		if err := Validate(ApplyDefaults(Config{ScanHeadOnly: []HeadLimit{h}})); err == nil {
+			t.Fatalf("expected validation error for %+v", h)
+		}
+	}
+}
+
+func TestPoliciesConfig(t *testing.T) {
+	input := `policies:
+  - paths: ["**/*.md", "docs/**"]
+    categories: ["Unicode Symbol=off", "Latin Extended=Off"]
+  - paths: "**/*_test.go"
+    categories: ["*=warning"]
+  - paths: ["**/*.vue"]
+    regions: ["template", "style"]
+    categories: ["*=off"]
+severity: error
+`
+	cfg, err := parseConfigYAML(input)
+	if err != nil {
+		t.Fatalf("parseConfigYAML() error = %v", err)
+	}
+	want := []Policy{
+		{Paths: []string{"**/*.md", "docs/**"}, Categories: []string{"Unicode Symbol=off", "Latin Extended=Off"}},
+		{Paths: []string{"**/*_test.go"}, Categories: []string{"*=warning"}},
+		{Paths: []string{"**/*.vue"}, Regions: []string{"template", "style"}, Categories: []string{"*=off"}},
+	}
+	if !reflect.DeepEqual(cfg.Policies, want) || cfg.Severity != SeverityError {
+		t.Fatalf("unexpected policies: %+v", cfg.Policies)
+	}
+	if err := Validate(ApplyDefaults(cfg)); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := PolicyLevels(want[0].Categories); !reflect.DeepEqual(got, map[string]string{"unicode symbol": "off", "latin extended": "off"}) {
+		t.Fatalf("PolicyLevels() = %v", got)
+	}
+
+	rendered, err := renderConfigYAML(ApplyDefaults(cfg))
+	if err != nil {
+		t.Fatalf("renderConfigYAML() error = %v", err)
+	}
+	reparsed, err := parseConfigYAML(rendered)
+	if err != nil || !reflect.DeepEqual(reparsed.Policies, want) {
+		t.Fatalf("round trip = %+v, %v\n%s", reparsed.Policies, err, rendered)
+	}
+
+	if _, err := parseConfigYAML("policies:\n  - paths: [\"a\"]\n    severity: off\n"); err == nil {
+		t.Fatalf("expected unknown policy key error")
+	}
+	for _, bad := range []Policy{
+		{Categories: []string{"CJK=off"}},
+		{Paths: []string{"a"}},
+		{Paths: []string{"a"}, Categories: []string{"CJK"}},
+		{Paths: []string{"a"}, Categories: []string{"CJK=ignore"}},
+		{Paths: []string{"a"}, Regions: []string{"markup"}, Categories: []string{"CJK=off"}},
+	} {
+		if err := Validate(Config{Severity: SeverityError, Policies: []Policy{bad}}); err == nil {
+			t.Fatalf("expected validation error for %+v", bad)
+		}
+	}
+}
+
+func TestMinConfidenceConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("min_confidence: Medium\n")
+	if err != nil {
+		t.Fatalf("parseConfigYAML() error = %v", err)
+	}
+	cfg = ApplyDefaults(cfg)
+	if cfg.MinConfidence != "medium" || Validate(cfg) != nil {
+		t.Fatalf("unexpected min_confidence: %+v", cfg)
+	}
+	if err := Validate(Config{Severity: SeverityError, MinConfidence: "certain"}); err == nil {
+		t.Fatalf("expected invalid min_confidence error")
+	}
+	rendered, err := renderConfigYAML(cfg)
+	if err != nil || !strings.Contains(rendered, "min_confidence: medium\n") {
+		t.Fatalf("expected rendered min_confidence, got %q", rendered)
+	}
+}
+
+func TestInvalidUTF8FixConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("invalid_utf8_fix: Legacy\n")
+	if err != nil {
+		t.Fatalf("parseConfigYAML() error = %v", err)
+	}
+	cfg = ApplyDefaults(cfg)
+	if cfg.InvalidUTF8Fix != "legacy" || Validate(cfg) != nil {
+		t.Fatalf("unexpected invalid_utf8_fix: %+v", cfg)
+	}
+	if err := Validate(Config{Severity: SeverityError, InvalidUTF8Fix: "latin9"}); err == nil {
+		t.Fatalf("expected invalid invalid_utf8_fix error")
+	}
+	rendered, err := renderConfigYAML(cfg)
+	if err != nil || !strings.Contains(rendered, "invalid_utf8_fix: legacy\n") {
+		t.Fatalf("expected rendered invalid_utf8_fix, got %q", rendered)
+	}
+}
+
+func TestFixModeConfig(t *testing.T) {
+	cfg, err := parseConfigYAML("fix_mode: Transliterate\n")
+	if err != nil {
+		t.Fatalf("parseConfigYAML() error = %v", err)
+	}
+	cfg = ApplyDefaults(cfg)
+	if cfg.FixMode != "transliterate" || Validate(cfg) != nil {
+		t.Fatalf("unexpected fix_mode: %+v", cfg)
+	}
+	if err := Validate(Config{Severity: SeverityError, FixMode: "ascii"}); err == nil {
+		t.Fatalf("expected invalid fix_mode error")
+	}
+	rendered, err := renderConfigYAML(cfg)
+	if err != nil || !strings.Contains(rendered, "fix_mode: transliterate\n") {
+		t.Fatalf("expected rendered fix_mode, got %q", rendered)
+	}
+}
+
+func TestPatternRoot(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(path, []byte("root: \"..\"\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Root != ".." || cfg.PatternRoot() != filepath.Dir(tmp) {
+		t.Fatalf("unexpected root %q, pattern root %q", cfg.Root, cfg.PatternRoot())
+	}
+	rendered, err := renderConfigYAML(cfg)
+	if err != nil || !strings.Contains(rendered, "root: \"..\"\n") {
+		t.Fatalf("expected rendered root, got %q", rendered)
+	}
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{cfg: Config{}, want: ""},
+		{cfg: Config{Dir: "conf"}, want: "conf"},
+		{cfg: Config{Dir: "conf", Root: "/repo"}, want: "/repo"},
+		{cfg: Config{Dir: "conf", Root: "src"}, want: filepath.Join("conf", "src")},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.PatternRoot(); got != tt.want {
+			t.Fatalf("PatternRoot(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	tmp := t.TempDir()
+	org := filepath.Join(tmp, "org", "englint.yaml")
+	repo := filepath.Join(tmp, "repo", ".englint.yaml")
+	for path, content := range map[string]string{
+		org:  "severity: warning\nignore_urls: true\nroot: \"shared\"\nallow:\n  - {value: \"©\", expires: 2020-01-01}\nexclude:\n  - \"vendor/**\"\n",
+		repo: "ignore_urls: false\nexclude:\n  - \"gen/**\"\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := LoadLayers([]string{org, filepath.Join(tmp, "missing.yaml"), repo})
+	if err != nil {
+		t.Fatalf("LoadLayers() error = %v", err)
+	}
+	if cfg.Severity != SeverityWarning || cfg.IgnoreURLs {
+		t.Fatalf("expected severity from org and ignore_urls from repo, got %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Exclude, []string{"gen/**"}) {
+		t.Fatalf("expected repo exclude to replace org exclude, got %q", cfg.Exclude)
+	}
+	if len(cfg.AllowEntries) != 1 || cfg.AllowEntries[0].Path != org || cfg.AllowEntries[0].Line != 5 {
+		t.Fatalf("expected allow entry from org config, got %+v", cfg.AllowEntries)
+	}
+	if cfg.Dir != filepath.Dir(repo) || cfg.PatternRoot() != filepath.Join(tmp, "org", "shared") {
+		t.Fatalf("unexpected dir %q, pattern root %q", cfg.Dir, cfg.PatternRoot())
+	}
+
+	cfg, err = LoadLayers([]string{repo, org})
+	if err != nil {
+		t.Fatalf("LoadLayers() error = %v", err)
+	}
+	if !cfg.IgnoreURLs || !reflect.DeepEqual(cfg.Exclude, []string{"vendor/**"}) {
+		t.Fatalf("expected org config to win when last, got %+v", cfg)
+	}
+
+	bad := filepath.Join(tmp, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("severity: loud\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLayers([]string{org, bad}); err == nil {
+		t.Fatalf("expected invalid merged config error")
+	}
+	if _, err := LoadLayers([]string{bad, org}); err != nil {
+		t.Fatalf("expected a later severity to replace the invalid one, got %v", err)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, ".englint.yaml")
+	if err := os.WriteFile(path, []byte("severity: error\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"ENGLINT_SEVERITY":       "warning",
+		"ENGLINT_IGNORE_URLS":    "true",
+		"ENGLINT_EXCLUDE":        "vendor/**, gen/**,",
+		"ENGLINT_MMAP_THRESHOLD": "1MB",
+	}
+	cfg, err := LoadEnv(func(name string) string { return env[name] }, []string{path})
+	if err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	if cfg.Severity != SeverityError {
+		t.Fatalf("expected the config file to override the environment, got %q", cfg.Severity)
+	}
+	if !cfg.IgnoreURLs || cfg.MmapThreshold != 1<<20 || !reflect.DeepEqual(cfg.Exclude, []string{"vendor/**", "gen/**"}) {
+		t.Fatalf("expected environment values, got %+v", cfg)
+	}
+
+	env = map[string]string{"ENGLINT_IGNORE_URLS": "maybe"}
+	if _, err := LoadEnv(func(name string) string { return env[name] }, nil); err == nil || !strings.Contains(err.Error(), "ENGLINT_IGNORE_URLS") {
+		t.Fatalf("expected invalid ENGLINT_IGNORE_URLS error, got %v", err)
+	}
+}
+
+func TestLoadEnvUserConfig(t *testing.T) {
+	tmp := t.TempDir()
+	userPath := filepath.Join(tmp, "home", ".config", "englint", "config.yaml")
+	projectPath := filepath.Join(tmp, ".englint.yaml")
+	if err := os.MkdirAll(filepath.Dir(userPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userPath, []byte("severity: warning\nverbose: true\nallow:\n  - value: \"é\"\n    reason: \"my name\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectPath, []byte("severity: error\nallow:\n  - \"ü\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		paths []string
+		want  Config
+	}{
+		{
+			name:  "project overrides user",
+			env:   map[string]string{"HOME": filepath.Join(tmp, "home")},
+			paths: []string{projectPath},
+			want:  Config{Severity: SeverityError, Verbose: true, Allow: []string{"ü", "é"}},
+		},
+		{
+			name: "user adds to default allow",
+			env:  map[string]string{"HOME": filepath.Join(tmp, "home")},
+			want: Config{Severity: SeverityWarning, Verbose: true, Allow: []string{"©", "→", "€", "£", "¥", "°", "é"}},
+		},
+		{
+			name: "environment overrides user",
+			env:  map[string]string{"HOME": filepath.Join(tmp, "home"), "ENGLINT_SEVERITY": "error"},
+			want: Config{Severity: SeverityError, Verbose: true, Allow: []string{"©", "→", "€", "£", "¥", "°", "é"}},
+		},
+		{
+			name:  "xdg config home wins",
+			env:   map[string]string{"HOME": filepath.Join(tmp, "home"), "XDG_CONFIG_HOME": filepath.Join(tmp, "missing")},
+			paths: []string{projectPath},
+			want:  Config{Severity: SeverityError, Allow: []string{"ü"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadEnv(func(name string) string { return tt.env[name] }, tt.paths)
+			if err != nil {
+				t.Fatalf("LoadEnv() error = %v", err)
+			}
+			if cfg.Severity != tt.want.Severity || cfg.Verbose != tt.want.Verbose || !reflect.DeepEqual(cfg.Allow, tt.want.Allow) {
+				t.Fatalf("got severity %q verbose %v allow %q, want %q %v %q", cfg.Severity, cfg.Verbose, cfg.Allow, tt.want.Severity, tt.want.Verbose, tt.want.Allow)
+			}
+		})
+	}
+
+	cfg, err := LoadEnv(func(name string) string { return map[string]string{"HOME": filepath.Join(tmp, "home")}[name] }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.AllowEntries) != 1 || cfg.AllowEntries[0].Path != userPath {
+		t.Fatalf("expected allow entry from the user config, got %+v", cfg.AllowEntries)
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TT-AIXion/englint/internal/match"
+)
+
+// Lint reports likely mistakes in a valid config that do not make it
+// invalid: exclude patterns shadowed by earlier ones, include patterns that
+// every file they match is excluded from, and allow entries with more than
+// one character.
+func Lint(cfg Config) []string {
+	var warnings []string
+	for j, pattern := range cfg.Exclude {
+		for _, earlier := range cfg.Exclude[:j] {
+			if excludeCovers(earlier, pattern) {
+				warnings = append(warnings, fmt.Sprintf("exclude pattern %q is shadowed by the earlier exclude pattern %q", pattern, earlier))
+				break
+			}
+		}
+	}
+	for _, pattern := range cfg.Include {
+		for _, exclude := range cfg.Exclude {
+			if excludeCovers(exclude, pattern) {
+				warnings = append(warnings, fmt.Sprintf("include pattern %q can never match: exclude pattern %q excludes every file it matches", pattern, exclude))
+				break
+			}
+		}
+	}
+	for _, value := range cfg.Allow {
+		if n := utf8.RuneCountInString(value); n > 1 {
+			warnings = append(warnings, fmt.Sprintf("allow entry %q has %d characters; each of them is allowed on its own", value, n))
+		}
+	}
+	return warnings
+}
+
+// excludeCovers reports whether exclude excludes every path that pattern
+// matches, either directly or through a parent directory, which is then
+// not walked. Wildcards in pattern are matched as literal text, so they are
+// only covered by wildcards that are at least as broad.
+func excludeCovers(exclude, pattern string) bool {
+	exclude = strings.TrimSpace(strings.ReplaceAll(exclude, "\\", "/"))
+	pattern = strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/"))
+	if exclude == "" || pattern == "" {
+		return false
+	}
+	if exclude == pattern {
+		return true
+	}
+	for p := pattern; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if literalMatch(exclude, p) || literalMatch(exclude, path.Base(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// literalMatch matches exclude against the text of a pattern, refusing
+// matches where a wildcard of the pattern is broader than exclude's.
+func literalMatch(exclude, text string) bool {
+	if strings.ContainsAny(text, "*?") && !strings.Contains(exclude, "*") {
+		return false
+	}
+	// Only "**" crosses slashes; patterns without a slash match base names
+	// at any depth.
+	if strings.Contains(text, "**") && strings.Contains(exclude, "/") && !strings.Contains(exclude, "**") {
+		return false
+	}
+	return match.Match(exclude, text)
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{name: "clean", cfg: Config{Include: []string{"**/*.go", "**/*.md"}, Exclude: []string{"vendor/**", "**/*.pb.go"}, Allow: []string{"©", "é"}}},
+		{name: "shadowed excludes", cfg: Config{Exclude: []string{"vendor/**", "vendor/github.com/**", "*.min.js", "dist/*.min.js", "node_modules", "web/node_modules/**", "vendor/**"}}, want: []string{
+			`exclude pattern "vendor/github.com/**" is shadowed by the earlier exclude pattern "vendor/**"`,
+			`exclude pattern "dist/*.min.js" is shadowed by the earlier exclude pattern "*.min.js"`,
+			`exclude pattern "web/node_modules/**" is shadowed by the earlier exclude pattern "node_modules"`,
+			`exclude pattern "vendor/**" is shadowed by the earlier exclude pattern "vendor/**"`,
+		}},
+		{name: "broader later exclude", cfg: Config{Exclude: []string{"gen/*.go", "gen/**", "a?c", "a*c", "src/*/x.go", "src/**/x.go"}}},
+		{name: "unreachable includes", cfg: Config{Include: []string{"docs/**/*.md", "**/*.go", "third_party/*.go"}, Exclude: []string{"docs/**", "third_party"}}, want: []string{
+			`include pattern "docs/**/*.md" can never match: exclude pattern "docs/**" excludes every file it matches`,
+			`include pattern "third_party/*.go" can never match: exclude pattern "third_party" excludes every file it matches`,
+		}},
+		{name: "multi-rune allow entries", cfg: Config{Allow: []string{"é", "e\u0301", "U+00E9", "–"}}, want: []string{
+			"allow entry \"e\u0301\" has 2 characters; each of them is allowed on its own",
+			`allow entry "U+00E9" has 6 characters; each of them is allowed on its own`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lint(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
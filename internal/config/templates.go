@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TemplateNames lists the starter configs englint init writes, the default
+// first.
+var TemplateNames = []string{"default", "go", "node", "docs", "security", "monorepo"}
+
+// templates holds the starter configs by name. Only the default lists every
+// key; the others set what their kind of project needs.
+var templates = map[string]string{
+	"default": DefaultTemplate,
+	"go": `# Starter config for Go modules. englint init --template=default writes
+# one that lists every key.
+include:
+  - "**/*.go"
+  - "**/*.md"
+exclude:
+  - ".git/**"
+  - "vendor/**"
+  - "testdata/**"
+  - "**/testdata/**"
+allow:
+  - "©"
+severity: error
+ignore_urls: true
+decode_escapes: true  # report "\u4e2d" in string literals like 中
+`,
+	"node": `# Starter config for JavaScript and TypeScript projects. englint init
+# --template=default writes one that lists every key.
+include:
+  - "**/*.js"
+  - "**/*.jsx"
+  - "**/*.mjs"
+  - "**/*.cjs"
+  - "**/*.ts"
+  - "**/*.tsx"
+  - "**/*.vue"
+  - "**/*.svelte"
+  - "**/*.md"
+exclude:
+  - ".git/**"
+  - "node_modules/**"
+  - "dist/**"
+  - "build/**"
+  - "coverage/**"
+  - "*.min.js"
+  - "*.lock"
+  - "package-lock.json"
+allow:
+  - "©"
+  - "→"
+severity: error
+ignore_urls: true
+ignore_blobs: true  # skip inlined base64 assets and data: URIs
+decode_escapes: true  # report "\u4e2d" in string literals like 中
+# allow_patterns:  # allow findings inside translation calls
+#   - pattern: 't\("[^"]*"\)'
+`,
+	"docs": `# Starter config for documentation. Prose keeps typographic punctuation
+# and accented names, and only warns. englint init --template=default
+# writes one that lists every key.
+include:
+  - "**/*.md"
+  - "**/*.mdx"
+  - "**/*.rst"
+  - "**/*.adoc"
+  - "**/*.txt"
+exclude:
+  - ".git/**"
+  - "node_modules/**"
+allow:
+  - "©"
+  - "→"
+  - "–"
+  - "—"
+  - "…"
+  - "‘"
+  - "’"
+  - "“"
+  - "”"
+severity: warning
+allow_latin_extended: true  # allow é, ü, ß, and other Latin letters
+ignore_urls: true
+ignore_code_blocks: true  # report only prose
+check_entities: true  # report &nbsp; and &#x4e2d;
+`,
+	"security": `# Starter config that fails on characters that hide or disguise code,
+# such as the bidirectional controls of Trojan Source attacks, and only
+# warns about other non-English text. englint init --template=default
+# writes one that lists every key.
+include:
+  - "**"
+exclude:
+  - ".git/**"
+  - "node_modules/**"
+  - "vendor/**"
+allow:
+  - "©"
+severity: warning
+decode_escapes: true  # report "\u202e" in string literals too
+check_entities: true  # report &#x202e; in HTML and Markdown
+categories:
+  - name: "Bidi Control"
+    ranges: ["U+061C", "U+200E..U+200F", "U+202A..U+202E", "U+2066..U+2069"]
+    severity: error
+  - name: "Zero Width"
+    ranges: ["U+200B..U+200D", "U+2060"]
+    severity: error
+policies:
+  - paths: ["**"]
+    categories: ["Invisible Character=error", "Format Character=error", "Confusable Latin=error", "Private Use=error"]
+`,
+	"monorepo": `# Starter config for repositories of several packages in several
+# languages. A .englint.yaml in a package directory adds allow and exclude
+# entries for the files beneath it. englint init --template=default writes
+# one that lists every key.
+include:
+  - "**/*.go"
+  - "**/*.js"
+  - "**/*.jsx"
+  - "**/*.ts"
+  - "**/*.tsx"
+  - "**/*.py"
+  - "**/*.java"
+  - "**/*.kt"
+  - "**/*.rs"
+  - "**/*.md"
+exclude:
+  - ".git/**"
+  - "node_modules/**"
+  - "**/node_modules/**"
+  - "vendor/**"
+  - "**/vendor/**"
+  - "**/dist/**"
+  - "**/build/**"
+  - "**/target/**"
+  - "*.lock"
+  - "package-lock.json"
+allow:
+  - "©"
+  - "→"
+severity: error
+ignore_urls: true
+ignore_blobs: true
+dedupe_content: true  # report the findings of copied files once
+# Default severity of matching files; later entries win.
+paths:
+  "docs/**": {severity: warning}
+`,
+}
+
+// Template returns the starter config called name, one of TemplateNames.
+func Template(name string) (string, error) {
+	template, ok := templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q: want one of %s", name, strings.Join(TemplateNames, ", "))
+	}
+	return template, nil
+}
+
+// WriteTemplate writes the starter config called name to path, replacing
+// any existing file.
+func WriteTemplate(path, name string) error {
+	template, err := Template(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(template), 0o644)
+}
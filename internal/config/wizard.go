@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Language is a language englint init --interactive offers, with the file
+// extensions of its source files and the directories its tooling generates.
+type Language struct {
+	Name       string
+	Extensions []string
+	Exclude    []string
+}
+
+// Languages lists the languages englint init --interactive offers.
+var Languages = []Language{
+	{Name: "go", Extensions: []string{".go"}},
+	{Name: "javascript", Extensions: []string{".js", ".jsx", ".mjs", ".cjs"}, Exclude: []string{"dist/**", "build/**", "coverage/**", "*.min.js", "package-lock.json"}},
+	{Name: "typescript", Extensions: []string{".ts", ".tsx"}, Exclude: []string{"dist/**", "build/**", "coverage/**", "package-lock.json"}},
+	{Name: "python", Extensions: []string{".py"}, Exclude: []string{".venv/**", "venv/**", "**/__pycache__/**"}},
+	{Name: "java", Extensions: []string{".java"}, Exclude: []string{"build/**", "target/**"}},
+	{Name: "kotlin", Extensions: []string{".kt", ".kts"}, Exclude: []string{"build/**", "target/**"}},
+	{Name: "rust", Extensions: []string{".rs"}, Exclude: []string{"target/**"}},
+	{Name: "ruby", Extensions: []string{".rb"}},
+	{Name: "php", Extensions: []string{".php"}},
+	{Name: "csharp", Extensions: []string{".cs"}, Exclude: []string{"bin/**", "obj/**"}},
+	{Name: "c", Extensions: []string{".c", ".h"}},
+	{Name: "cpp", Extensions: []string{".cc", ".cpp", ".cxx", ".hh", ".hpp"}},
+	{Name: "swift", Extensions: []string{".swift"}},
+}
+
+// LanguageNames returns the names of Languages.
+func LanguageNames() []string {
+	names := make([]string, len(Languages))
+	for i, l := range Languages {
+		names[i] = l.Name
+	}
+	return names
+}
+
+func findLanguage(name string) (Language, bool) {
+	i := slices.IndexFunc(Languages, func(l Language) bool { return l.Name == name })
+	if i < 0 {
+		return Language{}, false
+	}
+	return Languages[i], true
+}
+
+// detectLimit caps how many files DetectLanguages looks at.
+const detectLimit = 10000
+
+// DetectLanguages returns the names of the Languages with source files
+// under dir, in the order of Languages. It skips hidden and dependency
+// directories and stops after detectLimit files.
+func DetectLanguages(dir string) []string {
+	found := make(map[string]bool)
+	seen := 0
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if seen++; seen > detectLimit {
+			return fs.SkipAll
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, l := range Languages {
+			if slices.Contains(l.Extensions, ext) {
+				found[l.Name] = true
+			}
+		}
+		return nil
+	})
+	var out []string
+	for _, l := range Languages {
+		if found[l.Name] {
+			out = append(out, l.Name)
+		}
+	}
+	return out
+}
+
+// DocsPolicies are the answers to the docs question of englint init
+// --interactive: report findings in documentation at the default severity,
+// as warnings, or not at all.
+var DocsPolicies = []string{"error", "warning", "off"}
+
+// docsPatterns match the documentation files englint init --interactive
+// includes.
+var docsPatterns = []string{"**/*.md", "**/*.mdx", "**/*.rst", "**/*.adoc"}
+
+// emojiRanges cover pictographs, dingbats, and the variation selector that
+// asks for their emoji style.
+var emojiRanges = []string{"U+2600..U+27BF", "U+1F000..U+1FAFF", "U+FE0F"}
+
+// InitAnswers are the answers to the questions of englint init
+// --interactive.
+type InitAnswers struct {
+	// Languages are names from Languages.
+	Languages []string
+	// Docs is one of DocsPolicies.
+	Docs       string
+	AllowEmoji bool
+}
+
+// InitConfig builds the config englint init --interactive writes for a.
+func InitConfig(a InitAnswers) (Config, error) {
+	if len(a.Languages) == 0 && a.Docs == "off" {
+		return Config{}, errors.New("choose at least one language or include docs")
+	}
+	cfg := DefaultConfig()
+	cfg.Include = nil
+	for _, name := range a.Languages {
+		l, ok := findLanguage(name)
+		if !ok {
+			return Config{}, fmt.Errorf("unknown language %q: want one of %s", name, strings.Join(LanguageNames(), ", "))
+		}
+		for _, ext := range l.Extensions {
+			cfg.Include = append(cfg.Include, "**/*"+ext)
+		}
+		for _, pattern := range l.Exclude {
+			if !slices.Contains(cfg.Exclude, pattern) {
+				cfg.Exclude = append(cfg.Exclude, pattern)
+			}
+		}
+	}
+	switch a.Docs {
+	case "error":
+		cfg.Include = append(cfg.Include, docsPatterns...)
+	case "warning":
+		cfg.Include = append(cfg.Include, docsPatterns...)
+		for _, pattern := range docsPatterns {
+			cfg.Paths = append(cfg.Paths, PathSeverity{Pattern: pattern, Severity: SeverityWarning})
+		}
+	case "off":
+	default:
+		return Config{}, fmt.Errorf("docs must be one of %s", strings.Join(DocsPolicies, ", "))
+	}
+	if a.AllowEmoji {
+		cfg.Categories = append(cfg.Categories, Category{Name: "Emoji", Ranges: emojiRanges, Severity: "off"})
+	}
+	return cfg, nil
+}
@@ -0,0 +1,75 @@
+// Package diff compares the findings of two scan results.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// Result lists findings present only in the new scan, only in the old scan,
+// and in both.
+type Result struct {
+	Added     []scanner.Finding `json:"added"`
+	Removed   []scanner.Finding `json:"removed"`
+	Unchanged []scanner.Finding `json:"unchanged"`
+}
+
+// Compare matches current findings against previous ones by fingerprint.
+// Findings from results written before fingerprints existed are matched by
+// path, line, column, and code point instead.
+func Compare(previous, current []scanner.Finding) Result {
+	remaining := make(map[string]int)
+	for _, f := range previous {
+		remaining[key(f)]++
+	}
+
+	out := Result{Added: []scanner.Finding{}, Removed: []scanner.Finding{}, Unchanged: []scanner.Finding{}}
+	matched := make(map[string]int)
+	for _, f := range current {
+		k := key(f)
+		if remaining[k] > 0 {
+			remaining[k]--
+			matched[k]++
+			out.Unchanged = append(out.Unchanged, f)
+			continue
+		}
+		out.Added = append(out.Added, f)
+	}
+	for _, f := range previous {
+		k := key(f)
+		if matched[k] > 0 {
+			matched[k]--
+			continue
+		}
+		out.Removed = append(out.Removed, f)
+	}
+	return out
+}
+
+func key(f scanner.Finding) string {
+	if f.Fingerprint != "" {
+		return f.Fingerprint
+	}
+	return fmt.Sprintf("%s:%d:%d:%s", f.Path, f.Line, f.Column, f.CodePoint)
+}
+
+// LoadFindings reads the findings from a `scan --json` result file.
+func LoadFindings(path string) ([]scanner.Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Findings *[]scanner.Finding `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if payload.Findings == nil {
+		return nil, fmt.Errorf("%s is not an englint JSON result (missing findings)", path)
+	}
+	return *payload.Findings, nil
+}
@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestCompare(t *testing.T) {
+	previous := []scanner.Finding{
+		{Path: "a.go", Line: 1, Fingerprint: "aaa"},
+		{Path: "a.go", Line: 2, Fingerprint: "bbb"},
+		{Path: "legacy.go", Line: 3, Column: 4, CodePoint: "U+00E9"},
+		{Path: "dup.go", Line: 1, Fingerprint: "ddd"},
+	}
+	current := []scanner.Finding{
+		{Path: "a.go", Line: 5, Fingerprint: "aaa"},
+		{Path: "a.go", Line: 6, Fingerprint: "ccc"},
+		{Path: "legacy.go", Line: 3, Column: 4, CodePoint: "U+00E9"},
+		{Path: "dup.go", Line: 1, Fingerprint: "ddd"},
+		{Path: "dup.go", Line: 1, Fingerprint: "ddd"},
+	}
+
+	got := Compare(previous, current)
+	if len(got.Added) != 2 || got.Added[0].Fingerprint != "ccc" || got.Added[1].Fingerprint != "ddd" {
+		t.Fatalf("unexpected added: %+v", got.Added)
+	}
+	if len(got.Removed) != 1 || got.Removed[0].Fingerprint != "bbb" {
+		t.Fatalf("unexpected removed: %+v", got.Removed)
+	}
+	if len(got.Unchanged) != 3 || got.Unchanged[0].Line != 5 {
+		t.Fatalf("unexpected unchanged: %+v", got.Unchanged)
+	}
+
+	empty := Compare(nil, nil)
+	if empty.Added == nil || empty.Removed == nil || empty.Unchanged == nil {
+		t.Fatalf("expected empty slices, got %+v", empty)
+	}
+}
+
+func TestLoadFindings(t *testing.T) {
+	tmp := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(tmp, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	findings, err := LoadFindings(write("ok.json", `{"summary":{"findings":1},"findings":[{"path":"a.go","line":1,"fingerprint":"aaa"}]}`))
+	if err != nil || len(findings) != 1 || findings[0].Fingerprint != "aaa" {
+		t.Fatalf("unexpected findings: %+v (%v)", findings, err)
+	}
+	if findings, err := LoadFindings(write("empty.json", `{"findings":[]}`)); err != nil || len(findings) != 0 {
+		t.Fatalf("unexpected empty findings: %+v (%v)", findings, err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "missing", path: filepath.Join(tmp, "missing.json"), want: "no such file"},
+		{name: "invalid", path: write("bad.json", "{"), want: "invalid JSON"},
+		{name: "not a result", path: write("other.json", `{"explanations":[]}`), want: "missing findings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadFindings(tt.path); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q error, got %v", tt.want, err)
+			}
+		})
+	}
+}
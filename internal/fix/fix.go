@@ -0,0 +1,132 @@
+// Package fix repairs files in place for findings that have a mechanical
+// remedy.
+package fix
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"unicode/utf8"
+)
+
+// Strategy selects how InvalidUTF8 repairs invalid bytes.
+type Strategy string
+
+const (
+	// Replace substitutes U+FFFD for every invalid byte.
+	Replace Strategy = "replace"
+	// Strip deletes invalid bytes.
+	Strip Strategy = "strip"
+	// Legacy re-decodes invalid bytes as Windows-1252, the usual source of
+	// stray bytes in otherwise UTF-8 files, keeping é in "caf\xe9".
+	Legacy Strategy = "legacy"
+)
+
+// Strategies lists the valid strategies.
+var Strategies = []Strategy{Replace, Strip, Legacy}
+
+// cp1252 maps the Windows-1252 bytes 0x80-0x9F to runes; the other high
+// bytes match Latin-1. Undefined bytes map to U+FFFD.
+var cp1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
+
+// InvalidUTF8 returns data with every byte that is not part of a valid
+// UTF-8 sequence repaired by strategy, and the number of bytes repaired.
+func InvalidUTF8(data []byte, strategy Strategy) ([]byte, int) {
+	out := make([]byte, 0, len(data))
+	repaired := 0
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r != utf8.RuneError || size != 1 {
+			out = append(out, data[:size]...)
+			data = data[size:]
+			continue
+		}
+		repaired++
+		switch strategy {
+		case Strip:
+		case Legacy:
+			out = utf8.AppendRune(out, decodeCP1252(data[0]))
+		default:
+			out = utf8.AppendRune(out, utf8.RuneError)
+		}
+		data = data[1:]
+	}
+	return out, repaired
+}
+
+func decodeCP1252(b byte) rune {
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252[b-0x80]
+	}
+	return rune(b)
+}
+
+// WriteFile replaces the content of the existing file at path, keeping its
+// permissions.
+func WriteFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Replacement replaces the character Old at Line and Column, both counted
+// from 1 in runes like scanner findings, with New.
+type Replacement struct {
+	Line   int
+	Column int
+	Old    string
+	New    string
+}
+
+// ReplaceCharacters returns data with the replacements applied and the
+// replacements it applied. A replacement whose Old text is no longer at its
+// position, because the file changed since it was scanned, is left out.
+// Lines end at \n, \r\n, or a lone \r, and each invalid UTF-8 byte counts
+// as one column, as when scanning.
+func ReplaceCharacters(data []byte, replacements []Replacement) ([]byte, []Replacement) {
+	if len(replacements) == 0 {
+		return data, nil
+	}
+	pending := slices.Clone(replacements)
+	slices.SortStableFunc(pending, func(a, b Replacement) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	out := make([]byte, 0, len(data))
+	var applied []Replacement
+	line, col := 1, 1
+	for i := 0; i < len(data); {
+		for len(pending) > 0 && (pending[0].Line < line || (pending[0].Line == line && pending[0].Column < col)) {
+			pending = pending[1:]
+		}
+		if len(pending) > 0 && pending[0].Line == line && pending[0].Column == col && pending[0].Old != "" && bytes.HasPrefix(data[i:], []byte(pending[0].Old)) {
+			r := pending[0]
+			out = append(out, r.New...)
+			applied = append(applied, r)
+			pending = pending[1:]
+			i += len(r.Old)
+			col += utf8.RuneCountInString(r.Old)
+			continue
+		}
+		_, size := utf8.DecodeRune(data[i:])
+		out = append(out, data[i:i+size]...)
+		switch {
+		case data[i] == '\n', data[i] == '\r' && (i+1 == len(data) || data[i+1] != '\n'):
+			line++
+			col = 1
+		default:
+			col++
+		}
+		i += size
+	}
+	return out, applied
+}
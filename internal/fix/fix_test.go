@@ -0,0 +1,138 @@
+package fix
+
+import "testing"
+
+func TestInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		data     string
+		want     string
+		repaired int
+	}{
+		{name: "valid", strategy: Replace, data: "café 日本", want: "café 日本"},
+		{name: "replace", strategy: Replace, data: "caf\xe9 \xff\xfe日", want: "caf� ��日", repaired: 3},
+		{name: "default replaces", data: "a\x80", want: "a�", repaired: 1},
+		{name: "strip", strategy: Strip, data: "caf\xe9 \xc3", want: "caf ", repaired: 2},
+		{name: "legacy latin1", strategy: Legacy, data: "caf\xe9 na\xefve é", want: "café naïve é", repaired: 2},
+		{name: "legacy cp1252", strategy: Legacy, data: "\x93quoted\x94 \x80 \x81", want: "“quoted” € �", repaired: 4},
+		{name: "truncated sequence", strategy: Legacy, data: "x\xe6\x97", want: "xæ—", repaired: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n := InvalidUTF8([]byte(tt.data), tt.strategy)
+			if string(got) != tt.want || n != tt.repaired {
+				t.Fatalf("InvalidUTF8 = %q, %d, want %q, %d", got, n, tt.want, tt.repaired)
+			}
+		})
+	}
+}
+
+func TestReplaceCharacters(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		replacements []Replacement
+		want         string
+		applied      int
+	}{
+		{name: "none", data: "a 𝐚\n", want: "a 𝐚\n"},
+		{
+			name:         "columns in runes",
+			data:         "é 𝐚 𝐛\n",
+			replacements: []Replacement{{Line: 1, Column: 5, Old: "𝐛", New: "b"}, {Line: 1, Column: 3, Old: "𝐚", New: "a"}},
+			want:         "é a b\n",
+			applied:      2,
+		},
+		{
+			name:         "line endings",
+			data:         "x\r\n𝐚\r𝐛\n\xff𝐜",
+			replacements: []Replacement{{Line: 2, Column: 1, Old: "𝐚", New: "a"}, {Line: 3, Column: 1, Old: "𝐛", New: "b"}, {Line: 4, Column: 2, Old: "𝐜", New: "c"}},
+			want:         "x\r\na\rb\n\xffc",
+			applied:      3,
+		},
+		{
+			name:         "stale",
+			data:         "𝐚𝐛\n",
+			replacements: []Replacement{{Line: 1, Column: 1, Old: "𝐛", New: "b"}, {Line: 1, Column: 2, Old: "𝐛", New: "b"}, {Line: 2, Column: 1, Old: "𝐚", New: "a"}},
+			want:         "𝐚b\n",
+			applied:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, applied := ReplaceCharacters([]byte(tt.data), tt.replacements)
+			if string(got) != tt.want || len(applied) != tt.applied {
+				t.Fatalf("ReplaceCharacters = %q, %d applied, want %q, %d", got, len(applied), tt.want, tt.applied)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{name: "equal", old: "a\n", new: "a\n"},
+		{
+			name: "merged hunk",
+			old:  "x\n1\n2\n3\n4\n5\n6\ny\n",
+			new:  "X\n1\n2\n3\n4\n5\n6\nY\n",
+			want: "diff --git a/p.go b/p.go\n--- a/p.go\n+++ b/p.go\n@@ -1,8 +1,8 @@\n-x\n+X\n 1\n 2\n 3\n 4\n 5\n 6\n-y\n+Y\n",
+		},
+		{
+			name: "consecutive lines",
+			old:  "a\nb\nc\n",
+			new:  "A\nB\nc\n",
+			want: "diff --git a/p.go b/p.go\n--- a/p.go\n+++ b/p.go\n@@ -1,3 +1,3 @@\n-a\n-b\n+A\n+B\n c\n",
+		},
+		{
+			name: "line count changed",
+			old:  "a\n",
+			new:  "b\nc\n",
+			want: "diff --git a/p.go b/p.go\n--- a/p.go\n+++ b/p.go\n@@ -1,1 +1,2 @@\n-a\n+b\n+c\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Patch("p.go", []byte(tt.old), []byte(tt.new))); got != tt.want {
+				t.Fatalf("Patch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModeSubstitute(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		char string
+		want string
+		ok   bool
+	}{
+		{mode: Transliterate, char: "é", want: "e", ok: true},
+		{mode: Transliterate, char: "ß", want: "ss", ok: true},
+		{mode: Transliterate, char: "Æ", want: "AE", ok: true},
+		{mode: Transliterate, char: "ж", want: "zh", ok: true},
+		{mode: Transliterate, char: "Ъ", want: "", ok: true},
+		{mode: Transliterate, char: "Ω", want: "O", ok: true},
+		{mode: Transliterate, char: "—", want: "--", ok: true},
+		{mode: Transliterate, char: "“", want: `"`, ok: true},
+		{mode: Transliterate, char: "Ａ", want: "A", ok: true},
+		{mode: Transliterate, char: "　", want: " ", ok: true},
+		{mode: Transliterate, char: "日"},
+		{mode: Transliterate, char: "é日"},
+		{mode: Question, char: "日", want: "?", ok: true},
+		{mode: Delete, char: "日", want: "", ok: true},
+		{mode: "", char: "é"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.char, func(t *testing.T) {
+			got, ok := tt.mode.Substitute(tt.char)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("Substitute(%q) = %q, %v, want %q, %v", tt.char, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package fix
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// patchContext is the number of unchanged lines shown around each change.
+const patchContext = 3
+
+// Patch returns a unified diff in git format that turns old into new for
+// the file at path, a slash-separated path relative to the repository
+// root, or nil when they are equal. Fixes replace characters within lines,
+// so lines are compared pairwise; if the line counts differ, the whole
+// file is one hunk.
+func Patch(path string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	a, b := splitLines(old), splitLines(new)
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	if len(a) != len(b) {
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(0, len(a)), hunkRange(0, len(b)))
+		writeLines(&out, '-', a)
+		writeLines(&out, '+', b)
+		return out.Bytes()
+	}
+	for i := 0; i < len(a); i++ {
+		if bytes.Equal(a[i], b[i]) {
+			continue
+		}
+		// Grow the hunk while the next change is close enough to share
+		// its context.
+		start, end := max(0, i-patchContext), i+1
+		for j := end; j < len(a) && j <= end+2*patchContext; j++ {
+			if !bytes.Equal(a[j], b[j]) {
+				end = j + 1
+			}
+		}
+		stop := min(len(a), end+patchContext)
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(start, stop-start), hunkRange(start, stop-start))
+		for k := start; k < stop; {
+			if bytes.Equal(a[k], b[k]) {
+				writeLines(&out, ' ', a[k:k+1])
+				k++
+				continue
+			}
+			run := k
+			for run < stop && !bytes.Equal(a[run], b[run]) {
+				run++
+			}
+			writeLines(&out, '-', a[k:run])
+			writeLines(&out, '+', b[k:run])
+			k = run
+		}
+		i = stop - 1
+	}
+	return out.Bytes()
+}
+
+// splitLines splits data after each \n, keeping the line breaks.
+func splitLines(data []byte) [][]byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange formats the 0-based start and length of a hunk side.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// writeLines writes lines with prefix, marking a last line without a line
+// break the way diff does.
+func writeLines(out *bytes.Buffer, prefix byte, lines [][]byte) {
+	for _, line := range lines {
+		out.WriteByte(prefix)
+		out.Write(line)
+		if !bytes.HasSuffix(line, []byte("\n")) {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package fix
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mode selects what replaces a reported character that has no suggested
+// fix of its own.
+type Mode string
+
+const (
+	// Transliterate spells a character in ASCII, such as e for é, ss for ß,
+	// or zh for ж, and leaves characters without a spelling unfixed.
+	Transliterate Mode = "transliterate"
+	// Question replaces each character with ?.
+	Question Mode = "question"
+	// Delete removes each character.
+	Delete Mode = "delete"
+)
+
+// Modes lists the valid modes.
+var Modes = []Mode{Transliterate, Question, Delete}
+
+// Substitute returns the text that replaces char, a single character, in
+// mode m, or false when m has none for it.
+func (m Mode) Substitute(char string) (string, bool) {
+	switch m {
+	case Question:
+		return "?", true
+	case Delete:
+		return "", true
+	case Transliterate:
+		r, size := utf8.DecodeRuneInString(char)
+		if size != len(char) {
+			return "", false
+		}
+		return Transliteration(r)
+	}
+	return "", false
+}
+
+// Transliteration returns the ASCII spelling of r: Latin letters without
+// their diacritics, Cyrillic and Greek letters spelled out, typographic
+// punctuation and spaces as their ASCII counterparts, and fullwidth forms
+// as the ASCII characters they widen.
+func Transliteration(r rune) (string, bool) {
+	if ascii, ok := transliterations[r]; ok {
+		return ascii, true
+	}
+	switch {
+	case r >= '！' && r <= '～':
+		return string(r - '！' + '!'), true
+	case unicode.Is(unicode.Zs, r):
+		return " ", true
+	}
+	return "", false
+}
+
+// transliterations holds the spellings of transliterationGroups by rune.
+var transliterations = func() map[rune]string {
+	out := make(map[rune]string)
+	for _, g := range transliterationGroups {
+		for _, r := range g.chars {
+			out[r] = g.ascii
+		}
+	}
+	return out
+}()
+
+// transliterationGroups spell each of chars as ascii. The Latin groups
+// follow the canonical decompositions of the Latin-1 Supplement, Latin
+// Extended-A and -B, and Latin Extended Additional blocks, plus letters
+// such as ß and ł that do not decompose.
+var transliterationGroups = []struct {
+	chars string
+	ascii string
+}{
+	{"ÀÁÂÃÄÅĀĂĄǍǞǠǺȀȂȦḀẠẢẤẦẨẪẬẮẰẲẴẶ", "A"},
+	{"Æ", "AE"},
+	{"ÇĆĈĊČḈ", "C"},
+	{"ÈÉÊËĒĔĖĘĚȄȆȨḔḖḘḚḜẸẺẼẾỀỂỄỆ", "E"},
+	{"ÌÍÎÏĨĪĬĮİǏȈȊḬḮỈỊ", "I"},
+	{"ÐĎĐƉḊḌḎḐḒ", "D"},
+	{"ÑŃŅŇŊǸṄṆṈṊ", "N"},
+	{"ÒÓÔÕÖØŌŎŐƠǑǪǬȌȎȪȬȮȰṌṎṐṒỌỎỐỒỔỖỘỚỜỞỠỢ", "O"},
+	{"ÙÚÛÜŨŪŬŮŰŲƯǓǕǗǙǛȔȖṲṴṶṸṺỤỦỨỪỬỮỰ", "U"},
+	{"ÝŶŸȲẎỲỴỶỸ", "Y"},
+	{"Þ", "Th"},
+	{"ß", "ss"},
+	{"àáâãäåāăąǎǟǡǻȁȃȧḁạảấầẩẫậắằẳẵặ", "a"},
+	{"æ", "ae"},
+	{"çćĉċčḉ", "c"},
+	{"èéêëēĕėęěȅȇȩḕḗḙḛḝẹẻẽếềểễệ", "e"},
+	{"ìíîïĩīĭįıǐȉȋḭḯỉị", "i"},
+	{"ðďđḋḍḏḑḓ", "d"},
+	{"ñńņňŋǹṅṇṉṋ", "n"},
+	{"òóôõöøōŏőơǒǫǭȍȏȫȭȯȱṍṏṑṓọỏốồổỗộớờởỡợ", "o"},
+	{"ùúûüũūŭůűųưǔǖǘǚǜȕȗṳṵṷṹṻụủứừửữự", "u"},
+	{"ýÿŷȳẏẙỳỵỷỹ", "y"},
+	{"þ", "th"},
+	{"ĜĞĠĢǦǴḠ", "G"},
+	{"ĝğġģǧǵḡ", "g"},
+	{"ĤĦȞḢḤḦḨḪ", "H"},
+	{"ĥħȟḣḥḧḩḫẖ", "h"},
+	{"Ĳ", "IJ"},
+	{"ĳ", "ij"},
+	{"Ĵ", "J"},
+	{"ĵǰ", "j"},
+	{"ĶǨḰḲḴ", "K"},
+	{"ķǩḱḳḵ", "k"},
+	{"ĸ", "q"},
+	{"ĹĻĽĿŁḶḸḺḼ", "L"},
+	{"ĺļľŀłḷḹḻḽ", "l"},
+	{"Œ", "OE"},
+	{"œ", "oe"},
+	{"ŔŖŘȐȒṘṚṜṞ", "R"},
+	{"ŕŗřȑȓṙṛṝṟ", "r"},
+	{"ŚŜŞŠȘṠṢṤṦṨ", "S"},
+	{"śŝşšſșṡṣṥṧṩẛ", "s"},
+	{"ŢŤŦȚṪṬṮṰ", "T"},
+	{"ţťŧțṫṭṯṱẗ", "t"},
+	{"ŴẀẂẄẆẈ", "W"},
+	{"ŵẁẃẅẇẉẘ", "w"},
+	{"ŹŻŽẐẒẔ", "Z"},
+	{"źżžẑẓẕ", "z"},
+	{"ƒḟ", "f"},
+	{"ǄǱ", "DZ"},
+	{"ǅǲ", "Dz"},
+	{"ǆǳ", "dz"},
+	{"Ǉ", "LJ"},
+	{"ǈ", "Lj"},
+	{"ǉ", "lj"},
+	{"Ǌ", "NJ"},
+	{"ǋ", "Nj"},
+	{"ǌ", "nj"},
+	{"ḂḄḆ", "B"},
+	{"ḃḅḇ", "b"},
+	{"Ḟ", "F"},
+	{"ḾṀṂ", "M"},
+	{"ḿṁṃ", "m"},
+	{"ṔṖ", "P"},
+	{"ṕṗ", "p"},
+	{"ṼṾ", "V"},
+	{"ṽṿ", "v"},
+	{"ẊẌ", "X"},
+	{"ẋẍ", "x"},
+	{"ẞ", "SS"},
+	// Cyrillic, following common romanization.
+	{"А", "A"},
+	{"а", "a"},
+	{"Б", "B"},
+	{"б", "b"},
+	{"В", "V"},
+	{"в", "v"},
+	{"ГҐ", "G"},
+	{"гґ", "g"},
+	{"Д", "D"},
+	{"д", "d"},
+	{"ЕЭ", "E"},
+	{"еэ", "e"},
+	{"Є", "Ye"},
+	{"є", "ye"},
+	{"Ё", "Yo"},
+	{"ё", "yo"},
+	{"Ж", "Zh"},
+	{"ж", "zh"},
+	{"З", "Z"},
+	{"з", "z"},
+	{"ИІ", "I"},
+	{"иі", "i"},
+	{"Ї", "Yi"},
+	{"ї", "yi"},
+	{"ЙЫ", "Y"},
+	{"йы", "y"},
+	{"К", "K"},
+	{"к", "k"},
+	{"Л", "L"},
+	{"л", "l"},
+	{"М", "M"},
+	{"м", "m"},
+	{"Н", "N"},
+	{"н", "n"},
+	{"О", "O"},
+	{"о", "o"},
+	{"П", "P"},
+	{"п", "p"},
+	{"Р", "R"},
+	{"р", "r"},
+	{"С", "S"},
+	{"с", "s"},
+	{"Т", "T"},
+	{"т", "t"},
+	{"УЎ", "U"},
+	{"уў", "u"},
+	{"Ф", "F"},
+	{"ф", "f"},
+	{"Х", "Kh"},
+	{"х", "kh"},
+	{"Ц", "Ts"},
+	{"ц", "ts"},
+	{"Ч", "Ch"},
+	{"ч", "ch"},
+	{"Ш", "Sh"},
+	{"ш", "sh"},
+	{"Щ", "Shch"},
+	{"щ", "shch"},
+	{"Ю", "Yu"},
+	{"ю", "yu"},
+	{"Я", "Ya"},
+	{"я", "ya"},
+	{"ЪъЬь", ""},
+	// Greek, with accented letters spelled like their base letters.
+	{"ΆΑ", "A"},
+	{"ΈΕ", "E"},
+	{"ΉΊΗΙΪ", "I"},
+	{"ΌΏΟΩ", "O"},
+	{"ΎΥΫ", "Y"},
+	{"ΐήίηιϊ", "i"},
+	{"Β", "V"},
+	{"Γ", "G"},
+	{"Δ", "D"},
+	{"Ζ", "Z"},
+	{"Θ", "Th"},
+	{"Κ", "K"},
+	{"Λ", "L"},
+	{"Μ", "M"},
+	{"Ν", "N"},
+	{"Ξ", "X"},
+	{"Π", "P"},
+	{"Ρ", "R"},
+	{"Σ", "S"},
+	{"Τ", "T"},
+	{"Φ", "F"},
+	{"Χ", "Ch"},
+	{"Ψ", "Ps"},
+	{"άα", "a"},
+	{"έε", "e"},
+	{"ΰυϋύ", "y"},
+	{"β", "v"},
+	{"γ", "g"},
+	{"δ", "d"},
+	{"ζ", "z"},
+	{"θ", "th"},
+	{"κ", "k"},
+	{"λ", "l"},
+	{"μ", "m"},
+	{"ν", "n"},
+	{"ξ", "x"},
+	{"οωόώ", "o"},
+	{"π", "p"},
+	{"ρ", "r"},
+	{"ςσ", "s"},
+	{"τ", "t"},
+	{"φ", "f"},
+	{"χ", "ch"},
+	{"ψ", "ps"},
+	// Typographic punctuation and symbols.
+	{"‘’‚‛′", "'"},
+	{"“”„‟″", "\""},
+	{"‐‑‒–−", "-"},
+	{"—―", "--"},
+	{"…", "..."},
+	{"«", "<<"},
+	{"»", ">>"},
+	{"‹", "<"},
+	{"›", ">"},
+	{"•·", "*"},
+	{"×", "x"},
+	{"÷", "/"},
+	{"±", "+/-"},
+	{"←", "<-"},
+	{"→", "->"},
+	{"↔", "<->"},
+	{"⇐≤", "<="},
+	{"⇒", "=>"},
+	{"≥", ">="},
+	{"≠", "!="},
+	{"©", "(c)"},
+	{"®", "(R)"},
+	{"™", "(TM)"},
+	{"¡", "!"},
+	{"¿", "?"},
+}
@@ -0,0 +1,265 @@
+// Package git runs read-only git plumbing commands.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is the git executable; tests may override it.
+var command = "git"
+
+// Run executes git with args in dir and returns trimmed stdout.
+func Run(dir string, args ...string) (string, error) {
+	out, err := output(dir, args...)
+	return strings.TrimSpace(out), err
+}
+
+// output executes git with args in dir and returns its stdout unchanged.
+func output(dir string, args ...string) (string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+	return stdout.String(), nil
+}
+
+// HeadSHA returns the commit checked out in dir.
+func HeadSHA(dir string) (string, error) {
+	return Run(dir, "rev-parse", "HEAD")
+}
+
+// Root returns the top-level directory of the repository containing dir.
+func Root(dir string) (string, error) {
+	return Run(dir, "rev-parse", "--show-toplevel")
+}
+
+// Changed lists the files of the repository containing dir that differ from
+// HEAD in the index or the working tree, plus untracked files that are not
+// ignored. Paths are relative to the repository root; deleted files are
+// left out.
+func Changed(dir string) ([]string, error) {
+	out, err := output(dir, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	records := strings.Split(out, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		status, path := record[:2], record[3:]
+		// Renames and copies are followed by their source path.
+		if strings.ContainsAny(status, "RC") {
+			i++
+		}
+		if strings.Contains(status, "D") {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// LineRange is an inclusive range of 1-based line numbers.
+type LineRange struct {
+	Start, End int
+}
+
+// Contains reports whether line is in r.
+func (r LineRange) Contains(line int) bool {
+	return line >= r.Start && line <= r.End
+}
+
+// wholeFile covers every line of an untracked file.
+var wholeFile = LineRange{Start: 1, End: math.MaxInt}
+
+// ChangedSince lists the files of the repository containing dir whose
+// working tree content differs from the merge base of rev and HEAD, with
+// the ranges of lines they added or modified, plus untracked files that are
+// not ignored, whose every line counts as added. Paths are relative to the
+// repository root; deleted files and files that only lost lines are left
+// out.
+func ChangedSince(dir, rev string) (map[string][]LineRange, error) {
+	root, err := Root(dir)
+	if err != nil {
+		return nil, err
+	}
+	base, err := Run(root, "merge-base", rev, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	out, err := output(root, "-c", "core.quotePath=false", "diff", "-U0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "--diff-filter=d", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	changed, err := parseAddedLines(out)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := output(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path != "" {
+			changed[path] = []LineRange{wholeFile}
+		}
+	}
+	return changed, nil
+}
+
+// parseAddedLines returns the ranges of lines each file gains in the
+// zero-context unified diff out.
+func parseAddedLines(out string) (map[string][]LineRange, error) {
+	changed := make(map[string][]LineRange)
+	path := ""
+	// Added lines also start with +, so +++ names a file only in the
+	// header before a file's first hunk.
+	header := false
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header = true
+			path = ""
+		case header && strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if strings.HasPrefix(name, `"`) {
+				unquoted, err := strconv.Unquote(name)
+				if err != nil {
+					return nil, fmt.Errorf("parse diff path %s: %w", name, err)
+				}
+				name = unquoted
+			}
+			path = strings.TrimPrefix(name, "b/")
+			if name == "/dev/null" {
+				path = ""
+			}
+		case strings.HasPrefix(line, "@@ ") && path != "":
+			header = false
+			// @@ -old[,n] +new[,n] @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("parse diff hunk %q", line)
+			}
+			startText, countText, hasCount := strings.Cut(fields[2][1:], ",")
+			start, err := strconv.Atoi(startText)
+			if err != nil {
+				return nil, fmt.Errorf("parse diff hunk %q: %w", line, err)
+			}
+			count := 1
+			if hasCount {
+				if count, err = strconv.Atoi(countText); err != nil {
+					return nil, fmt.Errorf("parse diff hunk %q: %w", line, err)
+				}
+			}
+			if count > 0 {
+				changed[path] = append(changed[path], LineRange{Start: start, End: start + count - 1})
+			}
+		}
+	}
+	return changed, nil
+}
+
+// TreeEntry is a blob listed by ls-tree.
+type TreeEntry struct {
+	Path string
+	Blob string
+}
+
+// CommitBefore returns the last first-parent commit on rev made before t,
+// or "" when there is none.
+func CommitBefore(dir, rev string, t time.Time) (string, error) {
+	return Run(dir, "rev-list", "-1", "--first-parent", "--before="+t.UTC().Format(time.RFC3339), rev)
+}
+
+// Files lists the regular files in the tree of commit.
+func Files(dir, commit string) ([]TreeEntry, error) {
+	out, err := Run(dir, "ls-tree", "-r", "-z", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+	var entries []TreeEntry
+	for _, record := range strings.Split(out, "\x00") {
+		meta, path, ok := strings.Cut(record, "\t")
+		fields := strings.Fields(meta)
+		// Symlinks (120000) and submodules (160000) have no content to scan.
+		if !ok || len(fields) != 3 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		entries = append(entries, TreeEntry{Path: path, Blob: fields[2]})
+	}
+	return entries, nil
+}
+
+// ReadBlobs streams the content of each blob to fn using a single
+// `git cat-file --batch` process.
+func ReadBlobs(dir string, blobs []string, fn func(blob string, data []byte) error) error {
+	if len(blobs) == 0 {
+		return nil
+	}
+	cmd := exec.Command(command, "cat-file", "--batch")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(blobs, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git cat-file: %w", err)
+	}
+
+	readErr := func() error {
+		in := bufio.NewReader(stdout)
+		for _, blob := range blobs {
+			header, err := in.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("git cat-file: %w", err)
+			}
+			fields := strings.Fields(header)
+			if len(fields) != 3 {
+				return fmt.Errorf("git cat-file: %s", strings.TrimSpace(header))
+			}
+			size, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return fmt.Errorf("git cat-file: invalid header %q", strings.TrimSpace(header))
+			}
+			data := make([]byte, size+1)
+			if _, err := io.ReadFull(in, data); err != nil {
+				return fmt.Errorf("git cat-file: %w", err)
+			}
+			if err := fn(blob, data[:size]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if readErr != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return readErr
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git cat-file: %s", strings.TrimSpace(stderr.String()+" "+err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath(command); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if _, err := Run(dir, args...); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestHeadSHA(t *testing.T) {
+	dir := initRepo(t)
+	sha, err := HeadSHA(dir)
+	if err != nil || len(sha) != 40 {
+		t.Fatalf("unexpected HEAD sha %q: %v", sha, err)
+	}
+	if _, err := HeadSHA(t.TempDir()); err == nil || !strings.Contains(err.Error(), "git rev-parse HEAD") {
+		t.Fatalf("expected error outside a repository, got %v", err)
+	}
+
+	orig := command
+	defer func() { command = orig }()
+	command = "englint-missing-git"
+	if _, err := HeadSHA(dir); err == nil {
+		t.Fatalf("expected missing executable error")
+	}
+}
+
+func TestRoot(t *testing.T) {
+	dir := initRepo(t)
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	root, err := Root(sub)
+	if err != nil {
+		t.Fatalf("Root: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(root); got != want {
+		t.Fatalf("Root = %q, want %q", got, want)
+	}
+	if _, err := Root(t.TempDir()); err == nil {
+		t.Fatalf("expected error outside a repository")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	dir := initRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "old.txt"} {
+		write(name, name+"\n")
+	}
+	if _, err := Run(dir, "add", "-A"); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if _, err := Run(dir, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "files"); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	write("a.txt", "changed\n")
+	write("n.txt", "staged\n")
+	write("dir/u.txt", "untracked\n")
+	write(".gitignore", "ignored.txt\n")
+	write("ignored.txt", "ignored\n")
+	for _, args := range [][]string{{"add", "n.txt"}, {"rm", "-q", "b.txt"}, {"mv", "old.txt", "new.txt"}} {
+		if _, err := Run(dir, args...); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	got, err := Changed(filepath.Join(dir, "dir"))
+	if err != nil {
+		t.Fatalf("Changed: %v", err)
+	}
+	sort.Strings(got)
+	want := []string{".gitignore", "a.txt", "dir/u.txt", "n.txt", "new.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Changed = %q, want %q", got, want)
+	}
+	if _, err := Changed(t.TempDir()); err == nil {
+		t.Fatalf("expected error outside a repository")
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	dir := initRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	commit := func() {
+		t.Helper()
+		for _, args := range [][]string{{"add", "-A"}, {"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "c"}} {
+			if _, err := Run(dir, args...); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+		}
+	}
+	write("a.txt", "1\n2\n3\n4\n5\n")
+	write("shrunk.txt", "1\n2\n")
+	write("gone.txt", "x\n")
+	commit()
+	base, err := HeadSHA(dir)
+	if err != nil {
+		t.Fatalf("HeadSHA: %v", err)
+	}
+	write("a.txt", "1\nTWO\n3\n4\n5\n++ six\n7\n")
+	write("shrunk.txt", "1\n")
+	write("ünï.txt", "new\n")
+	commit()
+	if _, err := Run(dir, "rm", "-q", "gone.txt"); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	write("dir/untracked.txt", "u\n")
+	write(".gitignore", "ignored.txt\n")
+	write("ignored.txt", "i\n")
+
+	want := map[string][]LineRange{
+		"a.txt":             {{Start: 2, End: 2}, {Start: 6, End: 7}},
+		"ünï.txt":           {{Start: 1, End: 1}},
+		"dir/untracked.txt": {wholeFile},
+		".gitignore":        {wholeFile},
+	}
+	// Diff prefix settings in the user's config must not change the paths.
+	for _, setting := range []string{"", "diff.mnemonicPrefix", "diff.noprefix"} {
+		if setting != "" {
+			if _, err := Run(dir, "config", setting, "true"); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+		}
+		got, err := ChangedSince(filepath.Join(dir, "dir"), base)
+		if err != nil {
+			t.Fatalf("ChangedSince: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("ChangedSince with %q = %v, want %v", setting, got, want)
+		}
+	}
+	if !wholeFile.Contains(1000) || (LineRange{Start: 2, End: 3}).Contains(4) {
+		t.Fatalf("unexpected LineRange.Contains")
+	}
+	if _, err := ChangedSince(dir, "no-such-branch"); err == nil {
+		t.Fatalf("expected error for an unknown revision")
+	}
+}
+
+func TestParseAddedLines(t *testing.T) {
+	out := "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n@@ -5,0 +6,2 @@\n+c\n+d\n" +
+		"diff --git \"a/t\\tab\" \"b/t\\tab\"\n--- \"a/t\\tab\"\n+++ \"b/t\\tab\"\n@@ -1,2 +0,0 @@\n-a\n-b\n@@ -4 +2,3 @@\n-x\n+y\n+z\n+w\n"
+	got, err := parseAddedLines(out)
+	if err != nil {
+		t.Fatalf("parseAddedLines: %v", err)
+	}
+	want := map[string][]LineRange{"x": {{Start: 1, End: 1}, {Start: 6, End: 7}}, "t\tab": {{Start: 2, End: 4}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseAddedLines = %v, want %v", got, want)
+	}
+	if _, err := parseAddedLines("diff --git a/x b/x\n+++ b/x\n@@ -1 +x @@\n"); err == nil {
+		t.Fatalf("expected hunk error")
+	}
+}
+
+func TestFilesAndReadBlobs(t *testing.T) {
+	dir := initRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("alpha\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "files"}} {
+		if _, err := Run(dir, args...); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	entries, err := Files(dir, "HEAD")
+	if err != nil || len(entries) != 1 || entries[0].Path != "a.txt" {
+		t.Fatalf("unexpected entries: %+v (%v)", entries, err)
+	}
+	var got []string
+	err = ReadBlobs(dir, []string{entries[0].Blob, entries[0].Blob}, func(blob string, data []byte) error {
+		got = append(got, string(data))
+		return nil
+	})
+	if err != nil || len(got) != 2 || got[0] != "alpha\n" {
+		t.Fatalf("unexpected blobs: %q (%v)", got, err)
+	}
+	if err := ReadBlobs(dir, nil, nil); err != nil {
+		t.Fatalf("expected no-op for empty blob list: %v", err)
+	}
+
+	if err := ReadBlobs(dir, []string{strings.Repeat("0", 40)}, func(string, []byte) error { return nil }); err == nil {
+		t.Fatalf("expected missing blob error")
+	}
+	stop := errors.New("stop")
+	if err := ReadBlobs(dir, []string{entries[0].Blob}, func(string, []byte) error { return stop }); !errors.Is(err, stop) {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if _, err := Files(dir, "no-such-rev"); err == nil {
+		t.Fatalf("expected unknown revision error")
+	}
+
+	commit, err := CommitBefore(dir, "HEAD", time.Now().Add(time.Hour))
+	if err != nil || len(commit) != 40 {
+		t.Fatalf("unexpected commit: %q (%v)", commit, err)
+	}
+	if commit, err := CommitBefore(dir, "HEAD", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil || commit != "" {
+		t.Fatalf("expected no commit before 2000, got %q (%v)", commit, err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package i18n translates englint's own human-readable messages. Findings,
+// JSON output, and flag descriptions stay in English.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Lang is a two-letter ISO 639-1 language code.
+type Lang string
+
+// English is the default language and the fallback for missing messages.
+const English Lang = "en"
+
+// Key identifies a translatable message.
+type Key int
+
+const (
+	// NoFindings is printed when a scan finds nothing.
+	NoFindings Key = iota
+	// Summary formats the files scanned, files skipped, and findings.
+	Summary
+	// Omitted formats the findings cut off by max_findings_per_file and is
+	// appended to Summary.
+	Omitted
+	// Baselined formats the findings a baseline suppressed and is appended
+	// to Summary.
+	Baselined
+	// FixSuggestion is printed for --fix when findings remain after fixing.
+	FixSuggestion
+	// Tagline is the first line of the usage text.
+	Tagline
+	// Usage heads the list of commands.
+	Usage
+	// GlobalFlags heads the list of flags accepted by every command.
+	GlobalFlags
+	// ScanFlags heads the list of scan flags.
+	ScanFlags
+)
+
+var catalog = map[Lang]map[Key]string{
+	English: {
+		NoFindings:    "No non-English text found.",
+		Summary:       "Summary: scanned=%d skipped=%d findings=%d",
+		Omitted:       " omitted=%d",
+		Baselined:     " baselined=%d",
+		FixSuggestion: "Some findings have no automatic fix. Replace those characters manually, set fix_mode in .englint.yaml, or add safe symbols to its allow list.",
+		Tagline:       "englint - detect non-English text in source files",
+		Usage:         "Usage:",
+		GlobalFlags:   "Global flags:",
+		ScanFlags:     "Scan flags:",
+	},
+	"de": {
+		NoFindings:    "Kein nicht-englischer Text gefunden.",
+		Summary:       "Zusammenfassung: geprüft=%d übersprungen=%d Funde=%d",
+		Omitted:       " ausgelassen=%d",
+		Baselined:     " in Baseline=%d",
+		FixSuggestion: "Einige Funde lassen sich nicht automatisch korrigieren. Ersetzen Sie diese Zeichen manuell, setzen Sie fix_mode in .englint.yaml oder fügen Sie unbedenkliche Symbole zu deren allow-Liste hinzu.",
+		Tagline:       "englint - findet nicht-englischen Text in Quelldateien",
+		Usage:         "Verwendung:",
+		GlobalFlags:   "Globale Optionen:",
+		ScanFlags:     "Optionen für scan:",
+	},
+	"es": {
+		NoFindings:    "No se encontró texto en idiomas distintos del inglés.",
+		Summary:       "Resumen: analizados=%d omitidos=%d hallazgos=%d",
+		Omitted:       " no mostrados=%d",
+		Baselined:     " en línea base=%d",
+		FixSuggestion: "Algunos hallazgos no tienen corrección automática. Reemplace esos caracteres manualmente, defina fix_mode en .englint.yaml o añada los símbolos seguros a su lista allow.",
+		Tagline:       "englint - detecta texto no inglés en archivos de código fuente",
+		Usage:         "Uso:",
+		GlobalFlags:   "Opciones globales:",
+		ScanFlags:     "Opciones de scan:",
+	},
+	"fr": {
+		NoFindings:    "Aucun texte non anglais trouvé.",
+		Summary:       "Résumé : analysés=%d ignorés=%d résultats=%d",
+		Omitted:       " masqués=%d",
+		Baselined:     " en référence=%d",
+		FixSuggestion: "Certains résultats n'ont pas de correction automatique. Remplacez ces caractères manuellement, définissez fix_mode dans .englint.yaml ou ajoutez les symboles sûrs à sa liste allow.",
+		Tagline:       "englint - détecte le texte non anglais dans les fichiers source",
+		Usage:         "Utilisation :",
+		GlobalFlags:   "Options globales :",
+		ScanFlags:     "Options de scan :",
+	},
+	"ja": {
+		NoFindings:    "英語以外のテキストは見つかりませんでした。",
+		Summary:       "概要: スキャン=%d スキップ=%d 検出=%d",
+		Omitted:       " 省略=%d",
+		Baselined:     " ベースライン=%d",
+		FixSuggestion: "自動修正できない検出があります。該当する文字を手動で置き換えるか、.englint.yaml で fix_mode を設定するか、安全な記号を allow リストに追加してください。",
+		Tagline:       "englint - ソースファイル内の英語以外のテキストを検出します",
+		Usage:         "使い方:",
+		GlobalFlags:   "共通オプション:",
+		ScanFlags:     "scan のオプション:",
+	},
+	"ko": {
+		NoFindings:    "영어가 아닌 텍스트가 발견되지 않았습니다.",
+		Summary:       "요약: 검사=%d 건너뜀=%d 발견=%d",
+		Omitted:       " 생략=%d",
+		Baselined:     " 기준선=%d",
+		FixSuggestion: "자동으로 수정할 수 없는 항목이 있습니다. 해당 문자를 직접 바꾸거나, .englint.yaml에서 fix_mode를 설정하거나, 안전한 기호를 allow 목록에 추가하세요.",
+		Tagline:       "englint - 소스 파일에서 영어가 아닌 텍스트를 검출합니다",
+		Usage:         "사용법:",
+		GlobalFlags:   "공통 옵션:",
+		ScanFlags:     "scan 옵션:",
+	},
+	"pt": {
+		NoFindings:    "Nenhum texto em idioma diferente do inglês foi encontrado.",
+		Summary:       "Resumo: analisados=%d ignorados=%d ocorrências=%d",
+		Omitted:       " omitidas=%d",
+		Baselined:     " na linha de base=%d",
+		FixSuggestion: "Algumas ocorrências não têm correção automática. Substitua esses caracteres manualmente, defina fix_mode em .englint.yaml ou adicione símbolos seguros à lista allow.",
+		Tagline:       "englint - detecta texto que não está em inglês em arquivos de código-fonte",
+		Usage:         "Uso:",
+		GlobalFlags:   "Opções globais:",
+		ScanFlags:     "Opções de scan:",
+	},
+	"zh": {
+		NoFindings:    "未发现非英文文本。",
+		Summary:       "摘要: 已扫描=%d 已跳过=%d 发现=%d",
+		Omitted:       " 已省略=%d",
+		Baselined:     " 基线=%d",
+		FixSuggestion: "部分结果无法自动修复。请手动替换这些字符、在 .englint.yaml 中设置 fix_mode，或将安全的符号添加到 allow 列表中。",
+		Tagline:       "englint - 检测源文件中的非英文文本",
+		Usage:         "用法:",
+		GlobalFlags:   "全局选项:",
+		ScanFlags:     "scan 选项:",
+	},
+}
+
+// Supported lists the available languages in alphabetical order.
+func Supported() []string {
+	out := make([]string, 0, len(catalog))
+	for lang := range catalog {
+		out = append(out, string(lang))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Parse accepts a language code such as "ja" or a locale such as
+// "pt_BR.UTF-8" and returns its language if a catalog exists for it.
+func Parse(value string) (Lang, bool) {
+	code := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := catalog[Lang(code)]; !ok {
+		return English, false
+	}
+	return Lang(code), true
+}
+
+// Resolve picks the language from the --lang flag value, falling back to
+// the LC_ALL, LC_MESSAGES, and LANG environment variables in that order, as
+// POSIX does. An unsupported flag value is an error; unsupported or "C"
+// locales from the environment select English.
+func Resolve(flag string, getenv func(string) string) (Lang, error) {
+	if strings.TrimSpace(flag) != "" {
+		lang, ok := Parse(flag)
+		if !ok {
+			return English, fmt.Errorf("unsupported language %q (supported: %s)", flag, strings.Join(Supported(), ", "))
+		}
+		return lang, nil
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(name); value != "" {
+			lang, _ := Parse(value)
+			return lang, nil
+		}
+	}
+	return English, nil
+}
+
+// T returns the message for key in l, or in English if l has none.
+func (l Lang) T(key Key) string {
+	if msg, ok := catalog[l][key]; ok {
+		return msg
+	}
+	return catalog[English][key]
+}
@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     map[string]string
+		want    Lang
+		wantErr bool
+	}{
+		{name: "default", want: English},
+		{name: "flag", flag: "ja", env: map[string]string{"LANG": "de_DE.UTF-8"}, want: "ja"},
+		{name: "flag locale", flag: "pt_BR.UTF-8", want: "pt"},
+		{name: "flag unsupported", flag: "xx", wantErr: true},
+		{name: "LANG", env: map[string]string{"LANG": "fr_FR.UTF-8"}, want: "fr"},
+		{name: "LC_MESSAGES over LANG", env: map[string]string{"LC_MESSAGES": "ko_KR", "LANG": "fr_FR"}, want: "ko"},
+		{name: "LC_ALL over all", env: map[string]string{"LC_ALL": "zh-CN", "LC_MESSAGES": "ko_KR", "LANG": "fr_FR"}, want: "zh"},
+		{name: "C locale", env: map[string]string{"LANG": "C.UTF-8"}, want: English},
+		{name: "unsupported env", env: map[string]string{"LC_ALL": "xx_XX", "LANG": "ja_JP"}, want: English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.flag, func(name string) string { return tt.env[name] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for lang, messages := range catalog {
+		for key, english := range catalog[English] {
+			msg, ok := messages[key]
+			if !ok {
+				t.Errorf("%s: missing message %d", lang, key)
+				continue
+			}
+			if strings.Count(msg, "%d") != strings.Count(english, "%d") {
+				t.Errorf("%s: message %d has different verbs than English: %q", lang, key, msg)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := Lang("ja").T(Summary); fmt.Sprintf(got, 1, 2, 3) != "概要: スキャン=1 スキップ=2 検出=3" {
+		t.Fatalf("unexpected Japanese summary %q", got)
+	}
+	if got := Lang("xx").T(NoFindings); got != "No non-English text found." {
+		t.Fatalf("expected English fallback, got %q", got)
+	}
+}
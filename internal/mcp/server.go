@@ -0,0 +1,298 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// ProtocolVersion is the Model Context Protocol revision implemented here.
+const ProtocolVersion = "2024-11-05"
+
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Handlers perform the operations exposed as MCP tools.
+type Handlers struct {
+	Scan    func(paths []string) (scanner.Result, error)
+	Explain func(path string) (scanner.Explanation, error)
+	Allow   func(values []string) ([]string, error)
+	// Fix fixes the findings in paths and returns a report of the changes,
+	// or with dryRun the changes as a diff without writing any file.
+	Fix func(paths []string, dryRun bool) (string, error)
+}
+
+// Server answers MCP requests over a newline-delimited JSON-RPC stream.
+type Server struct {
+	Name     string
+	Version  string
+	Handlers Handlers
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// Serve reads requests from in until EOF and writes responses to out.
+func (s Server) Serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			if resp, ok := s.handle(line); ok {
+				if encErr := enc.Encode(resp); encErr != nil {
+					return encErr
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (s Server) handle(raw []byte) (response, bool) {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, "parse error: "+err.Error()), true
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(idOrNull(req.ID), codeInvalidRequest, "invalid request"), true
+	}
+	// Requests without an id are notifications and never get a response.
+	if len(req.ID) == 0 {
+		return response{}, false
+	}
+
+	switch req.Method {
+	case "initialize":
+		return resultResponse(req.ID, map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+		}), true
+	case "ping":
+		return resultResponse(req.ID, map[string]interface{}{}), true
+	case "tools/list":
+		return resultResponse(req.ID, map[string]interface{}{"tools": tools()}), true
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorResponse(req.ID, codeInvalidParams, "invalid params: "+err.Error()), true
+		}
+		result, err := s.callTool(params.Name, params.Arguments)
+		if err != nil {
+			var unknown unknownToolError
+			if errors.As(err, &unknown) {
+				return errorResponse(req.ID, codeInvalidParams, err.Error()), true
+			}
+			return resultResponse(req.ID, toolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}), true
+		}
+		return resultResponse(req.ID, result), true
+	default:
+		return errorResponse(req.ID, codeMethodNotFound, "method not found: "+req.Method), true
+	}
+}
+
+type unknownToolError string
+
+func (e unknownToolError) Error() string {
+	return "unknown tool: " + string(e)
+}
+
+func (s Server) callTool(name string, rawArgs json.RawMessage) (toolResult, error) {
+	var args struct {
+		Paths  []string `json:"paths"`
+		Path   string   `json:"path"`
+		Values []string `json:"values"`
+		DryRun *bool    `json:"dry_run"`
+	}
+	if len(rawArgs) > 0 {
+		if err := json.Unmarshal(rawArgs, &args); err != nil {
+			return toolResult{}, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	var payload interface{}
+	switch name {
+	case "scan":
+		if s.Handlers.Scan == nil {
+			return toolResult{}, unknownToolError(name)
+		}
+		result, err := s.Handlers.Scan(args.Paths)
+		if err != nil {
+			return toolResult{}, err
+		}
+		payload = result
+	case "explain":
+		if s.Handlers.Explain == nil {
+			return toolResult{}, unknownToolError(name)
+		}
+		if strings.TrimSpace(args.Path) == "" {
+			return toolResult{}, errors.New("explain requires a path argument")
+		}
+		explanation, err := s.Handlers.Explain(args.Path)
+		if err != nil {
+			return toolResult{}, err
+		}
+		payload = explanation
+	case "allow":
+		if s.Handlers.Allow == nil {
+			return toolResult{}, unknownToolError(name)
+		}
+		if len(args.Values) == 0 {
+			return toolResult{}, errors.New("allow requires a non-empty values argument")
+		}
+		allow, err := s.Handlers.Allow(args.Values)
+		if err != nil {
+			return toolResult{}, err
+		}
+		payload = map[string][]string{"allow": allow}
+	case "fix":
+		if s.Handlers.Fix == nil {
+			return toolResult{}, unknownToolError(name)
+		}
+		// Rewriting files is opt-in: without dry_run, the changes are
+		// only shown.
+		dryRun := args.DryRun == nil || *args.DryRun
+		changes, err := s.Handlers.Fix(args.Paths, dryRun)
+		if err != nil {
+			return toolResult{}, err
+		}
+		payload = map[string]interface{}{"dry_run": dryRun, "changes": changes}
+	default:
+		return toolResult{}, unknownToolError(name)
+	}
+
+	text, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return toolResult{}, err
+	}
+	return toolResult{Content: []textContent{{Type: "text", Text: string(text)}}}, nil
+}
+
+func tools() []tool {
+	return []tool{
+		{
+			Name:        "scan",
+			Description: "Scan files or directories for non-English text and return findings as JSON.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]string{"type": "string"},
+						"description": "Paths to scan (default: current directory).",
+					},
+				},
+			},
+		},
+		{
+			Name:        "explain",
+			Description: "Explain which include, exclude, or allow_file_patterns rule scans or skips a file.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]string{"type": "string", "description": "File or directory to explain."},
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        "allow",
+			Description: "Add characters to the allow list in the englint config file.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"values": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]string{"type": "string"},
+						"description": "Characters to allow.",
+					},
+				},
+				"required": []string{"values"},
+			},
+		},
+		{
+			Name:        "fix",
+			Description: "Replace findings that have a suggested fix and repair invalid UTF-8. Returns the changes as a diff unless dry_run is false.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"paths": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]string{"type": "string"},
+						"description": "Paths to fix (default: current directory).",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only return the changes as a diff (default: true).",
+					},
+				},
+			},
+		},
+	}
+}
+
+func resultResponse(id json.RawMessage, result interface{}) response {
+	return response{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
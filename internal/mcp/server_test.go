@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func serve(t *testing.T, s Server, lines ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(strings.NewReader(strings.Join(lines, "\n")), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+	var responses []map[string]interface{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]interface{}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func toolText(t *testing.T, resp map[string]interface{}) (string, bool) {
+	t.Helper()
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected result, got %v", resp)
+	}
+	content := result["content"].([]interface{})
+	isError, _ := result["isError"].(bool)
+	return content[0].(map[string]interface{})["text"].(string), isError
+}
+
+func TestServeProtocol(t *testing.T) {
+	s := Server{Name: "englint", Version: "test", Handlers: Handlers{
+		Scan:    func([]string) (scanner.Result, error) { return scanner.Result{}, nil },
+		Explain: func(string) (scanner.Explanation, error) { return scanner.Explanation{}, nil },
+	}}
+	responses := serve(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/list"}`,
+		`not json`,
+		`{"id":5,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"allow","arguments":{"values":["é"]}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":[]}`,
+	)
+	if len(responses) != 8 {
+		t.Fatalf("expected 8 responses, got %d: %v", len(responses), responses)
+	}
+
+	initResult := responses[0]["result"].(map[string]interface{})
+	if initResult["protocolVersion"] != ProtocolVersion {
+		t.Fatalf("unexpected protocol version: %v", initResult)
+	}
+	if info := initResult["serverInfo"].(map[string]interface{}); info["name"] != "englint" || info["version"] != "test" {
+		t.Fatalf("unexpected server info: %v", info)
+	}
+
+	tools := responses[1]["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 4 {
+		t.Fatalf("expected 4 tools, got %v", tools)
+	}
+
+	wantErrors := []struct {
+		index int
+		code  float64
+	}{
+		{3, codeMethodNotFound},
+		{4, codeParseError},
+		{5, codeInvalidRequest},
+		{6, codeInvalidParams},
+		{7, codeInvalidParams},
+	}
+	for _, want := range wantErrors {
+		rpcErr, ok := responses[want.index]["error"].(map[string]interface{})
+		if !ok || rpcErr["code"] != want.code {
+			t.Fatalf("response %d: expected error code %v, got %v", want.index, want.code, responses[want.index])
+		}
+	}
+}
+
+func TestServeToolCalls(t *testing.T) {
+	var scannedPaths []string
+	s := Server{Handlers: Handlers{
+		Scan: func(paths []string) (scanner.Result, error) {
+			scannedPaths = paths
+			return scanner.Result{Summary: scanner.Summary{Findings: 2}}, nil
+		},
+		Explain: func(path string) (scanner.Explanation, error) {
+			if path == "missing" {
+				return scanner.Explanation{}, errors.New("stat missing: no such file")
+			}
+			return scanner.Explanation{Path: path, Scanned: true}, nil
+		},
+		Allow: func(values []string) ([]string, error) {
+			return append([]string{"©"}, values...), nil
+		},
+		Fix: func(paths []string, dryRun bool) (string, error) {
+			if dryRun {
+				return "--- a/" + paths[0] + "\n", nil
+			}
+			return "fixed " + paths[0] + ": 1 character(s)\n", nil
+		},
+	}}
+
+	responses := serve(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"scan","arguments":{"paths":["src"]}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"explain","arguments":{"path":"a.go"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"explain","arguments":{"path":"missing"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"explain","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"allow","arguments":{"values":["é"]}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"allow","arguments":{"values":[]}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"scan","arguments":"bad"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"tools/call","params":{"name":"fix","arguments":{"paths":["a.go"]}}}`,
+		`{"jsonrpc":"2.0","id":10,"method":"tools/call","params":{"name":"fix","arguments":{"paths":["a.go"],"dry_run":false}}}`,
+	)
+	if len(responses) != 10 {
+		t.Fatalf("expected 10 responses, got %d", len(responses))
+	}
+
+	tests := []struct {
+		index   int
+		want    string
+		isError bool
+	}{
+		{0, `"findings": 2`, false},
+		{1, `"path": "a.go"`, false},
+		{2, "no such file", true},
+		{3, "requires a path", true},
+		{4, `"é"`, false},
+		{5, "non-empty values", true},
+		{6, "invalid arguments", true},
+		{8, `"changes": "--- a/a.go\n",
+  "dry_run": true`, false},
+		{9, `"changes": "fixed a.go: 1 character(s)\n",
+  "dry_run": false`, false},
+	}
+	for _, tt := range tests {
+		text, isError := toolText(t, responses[tt.index])
+		if !strings.Contains(text, tt.want) || isError != tt.isError {
+			t.Fatalf("response %d: expected %q (isError=%v), got %q (isError=%v)", tt.index, tt.want, tt.isError, text, isError)
+		}
+	}
+	if len(scannedPaths) != 1 || scannedPaths[0] != "src" {
+		t.Fatalf("unexpected scanned paths: %v", scannedPaths)
+	}
+	if rpcErr, ok := responses[7]["error"].(map[string]interface{}); !ok || !strings.Contains(rpcErr["message"].(string), "unknown tool") {
+		t.Fatalf("expected unknown tool error, got %v", responses[7])
+	}
+}
+
+func TestServeWriteError(t *testing.T) {
+	s := Server{}
+	err := s.Serve(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n"), failWriter{})
+	if err == nil {
+		t.Fatalf("expected write error")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failure")
+}
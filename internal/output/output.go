@@ -5,74 +5,239 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/TT-AIXion/englint/internal/config"
+	"github.com/TT-AIXion/englint/internal/diff"
+	"github.com/TT-AIXion/englint/internal/i18n"
 	"github.com/TT-AIXion/englint/internal/scanner"
+	"github.com/TT-AIXion/englint/internal/store"
+	"github.com/TT-AIXion/englint/internal/suggest"
+	"github.com/TT-AIXion/englint/internal/trend"
 )
 
-const fixSuggestion = "Auto-fix is not implemented yet. Replace characters manually or add safe symbols to the allow list in .englint.yaml."
-
 // ScanOptions controls printed details.
 type ScanOptions struct {
 	Verbose      bool
 	FixRequested bool
+	// Messages prints each finding's message below it, for configs with
+	// message templates.
+	Messages bool
+	// GroupByOwner adds a report of findings per CODEOWNERS owner.
+	GroupByOwner bool
+	// Version is the englint version FormatSARIF names as the tool's.
+	Version string
+}
+
+// SlowestFiles is how many of the slowest files verbose output lists.
+const SlowestFiles = 10
+
+// Unowned is the owner reported for findings in files without owners.
+const Unowned = "(unowned)"
+
+// OwnerSummary counts the findings and files of one CODEOWNERS owner.
+type OwnerSummary struct {
+	Owner    string `json:"owner"`
+	Files    int    `json:"files"`
+	Findings int    `json:"findings"`
+}
+
+// GroupByOwner counts findings per owner, most findings first. A finding
+// with several owners counts for each of them.
+func GroupByOwner(findings []scanner.Finding) []OwnerSummary {
+	counts := map[string]*OwnerSummary{}
+	files := map[string]map[string]bool{}
+	for _, f := range findings {
+		owners := f.Owners
+		if len(owners) == 0 {
+			owners = []string{Unowned}
+		}
+		for _, owner := range owners {
+			if counts[owner] == nil {
+				counts[owner] = &OwnerSummary{Owner: owner}
+				files[owner] = map[string]bool{}
+			}
+			counts[owner].Findings++
+			if !files[owner][f.Path] {
+				files[owner][f.Path] = true
+				counts[owner].Files++
+			}
+		}
+	}
+	out := make([]OwnerSummary, 0, len(counts))
+	for _, s := range counts {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Findings != out[j].Findings {
+			return out[i].Findings > out[j].Findings
+		}
+		return out[i].Owner < out[j].Owner
+	})
+	return out
+}
+
+// Format selects how a Writer renders results.
+type Format string
+
+const (
+	FormatHuman Format = "human"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+	// FormatProblemMatcher prints one path:line:column: severity: message
+	// line per finding, like the Go compiler and gcc, for the problem
+	// matchers of editors and CI systems.
+	FormatProblemMatcher Format = "problem-matcher"
+)
+
+// Formats lists every format with a short description, as printed by
+// --format=help.
+var Formats = []struct {
+	Format      Format
+	Description string
+}{
+	{FormatHuman, "Colored findings and a summary for terminals (default)"},
+	{FormatJSON, "Findings, summary, and file lists as one JSON document"},
+	{FormatSARIF, "SARIF 2.1.0 log for GitHub Code Scanning and other SARIF tools"},
+	{FormatProblemMatcher, "One path:line:col: severity: message line per finding for problem matchers"},
 }
 
-// Writer renders scan output in JSON or human-readable mode.
+// ParseFormat returns the format named s. The empty string is FormatHuman.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatHuman, nil
+	}
+	for _, f := range Formats {
+		if string(f.Format) == strings.ToLower(s) {
+			return f.Format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (see --format=help)", s)
+}
+
+// PrintFormats lists the Formats with their descriptions.
+func PrintFormats(out io.Writer) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, f := range Formats {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", f.Format, f.Description); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// Writer renders scan output in one of the Formats.
 type Writer struct {
-	JSON    bool
+	Format  Format
 	NoColor bool
 	Out     io.Writer
 	ErrW    io.Writer
+	// Lang translates human-readable scan messages; the zero value is
+	// English.
+	Lang i18n.Lang
 }
 
-func New(jsonMode, noColor bool, out, errW io.Writer) Writer {
+func New(format Format, noColor bool, out, errW io.Writer) Writer {
 	if out == nil {
 		out = os.Stdout
 	}
 	if errW == nil {
 		errW = os.Stderr
 	}
-	return Writer{JSON: jsonMode, NoColor: noColor, Out: out, ErrW: errW}
+	if format == "" {
+		format = FormatHuman
+	}
+	return Writer{Format: format, NoColor: noColor, Out: out, ErrW: errW}
 }
 
 func (w Writer) PrintScan(result scanner.Result, opts ScanOptions) error {
-	if w.JSON {
+	switch w.Format {
+	case FormatJSON:
 		return w.printScanJSON(result, opts)
+	case FormatSARIF:
+		return w.printScanSARIF(result, opts)
+	case FormatProblemMatcher:
+		return w.printScanProblemMatcher(result)
 	}
 	return w.printScanHuman(result, opts)
 }
 
+// printScanProblemMatcher prints each finding on one line without color or
+// a summary, such as
+//
+//	main.go:12:5: error: Detected Han character "日" (U+65E5)
+func (w Writer) printScanProblemMatcher(result scanner.Result) error {
+	for _, f := range result.Findings {
+		message := strings.Join(strings.Fields(f.Message), " ")
+		if message == "" {
+			message = fmt.Sprintf("%s character %q (%s)", f.Category, f.Character, f.CodePoint)
+		}
+		if _, err := fmt.Fprintf(w.Out, "%s:%d:%d: %s: %s\n", f.Path, f.Line, f.Column, f.Severity, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w Writer) printScanJSON(result scanner.Result, opts ScanOptions) error {
 	payload := struct {
-		Summary      scanner.Summary       `json:"summary"`
-		Findings     []scanner.Finding     `json:"findings"`
-		Scanned      []string              `json:"scannedFiles,omitempty"`
-		Skipped      []scanner.SkippedFile `json:"skippedFiles,omitempty"`
-		FixSuggested string                `json:"fixSuggested,omitempty"`
+		Summary      scanner.Summary         `json:"summary"`
+		Findings     []scanner.Finding       `json:"findings"`
+		Scanned      []string                `json:"scannedFiles,omitempty"`
+		Skipped      []scanner.SkippedFile   `json:"skippedFiles,omitempty"`
+		Limited      []scanner.LimitedFile   `json:"limitedFiles,omitempty"`
+		Truncated    []scanner.TruncatedFile `json:"truncatedFiles,omitempty"`
+		Timings      []scanner.FileTiming    `json:"timings,omitempty"`
+		Roots        []scanner.RootSummary   `json:"roots,omitempty"`
+		Owners       []OwnerSummary          `json:"owners,omitempty"`
+		FixSuggested string                  `json:"fixSuggested,omitempty"`
 	}{
-		Summary:  result.Summary,
-		Findings: result.Findings,
-		Scanned:  result.ScannedFiles,
-		Skipped:  result.SkippedFiles,
+		Summary:   result.Summary,
+		Roots:     result.Roots,
+		Findings:  result.Findings,
+		Scanned:   result.ScannedFiles,
+		Skipped:   result.SkippedFiles,
+		Limited:   result.LimitedFiles,
+		Truncated: result.TruncatedFiles,
+		Timings:   result.Timings,
+	}
+	if opts.GroupByOwner {
+		payload.Owners = GroupByOwner(result.Findings)
 	}
 	if opts.FixRequested && result.Summary.Findings > 0 {
-		payload.FixSuggested = fixSuggestion
+		payload.FixSuggested = i18n.English.T(i18n.FixSuggestion)
 	}
 	enc := json.NewEncoder(w.Out)
 	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
 	return enc.Encode(payload)
 }
 
 func (w Writer) printScanHuman(result scanner.Result, opts ScanOptions) error {
 	if opts.Verbose {
+		timings := make(map[string]scanner.FileTiming, len(result.Timings))
+		for _, t := range result.Timings {
+			timings[t.Path] = t
+		}
 		for _, file := range result.ScannedFiles {
-			if _, err := fmt.Fprintf(w.Out, "SCANNED %s\n", file); err != nil {
+			line := "SCANNED " + file
+			if t, ok := timings[file]; ok {
+				line += " (" + formatTiming(t)
+				if t.EOL != "" {
+					line += ", " + strings.ToUpper(t.EOL)
+				}
+				line += ")"
+			}
+			if _, err := fmt.Fprintln(w.Out, line); err != nil {
 				return err
 			}
 		}
 		for _, skipped := range result.SkippedFiles {
-			if _, err := fmt.Fprintf(w.Out, "SKIPPED %s (%s)\n", skipped.Path, skipped.Reason); err != nil {
+			if _, err := fmt.Fprintf(w.Out, "SKIPPED %s (%s)\n", skipped.Path, skipped.Describe()); err != nil {
 				return err
 			}
 		}
@@ -81,19 +246,36 @@ func (w Writer) printScanHuman(result scanner.Result, opts ScanOptions) error {
 	for _, finding := range result.Findings {
 		label := strings.ToUpper(string(finding.Severity))
 		label = w.colorize(label, finding.Severity)
+		location := fmt.Sprintf("%s:%d:%d", finding.Path, finding.Line, finding.Column)
+		if finding.URI != "" {
+			location = finding.URI
+		}
 		if _, err := fmt.Fprintf(
 			w.Out,
-			"%s %s:%d:%d [%s] %s (%s)\n",
+			"%s %s [%s] %s (%s)\n",
 			label,
-			finding.Path,
-			finding.Line,
-			finding.Column,
+			location,
 			finding.Category,
 			finding.Character,
-			finding.CodePoint,
+			codePointName(finding),
 		); err != nil {
 			return err
 		}
+		if len(finding.Duplicates) > 0 {
+			if _, err := fmt.Fprintf(w.Out, "  also in: %s\n", strings.Join(finding.Duplicates, ", ")); err != nil {
+				return err
+			}
+		}
+		if opts.FixRequested && finding.Fix != "" {
+			if _, err := fmt.Fprintf(w.Out, "  fix: replace with %q\n", finding.Fix); err != nil {
+				return err
+			}
+		}
+		if opts.Messages && finding.Message != "" {
+			if _, err := fmt.Fprintf(w.Out, "  %s\n", finding.Message); err != nil {
+				return err
+			}
+		}
 		if strings.TrimSpace(finding.Excerpt) != "" {
 			if _, err := fmt.Fprintf(w.Out, "  %s\n", finding.Excerpt); err != nil {
 				return err
@@ -101,29 +283,288 @@ func (w Writer) printScanHuman(result scanner.Result, opts ScanOptions) error {
 		}
 	}
 
+	if opts.GroupByOwner && len(result.Findings) > 0 {
+		tw := tabwriter.NewWriter(w.Out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "OWNER\tFILES\tFINDINGS")
+		for _, s := range GroupByOwner(result.Findings) {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\n", s.Owner, s.Files, s.Findings)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, limited := range result.LimitedFiles {
+		if _, err := fmt.Fprintf(w.Out, "NOTE %s: %d more %s omitted (showing first %d)\n", limited.Path, limited.Omitted, plural(limited.Omitted, "finding", "findings"), limited.Reported); err != nil {
+			return err
+		}
+	}
+	for _, truncated := range result.TruncatedFiles {
+		if _, err := fmt.Fprintf(w.Out, "NOTE %s: scanned only the first %d %s (%s)\n", truncated.Path, truncated.Lines, plural(truncated.Lines, "line", "lines"), formatBytes(truncated.Bytes)); err != nil {
+			return err
+		}
+	}
+
+	if opts.Verbose && len(result.Timings) > 0 {
+		if _, err := fmt.Fprintln(w.Out, "Slowest files:"); err != nil {
+			return err
+		}
+		for _, t := range result.Slowest(SlowestFiles) {
+			if _, err := fmt.Fprintf(w.Out, "  %s (%s)\n", t.Path, formatTiming(t)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(result.Roots) > 0 {
+		tw := tabwriter.NewWriter(w.Out, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "ROOT\tSCANNED\tSKIPPED\tFINDINGS")
+		for _, root := range result.Roots {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", root.Root, root.FilesScanned, root.FilesSkipped, root.Findings)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
 	if result.Summary.Findings == 0 {
-		if _, err := fmt.Fprintln(w.Out, "No non-English text found."); err != nil {
+		if _, err := fmt.Fprintln(w.Out, w.Lang.T(i18n.NoFindings)); err != nil {
 			return err
 		}
 	}
+	omitted := ""
+	if result.Summary.FindingsOmitted > 0 {
+		omitted = fmt.Sprintf(w.Lang.T(i18n.Omitted), result.Summary.FindingsOmitted)
+	}
+	if result.Summary.FindingsBaselined > 0 {
+		omitted += fmt.Sprintf(w.Lang.T(i18n.Baselined), result.Summary.FindingsBaselined)
+	}
 	if _, err := fmt.Fprintf(
 		w.Out,
-		"Summary: scanned=%d skipped=%d findings=%d\n",
+		w.Lang.T(i18n.Summary)+"%s\n",
 		result.Summary.FilesScanned,
 		result.Summary.FilesSkipped,
 		result.Summary.Findings,
+		omitted,
 	); err != nil {
 		return err
 	}
 
 	if opts.FixRequested && result.Summary.Findings > 0 {
-		if _, err := fmt.Fprintln(w.Out, fixSuggestion); err != nil {
+		if _, err := fmt.Fprintln(w.Out, w.Lang.T(i18n.FixSuggestion)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// PrintExplanations renders --why results.
+func (w Writer) PrintExplanations(explanations []scanner.Explanation) error {
+	if w.Format == FormatJSON {
+		enc := json.NewEncoder(w.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Explanations []scanner.Explanation `json:"explanations"`
+		}{Explanations: explanations})
+	}
+	for _, e := range explanations {
+		decision := "skipped"
+		if e.Scanned {
+			decision = "scanned"
+		}
+		rule := e.Rule
+		if e.Pattern != "" {
+			rule = fmt.Sprintf("%s %q", e.Rule, e.Pattern)
+		}
+		if _, err := fmt.Fprintf(w.Out, "%s: %s by %s (%s)\n", e.Path, decision, rule, e.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintSkipped renders the --list-skipped table of skipped files with
+// their reasons and details.
+func (w Writer) PrintSkipped(skipped []scanner.SkippedFile) error {
+	if w.Format == FormatJSON {
+		enc := json.NewEncoder(w.Out)
+		enc.SetIndent("", "  ")
+		if skipped == nil {
+			skipped = []scanner.SkippedFile{}
+		}
+		return enc.Encode(struct {
+			SkippedFiles []scanner.SkippedFile `json:"skippedFiles"`
+		}{SkippedFiles: skipped})
+	}
+	if len(skipped) == 0 {
+		_, err := fmt.Fprintln(w.Out, "No files skipped.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w.Out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PATH\tREASON\tDETAIL")
+	for _, s := range skipped {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Path, s.Reason, s.Detail)
+	}
+	return tw.Flush()
+}
+
+type diffSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+type diffPayload struct {
+	Summary diffSummary `json:"summary"`
+	diff.Result
+}
+
+func (w Writer) PrintDiff(result diff.Result) error {
+	if w.Format == FormatJSON {
+		enc := json.NewEncoder(w.Out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(diffPayload{
+			Summary: diffSummary{Added: len(result.Added), Removed: len(result.Removed), Unchanged: len(result.Unchanged)},
+			Result:  result,
+		})
+	}
+	removed := "REMOVED"
+	if !w.NoColor {
+		removed = "\x1b[32m" + removed + "\x1b[0m"
+	}
+	for _, group := range []struct {
+		label    string
+		findings []scanner.Finding
+	}{
+		{w.colorize("ADDED", scanner.SeverityError), result.Added},
+		{removed, result.Removed},
+	} {
+		for _, f := range group.findings {
+			if _, err := fmt.Fprintf(w.Out, "%s %s:%d:%d [%s] %s (%s)\n",
+				group.label, f.Path, f.Line, f.Column, f.Category, f.Character, codePointName(f)); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w.Out, "Summary: added=%d removed=%d unchanged=%d\n", len(result.Added), len(result.Removed), len(result.Unchanged))
+	return err
+}
+
+func (w Writer) PrintHistory(scans []store.Scan) error {
+	if w.Format == FormatJSON {
+		enc := json.NewEncoder(w.Out)
+		enc.SetIndent("", "  ")
+		if scans == nil {
+			scans = []store.Scan{}
+		}
+		return enc.Encode(struct {
+			Scans []store.Scan `json:"scans"`
+		}{Scans: scans})
+	}
+	if len(scans) == 0 {
+		_, err := fmt.Fprintln(w.Out, "No scans recorded.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w.Out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSCANNED AT\tCOMMIT\tFILES\tFINDINGS\tCATEGORIES")
+	for _, s := range scans {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s\n", s.ID, s.ScannedAt, shortCommit(s.GitSHA), s.FilesScanned, s.Findings, categoryCounts(s.ByCategory))
+	}
+	return tw.Flush()
+}
+
+func (w Writer) PrintTrend(points []trend.Point) error {
+	if w.Format == FormatJSON {
+		enc := json.NewEncoder(w.Out)
+		enc.SetIndent("", "  ")
+		if points == nil {
+			points = []trend.Point{}
+		}
+		return enc.Encode(struct {
+			Points []trend.Point `json:"points"`
+		}{Points: points})
+	}
+	if len(points) == 0 {
+		_, err := fmt.Fprintln(w.Out, "No commits in the requested range.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w.Out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "DATE\tCOMMIT\tFILES\tFINDINGS\tCATEGORIES")
+	for _, p := range points {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", p.Date, shortCommit(p.Commit), p.Files, p.Findings, categoryCounts(p.ByCategory))
+	}
+	return tw.Flush()
+}
+
+// PrintSuggestions prints an allow list that keeps the existing values and
+// structured entries, written as config.Save writes them, and appends the
+// suggested ones, each with a comment saying how often it occurs, so the
+// block can replace the allow key of a config file as-is.
+func (w Writer) PrintSuggestions(allow []string, entries []config.AllowEntry, suggestions []suggest.Suggestion) error {
+	if w.Format == FormatJSON {
+		enc := json.NewEncoder(w.Out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if suggestions == nil {
+			suggestions = []suggest.Suggestion{}
+		}
+		return enc.Encode(struct {
+			Suggestions []suggest.Suggestion `json:"suggestions"`
+		}{Suggestions: suggestions})
+	}
+	if len(suggestions) == 0 {
+		_, err := fmt.Fprintln(w.Out, "No allow entries to suggest.")
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(config.AllowListYAML(allow, entries))
+	for _, s := range suggestions {
+		fmt.Fprintf(&b, "  # %s (%s) appears %d %s across %d %s — consider allowing\n",
+			s.CodePoint, s.Category, s.Count, plural(s.Count, "time", "times"), s.Files, plural(s.Files, "file", "files"))
+		fmt.Fprintf(&b, "  - %s\n", strconv.Quote(s.Value))
+	}
+	_, err := io.WriteString(w.Out, b.String())
+	return err
+}
+
+// codePointName returns the code point of a finding followed by its Unicode
+// name, if it has one, such as "U+3042 HIRAGANA LETTER A".
+func codePointName(f scanner.Finding) string {
+	if f.Name == "" {
+		return f.CodePoint
+	}
+	return f.CodePoint + " " + f.Name
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// shortCommit abbreviates a commit hash for tables.
+func shortCommit(sha string) string {
+	if sha == "" {
+		return "-"
+	}
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+// categoryCounts renders counts as sorted "Category=n" pairs.
+func categoryCounts(counts map[string]int) string {
+	pairs := make([]string, 0, len(counts))
+	for category, n := range counts {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", category, n))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
 func (w Writer) colorize(label string, severity scanner.Severity) string {
 	if w.NoColor {
 		return label
@@ -135,3 +576,26 @@ func (w Writer) colorize(label string, severity scanner.Severity) string {
 		return "\x1b[31m" + label + "\x1b[0m"
 	}
 }
+
+// formatTiming renders a file's size and scan duration, such as
+// "1.5 KB, 2.314ms".
+func formatTiming(t scanner.FileTiming) string {
+	return formatBytes(t.Bytes) + ", " + t.Duration.Round(time.Microsecond).String()
+}
+
+// formatBytes renders n with 1024-based units, matching the sizes
+// mmap_threshold accepts.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	size, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if size < unit {
+			break
+		}
+		size, suffix = size/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", size, suffix)
+}
@@ -4,10 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/TT-AIXion/englint/internal/config"
+	"github.com/TT-AIXion/englint/internal/diff"
 	"github.com/TT-AIXion/englint/internal/scanner"
+	"github.com/TT-AIXion/englint/internal/store"
+	"github.com/TT-AIXion/englint/internal/suggest"
+	"github.com/TT-AIXion/englint/internal/trend"
 )
 
 type errWriter struct{}
@@ -31,7 +38,7 @@ func (w *failAtWriter) Write(p []byte) (int, error) {
 
 func TestPrintScanHuman(t *testing.T) {
 	var out bytes.Buffer
-	w := New(false, true, &out, &out)
+	w := New(FormatHuman, true, &out, &out)
 	result := scanner.Result{
 		Findings: []scanner.Finding{
 			{
@@ -40,14 +47,16 @@ func TestPrintScanHuman(t *testing.T) {
 				Column:    7,
 				Character: "あ",
 				CodePoint: "U+3042",
+				Name:      "HIRAGANA LETTER A",
 				Category:  "CJK",
 				Severity:  scanner.SeverityError,
 				Excerpt:   "var s = \"あ\"",
 			},
 		},
-		ScannedFiles: []string{"a.go"},
-		SkippedFiles: []scanner.SkippedFile{{Path: "b.bin", Reason: "binary file"}},
-		Summary:      scanner.Summary{FilesScanned: 1, FilesSkipped: 1, Findings: 1},
+		ScannedFiles:   []string{"a.go"},
+		SkippedFiles:   []scanner.SkippedFile{{Path: "b.bin", Reason: scanner.SkipBinary}},
+		TruncatedFiles: []scanner.TruncatedFile{{Path: "a.go", Bytes: 2048, Lines: 40}},
+		Summary:        scanner.Summary{FilesScanned: 1, FilesSkipped: 1, Findings: 1},
 	}
 
 	if err := w.PrintScan(result, ScanOptions{Verbose: true, FixRequested: true}); err != nil {
@@ -56,10 +65,11 @@ func TestPrintScanHuman(t *testing.T) {
 	text := out.String()
 	for _, mustContain := range []string{
 		"SCANNED a.go",
-		"SKIPPED b.bin (binary file)",
-		"ERROR a.go:3:7 [CJK]",
+		"SKIPPED b.bin (binary)",
+		"ERROR a.go:3:7 [CJK] あ (U+3042 HIRAGANA LETTER A)\n",
+		"NOTE a.go: scanned only the first 40 lines (2.0 KB)\n",
 		"Summary: scanned=1 skipped=1 findings=1",
-		"Auto-fix is not implemented yet.",
+		"Some findings have no automatic fix.",
 	} {
 		if !strings.Contains(text, mustContain) {
 			t.Fatalf("expected output to contain %q\nactual:\n%s", mustContain, text)
@@ -67,9 +77,143 @@ func TestPrintScanHuman(t *testing.T) {
 	}
 }
 
+func TestPrintScanHumanTimings(t *testing.T) {
+	var out bytes.Buffer
+	result := scanner.Result{
+		ScannedFiles: []string{"a.go", "b.json"},
+		Timings: []scanner.FileTiming{
+			{Path: "a.go", Bytes: 512, Duration: 40 * time.Microsecond, EOL: scanner.EOLCRLF},
+			{Path: "b.json", Bytes: 3 << 20, Duration: 1500 * time.Millisecond},
+		},
+		Summary: scanner.Summary{FilesScanned: 2},
+	}
+	if err := New(FormatHuman, true, &out, &out).PrintScan(result, ScanOptions{Verbose: true}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	for _, want := range []string{
+		"SCANNED a.go (512 B, 40µs, CRLF)\n",
+		"SCANNED b.json (3.0 MB, 1.5s)\n",
+		"Slowest files:\n  b.json (3.0 MB, 1.5s)\n  a.go (512 B, 40µs)\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPrintScanRoots(t *testing.T) {
+	result := scanner.Result{
+		ScannedFiles: []string{"api/a.go", "web/b.go"},
+		Roots: []scanner.RootSummary{
+			{Root: "api", Summary: scanner.Summary{FilesScanned: 1, Findings: 12}},
+			{Root: "web", Summary: scanner.Summary{FilesScanned: 1, FilesSkipped: 3}},
+		},
+		Summary: scanner.Summary{FilesScanned: 2, FilesSkipped: 3, Findings: 12},
+	}
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintScan(result, ScanOptions{}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	want := "ROOT  SCANNED  SKIPPED  FINDINGS\napi   1        0        12\nweb   1        3        0\nSummary: scanned=2 skipped=3 findings=12\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintScan(result, ScanOptions{}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"roots": [
+    {
+      "root": "api",
+      "filesScanned": 1,`) {
+		t.Fatalf("expected roots in JSON output:\n%s", out.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1536, want: "1.5 KB"},
+		{n: 64 << 20, want: "64.0 MB"},
+		{n: 5 << 30, want: "5.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Fatalf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPrintScanHumanURI(t *testing.T) {
+	var out bytes.Buffer
+	result := scanner.Result{
+		Findings: []scanner.Finding{{Path: "a.go", Line: 3, Column: 7, Character: "あ", CodePoint: "U+3042", Category: "CJK", Severity: scanner.SeverityError, URI: "file:///src/a.go#L3"}},
+		Summary:  scanner.Summary{FilesScanned: 1, Findings: 1},
+	}
+	if err := New(FormatHuman, true, &out, &out).PrintScan(result, ScanOptions{}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "ERROR file:///src/a.go#L3 [CJK] あ (U+3042)") {
+		t.Fatalf("expected URI location, got:\n%s", out.String())
+	}
+}
+
+func TestPrintScanHumanLimitedFiles(t *testing.T) {
+	var out bytes.Buffer
+	w := New(FormatHuman, true, &out, &out)
+	result := scanner.Result{
+		Findings:     []scanner.Finding{{Path: "dump.sql", Line: 1, Column: 1, Character: "あ", CodePoint: "U+3042", Category: "CJK", Severity: scanner.SeverityError}},
+		LimitedFiles: []scanner.LimitedFile{{Path: "dump.sql", Reported: 1, Omitted: 41}},
+		Summary:      scanner.Summary{FilesScanned: 1, Findings: 1, FindingsOmitted: 41},
+	}
+	if err := w.PrintScan(result, ScanOptions{}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	for _, mustContain := range []string{
+		"NOTE dump.sql: 41 more findings omitted (showing first 1)",
+		"Summary: scanned=1 skipped=0 findings=1 omitted=41",
+	} {
+		if !strings.Contains(out.String(), mustContain) {
+			t.Fatalf("expected output to contain %q\nactual:\n%s", mustContain, out.String())
+		}
+	}
+
+	fw := &failAtWriter{failAt: 2}
+	if err := New(FormatHuman, true, fw, fw).PrintScan(result, ScanOptions{}); err == nil {
+		t.Fatalf("expected note write error")
+	}
+
+	out.Reset()
+	result.LimitedFiles[0].Omitted = 1
+	result.Summary.FindingsOmitted = 1
+	if err := w.PrintScan(result, ScanOptions{}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "NOTE dump.sql: 1 more finding omitted (showing first 1)") {
+		t.Fatalf("expected singular note, got:\n%s", out.String())
+	}
+}
+
+func TestPrintScanHumanBaselined(t *testing.T) {
+	var out bytes.Buffer
+	w := New(FormatHuman, true, &out, &out)
+	result := scanner.Result{Summary: scanner.Summary{FilesScanned: 2, FindingsBaselined: 7}}
+	if err := w.PrintScan(result, ScanOptions{}); err != nil {
+		t.Fatalf("PrintScan returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Summary: scanned=2 skipped=0 findings=0 baselined=7") {
+		t.Fatalf("expected baselined count, got:\n%s", out.String())
+	}
+}
+
 func TestPrintScanHumanNoFindings(t *testing.T) {
 	var out bytes.Buffer
-	w := New(false, false, &out, &out)
+	w := New(FormatHuman, false, &out, &out)
 	result := scanner.Result{Summary: scanner.Summary{FilesScanned: 2}}
 	if err := w.PrintScan(result, ScanOptions{}); err != nil {
 		t.Fatalf("PrintScan returned error: %v", err)
@@ -83,9 +227,122 @@ func TestPrintScanHumanNoFindings(t *testing.T) {
 	}
 }
 
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "", want: FormatHuman},
+		{in: "human", want: FormatHuman},
+		{in: "JSON", want: FormatJSON},
+		{in: "SARIF", want: FormatSARIF},
+		{in: "problem-matcher", want: FormatProblemMatcher},
+		{in: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Fatalf("ParseFormat(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+	var out bytes.Buffer
+	if err := PrintFormats(&out); err != nil || !strings.HasPrefix(out.String(), "human            Colored findings") || !strings.Contains(out.String(), "\nproblem-matcher  One path:line:col") {
+		t.Fatalf("unexpected format list %q: %v", out.String(), err)
+	}
+}
+
+func TestPrintScanSARIF(t *testing.T) {
+	var out bytes.Buffer
+	w := New(FormatSARIF, true, &out, &out)
+	result := scanner.Result{
+		Findings: []scanner.Finding{
+			{Path: "src/a.go", Line: 3, Column: 7, Category: "Latin Extended", Severity: scanner.SeverityWarning, Message: "Detected Latin Extended character \"é\" (U+00E9)", Fingerprint: "0123456789abcdef"},
+			{Path: "src/a.go", Line: 4, Column: 1, Category: "Han", Severity: scanner.SeverityError, Message: "Detected Han character \"日\" (U+65E5)", HelpURI: "https://wiki.example.com/i18n#cjk"},
+			{Path: "b.md", Line: 1, Column: 2, Category: "Han", Severity: scanner.SeverityError, Message: "Detected Han character \"本\" (U+672C)", URI: "file:///repo/b.md#L1"},
+		},
+		Summary: scanner.Summary{Findings: 3},
+	}
+	if err := w.PrintScan(result, ScanOptions{Version: "1.2.3"}); err != nil {
+		t.Fatalf("PrintScan: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("decode SARIF: %v\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+	run := log.Runs[0]
+	wantRules := []sarifRule{
+		{ID: "han", ShortDescription: sarifMessage{Text: "Han character"}, HelpURI: "https://wiki.example.com/i18n#cjk"},
+		{ID: "latin-extended", ShortDescription: sarifMessage{Text: "Latin Extended character"}},
+	}
+	if run.Tool.Driver.Name != "englint" || run.Tool.Driver.Version != "1.2.3" || !reflect.DeepEqual(run.Tool.Driver.Rules, wantRules) {
+		t.Fatalf("unexpected driver: %+v", run.Tool.Driver)
+	}
+	if run.ColumnKind != "unicodeCodePoints" || len(run.Results) != 3 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	first := run.Results[0]
+	if first.RuleID != "latin-extended" || first.RuleIndex != 1 || first.Level != "warning" || first.PartialFingerprints["englint/v1"] != "0123456789abcdef" {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+	if loc := first.Locations[0].PhysicalLocation; loc.ArtifactLocation.URI != "src/a.go" || loc.Region != (sarifRegion{StartLine: 3, StartColumn: 7}) {
+		t.Fatalf("unexpected first location: %+v", loc)
+	}
+	if r := run.Results[2]; r.RuleIndex != 0 || r.Level != "error" || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "file:///repo/b.md" {
+		t.Fatalf("unexpected last result: %+v", r)
+	}
+
+	out.Reset()
+	if err := w.PrintScan(scanner.Result{}, ScanOptions{}); err != nil || !strings.Contains(out.String(), `"rules": []`) || !strings.Contains(out.String(), `"results": []`) {
+		t.Fatalf("expected empty rules and results, got %q (%v)", out.String(), err)
+	}
+}
+
+func TestSARIFURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "src/a.go", want: "src/a.go"},
+		{path: "docs/my notes #1 100%.md", want: "docs/my%20notes%20%231%20100%25.md"},
+		{path: "docs/日本/café.md", want: "docs/%E6%97%A5%E6%9C%AC/caf%C3%A9.md"},
+	}
+	for _, tt := range tests {
+		if got := sarifURI(scanner.Finding{Path: tt.path}); got != tt.want {
+			t.Fatalf("sarifURI(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPrintScanProblemMatcher(t *testing.T) {
+	var out bytes.Buffer
+	w := New(FormatProblemMatcher, false, &out, &out)
+	result := scanner.Result{
+		Findings: []scanner.Finding{
+			{Path: "src/a.go", Line: 3, Column: 7, Category: "Latin Extended", Character: "é", CodePoint: "U+00E9", Severity: scanner.SeverityWarning, Message: "Detected Latin Extended character \"é\" (U+00E9)", URI: "file:///repo/src/a.go#L3"},
+			{Path: "b.md", Line: 1, Column: 2, Category: "Han", Character: "本", CodePoint: "U+672C", Severity: scanner.SeverityError, Message: "Use English\nin docs"},
+			{Path: "c.txt", Line: 2, Column: 1, Category: "Han", Character: "日", CodePoint: "U+65E5", Severity: scanner.SeverityError},
+		},
+		SkippedFiles: []scanner.SkippedFile{{Path: "d.bin", Reason: scanner.SkipBinary}},
+		Summary:      scanner.Summary{Findings: 3, FilesSkipped: 1},
+	}
+	if err := w.PrintScan(result, ScanOptions{Verbose: true}); err != nil {
+		t.Fatalf("PrintScan: %v", err)
+	}
+	want := "src/a.go:3:7: warning: Detected Latin Extended character \"é\" (U+00E9)\n" +
+		"b.md:1:2: error: Use English in docs\n" +
+		"c.txt:2:1: error: Han character \"日\" (U+65E5)\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
+
 func TestPrintScanJSON(t *testing.T) {
 	var out bytes.Buffer
-	w := New(true, true, &out, &out)
+	w := New(FormatJSON, true, &out, &out)
 	result := scanner.Result{
 		Findings: []scanner.Finding{{Path: "a.go", Severity: scanner.SeverityWarning}},
 		Summary:  scanner.Summary{Findings: 1},
@@ -110,19 +367,19 @@ func TestPrintScanWriterErrors(t *testing.T) {
 	result := scanner.Result{
 		Findings:     []scanner.Finding{{Path: "a.go", Severity: scanner.SeverityError, Category: "CJK", Character: "あ", CodePoint: "U+3042"}},
 		ScannedFiles: []string{"a.go"},
-		SkippedFiles: []scanner.SkippedFile{{Path: "b.bin", Reason: "binary"}},
+		SkippedFiles: []scanner.SkippedFile{{Path: "b.bin", Reason: scanner.SkipBinary}},
 		Summary:      scanner.Summary{FilesScanned: 1, FilesSkipped: 1, Findings: 1},
 	}
 
 	t.Run("json encode error", func(t *testing.T) {
-		w := New(true, true, errWriter{}, errWriter{})
+		w := New(FormatJSON, true, errWriter{}, errWriter{})
 		if err := w.PrintScan(result, ScanOptions{}); err == nil {
 			t.Fatalf("expected json output error")
 		}
 	})
 
 	t.Run("human verbose write error", func(t *testing.T) {
-		w := New(false, true, errWriter{}, errWriter{})
+		w := New(FormatHuman, true, errWriter{}, errWriter{})
 		if err := w.PrintScan(result, ScanOptions{Verbose: true}); err == nil {
 			t.Fatalf("expected human output error")
 		}
@@ -130,7 +387,7 @@ func TestPrintScanWriterErrors(t *testing.T) {
 
 	t.Run("human excerpt write error", func(t *testing.T) {
 		fw := &failAtWriter{failAt: 2}
-		w := New(false, true, fw, fw)
+		w := New(FormatHuman, true, fw, fw)
 		res := scanner.Result{
 			Findings: []scanner.Finding{{
 				Path:      "a.go",
@@ -151,7 +408,7 @@ func TestPrintScanWriterErrors(t *testing.T) {
 
 	t.Run("human no-findings message error", func(t *testing.T) {
 		fw := &failAtWriter{failAt: 1}
-		w := New(false, true, fw, fw)
+		w := New(FormatHuman, true, fw, fw)
 		if err := w.PrintScan(scanner.Result{}, ScanOptions{}); err == nil {
 			t.Fatalf("expected no-findings write error")
 		}
@@ -159,7 +416,7 @@ func TestPrintScanWriterErrors(t *testing.T) {
 
 	t.Run("human summary error", func(t *testing.T) {
 		fw := &failAtWriter{failAt: 2}
-		w := New(false, true, fw, fw)
+		w := New(FormatHuman, true, fw, fw)
 		res := scanner.Result{
 			Findings: []scanner.Finding{{Path: "a.go", Character: "あ", CodePoint: "U+3042", Category: "CJK", Severity: scanner.SeverityError}},
 			Summary:  scanner.Summary{FilesScanned: 1, Findings: 1},
@@ -171,7 +428,7 @@ func TestPrintScanWriterErrors(t *testing.T) {
 
 	t.Run("human fix message error", func(t *testing.T) {
 		fw := &failAtWriter{failAt: 3}
-		w := New(false, true, fw, fw)
+		w := New(FormatHuman, true, fw, fw)
 		res := scanner.Result{
 			Findings: []scanner.Finding{{Path: "a.go", Character: "あ", CodePoint: "U+3042", Category: "CJK", Severity: scanner.SeverityError}},
 			Summary:  scanner.Summary{FilesScanned: 1, Findings: 1},
@@ -183,7 +440,7 @@ func TestPrintScanWriterErrors(t *testing.T) {
 }
 
 func TestNewDefaultsAndColorize(t *testing.T) {
-	w := New(false, false, nil, nil)
+	w := New(FormatHuman, false, nil, nil)
 	if w.Out == nil || w.ErrW == nil {
 		t.Fatalf("expected stdio defaults")
 	}
@@ -197,8 +454,216 @@ func TestNewDefaultsAndColorize(t *testing.T) {
 		t.Fatalf("expected yellow color for warning")
 	}
 
-	plain := New(false, true, &bytes.Buffer{}, &bytes.Buffer{}).colorize("ERROR", scanner.SeverityError)
+	plain := New(FormatHuman, true, &bytes.Buffer{}, &bytes.Buffer{}).colorize("ERROR", scanner.SeverityError)
 	if plain != "ERROR" {
 		t.Fatalf("expected plain label without color")
 	}
 }
+
+func TestPrintExplanations(t *testing.T) {
+	explanations := []scanner.Explanation{
+		{Path: "a.go", Scanned: true, Rule: "include", Pattern: "**/*.go", Reason: "file matches an include pattern"},
+		{Path: "vendor/b.go", Rule: "exclude", Pattern: "vendor/**", Reason: "file matches an exclude pattern"},
+		{Path: "c.bin", Rule: "binary", Reason: "file content looks binary"},
+	}
+
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintExplanations(explanations); err != nil {
+		t.Fatalf("PrintExplanations returned error: %v", err)
+	}
+	for _, mustContain := range []string{
+		`a.go: scanned by include "**/*.go" (file matches an include pattern)`,
+		`vendor/b.go: skipped by exclude "vendor/**"`,
+		"c.bin: skipped by binary (file content looks binary)",
+	} {
+		if !strings.Contains(out.String(), mustContain) {
+			t.Fatalf("expected output to contain %q\nactual:\n%s", mustContain, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintExplanations(explanations); err != nil {
+		t.Fatalf("PrintExplanations json returned error: %v", err)
+	}
+	var payload struct {
+		Explanations []scanner.Explanation `json:"explanations"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("json decode: %v", err)
+	}
+	if len(payload.Explanations) != 3 || payload.Explanations[1].Pattern != "vendor/**" {
+		t.Fatalf("unexpected explanations: %+v", payload.Explanations)
+	}
+
+	if err := New(FormatHuman, true, errWriter{}, errWriter{}).PrintExplanations(explanations); err == nil {
+		t.Fatalf("expected write error")
+	}
+}
+
+func TestPrintHistory(t *testing.T) {
+	scans := []store.Scan{
+		{ID: 2, ScannedAt: "2024-02-01T00:00:00Z", GitSHA: "0123456789abcdef", FilesScanned: 10, Findings: 3, ByCategory: map[string]int{"Cyrillic": 1, "CJK": 2}},
+		{ID: 1, ScannedAt: "2024-01-01T00:00:00Z", FilesScanned: 9},
+	}
+
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintHistory(scans); err != nil {
+		t.Fatalf("PrintHistory returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID  SCANNED AT") {
+		t.Fatalf("unexpected history output:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "0123456789ab ") || !strings.HasSuffix(lines[1], "CJK=2 Cyrillic=1") || !strings.Contains(lines[2], " - ") {
+		t.Fatalf("unexpected history rows:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := New(FormatHuman, true, &out, &out).PrintHistory(nil); err != nil || out.String() != "No scans recorded.\n" {
+		t.Fatalf("unexpected empty history output: %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintHistory(nil); err != nil || !strings.Contains(out.String(), `"scans": []`) {
+		t.Fatalf("unexpected empty json history: %q (%v)", out.String(), err)
+	}
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintHistory(scans); err != nil || !strings.Contains(out.String(), `"gitSha": "0123456789abcdef"`) {
+		t.Fatalf("unexpected json history: %q (%v)", out.String(), err)
+	}
+}
+
+func TestPrintSkipped(t *testing.T) {
+	skipped := []scanner.SkippedFile{
+		{Path: "assets/logo.go", Reason: scanner.SkipBinary, Detail: "NUL byte at offset 12"},
+		{Path: "gen.go", Reason: scanner.SkipAllowedPattern},
+	}
+
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintSkipped(skipped); err != nil {
+		t.Fatalf("PrintSkipped returned error: %v", err)
+	}
+	want := "PATH            REASON           DETAIL\n" +
+		"assets/logo.go  binary           NUL byte at offset 12\n" +
+		"gen.go          allowed-pattern  \n"
+	if out.String() != want {
+		t.Fatalf("unexpected skipped table:\n%q\nwant:\n%q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := New(FormatHuman, true, &out, &out).PrintSkipped(nil); err != nil || out.String() != "No files skipped.\n" {
+		t.Fatalf("unexpected empty skipped output: %q (%v)", out.String(), err)
+	}
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintSkipped(nil); err != nil || !strings.Contains(out.String(), `"skippedFiles": []`) {
+		t.Fatalf("unexpected empty json skipped: %q (%v)", out.String(), err)
+	}
+}
+
+func TestPrintSuggestions(t *testing.T) {
+	suggestions := []suggest.Suggestion{
+		{Value: "©", CodePoint: "U+00A9", Category: "Symbol", Count: 412, Files: 80},
+		{Value: "\u200b", CodePoint: "U+200B", Category: "Invisible", Count: 5, Files: 1},
+	}
+
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintSuggestions([]string{"→", "ß"}, []config.AllowEntry{{Value: "ß", Expires: "2027-01-01", Reason: "German copy", AddedBy: "ana"}}, suggestions); err != nil {
+		t.Fatalf("PrintSuggestions returned error: %v", err)
+	}
+	want := "allow:\n" +
+		"  - \"→\"\n" +
+		"  - {value: \"ß\", expires: 2027-01-01, reason: \"German copy\", added_by: \"ana\"}\n" +
+		"  # U+00A9 (Symbol) appears 412 times across 80 files — consider allowing\n" +
+		"  - \"©\"\n" +
+		"  # U+200B (Invisible) appears 5 times across 1 file — consider allowing\n" +
+		"  - \"\\u200b\"\n"
+	if out.String() != want {
+		t.Fatalf("unexpected suggestions:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := New(FormatHuman, true, &out, &out).PrintSuggestions(nil, nil, nil); err != nil || out.String() != "No allow entries to suggest.\n" {
+		t.Fatalf("unexpected empty suggestions output: %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintSuggestions(nil, nil, suggestions[:1]); err != nil || !strings.Contains(out.String(), `"value": "©"`) {
+		t.Fatalf("unexpected json suggestions: %q (%v)", out.String(), err)
+	}
+	if err := New(FormatHuman, true, errWriter{}, errWriter{}).PrintSuggestions(nil, nil, suggestions); err == nil {
+		t.Fatalf("expected write error")
+	}
+}
+
+func TestPrintDiff(t *testing.T) {
+	result := diff.Result{
+		Added:     []scanner.Finding{{Path: "a.go", Line: 2, Column: 3, Category: "CJK", Character: "日", CodePoint: "U+65E5"}},
+		Removed:   []scanner.Finding{{Path: "b.go", Line: 1, Column: 1, Category: "Cyrillic", Character: "ж", CodePoint: "U+0436"}},
+		Unchanged: []scanner.Finding{{Path: "c.go"}},
+	}
+
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintDiff(result); err != nil {
+		t.Fatalf("PrintDiff returned error: %v", err)
+	}
+	want := "ADDED a.go:2:3 [CJK] 日 (U+65E5)\nREMOVED b.go:1:1 [Cyrillic] ж (U+0436)\nSummary: added=1 removed=1 unchanged=1\n"
+	if out.String() != want {
+		t.Fatalf("unexpected diff output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := New(FormatHuman, false, &out, &out).PrintDiff(result); err != nil || !strings.Contains(out.String(), "\x1b[32mREMOVED\x1b[0m") {
+		t.Fatalf("expected colored output, got %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintDiff(result); err != nil {
+		t.Fatalf("PrintDiff json returned error: %v", err)
+	}
+	var payload struct {
+		Summary struct {
+			Added     int `json:"added"`
+			Unchanged int `json:"unchanged"`
+		} `json:"summary"`
+		Removed []scanner.Finding `json:"removed"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("json decode: %v", err)
+	}
+	if payload.Summary.Added != 1 || payload.Summary.Unchanged != 1 || len(payload.Removed) != 1 {
+		t.Fatalf("unexpected diff payload: %+v", payload)
+	}
+
+	if err := New(FormatHuman, true, errWriter{}, errWriter{}).PrintDiff(result); err == nil {
+		t.Fatalf("expected write error")
+	}
+}
+
+func TestPrintTrend(t *testing.T) {
+	points := []trend.Point{
+		{Date: "2024-01-01", Commit: "0123456789abcdef", Files: 4, Findings: 3, ByCategory: map[string]int{"Cyrillic": 1, "CJK": 2}},
+		{Date: "2024-02-01", Commit: "fedcba", Files: 4, ByCategory: map[string]int{}},
+	}
+
+	var out bytes.Buffer
+	if err := New(FormatHuman, true, &out, &out).PrintTrend(points); err != nil {
+		t.Fatalf("PrintTrend returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "DATE") || !strings.HasSuffix(lines[1], "CJK=2 Cyrillic=1") || !strings.Contains(lines[1], "0123456789ab ") {
+		t.Fatalf("unexpected trend output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := New(FormatHuman, true, &out, &out).PrintTrend(nil); err != nil || out.String() != "No commits in the requested range.\n" {
+		t.Fatalf("unexpected empty trend output: %q (%v)", out.String(), err)
+	}
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintTrend(nil); err != nil || !strings.Contains(out.String(), `"points": []`) {
+		t.Fatalf("unexpected empty json trend: %q (%v)", out.String(), err)
+	}
+	out.Reset()
+	if err := New(FormatJSON, true, &out, &out).PrintTrend(points); err != nil || !strings.Contains(out.String(), `"CJK": 2`) {
+		t.Fatalf("unexpected json trend: %q (%v)", out.String(), err)
+	}
+}
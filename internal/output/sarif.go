@@ -0,0 +1,168 @@
+package output
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// sarifSchema and sarifVersion identify the SARIF version FormatSARIF
+// writes.
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifInformationURI is the home page SARIF consumers link the tool to.
+const sarifInformationURI = "https://github.com/TT-AIXion/englint"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool sarifTool `json:"tool"`
+	// ColumnKind tells consumers that columns count code points, like
+	// finding columns, rather than UTF-16 code units.
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// printScanSARIF writes result as a SARIF 2.1.0 log with one rule per
+// category, as GitHub Code Scanning and other SARIF consumers read it.
+func (w Writer) printScanSARIF(result scanner.Result, opts ScanOptions) error {
+	var categories []string
+	helpURIs := make(map[string]string)
+	for _, f := range result.Findings {
+		if _, ok := helpURIs[f.Category]; !ok {
+			categories = append(categories, f.Category)
+			helpURIs[f.Category] = ""
+		}
+		if f.HelpURI != "" {
+			helpURIs[f.Category] = f.HelpURI
+		}
+	}
+	sort.Strings(categories)
+	rules := make([]sarifRule, len(categories))
+	ruleIndex := make(map[string]int, len(categories))
+	for i, category := range categories {
+		rules[i] = sarifRule{
+			ID:               sarifRuleID(category),
+			ShortDescription: sarifMessage{Text: category + " character"},
+			HelpURI:          helpURIs[category],
+		}
+		ruleIndex[category] = i
+	}
+
+	results := make([]sarifResult, 0, len(result.Findings))
+	for _, f := range result.Findings {
+		level := "error"
+		if f.Severity == scanner.SeverityWarning {
+			level = "warning"
+		}
+		res := sarifResult{
+			RuleID:    sarifRuleID(f.Category),
+			RuleIndex: ruleIndex[f.Category],
+			Level:     level,
+			Message:   sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(f)},
+				Region:           sarifRegion{StartLine: f.Line, StartColumn: f.Column},
+			}}},
+		}
+		if f.Fingerprint != "" {
+			res.PartialFingerprints = map[string]string{"englint/v1": f.Fingerprint}
+		}
+		results = append(results, res)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "englint",
+				Version:        opts.Version,
+				InformationURI: sarifInformationURI,
+				Rules:          rules,
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+	enc := json.NewEncoder(w.Out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(log)
+}
+
+// sarifRuleID returns the SARIF rule ID of category: its name in lower
+// case with spaces as hyphens, such as latin-extended.
+func sarifRuleID(category string) string {
+	return strings.ReplaceAll(strings.ToLower(category), " ", "-")
+}
+
+// sarifURI returns the artifact URI of f: its --file-uris URI, else its
+// slash-separated path as a relative URI reference, which SARIF consumers
+// resolve against the repository root.
+func sarifURI(f scanner.Finding) string {
+	if f.URI != "" {
+		uri, _, _ := strings.Cut(f.URI, "#")
+		return uri
+	}
+	return (&url.URL{Path: filepath.ToSlash(f.Path)}).String()
+}
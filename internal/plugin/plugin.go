@@ -0,0 +1,172 @@
+// Package plugin runs external checkers configured in .englint.yaml and
+// converts their output into findings.
+//
+// A plugin is an executable that is started once per scanned file. It reads
+// a Request as JSON on stdin and writes a Response as JSON on stdout. A
+// non-zero exit status is an error.
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+	"github.com/TT-AIXion/englint/internal/uniname"
+)
+
+// ProtocolVersion is sent in every Request.
+const ProtocolVersion = 1
+
+// Request is written to a plugin's stdin.
+type Request struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Response is read from a plugin's stdout.
+type Response struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is a single plugin diagnostic. Line and Column are 1-based; zero
+// values report the start of the file or line. Category defaults to the
+// plugin's executable name and Severity to the configured severity.
+type Finding struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	Character string `json:"character"`
+	Category  string `json:"category"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+// Plugin is an external checker command.
+type Plugin struct {
+	// Command is the executable followed by its arguments.
+	Command []string
+}
+
+// Parse splits a configured plugin command line on whitespace.
+func Parse(command string) (Plugin, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return Plugin{}, errors.New("empty plugin command")
+	}
+	return Plugin{Command: fields}, nil
+}
+
+// Name is the base name of the plugin's executable.
+func (p Plugin) Name() string {
+	return strings.TrimSuffix(filepath.Base(p.Command[0]), filepath.Ext(p.Command[0]))
+}
+
+// Check runs the plugin on one file and returns its findings.
+func (p Plugin) Check(path string, content []byte, severity scanner.Severity) ([]scanner.Finding, error) {
+	req, err := json.Marshal(Request{Version: ProtocolVersion, Path: filepath.ToSlash(path), Content: string(content)})
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(p.Command[0], p.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s on %s: %w: %s", p.Name(), path, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s on %s: %w", p.Name(), path, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s on %s: invalid response: %w", p.Name(), path, err)
+	}
+	lines := bytes.Split(content, []byte("\n"))
+	seen := make(map[string]int)
+	out := make([]scanner.Finding, 0, len(resp.Findings))
+	for _, f := range resp.Findings {
+		finding := scanner.Finding{
+			Path:      path,
+			Line:      max(f.Line, 1),
+			Column:    max(f.Column, 1),
+			Character: f.Character,
+			Category:  f.Category,
+			Severity:  severity,
+			Message:   f.Message,
+		}
+		if finding.Category == "" {
+			finding.Category = p.Name()
+		}
+		switch scanner.Severity(f.Severity) {
+		case scanner.SeverityError, scanner.SeverityWarning:
+			finding.Severity = scanner.Severity(f.Severity)
+		case "":
+		default:
+			return nil, fmt.Errorf("plugin %s on %s: invalid severity %q", p.Name(), path, f.Severity)
+		}
+		if r, size := utf8.DecodeRuneInString(f.Character); size > 0 && size == len(f.Character) && r != utf8.RuneError {
+			finding.CodePoint = fmt.Sprintf("U+%04X", r)
+			finding.Name = uniname.Name(r)
+		}
+		if finding.Message == "" {
+			finding.Message = fmt.Sprintf("%s: %s", finding.Category, f.Character)
+		}
+		if ignored(lines, finding.Line) {
+			continue
+		}
+		text := ""
+		if finding.Line <= len(lines) {
+			text = strings.TrimSpace(string(lines[finding.Line-1]))
+		}
+		key := finding.Category + "\x00" + finding.Message + "\x00" + text
+		finding.Fingerprint = scanner.Fingerprint(path, key, seen[key])
+		seen[key]++
+		out = append(out, finding)
+	}
+	return out, nil
+}
+
+// ignored reports whether the line before line contains the
+// englint:ignore directive, mirroring the built-in checks.
+func ignored(lines [][]byte, line int) bool {
+	return line >= 2 && line-2 < len(lines) && bytes.Contains(lines[line-2], []byte(scanner.IgnoreDirective))
+}
+
+// Run checks every scanned file of result with each plugin and merges the
+// findings into result. Relative paths in result are relative to base, or
+// to the working directory when base is empty, as with
+// scanner.Options.DisplayRoot; plugins see them as reported.
+func Run(plugins []Plugin, result *scanner.Result, severity scanner.Severity, base string) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+	var findings []scanner.Finding
+	for _, path := range result.ScannedFiles {
+		file := path
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(base, file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		for _, p := range plugins {
+			found, err := p.Check(path, content, severity)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, found...)
+		}
+	}
+	result.Merge(findings)
+	return nil
+}
@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// writePlugin writes an executable shell script and skips the test when no
+// shell is available.
+func writePlugin(t *testing.T, body string) string {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	path := filepath.Join(t.TempDir(), "banned-terms.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse("  ./checks/banned.py --strict ")
+	if err != nil || !reflect.DeepEqual(p.Command, []string{"./checks/banned.py", "--strict"}) || p.Name() != "banned" {
+		t.Fatalf("Parse() = %+v, %v", p, err)
+	}
+	if _, err := Parse(" "); err == nil {
+		t.Fatalf("expected empty command error")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	script := writePlugin(t, `input=$(cat)
+case "$input" in
+  *'"version":1,"path":"dir/a.go"'*) ;;
+  *) echo "unexpected request: $input" >&2; exit 3 ;;
+esac
+case "$input" in
+  *forbidden*) printf '%s' '{"findings":[
+    {"line":2,"column":5,"message":"banned term \"forbidden\""},
+    {"line":4,"character":"ß","category":"Spelling","severity":"warning"},
+    {"line":6,"message":"suppressed"}]}' ;;
+  *) printf '{"findings":[]}' ;;
+esac
+`)
+	p := Plugin{Command: []string{script}}
+	content := []byte("package a\nvar forbidden = 1\n\nvar s = \"ß\"\n// englint:ignore TODO: legacy\nvar forbidden2 = 2\n")
+	got, err := p.Check(filepath.Join("dir", "a.go"), content, scanner.SeverityError)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Check() = %+v, want 2 findings", got)
+	}
+	first := got[0]
+	if first.Path != filepath.Join("dir", "a.go") || first.Line != 2 || first.Column != 5 || first.Category != "banned-terms" ||
+		first.Severity != scanner.SeverityError || first.Message != `banned term "forbidden"` || first.CodePoint != "" || first.Fingerprint == "" {
+		t.Fatalf("unexpected first finding: %+v", first)
+	}
+	second := got[1]
+	if second.Line != 4 || second.Column != 1 || second.Category != "Spelling" || second.Severity != scanner.SeverityWarning ||
+		second.CodePoint != "U+00DF" || second.Message != "Spelling: ß" {
+		t.Fatalf("unexpected second finding: %+v", second)
+	}
+
+	clean, err := p.Check(filepath.Join("dir", "a.go"), []byte("package a\n"), scanner.SeverityError)
+	if err != nil || len(clean) != 0 {
+		t.Fatalf("Check() on clean file = %+v, %v", clean, err)
+	}
+	if _, err := p.Check("other.go", content, scanner.SeverityError); err == nil || !strings.Contains(err.Error(), "unexpected request") {
+		t.Fatalf("expected exit error with stderr, got %v", err)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "invalid json", body: "cat >/dev/null; echo nope\n", want: "invalid response"},
+		{name: "invalid severity", body: "cat >/dev/null; echo '{\"findings\":[{\"severity\":\"fatal\"}]}'\n", want: `invalid severity "fatal"`},
+		{name: "exit status", body: "cat >/dev/null; exit 1\n", want: "exit status 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Plugin{Command: []string{writePlugin(t, tt.body)}}
+			if _, err := p.Check("a.go", []byte("x\n"), scanner.SeverityError); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Check() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := (Plugin{Command: []string{"englint-missing-plugin"}}).Check("a.go", nil, scanner.SeverityError); err == nil {
+		t.Fatalf("expected missing executable error")
+	}
+}
+
+func TestRun(t *testing.T) {
+	script := writePlugin(t, "cat >/dev/null; echo '{\"findings\":[{\"line\":1,\"message\":\"checked\"}]}'\n")
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "b.go"), filepath.Join(dir, "a.go")}
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte("package a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := scanner.Result{
+		Findings:     []scanner.Finding{{Path: paths[0], Line: 2, Column: 1, Category: "CJK"}},
+		ScannedFiles: paths,
+	}
+	if err := Run([]Plugin{{Command: []string{script}}}, &result, scanner.SeverityWarning, ""); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Summary.Findings != 3 || result.Findings[0].Path != filepath.Join(dir, "a.go") || result.Findings[1].Message != "checked" || result.Findings[2].Category != "CJK" {
+		t.Fatalf("unexpected merged result: %+v", result)
+	}
+	if result.Findings[0].Severity != scanner.SeverityWarning {
+		t.Fatalf("expected configured severity, got %q", result.Findings[0].Severity)
+	}
+
+	// Relative paths are read below base and reported as they are.
+	relative := scanner.Result{ScannedFiles: []string{"a.go"}}
+	if err := Run([]Plugin{{Command: []string{script}}}, &relative, scanner.SeverityError, dir); err != nil {
+		t.Fatalf("Run() with base error = %v", err)
+	}
+	if len(relative.Findings) != 1 || relative.Findings[0].Path != "a.go" {
+		t.Fatalf("unexpected findings below base: %+v", relative.Findings)
+	}
+
+	result.ScannedFiles = append(result.ScannedFiles, filepath.Join(dir, "missing.go"))
+	if err := Run([]Plugin{{Command: []string{script}}}, &result, scanner.SeverityError, ""); err == nil {
+		t.Fatalf("expected read error")
+	}
+	if err := Run(nil, &result, scanner.SeverityError, ""); err != nil {
+		t.Fatalf("Run() without plugins error = %v", err)
+	}
+}
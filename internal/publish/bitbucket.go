@@ -0,0 +1,199 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// DefaultBitbucketAPIURL is the Bitbucket Cloud API root.
+const DefaultBitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketReportID identifies the englint Code Insights report on a commit.
+const bitbucketReportID = "englint"
+
+// bitbucketMaxAnnotations is the per-report annotation cap enforced by both
+// Bitbucket Cloud and Server.
+const bitbucketMaxAnnotations = 1000
+
+// bitbucketCloudBatch is the maximum number of annotations per Cloud request.
+const bitbucketCloudBatch = 100
+
+// Bitbucket uploads findings as a Code Insights report with per-line
+// annotations. The report is replaced on every run.
+type Bitbucket struct {
+	// APIURL is the Cloud API root, or the Server base URL when Server is set.
+	APIURL string
+	// Server selects the Bitbucket Server/Data Center REST API.
+	Server bool
+	Token  string
+	// Owner is the Cloud workspace or the Server project key.
+	Owner  string
+	Repo   string
+	Commit string
+	// Limit caps the number of annotations uploaded. Zero means the
+	// platform maximum.
+	Limit  int
+	Client *http.Client
+}
+
+// Publish replaces the englint report on the commit.
+func (b Bitbucket) Publish(findings []scanner.Finding) (Result, error) {
+	if b.Owner == "" || b.Repo == "" || b.Commit == "" {
+		return Result{}, errors.New("repository owner, slug, and commit must be set")
+	}
+	if b.Server && (b.APIURL == "" || b.Token == "") {
+		return Result{}, errors.New("BITBUCKET_SERVER_URL and BITBUCKET_TOKEN must be set for Bitbucket Server")
+	}
+
+	limit := b.Limit
+	if limit <= 0 || limit > bitbucketMaxAnnotations {
+		limit = bitbucketMaxAnnotations
+	}
+	var result Result
+	if len(findings) > limit {
+		result.Limited = len(findings) - limit
+		findings = findings[:limit]
+	}
+
+	// Deleting the report also deletes its annotations, so stale findings
+	// from earlier runs never linger.
+	if _, err := doJSON(b.Client, http.MethodDelete, b.reportURL(), b.headers(), nil, nil); err != nil {
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+			return Result{}, err
+		}
+	}
+	if _, err := doJSON(b.Client, http.MethodPut, b.reportURL(), b.headers(), b.report(findings, result.Limited), nil); err != nil {
+		return Result{}, err
+	}
+	if len(findings) == 0 {
+		return result, nil
+	}
+
+	if b.Server {
+		annotations := make([]map[string]interface{}, 0, len(findings))
+		for _, f := range findings {
+			annotations = append(annotations, withLink(map[string]interface{}{
+				"externalId": annotationID(f),
+				"path":       repoPath(f.Path),
+				"line":       f.Line,
+				"message":    f.Message,
+				"severity":   annotationSeverity(f.Severity),
+				"type":       "CODE_SMELL",
+			}, f))
+		}
+		body := map[string]interface{}{"annotations": annotations}
+		if _, err := doJSON(b.Client, http.MethodPost, b.reportURL()+"/annotations", b.headers(), body, nil); err != nil {
+			return result, err
+		}
+		result.Posted = len(annotations)
+		return result, nil
+	}
+
+	for start := 0; start < len(findings); start += bitbucketCloudBatch {
+		end := start + bitbucketCloudBatch
+		if end > len(findings) {
+			end = len(findings)
+		}
+		annotations := make([]map[string]interface{}, 0, end-start)
+		for _, f := range findings[start:end] {
+			annotations = append(annotations, withLink(map[string]interface{}{
+				"external_id":     annotationID(f),
+				"annotation_type": "CODE_SMELL",
+				"path":            repoPath(f.Path),
+				"line":            f.Line,
+				"summary":         f.Message,
+				"severity":        annotationSeverity(f.Severity),
+			}, f))
+		}
+		if _, err := doJSON(b.Client, http.MethodPost, b.reportURL()+"/annotations", b.headers(), annotations, nil); err != nil {
+			return result, err
+		}
+		result.Posted += len(annotations)
+	}
+	return result, nil
+}
+
+func (b Bitbucket) report(findings []scanner.Finding, limited int) map[string]interface{} {
+	failed := false
+	for _, f := range findings {
+		if f.Severity == scanner.SeverityError {
+			failed = true
+			break
+		}
+	}
+	details := fmt.Sprintf("englint found %d non-English character(s).", len(findings)+limited)
+	if limited > 0 {
+		details += fmt.Sprintf(" %d annotation(s) were omitted.", limited)
+	}
+
+	if b.Server {
+		result := "PASS"
+		if failed {
+			result = "FAIL"
+		}
+		return map[string]interface{}{
+			"title":    "englint",
+			"details":  details,
+			"reporter": "englint",
+			"result":   result,
+			"data":     []map[string]interface{}{{"title": "Findings", "type": "NUMBER", "value": len(findings) + limited}},
+		}
+	}
+	result := "PASSED"
+	if failed {
+		result = "FAILED"
+	}
+	return map[string]interface{}{
+		"title":       "englint",
+		"details":     details,
+		"reporter":    "englint",
+		"report_type": "BUG",
+		"result":      result,
+		"data":        []map[string]interface{}{{"title": "Findings", "type": "NUMBER", "value": len(findings) + limited}},
+	}
+}
+
+func (b Bitbucket) reportURL() string {
+	if b.Server {
+		return fmt.Sprintf("%s/rest/insights/1.0/projects/%s/repos/%s/commits/%s/reports/%s",
+			strings.TrimRight(b.APIURL, "/"), url.PathEscape(b.Owner), url.PathEscape(b.Repo), b.Commit, bitbucketReportID)
+	}
+	base := strings.TrimRight(b.APIURL, "/")
+	if base == "" {
+		base = DefaultBitbucketAPIURL
+	}
+	return fmt.Sprintf("%s/repositories/%s/%s/commit/%s/reports/%s",
+		base, url.PathEscape(b.Owner), url.PathEscape(b.Repo), b.Commit, bitbucketReportID)
+}
+
+func (b Bitbucket) headers() map[string]string {
+	if b.Token == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + b.Token}
+}
+
+func annotationID(f scanner.Finding) string {
+	return fmt.Sprintf("englint-%s-%d-%d", repoPath(f.Path), f.Line, f.Column)
+}
+
+func annotationSeverity(severity scanner.Severity) string {
+	if severity == scanner.SeverityError {
+		return "HIGH"
+	}
+	return "MEDIUM"
+}
+
+// withLink adds the help URI of f to an annotation as its link.
+func withLink(annotation map[string]interface{}, f scanner.Finding) map[string]interface{} {
+	if f.HelpURI != "" {
+		annotation["link"] = f.HelpURI
+	}
+	return annotation
+}
@@ -0,0 +1,126 @@
+package publish
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   interface{}
+}
+
+func recordingServer(t *testing.T, status map[string]int) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Body: body})
+		if code, ok := status[r.Method]; ok {
+			w.WriteHeader(code)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestBitbucketCloudPublish(t *testing.T) {
+	srv, requests := recordingServer(t, map[string]int{http.MethodDelete: http.StatusNotFound})
+	var findings []scanner.Finding
+	for i := 1; i <= bitbucketCloudBatch+1; i++ {
+		findings = append(findings, scanner.Finding{Path: "./a.go", Line: i, Column: 2, Severity: scanner.SeverityWarning, Message: "m"})
+	}
+
+	bb := Bitbucket{APIURL: srv.URL, Owner: "ws", Repo: "repo", Commit: "abc", Client: srv.Client()}
+	result, err := bb.Publish(findings)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result.Posted != bitbucketCloudBatch+1 || result.Limited != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	reportPath := "/repositories/ws/repo/commit/abc/reports/englint"
+	got := *requests
+	if len(got) != 4 || got[0].Method != http.MethodDelete || got[1].Method != http.MethodPut || got[1].Path != reportPath {
+		t.Fatalf("unexpected requests: %+v", got)
+	}
+	report := got[1].Body.(map[string]interface{})
+	if report["result"] != "PASSED" || report["report_type"] != "BUG" {
+		t.Fatalf("unexpected report: %v", report)
+	}
+	first := got[2].Body.([]interface{})
+	if len(first) != bitbucketCloudBatch || got[2].Path != reportPath+"/annotations" {
+		t.Fatalf("unexpected first batch: %d %s", len(first), got[2].Path)
+	}
+	annotation := first[0].(map[string]interface{})
+	if annotation["path"] != "a.go" || annotation["external_id"] != "englint-a.go-1-2" || annotation["severity"] != "MEDIUM" {
+		t.Fatalf("unexpected annotation: %v", annotation)
+	}
+}
+
+func TestBitbucketServerPublish(t *testing.T) {
+	srv, requests := recordingServer(t, nil)
+	bb := Bitbucket{APIURL: srv.URL + "/", Server: true, Token: "tok", Owner: "PROJ", Repo: "repo", Commit: "abc", Limit: 2, Client: srv.Client()}
+	result, err := bb.Publish(sampleFindings())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result.Posted != 2 || result.Limited != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	reportPath := "/rest/insights/1.0/projects/PROJ/repos/repo/commits/abc/reports/englint"
+	got := *requests
+	if len(got) != 3 || got[1].Path != reportPath || got[2].Path != reportPath+"/annotations" {
+		t.Fatalf("unexpected requests: %+v", got)
+	}
+	report := got[1].Body.(map[string]interface{})
+	if report["result"] != "FAIL" || !strings.Contains(report["details"].(string), "2 annotation(s) were omitted") {
+		t.Fatalf("unexpected report: %v", report)
+	}
+	annotations := got[2].Body.(map[string]interface{})["annotations"].([]interface{})
+	if len(annotations) != 2 || annotations[0].(map[string]interface{})["severity"] != "HIGH" || annotations[0].(map[string]interface{})["link"] != "https://wiki.example.com/i18n#cjk" || annotations[1].(map[string]interface{})["link"] != nil {
+		t.Fatalf("unexpected annotations: %v", annotations)
+	}
+}
+
+func TestBitbucketPublishErrors(t *testing.T) {
+	srv, _ := recordingServer(t, map[string]int{http.MethodDelete: http.StatusForbidden})
+	bb := Bitbucket{APIURL: srv.URL, Owner: "ws", Repo: "repo", Commit: "abc", Client: srv.Client()}
+	if _, err := bb.Publish(nil); err == nil || !strings.Contains(err.Error(), "HTTP 403") {
+		t.Fatalf("expected HTTP 403 error, got %v", err)
+	}
+
+	srv, requests := recordingServer(t, nil)
+	bb = Bitbucket{APIURL: srv.URL, Owner: "ws", Repo: "repo", Commit: "abc", Client: srv.Client()}
+	if result, err := bb.Publish(nil); err != nil || result.Posted != 0 || len(*requests) != 2 {
+		t.Fatalf("expected report without annotations: %+v %v %+v", result, err, *requests)
+	}
+
+	tests := []struct {
+		name string
+		bb   Bitbucket
+		want string
+	}{
+		{name: "commit", bb: Bitbucket{Owner: "ws", Repo: "r"}, want: "commit must be set"},
+		{name: "server token", bb: Bitbucket{Server: true, APIURL: "u", Owner: "P", Repo: "r", Commit: "c"}, want: "BITBUCKET_TOKEN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.bb.Publish(nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q error, got %v", tt.want, err)
+			}
+		})
+	}
+	if got := (Bitbucket{Owner: "ws", Repo: "r", Commit: "c"}).reportURL(); !strings.HasPrefix(got, DefaultBitbucketAPIURL) {
+		t.Fatalf("unexpected default url: %s", got)
+	}
+}
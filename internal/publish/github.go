@@ -0,0 +1,171 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/TT-AIXion/englint/internal/git"
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// DefaultGitHubAPIURL is used when GITHUB_API_URL is not set.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// githubReviewBatch is the number of comments sent in a single review.
+const githubReviewBatch = 50
+
+// GitHub posts findings as pull request review comments.
+type GitHub struct {
+	APIURL string
+	Token  string
+	// Repo is the "owner/name" repository slug.
+	Repo string
+	PR   int
+	// Limit caps the number of new comments posted per run. Zero means no
+	// limit.
+	Limit int
+	// ChangedLines returns the lines of each file changed since the pull
+	// request's base commit, as git.ChangedSince does. Review comments can
+	// only be placed on those lines, so other findings are dropped; when it
+	// fails, each file gets one comment listing its findings instead. When
+	// it is nil, every finding is posted on its line.
+	ChangedLines func(base string) (map[string][]git.LineRange, error)
+	Client       *http.Client
+}
+
+type githubComment struct {
+	Path        string `json:"path"`
+	Line        int    `json:"line,omitempty"`
+	Side        string `json:"side,omitempty"`
+	SubjectType string `json:"subject_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Publish posts review comments for findings that are not already present
+// on the pull request.
+func (g GitHub) Publish(findings []scanner.Finding) (Result, error) {
+	if g.Token == "" {
+		return Result{}, errors.New("GITHUB_TOKEN is not set")
+	}
+	if !strings.Contains(g.Repo, "/") {
+		return Result{}, fmt.Errorf("invalid repository %q (expected owner/name)", g.Repo)
+	}
+	if g.PR <= 0 {
+		return Result{}, errors.New("pull request number must be positive")
+	}
+
+	var pull struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			SHA string `json:"sha"`
+		} `json:"base"`
+	}
+	if _, err := doJSON(g.Client, http.MethodGet, g.url("/pulls/%d", g.PR), g.headers(), nil, &pull); err != nil {
+		return Result{}, err
+	}
+	comments := groupByLine(findings)
+	if g.ChangedLines != nil {
+		if changed, err := g.ChangedLines(pull.Base.SHA); err == nil {
+			comments = groupByLine(onChangedLines(findings, changed))
+		} else {
+			comments = groupByFile(findings)
+		}
+	}
+
+	existing, err := g.existingComments()
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	var pending []githubComment
+	for _, c := range comments {
+		if _, ok := existing[commentKey(c.Path, c.Line, c.Body)]; ok {
+			result.Duplicates++
+			continue
+		}
+		if g.Limit > 0 && len(pending) >= g.Limit {
+			result.Limited++
+			continue
+		}
+		if c.Line == 0 {
+			pending = append(pending, githubComment{Path: c.Path, SubjectType: "file", Body: c.Body})
+			continue
+		}
+		pending = append(pending, githubComment{Path: c.Path, Line: c.Line, Side: "RIGHT", Body: c.Body})
+	}
+
+	for start := 0; start < len(pending); start += githubReviewBatch {
+		end := start + githubReviewBatch
+		if end > len(pending) {
+			end = len(pending)
+		}
+		review := map[string]interface{}{
+			"commit_id": pull.Head.SHA,
+			"event":     "COMMENT",
+			"body":      fmt.Sprintf("englint found non-English text on %d line(s).", end-start),
+			"comments":  pending[start:end],
+		}
+		if _, err := doJSON(g.Client, http.MethodPost, g.url("/pulls/%d/reviews", g.PR), g.headers(), review, nil); err != nil {
+			return result, err
+		}
+		result.Posted += end - start
+	}
+	return result, nil
+}
+
+// existingComments returns englint review comments already on the pull
+// request, keyed by path, line, and body.
+func (g GitHub) existingComments() (map[string]struct{}, error) {
+	out := make(map[string]struct{})
+	for page := 1; ; page++ {
+		var comments []struct {
+			Path         string `json:"path"`
+			Line         int    `json:"line"`
+			OriginalLine int    `json:"original_line"`
+			Body         string `json:"body"`
+		}
+		url := g.url("/pulls/%d/comments?per_page=100&page=%d", g.PR, page)
+		header, err := doJSON(g.Client, http.MethodGet, url, g.headers(), nil, &comments)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			if !strings.Contains(c.Body, Marker) {
+				continue
+			}
+			line := c.Line
+			if line == 0 {
+				line = c.OriginalLine
+			}
+			out[commentKey(c.Path, line, c.Body)] = struct{}{}
+		}
+		if len(comments) == 0 || !strings.Contains(header.Get("Link"), `rel="next"`) {
+			return out, nil
+		}
+	}
+}
+
+func (g GitHub) url(format string, args ...interface{}) string {
+	base := strings.TrimRight(g.APIURL, "/")
+	if base == "" {
+		base = DefaultGitHubAPIURL
+	}
+	return base + "/repos/" + g.Repo + fmt.Sprintf(format, args...)
+}
+
+func (g GitHub) headers() map[string]string {
+	return map[string]string{
+		"Authorization":        "Bearer " + g.Token,
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+}
+
+func commentKey(path string, line int, body string) string {
+	return fmt.Sprintf("%s\x00%d\x00%s", path, line, strings.TrimSpace(body))
+}
@@ -0,0 +1,196 @@
+package publish
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/git"
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func sampleFindings() []scanner.Finding {
+	return []scanner.Finding{
+		{Path: "./src/a.go", Line: 3, Column: 9, Severity: scanner.SeverityError, Message: "Detected CJK character \"日\" (U+65E5)", HelpURI: "https://wiki.example.com/i18n#cjk"},
+		{Path: "./src/a.go", Line: 3, Column: 10, Severity: scanner.SeverityError, Message: "Detected CJK character \"本\" (U+672C)"},
+		{Path: "src/b.go", Line: 1, Column: 1, Severity: scanner.SeverityWarning, Message: "Detected Latin Extended character \"é\" (U+00E9)"},
+		{Path: "src/c.go", Line: 7, Column: 2, Severity: scanner.SeverityError, Message: "Detected Cyrillic character \"ж\" (U+0436)"},
+	}
+}
+
+func TestGroupByLine(t *testing.T) {
+	comments := groupByLine(sampleFindings())
+	if len(comments) != 3 {
+		t.Fatalf("expected 3 grouped comments, got %d", len(comments))
+	}
+	if comments[0].Path != "src/a.go" || comments[0].Line != 3 {
+		t.Fatalf("unexpected first comment: %+v", comments[0])
+	}
+	if strings.Count(comments[0].Body, "\n- ") != 2 || !strings.Contains(comments[0].Body, "(U+65E5) (column 9) [How to fix](https://wiki.example.com/i18n#cjk)\n") || !strings.HasSuffix(comments[0].Body, Marker) {
+		t.Fatalf("unexpected body: %q", comments[0].Body)
+	}
+}
+
+func TestGitHubPublish(t *testing.T) {
+	existingBody := groupByLine(sampleFindings())[1].Body
+	var reviews []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"head":{"sha":"abc123"}}`))
+	})
+	mux.HandleFunc("/repos/o/r/pulls/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", `<next>; rel="next"`)
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"path": "src/x.go", "line": 1, "body": "human comment"},
+			})
+		default:
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"path": "src/b.go", "line": 0, "original_line": 1, "body": existingBody},
+			})
+		}
+	})
+	mux.HandleFunc("/repos/o/r/pulls/7/reviews", func(w http.ResponseWriter, r *http.Request) {
+		var review map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reviews = append(reviews, review)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	gh := GitHub{APIURL: srv.URL, Token: "tok", Repo: "o/r", PR: 7, Limit: 1, Client: srv.Client()}
+	result, err := gh.Publish(sampleFindings())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result != (Result{Posted: 1, Duplicates: 1, Limited: 1}) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(reviews) != 1 || reviews[0]["commit_id"] != "abc123" || reviews[0]["event"] != "COMMENT" {
+		t.Fatalf("unexpected reviews: %v", reviews)
+	}
+	comments := reviews[0]["comments"].([]interface{})
+	if first := comments[0].(map[string]interface{}); first["path"] != "src/a.go" || first["line"] != float64(3) {
+		t.Fatalf("unexpected comment: %v", first)
+	}
+
+	gh.Token = "bad"
+	if _, err := gh.Publish(sampleFindings()); err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Fatalf("expected HTTP 401 error, got %v", err)
+	}
+}
+
+func TestGitHubPublishBatches(t *testing.T) {
+	var batchSizes []int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"head":{"sha":"abc"}}`))
+	})
+	mux.HandleFunc("/repos/o/r/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/repos/o/r/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		var review struct {
+			Comments []githubComment `json:"comments"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		batchSizes = append(batchSizes, len(review.Comments))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var findings []scanner.Finding
+	for i := 1; i <= githubReviewBatch+5; i++ {
+		findings = append(findings, scanner.Finding{Path: "a.go", Line: i, Column: 1, Message: "m"})
+	}
+	result, err := GitHub{APIURL: srv.URL + "/", Token: "t", Repo: "o/r", PR: 1, Client: srv.Client()}.Publish(findings)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result.Posted != githubReviewBatch+5 || len(batchSizes) != 2 || batchSizes[0] != githubReviewBatch || batchSizes[1] != 5 {
+		t.Fatalf("unexpected batching: %+v %v", result, batchSizes)
+	}
+}
+
+func TestGitHubPublishValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		gh   GitHub
+		want string
+	}{
+		{name: "token", gh: GitHub{Repo: "o/r", PR: 1}, want: "GITHUB_TOKEN"},
+		{name: "repo", gh: GitHub{Token: "t", Repo: "repo", PR: 1}, want: "invalid repository"},
+		{name: "pr", gh: GitHub{Token: "t", Repo: "o/r"}, want: "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.gh.Publish(nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q error, got %v", tt.want, err)
+			}
+		})
+	}
+	if got := (GitHub{Repo: "o/r"}).url("/pulls/%d", 2); got != DefaultGitHubAPIURL+"/repos/o/r/pulls/2" {
+		t.Fatalf("unexpected default url: %s", got)
+	}
+}
+
+func TestGitHubPublishChangedLines(t *testing.T) {
+	var comments []githubComment
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"head":{"sha":"abc"},"base":{"sha":"def"}}`))
+	})
+	mux.HandleFunc("/repos/o/r/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/repos/o/r/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		var review struct {
+			Comments []githubComment `json:"comments"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		comments = append(comments, review.Comments...)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var base string
+	gh := GitHub{APIURL: srv.URL, Token: "t", Repo: "o/r", PR: 1, Client: srv.Client()}
+	gh.ChangedLines = func(rev string) (map[string][]git.LineRange, error) {
+		base = rev
+		return map[string][]git.LineRange{"src/a.go": {{Start: 2, End: 4}}, "src/b.go": {{Start: 5, End: 5}}}, nil
+	}
+	result, err := gh.Publish(sampleFindings())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if base != "def" || result.Posted != 1 || len(comments) != 1 || comments[0].Path != "src/a.go" || comments[0].Line != 3 || comments[0].Side != "RIGHT" {
+		t.Fatalf("unexpected comments for base %q: %+v %+v", base, result, comments)
+	}
+
+	comments = nil
+	gh.ChangedLines = func(string) (map[string][]git.LineRange, error) {
+		return nil, errors.New("bad revision")
+	}
+	result, err = gh.Publish(sampleFindings())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result.Posted != 3 || len(comments) != 3 {
+		t.Fatalf("expected one comment per file: %+v %+v", result, comments)
+	}
+	if c := comments[0]; c.Path != "src/a.go" || c.Line != 0 || c.SubjectType != "file" || !strings.Contains(c.Body, "(line 3, column 9)") || !strings.Contains(c.Body, "(line 3, column 10)") {
+		t.Fatalf("unexpected file comment: %+v", c)
+	}
+}
@@ -0,0 +1,179 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/TT-AIXion/englint/internal/git"
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// GitLab keeps merge request discussions in sync with findings: it opens a
+// discussion for each new finding line, updates discussions whose findings
+// changed, and resolves discussions whose findings are gone.
+type GitLab struct {
+	// APIURL is the v4 API root, e.g. CI_API_V4_URL.
+	APIURL string
+	Token  string
+	// Project is the numeric ID or "group/name" path of the project.
+	Project string
+	MR      int
+	// Limit caps the number of new discussions opened per run. Zero means
+	// no limit.
+	Limit int
+	// ChangedLines returns the lines of each file changed since the merge
+	// request's base commit, as git.ChangedSince does. Discussions can only
+	// be opened on those lines, so other findings are dropped. When it is
+	// nil, every finding is posted on its line.
+	ChangedLines func(base string) (map[string][]git.LineRange, error)
+	Client       *http.Client
+}
+
+type gitlabNote struct {
+	ID       int    `json:"id"`
+	Body     string `json:"body"`
+	Resolved bool   `json:"resolved"`
+	Position *struct {
+		NewPath string `json:"new_path"`
+		NewLine int    `json:"new_line"`
+	} `json:"position"`
+}
+
+type gitlabDiscussion struct {
+	ID    string       `json:"id"`
+	Notes []gitlabNote `json:"notes"`
+}
+
+// Publish synchronizes englint discussions on the merge request.
+func (g GitLab) Publish(findings []scanner.Finding) (Result, error) {
+	if g.Token == "" {
+		return Result{}, errors.New("GITLAB_TOKEN is not set")
+	}
+	if g.APIURL == "" || g.Project == "" {
+		return Result{}, errors.New("CI_API_V4_URL and CI_PROJECT_ID must be set")
+	}
+	if g.MR <= 0 {
+		return Result{}, errors.New("merge request IID must be positive")
+	}
+
+	var mr struct {
+		DiffRefs struct {
+			BaseSHA  string `json:"base_sha"`
+			HeadSHA  string `json:"head_sha"`
+			StartSHA string `json:"start_sha"`
+		} `json:"diff_refs"`
+	}
+	if _, err := doJSON(g.Client, http.MethodGet, g.url(""), g.headers(), nil, &mr); err != nil {
+		return Result{}, err
+	}
+	if g.ChangedLines != nil {
+		changed, err := g.ChangedLines(mr.DiffRefs.BaseSHA)
+		if err != nil {
+			return Result{}, fmt.Errorf("changed lines: %w", err)
+		}
+		findings = onChangedLines(findings, changed)
+	}
+
+	existing, err := g.discussions()
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	seen := make(map[string]struct{})
+	for _, c := range groupByLine(findings) {
+		key := commentKey(c.Path, c.Line, "")
+		seen[key] = struct{}{}
+		if d, ok := existing[key]; ok {
+			note := d.Notes[0]
+			switch {
+			case strings.TrimSpace(note.Body) != strings.TrimSpace(c.Body):
+				if _, err := doJSON(g.Client, http.MethodPut, g.url("/discussions/%s/notes/%d", d.ID, note.ID), g.headers(), map[string]string{"body": c.Body}, nil); err != nil {
+					return result, err
+				}
+				if note.Resolved {
+					if err := g.setResolved(d.ID, false); err != nil {
+						return result, err
+					}
+				}
+				result.Updated++
+			case note.Resolved:
+				if err := g.setResolved(d.ID, false); err != nil {
+					return result, err
+				}
+				result.Updated++
+			default:
+				result.Duplicates++
+			}
+			continue
+		}
+		if g.Limit > 0 && result.Posted >= g.Limit {
+			result.Limited++
+			continue
+		}
+		discussion := map[string]interface{}{
+			"body": c.Body,
+			"position": map[string]interface{}{
+				"position_type": "text",
+				"base_sha":      mr.DiffRefs.BaseSHA,
+				"head_sha":      mr.DiffRefs.HeadSHA,
+				"start_sha":     mr.DiffRefs.StartSHA,
+				"new_path":      c.Path,
+				"new_line":      c.Line,
+			},
+		}
+		if _, err := doJSON(g.Client, http.MethodPost, g.url("/discussions"), g.headers(), discussion, nil); err != nil {
+			return result, err
+		}
+		result.Posted++
+	}
+
+	for key, d := range existing {
+		if _, ok := seen[key]; ok || d.Notes[0].Resolved {
+			continue
+		}
+		if err := g.setResolved(d.ID, true); err != nil {
+			return result, err
+		}
+		result.Resolved++
+	}
+	return result, nil
+}
+
+// discussions returns englint discussions keyed by path and line.
+func (g GitLab) discussions() (map[string]gitlabDiscussion, error) {
+	out := make(map[string]gitlabDiscussion)
+	for page := "1"; page != ""; {
+		var discussions []gitlabDiscussion
+		header, err := doJSON(g.Client, http.MethodGet, g.url("/discussions?per_page=100&page=%s", page), g.headers(), nil, &discussions)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range discussions {
+			if len(d.Notes) == 0 || d.Notes[0].Position == nil || !strings.Contains(d.Notes[0].Body, Marker) {
+				continue
+			}
+			pos := d.Notes[0].Position
+			out[commentKey(pos.NewPath, pos.NewLine, "")] = d
+		}
+		page = header.Get("X-Next-Page")
+	}
+	return out, nil
+}
+
+func (g GitLab) setResolved(discussionID string, resolved bool) error {
+	_, err := doJSON(g.Client, http.MethodPut, g.url("/discussions/%s?resolved=%t", discussionID, resolved), g.headers(), nil, nil)
+	return err
+}
+
+func (g GitLab) url(format string, args ...interface{}) string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%d", strings.TrimRight(g.APIURL, "/"), url.PathEscape(g.Project), g.MR) +
+		fmt.Sprintf(format, args...)
+}
+
+func (g GitLab) headers() map[string]string {
+	return map[string]string{"PRIVATE-TOKEN": g.Token}
+}
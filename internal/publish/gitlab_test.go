@@ -0,0 +1,193 @@
+package publish
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/git"
+)
+
+func TestGitLabPublish(t *testing.T) {
+	comments := groupByLine(sampleFindings())
+	discussion := func(id string, noteID int, path string, line int, body string, resolved bool) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id,
+			"notes": []map[string]interface{}{{
+				"id": noteID, "body": body, "resolved": resolved,
+				"position": map[string]interface{}{"new_path": path, "new_line": line},
+			}},
+		}
+	}
+
+	var requests []string
+	var created []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/group%2Fproj/merge_requests/9", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"diff_refs":{"base_sha":"b","head_sha":"h","start_sha":"s"}}`))
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproj/merge_requests/9/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			_ = json.NewEncoder(w).Encode([]interface{}{
+				// Unchanged finding: left alone.
+				discussion("d1", 11, "src/a.go", 3, comments[0].Body, false),
+				// Not an englint discussion.
+				map[string]interface{}{"id": "human", "notes": []map[string]interface{}{{"id": 1, "body": "looks good"}}},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]interface{}{
+			// Finding text changed: note is updated.
+			discussion("d2", 22, "src/b.go", 1, "old\n"+Marker, false),
+			// Finding disappeared: discussion is resolved.
+			discussion("d3", 33, "src/gone.go", 4, "gone\n"+Marker, false),
+			// Already resolved and still gone: left alone.
+			discussion("d4", 44, "src/old.go", 5, "old\n"+Marker, true),
+		})
+	})
+	mux.HandleFunc("/api/v4/projects/group%2Fproj/merge_requests/9/discussions/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.RequestURI(), "/api/v4/projects/group%2Fproj/merge_requests/9"))
+		_, _ = w.Write([]byte(`{}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	gl := GitLab{APIURL: srv.URL + "/api/v4", Token: "tok", Project: "group/proj", MR: 9, Client: srv.Client()}
+	result, err := gl.Publish(sampleFindings())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result != (Result{Posted: 1, Updated: 1, Resolved: 1, Duplicates: 1}) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(created) != 1 {
+		t.Fatalf("expected one new discussion, got %v", created)
+	}
+	pos := created[0]["position"].(map[string]interface{})
+	if pos["new_path"] != "src/c.go" || pos["new_line"] != float64(7) || pos["head_sha"] != "h" {
+		t.Fatalf("unexpected position: %v", pos)
+	}
+	want := []string{"PUT /discussions/d2/notes/22", "PUT /discussions/d3?resolved=true"}
+	if strings.Join(requests, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected update requests: %v", requests)
+	}
+
+	gl.Limit = 1
+	requests = nil
+	created = nil
+	if result, err := gl.Publish(append(sampleFindings(), sampleFindings()[0])); err != nil || result.Posted != 1 {
+		t.Fatalf("unexpected limited result: %+v (%v)", result, err)
+	}
+
+	gl.Token = "bad"
+	if _, err := gl.Publish(sampleFindings()); err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Fatalf("expected HTTP 401 error, got %v", err)
+	}
+}
+
+func TestGitLabReopensResolvedDiscussion(t *testing.T) {
+	comments := groupByLine(sampleFindings()[3:])
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/1/merge_requests/2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/projects/1/merge_requests/2/discussions", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]interface{}{map[string]interface{}{
+			"id": "d1",
+			"notes": []map[string]interface{}{{
+				"id": 5, "body": comments[0].Body, "resolved": true,
+				"position": map[string]interface{}{"new_path": "src/c.go", "new_line": 7},
+			}},
+		}})
+	})
+	mux.HandleFunc("/projects/1/merge_requests/2/discussions/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	result, err := GitLab{APIURL: srv.URL, Token: "t", Project: "1", MR: 2, Client: srv.Client()}.Publish(sampleFindings()[3:])
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if result.Updated != 1 || len(requests) != 1 || requests[0] != "PUT /projects/1/merge_requests/2/discussions/d1?resolved=false" {
+		t.Fatalf("expected discussion to be reopened: %+v %v", result, requests)
+	}
+}
+
+func TestGitLabPublishChangedLines(t *testing.T) {
+	var created []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects/1/merge_requests/2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"diff_refs":{"base_sha":"b","head_sha":"h","start_sha":"s"}}`))
+	})
+	mux.HandleFunc("/projects/1/merge_requests/2/discussions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body)
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var base string
+	gl := GitLab{APIURL: srv.URL, Token: "t", Project: "1", MR: 2, Client: srv.Client()}
+	gl.ChangedLines = func(rev string) (map[string][]git.LineRange, error) {
+		base = rev
+		return map[string][]git.LineRange{"src/a.go": {{Start: 1, End: 2}}, "src/c.go": {{Start: 7, End: 7}}}, nil
+	}
+	result, err := gl.Publish(sampleFindings())
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if base != "b" || result.Posted != 1 || len(created) != 1 {
+		t.Fatalf("unexpected discussions for base %q: %+v %v", base, result, created)
+	}
+	if pos := created[0]["position"].(map[string]interface{}); pos["new_path"] != "src/c.go" || pos["new_line"] != float64(7) {
+		t.Fatalf("unexpected position: %v", pos)
+	}
+
+	gl.ChangedLines = func(string) (map[string][]git.LineRange, error) {
+		return nil, errors.New("bad revision")
+	}
+	if _, err := gl.Publish(sampleFindings()); err == nil || !strings.Contains(err.Error(), "changed lines: bad revision") {
+		t.Fatalf("expected changed lines error, got %v", err)
+	}
+}
+
+func TestGitLabPublishValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		gl   GitLab
+		want string
+	}{
+		{name: "token", gl: GitLab{APIURL: "u", Project: "1", MR: 1}, want: "GITLAB_TOKEN"},
+		{name: "project", gl: GitLab{Token: "t", MR: 1}, want: "CI_PROJECT_ID"},
+		{name: "mr", gl: GitLab{Token: "t", APIURL: "u", Project: "1"}, want: "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.gl.Publish(nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q error, got %v", tt.want, err)
+			}
+		})
+	}
+}
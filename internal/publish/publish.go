@@ -0,0 +1,191 @@
+// Package publish posts scan findings to code hosting platforms.
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TT-AIXion/englint/internal/git"
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// Marker is embedded in every published comment so englint can recognize
+// its own comments on later runs.
+const Marker = "<!-- englint -->"
+
+// Publisher sends findings to a code hosting platform.
+type Publisher interface {
+	Publish(findings []scanner.Finding) (Result, error)
+}
+
+// Result summarizes a publishing run.
+type Result struct {
+	Posted     int `json:"posted"`
+	Updated    int `json:"updated"`
+	Resolved   int `json:"resolved"`
+	Duplicates int `json:"duplicates"`
+	Limited    int `json:"limited"`
+}
+
+// lineComment groups all findings reported on a single line.
+type lineComment struct {
+	Path string
+	Line int
+	Body string
+}
+
+// groupByLine builds one comment per file line, ordered by path and line.
+func groupByLine(findings []scanner.Finding) []lineComment {
+	type key struct {
+		path string
+		line int
+	}
+	grouped := make(map[key][]scanner.Finding)
+	var keys []key
+	for _, f := range findings {
+		k := key{path: repoPath(f.Path), line: f.Line}
+		if _, ok := grouped[k]; !ok {
+			keys = append(keys, k)
+		}
+		grouped[k] = append(grouped[k], f)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		return keys[i].line < keys[j].line
+	})
+
+	out := make([]lineComment, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, lineComment{Path: k.path, Line: k.line, Body: commentBody(grouped[k], false)})
+	}
+	return out
+}
+
+// groupByFile builds one comment per file, ordered by path, whose body
+// gives the line of each finding. Its Line is zero.
+func groupByFile(findings []scanner.Finding) []lineComment {
+	grouped := make(map[string][]scanner.Finding)
+	var paths []string
+	for _, f := range findings {
+		path := repoPath(f.Path)
+		if _, ok := grouped[path]; !ok {
+			paths = append(paths, path)
+		}
+		grouped[path] = append(grouped[path], f)
+	}
+	sort.Strings(paths)
+
+	out := make([]lineComment, 0, len(paths))
+	for _, path := range paths {
+		out = append(out, lineComment{Path: path, Body: commentBody(grouped[path], true)})
+	}
+	return out
+}
+
+// onChangedLines keeps the findings on the lines in changed, which is keyed
+// by repository path as returned by git.ChangedSince.
+func onChangedLines(findings []scanner.Finding, changed map[string][]git.LineRange) []scanner.Finding {
+	var out []scanner.Finding
+	for _, f := range findings {
+		for _, r := range changed[repoPath(f.Path)] {
+			if r.Contains(f.Line) {
+				out = append(out, f)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// commentBody lists findings with their columns, and with their lines too
+// when withLine is set.
+func commentBody(findings []scanner.Finding, withLine bool) string {
+	var b strings.Builder
+	b.WriteString("**englint**")
+	b.WriteByte('\n')
+	for _, f := range findings {
+		if withLine {
+			fmt.Fprintf(&b, "\n- %s: %s (line %d, column %d)", f.Severity, f.Message, f.Line, f.Column)
+		} else {
+			fmt.Fprintf(&b, "\n- %s: %s (column %d)", f.Severity, f.Message, f.Column)
+		}
+		if f.HelpURI != "" {
+			fmt.Fprintf(&b, " [How to fix](%s)", f.HelpURI)
+		}
+	}
+	b.WriteString("\n\n")
+	b.WriteString(Marker)
+	return b.String()
+}
+
+// repoPath converts a display path into the slash-separated form used by
+// hosting APIs.
+func repoPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+// apiError reports a non-2xx response from a hosting API.
+type apiError struct {
+	Method string
+	URL    string
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.URL, e.Status, strings.TrimSpace(e.Body))
+}
+
+// doJSON sends body (if non-nil) as JSON and decodes a JSON response into out
+// (if non-nil). It returns the response headers for pagination.
+func doJSON(client *http.Client, method, url string, headers map[string]string, body, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &apiError{Method: method, URL: url, Status: resp.StatusCode, Body: string(data)}
+	}
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("%s %s: decode response: %w", method, url, err)
+		}
+	}
+	return resp.Header, nil
+}
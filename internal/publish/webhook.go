@@ -0,0 +1,50 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// Webhook posts a scan summary to a Slack, Teams, or generic JSON webhook.
+type Webhook struct {
+	URL string
+	// IncludeFindings attaches the full finding list to the payload.
+	IncludeFindings bool
+	Client          *http.Client
+}
+
+// WebhookPayload is the JSON body sent to the webhook. The text field makes
+// it directly usable with Slack and Teams incoming webhooks.
+type WebhookPayload struct {
+	Text     string            `json:"text"`
+	Summary  scanner.Summary   `json:"summary"`
+	Findings []scanner.Finding `json:"findings,omitempty"`
+}
+
+// Notify sends the summary of result. It does nothing when the scan
+// reported no findings.
+func (w Webhook) Notify(result scanner.Result) error {
+	if w.URL == "" {
+		return errors.New("webhook URL is not set")
+	}
+	if result.Summary.Findings == 0 {
+		return nil
+	}
+
+	files := make(map[string]struct{})
+	for _, f := range result.Findings {
+		files[f.Path] = struct{}{}
+	}
+	payload := WebhookPayload{
+		Text:    fmt.Sprintf("englint found %d non-English character(s) in %d file(s).", result.Summary.Findings, len(files)),
+		Summary: result.Summary,
+	}
+	if w.IncludeFindings {
+		payload.Findings = result.Findings
+	}
+	_, err := doJSON(w.Client, http.MethodPost, w.URL, nil, payload, nil)
+	return err
+}
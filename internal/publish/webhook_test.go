@@ -0,0 +1,71 @@
+package publish
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var payloads []WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer srv.Close()
+
+	result := scanner.Result{Summary: scanner.Summary{FilesScanned: 3, Findings: 4}, Findings: sampleFindings()}
+	tests := []struct {
+		name         string
+		hook         Webhook
+		result       scanner.Result
+		wantPayloads int
+		wantFindings int
+	}{
+		{name: "summary only", hook: Webhook{URL: srv.URL}, result: result, wantPayloads: 1},
+		{name: "with findings", hook: Webhook{URL: srv.URL, IncludeFindings: true}, result: result, wantPayloads: 1, wantFindings: 4},
+		{name: "clean scan", hook: Webhook{URL: srv.URL}, result: scanner.Result{}, wantPayloads: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloads = nil
+			if err := tt.hook.Notify(tt.result); err != nil {
+				t.Fatalf("notify: %v", err)
+			}
+			if len(payloads) != tt.wantPayloads {
+				t.Fatalf("expected %d payloads, got %d", tt.wantPayloads, len(payloads))
+			}
+			if tt.wantPayloads == 0 {
+				return
+			}
+			if payloads[0].Text != "englint found 4 non-English character(s) in 3 file(s)." || payloads[0].Summary.FilesScanned != 3 {
+				t.Fatalf("unexpected payload: %+v", payloads[0])
+			}
+			if len(payloads[0].Findings) != tt.wantFindings {
+				t.Fatalf("expected %d findings, got %d", tt.wantFindings, len(payloads[0].Findings))
+			}
+		})
+	}
+
+	if err := (Webhook{}).Notify(result); err == nil {
+		t.Fatalf("expected missing URL error")
+	}
+	if err := (Webhook{URL: srv.URL + "/%zz"}).Notify(result); err == nil {
+		t.Fatalf("expected invalid URL error")
+	}
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer failing.Close()
+	if err := (Webhook{URL: failing.URL}).Notify(result); err == nil || !strings.Contains(err.Error(), "HTTP 410") {
+		t.Fatalf("expected HTTP 410 error, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TT-AIXion/englint/internal/uniname"
+)
+
+// Built-in categories for characters that deserve a clearer message than
+// "Other Unicode".
+const (
+	// categoryInvisible holds variation selectors and tag characters. Both
+	// render as nothing: variation selectors pick the emoji or text style of
+	// the preceding character, and tag characters mirror ASCII, so a run of
+	// them can carry hidden text past a reviewer.
+	categoryInvisible = "Invisible Character"
+	// categoryPrivateUse holds Private Use Area code points, which have no
+	// meaning outside the font that defines them and are usually icon-font
+	// glyphs pasted from design tools.
+	categoryPrivateUse = "Private Use"
+	// categoryConfusableLatin holds Mathematical Alphanumeric Symbols such
+	// as 𝐛𝐨𝐥𝐝 and 𝘪𝘵𝘢𝘭𝘪𝘤, which are pasted as styled text but are not
+	// the Latin letters and digits they look like to search or a compiler.
+	categoryConfusableLatin = "Confusable Latin"
+	// categoryCurrency holds currency signs such as € and ₹, which belong
+	// in prices and finance code rather than untranslated text.
+	categoryCurrency = "Currency"
+	// categoryMeasurement holds measurementSymbols.
+	categoryMeasurement = "Measurement"
+	// categoryFormat holds formatCharacters.
+	categoryFormat = "Format Character"
+)
+
+// formatCharacters are discretionary and zero width formatting characters
+// such as the soft hyphen and zero width space. They render as nothing, or
+// only at a line break, so text containing them looks unchanged but no
+// longer matches in string comparisons, search, or a compiler.
+var formatCharacters = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00AD, Hi: 0x00AD, Stride: 1}, // soft hyphen
+		{Lo: 0x034F, Hi: 0x034F, Stride: 1}, // combining grapheme joiner
+		{Lo: 0x180E, Hi: 0x180E, Stride: 1}, // Mongolian vowel separator
+		{Lo: 0x200B, Hi: 0x200D, Stride: 1}, // zero width space, non-joiner, joiner
+		{Lo: 0x2060, Hi: 0x2064, Stride: 1}, // word joiner and invisible operators
+		{Lo: 0xFEFF, Hi: 0xFEFF, Stride: 1}, // zero width no-break space
+	},
+	LatinOffset: 1,
+}
+
+// IsFormatCharacter reports whether r is an invisible formatting character
+// of the Format Character category, whose fix is removing it.
+func IsFormatCharacter(r rune) bool {
+	return unicode.Is(formatCharacters, r)
+}
+
+// measurementSymbols are the unit, degree, and exponent signs of
+// measurements such as 20 °C, 5 µm, 3 m², and 2 ‰, including the squared
+// unit ligatures of the CJK Compatibility block such as ㎏. The era names
+// ㍻ through ㍾, ㍿ for "corporation", and ㏂ and ㏘ for a.m. and p.m. in
+// that block are not units.
+var measurementSymbols = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00B0, Hi: 0x00B3, Stride: 1}, // ° ± ² ³
+		{Lo: 0x00B5, Hi: 0x00B5, Stride: 1}, // µ
+		{Lo: 0x00B9, Hi: 0x00B9, Stride: 1}, // ¹
+		{Lo: 0x2030, Hi: 0x2033, Stride: 1}, // ‰ ‱ ′ ″
+		{Lo: 0x2103, Hi: 0x2103, Stride: 1}, // ℃
+		{Lo: 0x2109, Hi: 0x2109, Stride: 1}, // ℉
+		{Lo: 0x2126, Hi: 0x2126, Stride: 1}, // Ω ohm sign
+		{Lo: 0x212B, Hi: 0x212B, Stride: 1}, // Å angstrom sign
+		{Lo: 0x3371, Hi: 0x337A, Stride: 1}, // ㍱ through ㍺
+		{Lo: 0x3380, Hi: 0x33C1, Stride: 1}, // ㎀ through ㏁
+		{Lo: 0x33C3, Hi: 0x33D7, Stride: 1}, // ㏃ through ㏗
+		{Lo: 0x33D9, Hi: 0x33DF, Stride: 1}, // ㏙ through ㏟
+	},
+	LatinOffset: 3,
+}
+
+// categoryUmbrellas map categories to a broader category that policies,
+// --severity overrides, message templates, help links, and allow patterns
+// can name to cover them all, such as CJK for Han, Kana, and Hangul.
+var categoryUmbrellas = map[string]string{
+	"han":    "CJK",
+	"kana":   "CJK",
+	"hangul": "CJK",
+}
+
+// InCategory reports whether category is name, ignoring case, or falls
+// under the umbrella category name.
+func InCategory(category, name string) bool {
+	return strings.EqualFold(category, name) || strings.EqualFold(categoryUmbrellas[strings.ToLower(category)], name)
+}
+
+// categoryEntry returns the entry for category in m, which is keyed by
+// lower-case category: its own, else its umbrella category's, else the "*"
+// entry.
+func categoryEntry[V any](m map[string]V, category string) (V, bool) {
+	category = strings.ToLower(category)
+	if v, ok := m[category]; ok {
+		return v, true
+	}
+	if umbrella, ok := categoryUmbrellas[category]; ok {
+		if v, ok := m[strings.ToLower(umbrella)]; ok {
+			return v, true
+		}
+	}
+	v, ok := m["*"]
+	return v, ok
+}
+
+// scriptCategories name the categories of scripts without a case of their
+// own in categoryForRune, so they are not lumped into "Other Unicode".
+var scriptCategories = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Armenian", unicode.Armenian},
+	{"Georgian", unicode.Georgian},
+	{"Ethiopic", unicode.Ethiopic},
+	{"Syriac", unicode.Syriac},
+	{"Thaana", unicode.Thaana},
+	{"NKo", unicode.Nko},
+	{"Bengali", unicode.Bengali},
+	{"Gurmukhi", unicode.Gurmukhi},
+	{"Gujarati", unicode.Gujarati},
+	{"Oriya", unicode.Oriya},
+	{"Tamil", unicode.Tamil},
+	{"Telugu", unicode.Telugu},
+	{"Kannada", unicode.Kannada},
+	{"Malayalam", unicode.Malayalam},
+	{"Sinhala", unicode.Sinhala},
+	{"Tibetan", unicode.Tibetan},
+	{"Lao", unicode.Lao},
+	{"Myanmar", unicode.Myanmar},
+	{"Khmer", unicode.Khmer},
+	{"Mongolian", unicode.Mongolian},
+	{"Tagalog", unicode.Tagalog},
+	{"Javanese", unicode.Javanese},
+	{"Balinese", unicode.Balinese},
+	{"Sundanese", unicode.Sundanese},
+	{"Bopomofo", unicode.Bopomofo},
+	{"Yi", unicode.Yi},
+	{"Cherokee", unicode.Cherokee},
+	{"Canadian Syllabics", unicode.Canadian_Aboriginal},
+	{"Tifinagh", unicode.Tifinagh},
+	{"Vai", unicode.Vai},
+	{"Ol Chiki", unicode.Ol_Chiki},
+	{"Adlam", unicode.Adlam},
+}
+
+// scriptCategory returns the scriptCategories name of r, if any.
+func scriptCategory(r rune) (string, bool) {
+	for _, script := range scriptCategories {
+		if unicode.Is(script.table, r) {
+			return script.name, true
+		}
+	}
+	return "", false
+}
+
+// letterlikeASCII maps the Letterlike Symbols that fill the holes of the
+// Mathematical Alphanumeric Symbols block, such as italic ℎ, to ASCII.
+var letterlikeASCII = map[rune]rune{
+	'ℂ': 'C', 'ℊ': 'g', 'ℋ': 'H', 'ℌ': 'H', 'ℍ': 'H', 'ℎ': 'h', 'ℐ': 'I', 'ℑ': 'I',
+	'ℒ': 'L', 'ℕ': 'N', 'ℙ': 'P', 'ℚ': 'Q', 'ℛ': 'R', 'ℜ': 'R', 'ℝ': 'R', 'ℤ': 'Z',
+	'ℨ': 'Z', 'ℬ': 'B', 'ℭ': 'C', 'ℯ': 'e', 'ℰ': 'E', 'ℱ': 'F', 'ℳ': 'M', 'ℴ': 'o',
+}
+
+// confusableASCII returns the ASCII letter or digit that r, a mathematical
+// alphanumeric symbol, is styled from. Mathematical Greek letters have none.
+func confusableASCII(r rune) (rune, bool) {
+	switch {
+	case r >= 0x1D400 && r <= 0x1D6A3:
+		// 13 styles of A-Z followed by a-z.
+		i := (r - 0x1D400) % 52
+		if i < 26 {
+			return 'A' + i, true
+		}
+		return 'a' + i - 26, true
+	case r == 0x1D6A4:
+		return 'i', true
+	case r == 0x1D6A5:
+		return 'j', true
+	case r >= 0x1D7CE && r <= 0x1D7FF:
+		// 5 styles of 0-9.
+		return '0' + (r-0x1D7CE)%10, true
+	}
+	ascii, ok := letterlikeASCII[r]
+	return ascii, ok
+}
+
+func isConfusableLatin(r rune) bool {
+	_, ok := confusableASCII(r)
+	return ok
+}
+
+// isVariationSelector reports whether r is in Variation Selectors
+// (U+FE00-FE0F) or Variation Selectors Supplement (U+E0100-E01EF).
+func isVariationSelector(r rune) bool {
+	return (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0xE0100 && r <= 0xE01EF)
+}
+
+// isTagRune reports whether r is in the Tags block (U+E0000-E007F).
+func isTagRune(r rune) bool {
+	return r >= 0xE0000 && r <= 0xE007F
+}
+
+// categoryMessage describes a finding of a built-in category at codePoint,
+// such as "U+E0041", or returns "" for the default message. The code point
+// survives excerpt redaction, unlike the character.
+func categoryMessage(category, codePoint string) string {
+	v, _ := strconv.ParseInt(strings.TrimPrefix(codePoint, "U+"), 16, 32)
+	r := rune(v)
+	switch {
+	case category == categoryPrivateUse:
+		return fmt.Sprintf("Detected Private Use character (%s), likely an icon-font glyph that renders only with its font", codePoint)
+	case category == categoryConfusableLatin:
+		ascii, _ := confusableASCII(r)
+		return fmt.Sprintf("Detected mathematical symbol (%s) styled to look like Latin %q", codePoint, ascii)
+	case category == categoryFormat:
+		return fmt.Sprintf("Detected invisible %s (%s), which breaks string comparisons", strings.ToLower(uniname.Name(r)), codePoint)
+	case category != categoryInvisible:
+		return ""
+	case isVariationSelector(r):
+		return fmt.Sprintf("Detected invisible variation selector (%s)", codePoint)
+	case r >= 0xE0020 && r <= 0xE007E:
+		// Tags U+E0020-E007E mirror the printable ASCII characters.
+		return fmt.Sprintf("Detected invisible tag character (%s) hiding %q", codePoint, r-0xE0000)
+	default:
+		return fmt.Sprintf("Detected invisible tag character (%s)", codePoint)
+	}
+}
@@ -0,0 +1,313 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// editorConfig is a parsed .editorconfig file: the charset of each section
+// glob, in file order.
+type editorConfig struct {
+	root     bool
+	sections []editorConfigSection
+}
+
+type editorConfigSection struct {
+	pattern *regexp.Regexp
+	charset string
+}
+
+// editorConfigs caches the .editorconfig file of each directory, nil when a
+// directory has none.
+type editorConfigs map[string]*editorConfig
+
+// charset returns the .editorconfig charset that applies to the file at the
+// absolute path abs, or "" when none is declared. Files closer to abs win,
+// and a file with root = true stops the search.
+func (ec editorConfigs) charset(abs string) (string, error) {
+	var chain []string
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		cfg, err := ec.load(dir)
+		if err != nil {
+			return "", err
+		}
+		if cfg != nil {
+			chain = append(chain, dir)
+			if cfg.root {
+				break
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	charset := ""
+	for i := len(chain) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(chain[i], abs)
+		if err != nil {
+			return "", err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, section := range ec[chain[i]].sections {
+			if section.charset != "" && section.pattern.MatchString(rel) {
+				charset = section.charset
+			}
+		}
+	}
+	return charset, nil
+}
+
+func (ec editorConfigs) load(dir string) (*editorConfig, error) {
+	if cfg, ok := ec[dir]; ok {
+		return cfg, nil
+	}
+	path := filepath.Join(dir, ".editorconfig")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		ec[dir] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	cfg := parseEditorConfig(data)
+	ec[dir] = cfg
+	return cfg, nil
+}
+
+// parseEditorConfig reads the root flag and the charset of each section,
+// ignoring other properties and sections whose glob cannot be compiled.
+func parseEditorConfig(data []byte) *editorConfig {
+	cfg := &editorConfig{}
+	var section *editorConfigSection
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = nil
+			if re, err := editorConfigGlob(line[1 : len(line)-1]); err == nil {
+				cfg.sections = append(cfg.sections, editorConfigSection{pattern: re})
+				section = &cfg.sections[len(cfg.sections)-1]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch {
+		case key == "root" && len(cfg.sections) == 0:
+			cfg.root = value == "true"
+		case key == "charset" && section != nil:
+			section.charset = value
+		}
+	}
+	return cfg
+}
+
+// editorConfigGlob compiles an EditorConfig section glob. Globs without a
+// slash match files in any directory; *, **, ?, [...], and {a,b} work as in
+// the EditorConfig specification.
+func editorConfigGlob(glob string) (*regexp.Regexp, error) {
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	glob = strings.TrimPrefix(glob, "/")
+	var b strings.Builder
+	b.WriteString("^")
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		ch := glob[i]
+		switch {
+		case ch == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case ch == '*':
+			b.WriteString("[^/]*")
+		case ch == '?':
+			b.WriteString("[^/]")
+		case ch == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case ch == '{':
+			braces++
+			b.WriteString("(?:")
+		case ch == '}' && braces > 0:
+			braces--
+			b.WriteString(")")
+		case ch == ',' && braces > 0:
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// utf8BOM is the byte order mark of utf-8-bom files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeCharset returns a reader that decodes in from an .editorconfig
+// charset to UTF-8, dropping any byte order mark, and describes how the
+// start of the content disagrees with the charset, if it does. Unknown
+// charsets are read as UTF-8.
+func decodeCharset(in *bufio.Reader, charset string) (io.Reader, string) {
+	head, _ := in.Peek(binarySniffSize)
+	switch charset {
+	case "utf-8":
+		if bytes.HasPrefix(head, utf8BOM) {
+			return in, "has a UTF-8 byte order mark"
+		}
+	case "utf-8-bom":
+		if !bytes.HasPrefix(head, utf8BOM) {
+			return in, "has no UTF-8 byte order mark"
+		}
+		_, _ = in.Discard(len(utf8BOM))
+	case "latin1":
+		mismatch := ""
+		if !isASCII(head) && utf8.Valid(trimPartialRune(head)) {
+			mismatch = "is valid UTF-8"
+		}
+		return &decodingReader{in: in, next: nextLatin1}, mismatch
+	case "utf-16le", "utf-16be":
+		bigEndian := charset == "utf-16be"
+		mismatch := ""
+		switch {
+		case bytes.HasPrefix(head, []byte{0xFF, 0xFE}) && bigEndian, bytes.HasPrefix(head, []byte{0xFE, 0xFF}) && !bigEndian:
+			mismatch = "has a byte order mark of the other byte order"
+		case bytes.HasPrefix(head, []byte{0xFF, 0xFE}), bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+			_, _ = in.Discard(2)
+		}
+		return &decodingReader{in: in, next: func(in *bufio.Reader) (rune, error) { return nextUTF16(in, bigEndian) }}, mismatch
+	}
+	return in, ""
+}
+
+// decodingReader encodes the runes next decodes from in as UTF-8.
+type decodingReader struct {
+	in      *bufio.Reader
+	next    func(*bufio.Reader) (rune, error)
+	pending []byte
+	err     error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			c := copy(p[n:], d.pending)
+			d.pending = d.pending[c:]
+			n += c
+			continue
+		}
+		if d.err != nil {
+			break
+		}
+		r, err := d.next(d.in)
+		if err != nil {
+			d.err = err
+			continue
+		}
+		d.pending = utf8.AppendRune(d.pending[:0], r)
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, d.err
+}
+
+func nextLatin1(in *bufio.Reader) (rune, error) {
+	b, err := in.ReadByte()
+	return rune(b), err
+}
+
+// nextUTF16 decodes one code point, joining surrogate pairs and replacing
+// unpaired surrogates and a trailing odd byte with U+FFFD.
+func nextUTF16(in *bufio.Reader, bigEndian bool) (rune, error) {
+	unit, err := readUTF16Unit(in, bigEndian)
+	if err != nil {
+		return 0, err
+	}
+	r := rune(unit)
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+	if next, err := in.Peek(2); err == nil {
+		low := rune(uint16(next[0]) | uint16(next[1])<<8)
+		if bigEndian {
+			low = rune(uint16(next[0])<<8 | uint16(next[1]))
+		}
+		if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+			_, _ = in.Discard(2)
+			return pair, nil
+		}
+	}
+	return utf8.RuneError, nil
+}
+
+func readUTF16Unit(in *bufio.Reader, bigEndian bool) (uint16, error) {
+	var unit [2]byte
+	n, err := io.ReadFull(in, unit[:])
+	switch {
+	case n == 1:
+		return utf8.RuneError, nil
+	case err != nil:
+		return 0, err
+	case bigEndian:
+		return uint16(unit[0])<<8 | uint16(unit[1]), nil
+	default:
+		return uint16(unit[0]) | uint16(unit[1])<<8, nil
+	}
+}
+
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence cut off at the end of
+// data.
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditorConfigGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		path string
+		want bool
+	}{
+		{glob: "*", path: "a/b.txt", want: true},
+		{glob: "*.txt", path: "b.txt", want: true},
+		{glob: "*.txt", path: "a/b/c.txt", want: true},
+		{glob: "*.{md,rst}", path: "docs/a.rst", want: true},
+		{glob: "*.{md,rst}", path: "docs/a.txt"},
+		{glob: "docs/*.md", path: "docs/a.md", want: true},
+		{glob: "docs/*.md", path: "src/docs/a.md"},
+		{glob: "/docs/**.md", path: "docs/a/b.md", want: true},
+		{glob: "lib/**/*.js", path: "lib/a.js", want: true},
+		{glob: "file[0-9].txt", path: "file7.txt", want: true},
+		{glob: "file[!0-9].txt", path: "file7.txt"},
+		{glob: "a?.txt", path: "ab.txt", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+"|"+tt.path, func(t *testing.T) {
+			re, err := editorConfigGlob(tt.glob)
+			if err != nil {
+				t.Fatalf("editorConfigGlob: %v", err)
+			}
+			if got := re.MatchString(tt.path); got != tt.want {
+				t.Fatalf("match = %v, want %v (%s)", got, tt.want, re)
+			}
+		})
+	}
+}
+
+func TestEditorConfigCharset(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		".editorconfig":         "root = true\n[*]\ncharset = utf-8\n[*.txt]\ncharset = latin1\n",
+		"sub/.editorconfig":     "# comment\n[legacy/*.txt]\ncharset = UTF-16LE\n",
+		"nested/.editorconfig":  "root = true\n[*.md]\nindent_size = 2\n",
+		"nested/other.go":       "",
+		"sub/legacy/a.txt":      "",
+		"sub/b.txt":             "",
+		"sub/legacy/deep/c.txt": "",
+		"nested/README.md":      "",
+		"sub/legacy/notes.md":   "",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "sub/legacy/a.txt", want: "utf-16le"},
+		{path: "sub/b.txt", want: "latin1"},
+		{path: "sub/legacy/deep/c.txt", want: "latin1"},
+		{path: "sub/legacy/notes.md", want: "utf-8"},
+		{path: "nested/README.md"},
+		{path: "nested/other.go"},
+	}
+	configs := editorConfigs{}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := configs.charset(filepath.Join(tmp, tt.path))
+			if err != nil || got != tt.want {
+				t.Fatalf("charset = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		charset  string
+		data     []byte
+		want     string
+		mismatch string
+	}{
+		{name: "utf-8", charset: "utf-8", data: []byte("é"), want: "é"},
+		{name: "utf-8 with bom", charset: "utf-8", data: []byte("\xef\xbb\xbfa"), want: "\ufeffa", mismatch: "has a UTF-8 byte order mark"},
+		{name: "utf-8-bom", charset: "utf-8-bom", data: []byte("\xef\xbb\xbfé"), want: "é"},
+		{name: "utf-8-bom without bom", charset: "utf-8-bom", data: []byte("a"), want: "a", mismatch: "has no UTF-8 byte order mark"},
+		{name: "latin1", charset: "latin1", data: []byte("caf\xe9 \xa9"), want: "café ©"},
+		{name: "latin1 holding utf-8", charset: "latin1", data: []byte("caf\xc3\xa9"), want: "cafÃ©", mismatch: "is valid UTF-8"},
+		{name: "utf-16le", charset: "utf-16le", data: []byte("\xff\xfea\x00-N=\xd8\x00\xde"), want: "a中😀"},
+		{name: "utf-16be", charset: "utf-16be", data: []byte("\x00aN-\xd8"), want: "a中\ufffd"},
+		{name: "utf-16be unpaired", charset: "utf-16be", data: []byte("\xd8\x3d\x00a"), want: "\ufffda"},
+		{name: "utf-16be with le bom", charset: "utf-16be", data: []byte("\xff\xfe\x00a"), want: "\ufffea", mismatch: "has a byte order mark of the other byte order"},
+		{name: "unknown", charset: "koi8-r", data: []byte("a"), want: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mismatch := decodeCharset(bufio.NewReader(bytes.NewReader(tt.data)), tt.charset)
+			got, err := io.ReadAll(r)
+			if err != nil || string(got) != tt.want || mismatch != tt.mismatch {
+				t.Fatalf("decodeCharset = %q, %q, %v, want %q, %q", got, mismatch, err, tt.want, tt.mismatch)
+			}
+		})
+	}
+}
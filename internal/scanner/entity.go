@@ -0,0 +1,117 @@
+package scanner
+
+import "bytes"
+
+// namedEntities maps the HTML named character references that commonly sneak
+// into documentation to the characters they render as. ASCII-only entities
+// such as &amp; are left out since they never produce findings.
+var namedEntities = map[string]rune{
+	"nbsp":   '\u00a0',
+	"ensp":   '\u2002',
+	"emsp":   '\u2003',
+	"thinsp": '\u2009',
+	"zwsp":   '\u200b',
+	"zwnj":   '\u200c',
+	"zwj":    '\u200d',
+	"lrm":    '\u200e',
+	"rlm":    '\u200f',
+	"shy":    '\u00ad',
+	"copy":   '©',
+	"reg":    '®',
+	"trade":  '™',
+	"hellip": '…',
+	"ndash":  '–',
+	"mdash":  '—',
+	"lsquo":  '‘',
+	"rsquo":  '’',
+	"sbquo":  '‚',
+	"ldquo":  '“',
+	"rdquo":  '”',
+	"bdquo":  '„',
+	"laquo":  '«',
+	"raquo":  '»',
+	"bull":   '•',
+	"middot": '·',
+	"deg":    '°',
+	"times":  '×',
+	"divide": '÷',
+	"plusmn": '±',
+	"minus":  '−',
+	"larr":   '←',
+	"rarr":   '→',
+	"uarr":   '↑',
+	"darr":   '↓',
+	"harr":   '↔',
+	"lArr":   '⇐',
+	"rArr":   '⇒',
+	"euro":   '€',
+	"pound":  '£',
+	"yen":    '¥',
+	"cent":   '¢',
+	"sect":   '§',
+	"para":   '¶',
+	"iexcl":  '¡',
+	"iquest": '¿',
+	"eacute": 'é',
+	"egrave": 'è',
+	"aacute": 'á',
+	"agrave": 'à',
+	"ouml":   'ö',
+	"uuml":   'ü',
+	"auml":   'ä',
+	"szlig":  'ß',
+	"ntilde": 'ñ',
+	"ccedil": 'ç',
+}
+
+// asciiEquivalents maps characters to the ASCII text a policy-compliant
+// document would use instead. Invisible characters have no entry since the
+// fix for them is deleting the reference.
+var asciiEquivalents = map[rune]string{
+	'\u00a0': " ",
+	'\u2002': " ",
+	'\u2003': " ",
+	'\u2009': " ",
+	'©':      "(c)",
+	'®':      "(R)",
+	'™':      "(TM)",
+	'…':      "...",
+	'–':      "-",
+	'—':      "--",
+	'−':      "-",
+	'‘':      "'",
+	'’':      "'",
+	'‚':      "'",
+	'“':      "\"",
+	'”':      "\"",
+	'„':      "\"",
+	'«':      "<<",
+	'»':      ">>",
+	'•':      "*",
+	'·':      "*",
+	'×':      "x",
+	'÷':      "/",
+	'±':      "+/-",
+	'←':      "<-",
+	'→':      "->",
+	'↔':      "<->",
+	'⇐':      "<=",
+	'⇒':      "=>",
+}
+
+// decodeEntity decodes the HTML character reference at the start of head:
+// &name;, &#xX...;, or &#D...;. It returns the rune and the reference length.
+func decodeEntity(head []byte) (rune, int, bool) {
+	if len(head) < 2 || head[0] != '&' {
+		return 0, 0, false
+	}
+	if head[1] == '#' {
+		return decodeEscape(head)
+	}
+	end := bytes.IndexByte(head, ';')
+	if end < 2 {
+		return 0, 0, false
+	}
+	r, ok := namedEntities[string(head[1:end])]
+	return r, end + 1, ok
+}
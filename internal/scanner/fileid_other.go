@@ -0,0 +1,11 @@
+//go:build !unix
+
+package scanner
+
+import "os"
+
+// fileKeyOf identifies the file behind info by its absolute path; hard links
+// are not recognized on this platform.
+func fileKeyOf(abs string, _ os.FileInfo) fileKey {
+	return fileKey{path: abs}
+}
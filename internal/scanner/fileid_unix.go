@@ -0,0 +1,17 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileKeyOf identifies the file behind info by device and inode, so hard
+// links and bind mounts of one file share a key.
+func fileKeyOf(abs string, info os.FileInfo) fileKey {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileKey{dev: uint64(st.Dev), ino: uint64(st.Ino)}
+	}
+	return fileKey{path: abs}
+}
@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// fileTypePeekBytes is how much of an extensionless file syntaxForFile reads
+// for a shebang line or modeline.
+const fileTypePeekBytes = 1024
+
+// modelineLines is how many leading lines are searched for a modeline.
+const modelineLines = 5
+
+// interpreterExtensions maps interpreters and editor modes to the file
+// extension whose syntax rules their scripts use.
+var interpreterExtensions = map[string]string{
+	"python":       ".py",
+	"ruby":         ".rb",
+	"sh":           ".sh",
+	"bash":         ".sh",
+	"zsh":          ".sh",
+	"dash":         ".sh",
+	"ksh":          ".sh",
+	"fish":         ".sh",
+	"shell-script": ".sh",
+	"perl":         ".sh",
+	"node":         ".js",
+	"nodejs":       ".js",
+	"javascript":   ".js",
+	"js":           ".js",
+	"deno":         ".ts",
+	"bun":          ".ts",
+	"ts-node":      ".ts",
+	"tsx":          ".ts",
+	"typescript":   ".ts",
+	"php":          ".php",
+	"lua":          ".lua",
+	"luajit":       ".lua",
+	"sql":          ".sql",
+	"yaml":         ".yaml",
+	"toml":         ".toml",
+	"conf":         ".conf",
+	"dockerfile":   ".dockerfile",
+}
+
+// syntaxForFile returns the syntax rules of path, falling back for
+// extensionless files to the interpreter of a shebang line or the file type
+// of an Emacs or Vim modeline in the first lines of in.
+func syntaxForFile(path string, in *bufio.Reader) syntaxRules {
+	if filepath.Ext(path) != "" {
+		return syntaxForPath(path)
+	}
+	head, _ := in.Peek(fileTypePeekBytes)
+	if ext, ok := fileTypeExtension(head); ok {
+		return syntaxForPath("file" + ext)
+	}
+	return syntaxForPath(path)
+}
+
+// fileTypeExtension returns the extension for the file type declared in
+// head, preferring a shebang line over modelines.
+func fileTypeExtension(head []byte) (string, bool) {
+	lines := bytes.SplitN(head, []byte("\n"), modelineLines+1)
+	if len(lines) > modelineLines {
+		lines = lines[:modelineLines]
+	}
+	if line, ok := bytes.CutPrefix(lines[0], []byte("#!")); ok {
+		if ext, ok := interpreterExtension(shebangInterpreter(string(line))); ok {
+			return ext, true
+		}
+	}
+	for _, line := range lines {
+		if mode, ok := modelineMode(string(line)); ok {
+			if ext, ok := interpreterExtension(mode); ok {
+				return ext, true
+			}
+		}
+	}
+	return "", false
+}
+
+// shebangInterpreter returns the interpreter of a shebang line such as
+// "/usr/bin/env -S python3 -u", skipping env with its options and variable
+// assignments.
+func shebangInterpreter(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := filepath.Base(fields[0])
+	if name != "env" {
+		return name
+	}
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+			continue
+		}
+		return filepath.Base(field)
+	}
+	return ""
+}
+
+// modelineMode returns the file type set by an Emacs "-*- mode: python -*-"
+// or Vim "vim: set ft=python:" modeline in line.
+func modelineMode(line string) (string, bool) {
+	if _, rest, ok := strings.Cut(line, "-*-"); ok {
+		vars, _, ok := strings.Cut(rest, "-*-")
+		if !ok {
+			return "", false
+		}
+		if !strings.Contains(vars, ":") {
+			return strings.TrimSpace(vars), true
+		}
+		for _, v := range strings.Split(vars, ";") {
+			key, value, _ := strings.Cut(v, ":")
+			if strings.EqualFold(strings.TrimSpace(key), "mode") {
+				return strings.TrimSpace(value), true
+			}
+		}
+		return "", false
+	}
+	for _, marker := range []string{"vim:", "vi:", "ex:"} {
+		i := strings.Index(line, marker)
+		if i < 0 || (i > 0 && line[i-1] != ' ' && line[i-1] != '\t') {
+			continue
+		}
+		options := strings.FieldsFunc(line[i+len(marker):], func(r rune) bool { return r == ' ' || r == '\t' || r == ':' })
+		for _, option := range options {
+			key, value, ok := strings.Cut(option, "=")
+			if ok && (key == "ft" || key == "filetype" || key == "syn" || key == "syntax") {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// interpreterExtension looks up an interpreter or mode name, ignoring case
+// and version suffixes such as "python3.12".
+func interpreterExtension(name string) (string, bool) {
+	name = strings.TrimRight(strings.ToLower(name), "0123456789.")
+	ext, ok := interpreterExtensions[name]
+	return ext, ok
+}
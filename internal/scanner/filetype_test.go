@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileTypeExtension(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{name: "env", head: "#!/usr/bin/env python3\nprint()\n", want: ".py"},
+		{name: "env options", head: "#!/usr/bin/env -S NODE_OPTIONS=--x node --harmony\n", want: ".js"},
+		{name: "absolute", head: "#!/bin/bash -e\n", want: ".sh"},
+		{name: "versioned", head: "#! /usr/local/bin/python3.12\n", want: ".py"},
+		{name: "unknown interpreter", head: "#!/usr/bin/awk -f\n"},
+		{name: "emacs mode", head: "#!/usr/bin/awk -f\n# -*- mode: ruby; coding: utf-8 -*-\n", want: ".rb"},
+		{name: "emacs short", head: "-- -*- lua -*-\n", want: ".lua"},
+		{name: "vim set", head: "x\n# vim: set ts=2 ft=sh :\n", want: ".sh"},
+		{name: "vim filetype", head: "// vi:filetype=javascript\n", want: ".js"},
+		{name: "modeline too late", head: "1\n2\n3\n4\n5\n# vim: ft=python\n"},
+		{name: "no marker", head: "# envi: ft=python\n"},
+		{name: "empty", head: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fileTypeExtension([]byte(tt.head))
+			if got != tt.want || ok != (tt.want != "") {
+				t.Fatalf("fileTypeExtension = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxForFile(t *testing.T) {
+	tests := []struct {
+		path string
+		head string
+		want syntaxRules
+	}{
+		{path: "bin/deploy", head: "#!/usr/bin/env bash\n", want: syntaxForPath("a.sh")},
+		{path: "bin/tool.txt", head: "#!/usr/bin/env bash\n", want: syntaxForPath("a.txt")},
+		{path: "Dockerfile", head: "FROM scratch\n", want: syntaxForPath("Dockerfile")},
+		{path: "bin/notes", head: "plain text\n", want: syntaxRules{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := syntaxForFile(tt.path, bufio.NewReader(strings.NewReader(tt.head)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("syntaxForFile = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
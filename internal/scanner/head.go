@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// HeadLimit scans only the start of the files matching Pattern, such as
+// giant generated SQL seeds, so they are sampled instead of scanned in full
+// or skipped. Bytes and Lines cap the decoded content; zero means no limit.
+type HeadLimit struct {
+	Pattern string
+	Bytes   int64
+	Lines   int
+}
+
+// TruncatedFile records a file of which only the first Bytes bytes and
+// Lines lines were scanned because of a HeadLimit.
+type TruncatedFile struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Lines int    `json:"lines"`
+}
+
+// headLimit returns the last HeadLimit matching path.
+func headLimit(path string, limits []HeadLimit) (HeadLimit, bool) {
+	for i := len(limits) - 1; i >= 0; i-- {
+		if matches(path, []string{limits[i].Pattern}) {
+			return limits[i], true
+		}
+	}
+	return HeadLimit{}, false
+}
+
+// headReader reads the content of a file up to a HeadLimit. A byte limit
+// ends at the last complete UTF-8 sequence, so a character cut in half is
+// not reported as invalid UTF-8.
+type headReader struct {
+	in *bufio.Reader
+	// bytesLeft and linesLeft are negative without a limit.
+	bytesLeft int64
+	linesLeft int
+	done      bool
+	truncated bool
+	// n and lines count what was read.
+	n     int64
+	lines int
+	last  byte
+}
+
+func newHeadReader(r io.Reader, limit HeadLimit) *headReader {
+	h := &headReader{in: bufio.NewReaderSize(r, readBufferSize), bytesLeft: -1, linesLeft: -1}
+	if limit.Bytes > 0 {
+		h.bytesLeft = limit.Bytes
+	}
+	if limit.Lines > 0 {
+		h.linesLeft = limit.Lines
+	}
+	return h
+}
+
+func (h *headReader) Read(p []byte) (int, error) {
+	if h.done {
+		return 0, io.EOF
+	}
+	if h.bytesLeft >= 0 && int64(len(p)) > h.bytesLeft {
+		p = p[:h.bytesLeft]
+	}
+	n, err := h.in.Read(p)
+	if h.linesLeft >= 0 {
+		for i, b := range p[:n] {
+			if b != '\n' {
+				continue
+			}
+			h.linesLeft--
+			if h.linesLeft == 0 {
+				h.truncated = i+1 < n
+				n = i + 1
+				h.done = true
+				break
+			}
+		}
+	}
+	if h.bytesLeft >= 0 && !h.done {
+		h.bytesLeft -= int64(n)
+		if h.bytesLeft == 0 {
+			h.done = true
+			if cut := completeRunes(p[:n]); cut < n {
+				n = cut
+				h.truncated = true
+			}
+		}
+	}
+	if h.done && !h.truncated {
+		_, peekErr := h.in.Peek(1)
+		h.truncated = peekErr == nil
+	}
+	for _, b := range p[:n] {
+		if b == '\n' {
+			h.lines++
+		}
+	}
+	if n > 0 {
+		h.n += int64(n)
+		h.last = p[n-1]
+	}
+	if h.done && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// scannedLines returns the number of lines read, counting a final line
+// without a line break.
+func (h *headReader) scannedLines() int {
+	if h.n > 0 && h.last != '\n' {
+		return h.lines + 1
+	}
+	return h.lines
+}
+
+// completeRunes returns the length of the longest prefix of p that does not
+// end in an incomplete UTF-8 sequence.
+func completeRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// latexPeekBytes is how far latexStep looks ahead; it covers the longest
+// environment name in latexEnvironments.
+const latexPeekBytes = 64
+
+// latexEnvironments maps the LaTeX environments englint treats specially to
+// the state of their content: math, verbatim code, or comments.
+var latexEnvironments = map[string]scanState{
+	"math":        stateMath,
+	"displaymath": stateMath,
+	"equation":    stateMath,
+	"equation*":   stateMath,
+	"align":       stateMath,
+	"align*":      stateMath,
+	"alignat":     stateMath,
+	"alignat*":    stateMath,
+	"gather":      stateMath,
+	"gather*":     stateMath,
+	"multline":    stateMath,
+	"multline*":   stateMath,
+	"flalign":     stateMath,
+	"flalign*":    stateMath,
+	"eqnarray":    stateMath,
+	"eqnarray*":   stateMath,
+	"verbatim":    stateCodeBlock,
+	"verbatim*":   stateCodeBlock,
+	"Verbatim":    stateCodeBlock,
+	"lstlisting":  stateCodeBlock,
+	"minted":      stateCodeBlock,
+	"comment":     stateBlockComment,
+}
+
+// latexStep advances through LaTeX markup, reporting true when it consumed
+// a token. Control symbols such as \% and \$ are skipped, so only a bare %
+// opens a comment; $...$, $$...$$, \(...\), \[...\], and math environments
+// enter stateMath; and \verb and verbatim environments are code blocks. It
+// peeks further ahead than run, so it runs before head is taken.
+func (c *contentScanner) latexStep() bool {
+	if c.state != stateCode && c.state != stateMath && c.latexEnd == "" {
+		return false
+	}
+	head, _ := c.in.Peek(latexPeekBytes)
+	if len(head) == 0 {
+		return false
+	}
+	if c.latexEnd != "" {
+		if bytes.HasPrefix(head, []byte(c.latexEnd)) {
+			c.skipToken(c.latexEnd)
+			c.latexEnd = ""
+			c.state = stateCode
+			return true
+		}
+		if c.state != stateMath {
+			return false
+		}
+	}
+	if head[0] == '$' {
+		if c.state == stateMath {
+			return false
+		}
+		c.latexEnd = "$"
+		if bytes.HasPrefix(head, []byte("$$")) {
+			c.latexEnd = "$$"
+		}
+		c.skipToken(c.latexEnd)
+		c.state = stateMath
+		return true
+	}
+	if head[0] != '\\' || len(head) < 2 {
+		return false
+	}
+	if c.state == stateCode {
+		switch head[1] {
+		case '(':
+			c.openLatex(`\(`, `\)`, stateMath)
+			return true
+		case '[':
+			c.openLatex(`\[`, `\]`, stateMath)
+			return true
+		}
+	}
+	if head[1] >= utf8.RuneSelf {
+		// A control symbol cannot be a multibyte rune, so only the
+		// backslash is skipped and the rune after it is inspected.
+		c.skipToken(`\`)
+		return true
+	}
+	if !isASCIILetter(head[1]) {
+		c.skipToken(string(head[:2]))
+		return true
+	}
+	if c.state != stateCode {
+		return false
+	}
+	if bytes.HasPrefix(head, []byte(`\verb`)) && len(head) > 6 && !isASCIILetter(head[5]) {
+		open := 5
+		if head[5] == '*' {
+			open = 6
+		}
+		c.openLatex(string(head[:open+1]), string(head[open]), stateCodeBlock)
+		return true
+	}
+	if !bytes.HasPrefix(head, []byte(`\begin{`)) {
+		return false
+	}
+	end := bytes.IndexByte(head, '}')
+	if end < 0 {
+		return false
+	}
+	name := string(head[len(`\begin{`):end])
+	state, ok := latexEnvironments[name]
+	if !ok {
+		return false
+	}
+	c.openLatex(string(head[:end+1]), `\end{`+name+`}`, state)
+	return true
+}
+
+func (c *contentScanner) openLatex(open, end string, state scanState) {
+	c.skipToken(open)
+	c.latexEnd = end
+	c.state = state
+}
+
+// isMathRune reports whether r is a symbol or letter math notation uses,
+// which is not reported inside LaTeX math.
+func isMathRune(r rune) bool {
+	return unicode.IsSymbol(r) || unicode.IsPunct(r) || unicode.In(r, unicode.Greek) || (r >= 0x1D400 && r <= 0x1D7FF)
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// translatedLocale reports whether path is a mobile string resource for a
+// non-English locale: XML under an Android res/values-<locale> directory, or
+// a .strings or .stringsdict file under an iOS <locale>.lproj directory other
+// than en.lproj and Base.lproj. Such files hold translations and are skipped
+// like allowed files, while the default-locale variants are still scanned.
+func translatedLocale(path string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	if len(parts) < 2 {
+		return "", false
+	}
+	dir := parts[len(parts)-2]
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		if len(parts) < 3 || parts[len(parts)-3] != "res" || !strings.HasPrefix(dir, "values-") {
+			return "", false
+		}
+		lang := androidLanguage(strings.Split(dir, "-")[1:])
+		return lang, lang != "" && lang != "en"
+	case ".strings", ".stringsdict":
+		name, ok := strings.CutSuffix(dir, ".lproj")
+		if !ok || name == "Base" {
+			return "", false
+		}
+		lang, _, _ := strings.Cut(strings.ReplaceAll(name, "_", "-"), "-")
+		return name, !strings.EqualFold(lang, "en")
+	}
+	return "", false
+}
+
+// androidLanguage returns the language of Android resource qualifiers such
+// as "mcc310-fr-rCA-night" or "b+sr+Latn", skipping the MCC and MNC
+// qualifiers that may precede it. Qualifiers without a language, such as
+// "night" or "v21", return "".
+func androidLanguage(qualifiers []string) string {
+	for _, q := range qualifiers {
+		if strings.HasPrefix(q, "mcc") || strings.HasPrefix(q, "mnc") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(q, "b+"); ok {
+			lang, _, _ := strings.Cut(rest, "+")
+			return strings.ToLower(lang)
+		}
+		if len(q) == 2 && q[0] >= 'a' && q[0] <= 'z' && q[1] >= 'a' && q[1] <= 'z' {
+			return q
+		}
+		return ""
+	}
+	return ""
+}
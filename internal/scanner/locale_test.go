@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTranslatedLocale(t *testing.T) {
+	tests := []struct {
+		path   string
+		locale string
+		want   bool
+	}{
+		{path: "app/src/main/res/values/strings.xml"},
+		{path: "app/src/main/res/values-fr/strings.xml", locale: "fr", want: true},
+		{path: "app/src/main/res/values-zh-rCN/plurals.xml", locale: "zh", want: true},
+		{path: "res/values-b+sr+Latn/strings.xml", locale: "sr", want: true},
+		{path: "res/values-mcc310-ja/strings.xml", locale: "ja", want: true},
+		{path: "res/values-en-rGB/strings.xml", locale: "en"},
+		{path: "res/values-night/colors.xml"},
+		{path: "res/values-v21/styles.xml"},
+		{path: "docs/values-fr/strings.xml"},
+		{path: "res/values-fr/notes.txt"},
+		{path: "App/en.lproj/Localizable.strings", locale: "en"},
+		{path: "App/en-GB.lproj/Localizable.strings", locale: "en-GB"},
+		{path: "App/Base.lproj/Localizable.strings"},
+		{path: "App/zh-Hans.lproj/Localizable.stringsdict", locale: "zh-Hans", want: true},
+		{path: "App/de.lproj/Main.storyboard"},
+		{path: "Localizable.strings"},
+	}
+	for _, tt := range tests {
+		locale, ok := translatedLocale(tt.path)
+		if ok != tt.want || (ok && locale != tt.locale) {
+			t.Errorf("translatedLocale(%q) = %q, %v; want %q, %v", tt.path, locale, ok, tt.locale, tt.want)
+		}
+	}
+}
+
+func TestScanSkipsTranslatedLocales(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"res/values/strings.xml":               "<string name=\"a\">Café</string>\n",
+		"res/values-ja/strings.xml":            "<string name=\"a\">カフェ</string>\n",
+		"App/Base.lproj/Localizable.strings":   "\"a\" = \"Café\";\n",
+		"App/fr.lproj/Localizable.strings":     "\"a\" = \"Café\";\n",
+		"App/fr.lproj/Localizable.stringsdict": "<string>Café</string>\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := Scan([]string{dir}, Options{Include: []string{"**/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var skipped []string
+	for _, s := range res.SkippedFiles {
+		rel, _ := filepath.Rel(dir, s.Path)
+		skipped = append(skipped, filepath.ToSlash(rel)+": "+s.Describe())
+	}
+	want := []string{
+		"App/fr.lproj/Localizable.strings: suppressed: translated locale resource",
+		"App/fr.lproj/Localizable.stringsdict: suppressed: translated locale resource",
+		"res/values-ja/strings.xml: suppressed: translated locale resource",
+	}
+	if !reflect.DeepEqual(skipped, want) || len(res.Findings) != 2 {
+		t.Fatalf("skipped = %q, findings = %d", skipped, len(res.Findings))
+	}
+
+	explained, err := Explain(filepath.Join(dir, "res/values-ja/strings.xml"), Options{})
+	if err != nil || explained.Scanned || explained.Rule != "locale" {
+		t.Fatalf("Explain() = %+v, %v", explained, err)
+	}
+}
@@ -0,0 +1,404 @@
+package scanner
+
+import "bytes"
+
+// markupKind selects the line-oriented block rules of a documentation format.
+type markupKind int
+
+const (
+	markupNone markupKind = iota
+	markupMarkdown
+	markupRST
+	markupAsciiDoc
+	markupGettext
+	markupComponent
+)
+
+// docPeekBytes is how much of a line startDocLine inspects; block delimiters
+// and directives are recognized well before that.
+const docPeekBytes = 256
+
+// rstCodeDirectives are the reStructuredText directives whose content is
+// code rather than prose.
+var rstCodeDirectives = map[string]bool{
+	"code":           true,
+	"code-block":     true,
+	"sourcecode":     true,
+	"literalinclude": true,
+	"highlight":      true,
+	"math":           true,
+	"raw":            true,
+	"doctest":        true,
+	"testcode":       true,
+	"testoutput":     true,
+}
+
+// startDocLine sets the scan state of the line about to be scanned in a
+// documentation file: code blocks become stateCodeBlock and comments
+// stateBlockComment, so IgnoreCodeBlocks and IgnoreComments split code from
+// prose the way they do in source files.
+func (c *contentScanner) startDocLine() {
+	line, _ := c.in.Peek(docPeekBytes)
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	line = bytes.TrimRight(line, " \t\r")
+	trimmed := bytes.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+	c.docLineIndent = indent
+	c.docDirective = false
+	if c.syntax.markup == markupComponent {
+		c.startComponentLine(trimmed)
+		return
+	}
+
+	if c.docFence != "" {
+		c.state = c.docState
+		if c.closesFence(trimmed) {
+			c.docFence = ""
+		}
+		return
+	}
+	if c.docIndent >= 0 {
+		if len(trimmed) == 0 || indent > c.docIndent {
+			c.state = c.docState
+			return
+		}
+		c.docIndent = -1
+	}
+	c.state = stateCode
+
+	switch c.syntax.markup {
+	case markupMarkdown:
+		if fence := markdownFence(trimmed); fence != "" {
+			c.openFence(fence, stateCodeBlock)
+		}
+	case markupRST:
+		if !bytes.HasPrefix(trimmed, []byte("..")) || (len(trimmed) > 2 && trimmed[2] != ' ') {
+			return
+		}
+		c.docDirective = true
+		name, isDirective := rstDirective(trimmed)
+		switch {
+		case isDirective && rstCodeDirectives[name]:
+			c.openIndent(indent, stateCodeBlock)
+		case isDirective:
+		case len(trimmed) > 3 && bytes.ContainsAny(trimmed[3:4], "_[|"):
+			// Hyperlink targets, footnotes, and substitutions are prose.
+		default:
+			c.openIndent(indent, stateBlockComment)
+		}
+	case markupAsciiDoc:
+		switch {
+		case isDelimiterLine(trimmed, '-', 4), isDelimiterLine(trimmed, '.', 4), isDelimiterLine(trimmed, '`', 3):
+			c.openFence(string(trimmed), stateCodeBlock)
+		case isDelimiterLine(trimmed, '/', 4):
+			c.openFence(string(trimmed), stateBlockComment)
+		case bytes.HasPrefix(trimmed, []byte("//")) && !bytes.HasPrefix(trimmed, []byte("///")):
+			c.state = stateBlockComment
+		}
+	case markupGettext:
+		c.startGettextLine(trimmed)
+	}
+}
+
+// startGettextLine classifies a line of a gettext catalog. msgid, msgctxt,
+// and msgid_plural strings are scanned like source text, while msgstr
+// translations and "# " translator comments are skipped. Continuation lines
+// belong to the keyword above them; obsolete "#~" msgids and other "#"
+// comments are comments.
+func (c *contentScanner) startGettextLine(trimmed []byte) {
+	entry := trimmed
+	obsolete := bytes.HasPrefix(entry, []byte("#~"))
+	if obsolete {
+		entry = bytes.TrimLeft(entry[2:], " ")
+	}
+	switch {
+	case bytes.HasPrefix(entry, []byte("msgstr")):
+		c.docState = stateSkipped
+	case bytes.HasPrefix(entry, []byte("msgid")), bytes.HasPrefix(entry, []byte("msgctxt")):
+		c.docState = stateCode
+		if obsolete {
+			c.docState = stateBlockComment
+		}
+	case len(entry) > 0 && entry[0] == '"':
+	case len(trimmed) == 0:
+		return
+	case trimmed[0] == '#' && (len(trimmed) == 1 || trimmed[1] == ' '):
+		c.state = stateSkipped
+		return
+	case trimmed[0] == '#':
+		c.state = stateBlockComment
+		return
+	}
+	c.state = c.docState
+}
+
+// endDocLine opens a reStructuredText literal block after a paragraph line
+// ending in "::".
+func (c *contentScanner) endDocLine() {
+	if c.syntax.markup == markupRST && c.state == stateCode && !c.docDirective && c.docTail == [2]byte{':', ':'} {
+		c.docIndent = c.docLineIndent
+		c.docState = stateCodeBlock
+	}
+	c.docTail = [2]byte{}
+}
+
+func (c *contentScanner) openFence(fence string, state scanState) {
+	c.docFence = fence
+	c.docState = state
+	c.state = state
+}
+
+func (c *contentScanner) openIndent(indent int, state scanState) {
+	c.docIndent = indent
+	c.docState = state
+	c.state = state
+}
+
+// closesFence reports whether trimmed closes the open fenced block. Markdown
+// fences close on a run of at least as many fence characters; AsciiDoc
+// delimiters must repeat exactly.
+func (c *contentScanner) closesFence(trimmed []byte) bool {
+	if c.syntax.markup == markupMarkdown {
+		return len(trimmed) >= len(c.docFence) && isDelimiterLine(trimmed, c.docFence[0], len(c.docFence))
+	}
+	return string(trimmed) == c.docFence
+}
+
+// markdownFence returns the opening run of a ``` or ~~~ code fence.
+func markdownFence(trimmed []byte) string {
+	if len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == trimmed[0] {
+		n++
+	}
+	if n < 3 || (trimmed[0] == '`' && bytes.IndexByte(trimmed[n:], '`') >= 0) {
+		return ""
+	}
+	return string(trimmed[:n])
+}
+
+// isDelimiterLine reports whether line is at least min repetitions of b.
+func isDelimiterLine(line []byte, b byte, min int) bool {
+	if len(line) < min {
+		return false
+	}
+	for _, x := range line {
+		if x != b {
+			return false
+		}
+	}
+	return true
+}
+
+// rstDirective returns the name of the directive in a ".. name::" line.
+func rstDirective(trimmed []byte) (string, bool) {
+	rest := bytes.TrimLeft(trimmed[2:], " ")
+	end := bytes.Index(rest, []byte("::"))
+	if end <= 0 {
+		return "", false
+	}
+	name := rest[:end]
+	for _, b := range name {
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '-' || b == '_' || b == ':' || b == '.') {
+			return "", false
+		}
+	}
+	return string(bytes.ToLower(name)), true
+}
+
+// svgTextElements are the SVG elements whose content is rendered or read as
+// text; everything else, such as path data and attributes, is skipped.
+var svgTextElements = map[string]bool{
+	"text":  true,
+	"title": true,
+	"desc":  true,
+}
+
+// svgStep advances through SVG markup. It consumes tags and comment
+// delimiters, reporting true when it did, and otherwise sets the state of
+// the byte at head: code inside text elements and skipped elsewhere.
+func (c *contentScanner) svgStep(head []byte) bool {
+	if c.state == stateBlockComment {
+		if bytes.HasPrefix(head, []byte("-->")) {
+			c.skipToken("-->")
+			c.state = c.svgContentState()
+			return true
+		}
+		return false
+	}
+	if c.svgTag {
+		b := head[0]
+		switch {
+		case c.svgQuote != 0:
+			if b == c.svgQuote {
+				c.svgQuote = 0
+			}
+		case b == '"' || b == '\'':
+			c.svgQuote = b
+		case b == '>':
+			if c.svgOpen && c.svgPrev == '/' {
+				c.svgDepth--
+			}
+			c.svgTag = false
+			c.skipToken(">")
+			c.state = c.svgContentState()
+			return true
+		}
+		c.svgPrev = b
+		return false
+	}
+	if head[0] != '<' {
+		c.state = c.svgContentState()
+		return false
+	}
+	if bytes.HasPrefix(head, []byte("<!--")) {
+		c.skipToken("<!--")
+		c.state = stateBlockComment
+		return true
+	}
+	name := head[1:]
+	closing := len(name) > 0 && name[0] == '/'
+	if closing {
+		name = name[1:]
+	}
+	end := 0
+	for end < len(name) && (isSchemeByte(name[end]) || name[end] == ':' || name[end] == '_') {
+		end++
+	}
+	local := string(name[:end])
+	if i := bytes.LastIndexByte(name[:end], ':'); i >= 0 {
+		local = string(name[i+1 : end])
+	}
+	c.svgOpen = false
+	if svgTextElements[local] {
+		if closing {
+			c.svgDepth = max(c.svgDepth-1, 0)
+		} else {
+			c.svgDepth++
+			c.svgOpen = true
+		}
+	}
+	c.svgTag = true
+	c.svgQuote = 0
+	c.svgPrev = '<'
+	c.state = stateSkipped
+	c.skipToken("<")
+	return true
+}
+
+func (c *contentScanner) svgContentState() scanState {
+	if c.svgDepth > 0 {
+		return stateCode
+	}
+	return stateSkipped
+}
+
+// Single-file component regions and the syntax rules applied to each.
+var (
+	componentTemplate = syntaxRules{blockStart: "<!--", blockEnd: "-->", entities: true, markup: markupComponent}
+	componentScript   = syntaxRules{lineComments: []string{"//"}, blockStart: "/*", blockEnd: "*/", strings: true, backtick: true, markup: markupComponent}
+	componentStyle    = syntaxRules{blockStart: "/*", blockEnd: "*/", strings: true, markup: markupComponent}
+)
+
+// startComponentLine switches the region of a Vue or Svelte single-file
+// component at lines opening or closing a <script> or <style> block. The
+// opening tag line already belongs to the new region and the closing tag
+// line to the template again.
+func (c *contentScanner) startComponentLine(trimmed []byte) {
+	switch {
+	case c.region != "template" && (bytes.HasPrefix(trimmed, []byte("</script")) || bytes.HasPrefix(trimmed, []byte("</style"))):
+		c.enterRegion("template", componentTemplate)
+	case c.region == "template" && bytes.HasPrefix(trimmed, []byte("<script")):
+		c.enterRegion("script", componentScript)
+	case c.region == "template" && bytes.HasPrefix(trimmed, []byte("<style")):
+		style := componentStyle
+		if bytes.Contains(trimmed, []byte(`lang="scss"`)) || bytes.Contains(trimmed, []byte(`lang="less"`)) {
+			style.lineComments = []string{"//"}
+		}
+		c.enterRegion("style", style)
+	}
+}
+
+func (c *contentScanner) enterRegion(region string, syntax syntaxRules) {
+	c.region = region
+	c.syntax = syntax
+	c.state = stateCode
+	c.escaped = false
+}
+
+// templateDelim is a template construct: an expression or, when comment is
+// set, a template comment.
+type templateDelim struct {
+	open, close string
+	comment     bool
+}
+
+// Template engine constructs, longest opener first since they share
+// prefixes.
+var (
+	goTemplateDelims = []templateDelim{
+		{open: "{{- /*", close: "*/ -}}", comment: true},
+		{open: "{{/*", close: "*/}}", comment: true},
+		{open: "{{", close: "}}"},
+	}
+	jinjaDelims = []templateDelim{
+		{open: "{#", close: "#}", comment: true},
+		{open: "{{", close: "}}"},
+		{open: "{%", close: "%}"},
+	}
+	erbDelims = []templateDelim{
+		{open: "<%#", close: "%>", comment: true},
+		{open: "<%", close: "%>"},
+	}
+	handlebarsDelims = []templateDelim{
+		{open: "{{!--", close: "--}}", comment: true},
+		{open: "{{!", close: "}}", comment: true},
+		{open: "{{", close: "}}"},
+	}
+)
+
+// templateText returns the rules of the text around template constructs,
+// and templateExpression those of the expressions inside them.
+func templateText(delims []templateDelim) syntaxRules {
+	return syntaxRules{templates: delims}
+}
+
+func templateExpression(delims []templateDelim) syntaxRules {
+	return syntaxRules{strings: true, templates: delims}
+}
+
+// templateStep moves between the "text" and "expression" regions of a
+// template file, consuming the delimiters of template constructs and
+// reporting true when it did. Template comments are block comments in the
+// expression region.
+func (c *contentScanner) templateStep(head []byte) bool {
+	if c.templateClose != "" {
+		if (c.state != stateCode && c.state != stateBlockComment) || !bytes.HasPrefix(head, []byte(c.templateClose)) {
+			return false
+		}
+		c.skipToken(c.templateClose)
+		c.templateClose = ""
+		c.enterRegion("text", templateText(c.syntax.templates))
+		return true
+	}
+	if c.state != stateCode {
+		return false
+	}
+	for _, delim := range c.syntax.templates {
+		if !bytes.HasPrefix(head, []byte(delim.open)) {
+			continue
+		}
+		c.skipToken(delim.open)
+		c.templateClose = delim.close
+		c.enterRegion("expression", templateExpression(c.syntax.templates))
+		if delim.comment {
+			c.state = stateBlockComment
+		}
+		return true
+	}
+	return false
+}
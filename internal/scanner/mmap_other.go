@@ -0,0 +1,13 @@
+//go:build !unix
+
+package scanner
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is unsupported on this platform; callers fall back to streaming.
+func mapFile(*os.File, int64) ([]byte, func() error, error) {
+	return nil, nil, errors.New("mmap is not supported on this platform")
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package scanner
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"syscall"
+)
+
+// mapFile memory-maps size bytes of f read-only. The returned function
+// unmaps the region and must be called once scanning is done. Files larger
+// than an int can hold, 2 GiB and up on 32-bit platforms, are not mapped,
+// so callers stream them instead.
+func mapFile(f *os.File, size int64) ([]byte, func() error, error) {
+	if size > math.MaxInt {
+		return nil, nil, fmt.Errorf("mmap: %d bytes do not fit in the address space", size)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
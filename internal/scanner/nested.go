@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NestedConfigName is the name of the config files picked up in
+// subdirectories of the pattern root while walking.
+const NestedConfigName = ".englint.yaml"
+
+// NestedConfig holds the additions a config file in a subdirectory makes
+// for the files beneath it, like a nested .gitignore.
+type NestedConfig struct {
+	// Exclude patterns are relative to the config file's directory.
+	Exclude []string
+	// AllowRunes are allowed in addition to Options.AllowRunes.
+	AllowRunes map[rune]struct{}
+}
+
+// nestedConfig is a loaded nested config and the directory it applies to.
+type nestedConfig struct {
+	dir string
+	NestedConfig
+}
+
+// nestedConfigs caches the nested config of each directory, nil when a
+// directory has none.
+type nestedConfigs map[string]*nestedConfig
+
+// chain returns the nested configs that apply beneath the absolute
+// directory dir, outermost first: those of dir and its parents below
+// opts.Root. The config at opts.Root itself is the main config.
+func (nc nestedConfigs) chain(dir string, opts Options) ([]*nestedConfig, error) {
+	if opts.LoadNestedConfig == nil {
+		return nil, nil
+	}
+	var chain []*nestedConfig
+	for ; ; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(opts.Root, dir)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			break
+		}
+		cfg, err := nc.load(dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		if cfg != nil {
+			chain = append([]*nestedConfig{cfg}, chain...)
+		}
+	}
+	return chain, nil
+}
+
+func (nc nestedConfigs) load(dir string, opts Options) (*nestedConfig, error) {
+	if cfg, ok := nc[dir]; ok {
+		return cfg, nil
+	}
+	path := filepath.Join(dir, NestedConfigName)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		nc[dir] = nil
+		return nil, nil
+	}
+	loaded, err := opts.LoadNestedConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("nested config %s: %w", path, err)
+	}
+	cfg := &nestedConfig{dir: dir, NestedConfig: loaded}
+	nc[dir] = cfg
+	return cfg, nil
+}
+
+// nestedExcluded reports whether a nested config in chain excludes the
+// absolute path.
+func nestedExcluded(path string, chain []*nestedConfig) bool {
+	for _, cfg := range chain {
+		rel, err := filepath.Rel(cfg.dir, path)
+		if err == nil && isExcluded(filepath.ToSlash(rel), cfg.Exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedAllowRunes returns opts.AllowRunes plus those of the nested configs
+// in chain.
+func nestedAllowRunes(opts Options, chain []*nestedConfig) map[rune]struct{} {
+	allow := opts.AllowRunes
+	cloned := false
+	for _, cfg := range chain {
+		if len(cfg.AllowRunes) == 0 {
+			continue
+		}
+		if !cloned {
+			allow = maps.Clone(opts.AllowRunes)
+			cloned = true
+		}
+		maps.Copy(allow, cfg.AllowRunes)
+	}
+	return allow
+}
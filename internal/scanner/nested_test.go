@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadTestNestedConfig reads nested configs written as "exclude <glob>" and
+// "allow <char>" lines.
+func loadTestNestedConfig(path string) (NestedConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NestedConfig{}, err
+	}
+	cfg := NestedConfig{AllowRunes: map[rune]struct{}{}}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "exclude":
+			cfg.Exclude = append(cfg.Exclude, value)
+		case "allow":
+			cfg.AllowRunes[[]rune(value)[0]] = struct{}{}
+		default:
+			return NestedConfig{}, errors.New("bad line " + line)
+		}
+	}
+	return cfg, nil
+}
+
+func TestScanNestedConfigs(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		NestedConfigName:                "exclude **",
+		"team/" + NestedConfigName:      "allow é\nexclude gen/**",
+		"team/deep/" + NestedConfigName: "allow ü",
+		"team/a.go":                     "// é ü\n",
+		"team/gen/b.go":                 "// 中\n",
+		"team/deep/c.go":                "// é ü ß\n",
+		"other/d.go":                    "// é\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	res, err := Scan([]string{tmp}, Options{Root: tmp, DisplayRoot: tmp, Include: []string{"**/*.go"}, LoadNestedConfig: loadTestNestedConfig})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	var got []string
+	for _, f := range res.Findings {
+		got = append(got, f.Path+":"+f.Character)
+	}
+	want := []string{"other/d.go:é", "team/a.go:ü", "team/deep/c.go:ß"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("findings = %q, want %q", got, want)
+	}
+
+	res, err = Scan([]string{tmp}, Options{Root: tmp, Include: []string{"**/*.go"}})
+	if err != nil || res.Summary.Findings != 7 {
+		t.Fatalf("expected nested configs to be ignored without a loader, got %d findings (%v)", res.Summary.Findings, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, "other", NestedConfigName), []byte("include **"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Scan([]string{tmp}, Options{Root: tmp, LoadNestedConfig: loadTestNestedConfig}); err == nil || !strings.Contains(err.Error(), "nested config") {
+		t.Fatalf("expected nested config error, got %v", err)
+	}
+}
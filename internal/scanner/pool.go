@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// errPoolStopped ends a walk after a queued file failed; filePool.close
+// returns that file's error instead.
+var errPoolStopped = errors.New("scan stopped")
+
+// filePool scans files on up to Options.Threads goroutines while the walk
+// goes on. The walk itself, with the include and exclude rules and the
+// nested and .editorconfig lookups, stays on the calling goroutine; only
+// reading and scanning a file runs on the workers.
+type filePool struct {
+	res    *Result
+	jobs   chan *fileJob
+	queued []*fileJob
+	wg     sync.WaitGroup
+	failed atomic.Bool
+}
+
+// fileJob scans one file into its own Result, which filePool.close merges
+// into the scan's.
+type fileJob struct {
+	scan func(res *Result) error
+	part Result
+	err  error
+}
+
+// newFilePool returns a pool that collects into res. Zero threads means
+// one per CPU; with one, files are scanned on the calling goroutine as they
+// are submitted.
+func newFilePool(threads int, res *Result) *filePool {
+	if threads <= 0 {
+		threads = runtime.GOMAXPROCS(0)
+	}
+	p := &filePool{res: res}
+	if threads == 1 {
+		return p
+	}
+	p.jobs = make(chan *fileJob, threads)
+	p.wg.Add(threads)
+	for i := 0; i < threads; i++ {
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				if job.err = job.scan(&job.part); job.err != nil {
+					p.failed.Store(true)
+				}
+			}
+		}()
+	}
+	return p
+}
+
+// stopped reports whether a queued file failed, so the walk can end early
+// instead of opening more files.
+func (p *filePool) stopped() bool {
+	return p.failed.Load()
+}
+
+// submit scans a file with scan, now or on a worker.
+func (p *filePool) submit(scan func(res *Result) error) error {
+	if p.jobs == nil {
+		return scan(p.res)
+	}
+	job := &fileJob{scan: scan}
+	p.queued = append(p.queued, job)
+	p.jobs <- job
+	return nil
+}
+
+// close waits for the queued files and merges their results in the order
+// they were submitted, so the result does not depend on which worker
+// finished first. It returns the error of the first file that failed, else
+// err, the error that ended the walk.
+func (p *filePool) close(err error) error {
+	if p.jobs == nil {
+		return err
+	}
+	close(p.jobs)
+	p.wg.Wait()
+	for _, job := range p.queued {
+		if job.err != nil {
+			return job.err
+		}
+		p.res.absorb(job.part)
+	}
+	p.queued = nil
+	return err
+}
+
+// absorb adds the files and findings of part, the result of a single file.
+func (r *Result) absorb(part Result) {
+	r.Findings = append(r.Findings, part.Findings...)
+	r.ScannedFiles = append(r.ScannedFiles, part.ScannedFiles...)
+	r.SkippedFiles = append(r.SkippedFiles, part.SkippedFiles...)
+	r.LimitedFiles = append(r.LimitedFiles, part.LimitedFiles...)
+	r.TruncatedFiles = append(r.TruncatedFiles, part.TruncatedFiles...)
+	r.Timings = append(r.Timings, part.Timings...)
+	for sum, paths := range part.contents {
+		if r.contents == nil {
+			r.contents = make(map[string][]string)
+		}
+		r.contents[sum] = append(r.contents[sum], paths...)
+	}
+}
@@ -1,17 +1,30 @@
 package scanner
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/TT-AIXion/englint/internal/match"
+	"github.com/TT-AIXion/englint/internal/uniname"
 )
 
 // Severity indicates result importance in output rendering.
@@ -20,17 +33,238 @@ type Severity string
 const (
 	SeverityError   Severity = "error"
 	SeverityWarning Severity = "warning"
+	// SeverityOff is only used as a Policy level, a Category severity, or
+	// by a Categorizer; findings at this level are dropped.
+	SeverityOff Severity = "off"
+)
+
+// Confidence ranks how likely a finding is a real problem.
+type Confidence string
+
+const (
+	ConfidenceLow    Confidence = "low"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceHigh   Confidence = "high"
+)
+
+// AtLeast reports whether c is min or higher. Findings without a
+// confidence, such as plugin findings, always qualify.
+func (c Confidence) AtLeast(min Confidence) bool {
+	rank := map[Confidence]int{ConfidenceLow: 1, ConfidenceMedium: 2, ConfidenceHigh: 3}
+	return c == "" || rank[c] >= rank[min]
+}
+
+// IgnoreDirective suppresses all findings on the line after the line that
+// contains it, typically inside a comment.
+const IgnoreDirective = "englint:ignore"
+
+// ExcerptMode controls how line excerpts are attached to findings.
+type ExcerptMode string
+
+const (
+	ExcerptFull   ExcerptMode = "full"
+	ExcerptOmit   ExcerptMode = "omit"
+	ExcerptRedact ExcerptMode = "redact"
 )
 
 // Options controls scan behavior.
 type Options struct {
-	Include           []string
-	Exclude           []string
-	AllowRunes        map[rune]struct{}
-	Severity          Severity
-	IgnoreComments    bool
-	IgnoreStrings     bool
+	Include    []string
+	Exclude    []string
+	AllowRunes map[rune]struct{}
+	// AllowScripts permits the characters of these Unicode scripts, such as
+	// unicode.Greek, and of other tables such as code point ranges.
+	AllowScripts   []*unicode.RangeTable
+	Severity       Severity
+	IgnoreComments bool
+	IgnoreStrings  bool
+	// AllowLatinExtended permits the characters isLatinExtended accepts.
+	AllowLatinExtended bool
+	// IgnoreURLs skips characters inside URLs and email addresses, such as
+	// internationalized domain names.
+	IgnoreURLs bool
+	// IgnoreBlobs skips characters inside long base64 or hex tokens and data
+	// URIs, which often flank binary content in mixed files.
+	IgnoreBlobs bool
+	// DecodeEscapes decodes \uXXXX, \u{...}, \UXXXXXXXX, \x{...}, and &#...;
+	// escapes inside string literals and reports the characters they encode.
+	DecodeEscapes bool
+	// CheckEntities reports HTML character references such as &nbsp; and
+	// &#x4e2d; in HTML and Markdown files, suggesting an ASCII fix.
+	CheckEntities bool
+	// IgnoreCodeBlocks skips code blocks in Markdown, reStructuredText, and
+	// AsciiDoc files, leaving only prose.
+	IgnoreCodeBlocks bool
+	// EditorConfig decodes files from the charset .editorconfig declares for
+	// them: latin1, utf-8, utf-8-bom, utf-16le, or utf-16be.
+	EditorConfig bool
+	// CheckCharset reports files whose content disagrees with their
+	// .editorconfig charset, such as a utf-8-bom file without a byte order
+	// mark. It implies EditorConfig.
+	CheckCharset      bool
 	AllowFilePatterns []string
+	// AllowFiles skip the files whose content still has the SHA-256 their
+	// entry records; a changed file is scanned as usual.
+	AllowFiles []AllowFile
+	// MaxFindingsPerFile caps the findings reported for a single file; the
+	// rest are counted in Result.LimitedFiles. Zero means no limit.
+	MaxFindingsPerFile int
+	// Excerpts selects full, omitted, or redacted excerpts. Redaction also
+	// replaces the reported character with its code point placeholder.
+	Excerpts ExcerptMode
+	// MmapThreshold memory-maps files of at least this many bytes instead of
+	// reading them through a buffer. Zero disables memory mapping.
+	MmapThreshold int64
+	// MessageTemplates replaces the message of findings by lower-case
+	// category, with "*" matching every category; see expandMessage.
+	MessageTemplates map[string]string
+	// HelpURIs links findings to guidance on resolving them by lower-case
+	// category, with "*" matching every category; see Options.HelpURI.
+	HelpURIs map[string]string
+	// Categories are matched in order before the built-in categories.
+	Categories []Category
+	// ErrorPolicy decides whether unreadable files and directories end the
+	// scan; the default, ErrorPolicySkip, records them as skipped.
+	ErrorPolicy ErrorPolicy
+	// LoadNestedConfig reads a NestedConfigName file found in a
+	// subdirectory of Root while walking. Its additions apply to the files
+	// beneath the file's directory. Nil ignores nested config files.
+	LoadNestedConfig func(path string) (NestedConfig, error)
+	// Strict fails the scan with an InternalError when scanning a file
+	// panics, instead of recording the file as skipped.
+	Strict bool
+	// ReadSpecialFiles reads named pipes and devices passed to Scan as
+	// paths, such as the /dev/fd/63 of a shell process substitution,
+	// regardless of the include patterns. Otherwise, and always while
+	// walking directories, they are recorded as skipped with SkipSpecial.
+	ReadSpecialFiles bool
+	// IgnoreLinePatterns suppresses every finding on a line that one of the
+	// expressions matches. Only the first maxLineMatchBytes of a line are
+	// matched.
+	IgnoreLinePatterns []*regexp.Regexp
+	// AllowPatterns allows findings inside matches of their expressions,
+	// such as strings passed to a translation function. Like
+	// IgnoreLinePatterns, they see only the first maxLineMatchBytes of a
+	// line.
+	AllowPatterns []AllowPattern
+	// Threads caps how many files are read and scanned at once; 1 scans
+	// them one after another. Zero means one per CPU. The result is the
+	// same whatever the value.
+	Threads int
+	// Timing records the size and scan duration of each scanned file in
+	// Result.Timings.
+	Timing bool
+	// DedupeContent reports the findings of files with identical content
+	// once, at the first of their paths, with the others in
+	// Finding.Duplicates.
+	DedupeContent bool
+	// Categorizer, when set, classifies characters that no Categories entry
+	// matches in place of the built-in categories.
+	Categorizer Categorizer
+	// MinConfidence drops findings below this confidence. Empty keeps all.
+	MinConfidence Confidence
+	// Policies override category levels in the files they match. Every
+	// matching policy applies in order, so later policies win.
+	Policies []Policy
+	// Root is the directory that Include, Exclude, AllowFilePatterns,
+	// Policies, PathSeverities, and HeadLimits patterns are relative to.
+	// Empty means the
+	// working directory. Findings are still reported relative to the
+	// working directory.
+	Root string
+	// DisplayRoot is the directory that reported paths are relative to.
+	// Empty means the working directory. Files outside it are reported by
+	// absolute path.
+	DisplayRoot string
+	// AbsolutePaths reports every path as absolute.
+	AbsolutePaths bool
+	// PathSeverities replace Severity in the files they match; the last
+	// matching entry wins. Custom category severities and policies still
+	// apply on top.
+	PathSeverities []PathSeverity
+	// HeadLimits scan only the start of the files they match; the last
+	// matching entry wins. Cut-off files are listed in
+	// Result.TruncatedFiles.
+	HeadLimits []HeadLimit
+}
+
+// AllowFile allows the file at Path, relative to Root, while its content
+// has the lower-case hex SHA-256 digest SHA256.
+type AllowFile struct {
+	Path   string
+	SHA256 string
+}
+
+// PathSeverity sets the default severity of the files matching Pattern.
+type PathSeverity struct {
+	Pattern  string
+	Severity Severity
+}
+
+// Policy sets the level of categories, keyed by lower-case name or "*", in
+// files matching any of Paths. Non-empty Regions limit the policy to those
+// regions of single-file components, template files, or Go rune literals;
+// see Finding.Region.
+type Policy struct {
+	Paths   []string
+	Regions []string
+	Levels  map[string]Severity
+}
+
+// AllowPattern allows findings inside the matches of Pattern on their line.
+// Categories, compared case-insensitively, limits it to some categories;
+// empty means every category.
+type AllowPattern struct {
+	Pattern    *regexp.Regexp
+	Categories []string
+}
+
+// Category is a user-defined finding category.
+type Category struct {
+	Name   string
+	Ranges []RuneRange
+	// Severity overrides Options.Severity when set. SeverityOff mutes the
+	// category unless a Policy sets another level.
+	Severity Severity
+	// Fix is the suggested replacement for the category's characters.
+	Fix string
+}
+
+// ErrorPolicy is how Scan handles files and directories it cannot read.
+type ErrorPolicy string
+
+const (
+	// ErrorPolicySkip records the file as a SkippedFile with reason
+	// SkipError and continues.
+	ErrorPolicySkip ErrorPolicy = "skip"
+	// ErrorPolicyWarn skips like ErrorPolicySkip; callers also warn about
+	// the skipped files.
+	ErrorPolicyWarn ErrorPolicy = "warn"
+	// ErrorPolicyAbort ends the scan with the error.
+	ErrorPolicyAbort ErrorPolicy = "abort"
+)
+
+// Categorizer classifies characters for embedders that need more than
+// Unicode ranges, such as allowing Greek letters in a math library.
+type Categorizer interface {
+	// Categorize returns the category and severity of r. An empty category
+	// keeps the built-in category and an empty severity keeps the file's
+	// severity; SeverityOff allows r. Scan calls it from several goroutines
+	// at once unless Options.Threads is 1.
+	Categorize(r rune) (category string, severity Severity)
+}
+
+// CategorizerFunc adapts a function to the Categorizer interface.
+type CategorizerFunc func(r rune) (string, Severity)
+
+// Categorize calls f(r).
+func (f CategorizerFunc) Categorize(r rune) (string, Severity) {
+	return f(r)
+}
+
+// RuneRange is an inclusive range of code points.
+type RuneRange struct {
+	Lo, Hi rune
 }
 
 // Finding is a single non-English character detection.
@@ -44,19 +278,161 @@ type Finding struct {
 	Severity  Severity `json:"severity"`
 	Message   string   `json:"message"`
 	Excerpt   string   `json:"excerpt,omitempty"`
+	// Name is the Unicode name of the character, such as
+	// "HIRAGANA LETTER A"; see uniname.Name.
+	Name string `json:"name,omitempty"`
+	// Fix is the replacement suggested by a custom category.
+	Fix string `json:"fix,omitempty"`
+	// HelpURI links to guidance on resolving findings of the category; see
+	// Options.HelpURIs.
+	HelpURI string `json:"helpUri,omitempty"`
+	// Region is the component region, such as "template" or "script", of a
+	// finding in a Vue or Svelte single-file component, the template
+	// region, "text" or "expression", of a finding in a template file, or
+	// "rune" for a finding in a Go rune literal.
+	Region string `json:"region,omitempty"`
+	// Escape is the escape sequence or HTML entity the character was decoded
+	// from; see Options.DecodeEscapes and Options.CheckEntities.
+	Escape string `json:"escape,omitempty"`
+	// Confidence estimates how likely the finding is a real problem; see
+	// confidenceFor.
+	Confidence Confidence `json:"confidence,omitempty"`
+	// Fingerprint identifies the finding across scans independently of its
+	// line number; see Fingerprint.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Owners are the CODEOWNERS owners of the finding's file.
+	Owners []string `json:"owners,omitempty"`
+	// URI is the finding's location as a file URI, such as
+	// file:///src/app.go#L12, when requested; see FileURI.
+	URI string `json:"uri,omitempty"`
+	// Duplicates are the other paths of files with the same content, whose
+	// findings are not reported separately; see Options.DedupeContent.
+	Duplicates []string `json:"duplicates,omitempty"`
 }
 
+// FileURI returns the file URI of line in the file at the absolute path
+// abs, such as file:///src/app.go#L12.
+func FileURI(abs string, line int) string {
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		// Windows paths such as C:/src become file:///C:/src.
+		p = "/" + p
+	}
+	u := url.URL{Scheme: "file", Path: p, Fragment: fmt.Sprintf("L%d", line)}
+	return u.String()
+}
+
+// SkipReason is why a file was skipped. Its values are stable so tools can
+// filter skipped files; SkippedFile.Detail carries the free text.
+type SkipReason string
+
+const (
+	// SkipBinary marks files whose content looks binary.
+	SkipBinary SkipReason = "binary"
+	// SkipTooLarge marks files skipped for their size.
+	SkipTooLarge SkipReason = "too-large"
+	// SkipAllowedPattern marks files matching allow_file_patterns.
+	SkipAllowedPattern SkipReason = "allowed-pattern"
+	// SkipAllowedHash marks files whose content matches their allow_files
+	// digest.
+	SkipAllowedHash SkipReason = "allowed-hash"
+	// SkipGenerated marks generated files.
+	SkipGenerated SkipReason = "generated"
+	// SkipSuppressed marks files englint skips on its own, such as
+	// translated locale resources.
+	SkipSuppressed SkipReason = "suppressed"
+	// SkipError marks files that could not be read or whose scan failed.
+	SkipError SkipReason = "error"
+	// SkipSpecial marks named pipes, devices, and sockets, which may block
+	// or never end when read; see Options.ReadSpecialFiles.
+	SkipSpecial SkipReason = "special"
+)
+
 // SkippedFile tracks files skipped during scanning.
 type SkippedFile struct {
-	Path   string `json:"path"`
-	Reason string `json:"reason"`
+	Path   string     `json:"path"`
+	Reason SkipReason `json:"reason"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+// Describe returns the reason followed by the detail, if any.
+func (s SkippedFile) Describe() string {
+	if s.Detail == "" {
+		return string(s.Reason)
+	}
+	return string(s.Reason) + ": " + s.Detail
+}
+
+// FileTiming is the size of a scanned file, its line break style, and how
+// long scanning it took.
+type FileTiming struct {
+	Path     string        `json:"path"`
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"durationNs"`
+	// EOL is EOLLF, EOLCRLF, EOLCR, EOLMixed, or empty for a file without
+	// line breaks.
+	EOL string `json:"eol,omitempty"`
+}
+
+// Line break styles of scanned files.
+const (
+	EOLLF    = "lf"
+	EOLCRLF  = "crlf"
+	EOLCR    = "cr"
+	EOLMixed = "mixed"
+)
+
+// eolStyle is a set of the line break styles seen in a file.
+type eolStyle uint8
+
+const (
+	eolLF eolStyle = 1 << iota
+	eolCRLF
+	eolCR
+)
+
+// String returns the file's line break style.
+func (s eolStyle) String() string {
+	switch s {
+	case 0:
+		return ""
+	case eolLF:
+		return EOLLF
+	case eolCRLF:
+		return EOLCRLF
+	case eolCR:
+		return EOLCR
+	default:
+		return EOLMixed
+	}
+}
+
+// LimitedFile records a file whose findings were cut off by MaxFindingsPerFile.
+type LimitedFile struct {
+	Path     string `json:"path"`
+	Reported int    `json:"reported"`
+	Omitted  int    `json:"omitted"`
 }
 
 // Summary is a compact scan summary.
 type Summary struct {
-	FilesScanned int `json:"filesScanned"`
-	FilesSkipped int `json:"filesSkipped"`
-	Findings     int `json:"findings"`
+	FilesScanned    int `json:"filesScanned"`
+	FilesSkipped    int `json:"filesSkipped"`
+	Findings        int `json:"findings"`
+	FindingsOmitted int `json:"findingsOmitted,omitempty"`
+	// FindingsBaselined counts the findings a baseline suppressed.
+	FindingsBaselined int `json:"findingsBaselined,omitempty"`
+	// FindingsByCategory and FindingsBySeverity count the reported
+	// findings per category and per severity.
+	FindingsByCategory map[string]int   `json:"findingsByCategory"`
+	FindingsBySeverity map[Severity]int `json:"findingsBySeverity"`
+}
+
+// RootSummary summarizes the files under one of several paths passed to
+// Scan. A file under more than one of them counts toward the first.
+type RootSummary struct {
+	Root string `json:"root"`
+	Summary
 }
 
 // Result is the full scan output.
@@ -64,7 +440,35 @@ type Result struct {
 	Findings     []Finding     `json:"findings"`
 	ScannedFiles []string      `json:"scannedFiles"`
 	SkippedFiles []SkippedFile `json:"skippedFiles"`
-	Summary      Summary       `json:"summary"`
+	LimitedFiles []LimitedFile `json:"limitedFiles,omitempty"`
+	// TruncatedFiles are the files cut off by Options.HeadLimits.
+	TruncatedFiles []TruncatedFile `json:"truncatedFiles,omitempty"`
+	// Timings is filled in when Options.Timing is set, sorted by path.
+	Timings []FileTiming `json:"timings,omitempty"`
+	Summary Summary      `json:"summary"`
+	// Roots holds a summary per path when Scan is given more than one, in
+	// the order given.
+	Roots []RootSummary `json:"roots,omitempty"`
+	// Baselined counts the findings removed because a baseline records
+	// them; Summary.FindingsBaselined reports it.
+	Baselined int `json:"-"`
+	// roots are the display paths of the scan paths that Roots summarize.
+	roots []string
+	// contents maps the content hashes of scanned files to their paths
+	// while Options.DedupeContent is set.
+	contents map[string][]string
+}
+
+// Slowest returns the n files that took longest to scan, slowest first.
+func (r Result) Slowest(n int) []FileTiming {
+	slowest := slices.Clone(r.Timings)
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return slowest[i].Duration > slowest[j].Duration
+	})
+	if len(slowest) > n {
+		slowest = slowest[:n]
+	}
+	return slowest
 }
 
 // Scan traverses paths recursively and returns all findings.
@@ -89,34 +493,177 @@ func Scan(paths []string, opts Options) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
+	if opts.Root, err = patternRoot(opts.Root, cwd); err != nil {
+		return Result{}, err
+	}
+	base, err := displayBase(opts, cwd)
+	if err != nil {
+		return Result{}, err
+	}
 
 	res := Result{
 		Findings:     []Finding{},
 		ScannedFiles: []string{},
 		SkippedFiles: []SkippedFile{},
 	}
-	visited := make(map[string]struct{})
+	visited := make(map[fileKey]struct{})
+	configs := editorConfigs{}
+	nested := nestedConfigs{}
+	if len(cleanPaths) > 1 {
+		for _, path := range cleanPaths {
+			res.roots = append(res.roots, displayPath(base, path))
+		}
+	}
 
+	pool := newFilePool(opts.Threads, &res)
 	for _, path := range cleanPaths {
 		info, err := os.Stat(path)
 		if err != nil {
-			return Result{}, err
+			return Result{}, pool.close(err)
+		}
+		// Walking from an absolute root keeps displayPath from resolving
+		// every file against the working directory again.
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return Result{}, pool.close(err)
 		}
 		if info.IsDir() {
-			if err := walkDir(path, cwd, opts, visited, &res); err != nil {
-				return Result{}, err
+			if err := walkDir(abs, base, opts, visited, configs, nested, pool, &res); err != nil {
+				return Result{}, pool.close(err)
+			}
+			continue
+		}
+		fileOpts := opts
+		if special := specialFileType(info.Mode()); special != "" {
+			if !opts.ReadSpecialFiles {
+				res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: displayPath(base, abs), Reason: SkipSpecial, Detail: special})
+				continue
 			}
+			// Their names, such as /dev/fd/63, carry no file extension
+			// for the include patterns to match.
+			fileOpts.Include = nil
+		}
+		if err := scanFile(abs, displayPath(base, abs), displayPath(opts.Root, abs), fileOpts, visited, configs, nested, pool, &res); err != nil {
+			return Result{}, pool.close(err)
+		}
+	}
+	if err := pool.close(nil); err != nil {
+		return Result{}, err
+	}
+
+	if opts.DedupeContent {
+		dedupeContent(&res)
+	}
+	if len(opts.HelpURIs) > 0 {
+		for i := range res.Findings {
+			res.Findings[i].HelpURI = opts.HelpURI(res.Findings[i].Category)
+		}
+	}
+	finish(&res)
+	return res, nil
+}
+
+// HelpURI returns the HelpURIs entry of category, else that of its
+// umbrella category, else the "*" entry.
+func (o Options) HelpURI(category string) string {
+	uri, _ := categoryEntry(o.HelpURIs, category)
+	return uri
+}
+
+// dedupeContent keeps the findings of each set of files with identical
+// content only at the first path in sort order, listing the other paths as
+// its Duplicates.
+func dedupeContent(res *Result) {
+	primary := make(map[string]string)
+	duplicates := make(map[string][]string)
+	for _, paths := range res.contents {
+		if len(paths) < 2 {
+			continue
+		}
+		slices.Sort(paths)
+		for _, path := range paths[1:] {
+			primary[path] = paths[0]
+		}
+		duplicates[paths[0]] = paths[1:]
+	}
+	res.contents = nil
+	if len(primary) == 0 {
+		return
+	}
+	findings := res.Findings[:0]
+	for _, f := range res.Findings {
+		if _, ok := primary[f.Path]; ok {
 			continue
 		}
-		if err := scanFile(path, cwd, opts, visited, &res); err != nil {
+		f.Duplicates = duplicates[f.Path]
+		findings = append(findings, f)
+	}
+	res.Findings = findings
+	res.LimitedFiles = slices.DeleteFunc(res.LimitedFiles, func(limited LimitedFile) bool {
+		_, ok := primary[limited.Path]
+		return ok
+	})
+}
+
+// ScanReader scans one file whose content is read from r instead of the
+// filesystem, such as a blob from git history. path is matched against the
+// include, exclude, and allow_file_patterns rules like a display path.
+func ScanReader(path string, r io.Reader, opts Options) (Result, error) {
+	opts = normalizeOptions(opts)
+	path = filepath.ToSlash(path)
+	res := Result{
+		Findings:     []Finding{},
+		ScannedFiles: []string{},
+		SkippedFiles: []SkippedFile{},
+	}
+	if accept(path, path, opts, &res) {
+		if err := scanSource(path, path, r, "", opts, &res); err != nil {
 			return Result{}, err
 		}
 	}
+	finish(&res)
+	return res, nil
+}
+
+// Merge adds findings from another source, such as a plugin, and re-sorts
+// the result and recomputes its summary.
+func (r *Result) Merge(findings []Finding) {
+	r.Findings = append(r.Findings, findings...)
+	finish(r)
+}
+
+// Limit keeps the first n findings of each file, like MaxFindingsPerFile,
+// for results filtered after the scan, such as by a baseline. The rest are
+// counted in LimitedFiles. Zero means no limit.
+func (r *Result) Limit(n int) {
+	if n <= 0 {
+		return
+	}
+	finish(r)
+	kept := r.Findings[:0]
+	for i := 0; i < len(r.Findings); {
+		j := i
+		for j < len(r.Findings) && r.Findings[j].Path == r.Findings[i].Path {
+			j++
+		}
+		if j-i > n {
+			r.LimitedFiles = append(r.LimitedFiles, LimitedFile{Path: r.Findings[i].Path, Reported: n, Omitted: j - i - n})
+		}
+		kept = append(kept, r.Findings[i:min(j, i+n)]...)
+		i = j
+	}
+	r.Findings = kept
+	finish(r)
+}
 
+// finish sorts the collected files and findings and fills in the summary.
+func finish(res *Result) {
 	sort.Strings(res.ScannedFiles)
 	sort.Slice(res.SkippedFiles, func(i, j int) bool {
 		return res.SkippedFiles[i].Path < res.SkippedFiles[j].Path
 	})
+	// Overlapping scan paths reach skipped files more than once.
+	res.SkippedFiles = slices.Compact(res.SkippedFiles)
 	sort.Slice(res.Findings, func(i, j int) bool {
 		a, b := res.Findings[i], res.Findings[j]
 		if a.Path != b.Path {
@@ -131,12 +678,86 @@ func Scan(paths []string, opts Options) (Result, error) {
 		return a.CodePoint < b.CodePoint
 	})
 
+	sort.Slice(res.LimitedFiles, func(i, j int) bool {
+		return res.LimitedFiles[i].Path < res.LimitedFiles[j].Path
+	})
+	sort.Slice(res.TruncatedFiles, func(i, j int) bool {
+		return res.TruncatedFiles[i].Path < res.TruncatedFiles[j].Path
+	})
+	sort.Slice(res.Timings, func(i, j int) bool {
+		return res.Timings[i].Path < res.Timings[j].Path
+	})
+
+	omitted := 0
+	for _, limited := range res.LimitedFiles {
+		omitted += limited.Omitted
+	}
+	byCategory := map[string]int{}
+	bySeverity := map[Severity]int{}
+	for _, f := range res.Findings {
+		byCategory[f.Category]++
+		bySeverity[f.Severity]++
+	}
 	res.Summary = Summary{
-		FilesScanned: len(res.ScannedFiles),
-		FilesSkipped: len(res.SkippedFiles),
-		Findings:     len(res.Findings),
+		FilesScanned:       len(res.ScannedFiles),
+		FilesSkipped:       len(res.SkippedFiles),
+		Findings:           len(res.Findings),
+		FindingsOmitted:    omitted,
+		FindingsBaselined:  res.Baselined,
+		FindingsByCategory: byCategory,
+		FindingsBySeverity: bySeverity,
+	}
+	summarizeRoots(res)
+}
+
+// summarizeRoots fills in res.Roots from res.roots, counting each file
+// toward the first root it is under.
+func summarizeRoots(res *Result) {
+	if len(res.roots) == 0 {
+		return
+	}
+	res.Roots = make([]RootSummary, len(res.roots))
+	for i, root := range res.roots {
+		res.Roots[i] = RootSummary{Root: root, Summary: Summary{FindingsByCategory: map[string]int{}, FindingsBySeverity: map[Severity]int{}}}
+	}
+	rootOf := func(path string) *Summary {
+		for i, root := range res.roots {
+			if underRoot(path, root) {
+				return &res.Roots[i].Summary
+			}
+		}
+		return nil
+	}
+	for _, path := range res.ScannedFiles {
+		if s := rootOf(path); s != nil {
+			s.FilesScanned++
+		}
+	}
+	for _, skipped := range res.SkippedFiles {
+		if s := rootOf(skipped.Path); s != nil {
+			s.FilesSkipped++
+		}
+	}
+	for _, f := range res.Findings {
+		if s := rootOf(f.Path); s != nil {
+			s.Findings++
+			s.FindingsByCategory[f.Category]++
+			s.FindingsBySeverity[f.Severity]++
+		}
+	}
+	for _, limited := range res.LimitedFiles {
+		if s := rootOf(limited.Path); s != nil {
+			s.FindingsOmitted += limited.Omitted
+		}
 	}
-	return res, nil
+}
+
+// underRoot reports whether the display path is root or below it.
+func underRoot(path, root string) bool {
+	if root == "." {
+		return !filepath.IsAbs(filepath.FromSlash(path))
+	}
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/")
 }
 
 func normalizeOptions(opts Options) Options {
@@ -149,169 +770,680 @@ func normalizeOptions(opts Options) Options {
 	return opts
 }
 
-func walkDir(root, cwd string, opts Options, visited map[string]struct{}, res *Result) error {
+// patternRoot returns the absolute directory that patterns are relative to:
+// root, or the working directory cwd when root is empty.
+func patternRoot(root, cwd string) (string, error) {
+	if root == "" {
+		return cwd, nil
+	}
+	return filepath.Abs(root)
+}
+
+// displayBase returns the directory that reported paths are relative to, or
+// "" when they are absolute.
+func displayBase(opts Options, cwd string) (string, error) {
+	switch {
+	case opts.AbsolutePaths:
+		return "", nil
+	case opts.DisplayRoot != "":
+		return filepath.Abs(opts.DisplayRoot)
+	default:
+		return cwd, nil
+	}
+}
+
+// fileKey identifies a scanned file; see fileKeyOf.
+type fileKey struct {
+	dev, ino uint64
+	path     string
+}
+
+// walkDir scans the files below the absolute directory root, reporting
+// paths relative to base; see displayPath.
+func walkDir(root, base string, opts Options, visited map[fileKey]struct{}, configs editorConfigs, nested nestedConfigs, pool *filePool, res *Result) error {
 	return filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		display := displayPath(base, path)
 		if walkErr != nil {
-			return walkErr
+			return skipUnreadable(display, walkErr, opts, res)
 		}
-		display := displayPath(cwd, path)
+		match := displayPath(opts.Root, path)
 		if d.IsDir() {
-			if display != "." && isExcluded(display, opts.Exclude) {
+			if match != "." && isExcluded(match, opts.Exclude) {
+				return filepath.SkipDir
+			}
+			chain, err := nested.chain(filepath.Dir(path), opts)
+			if err != nil {
+				return err
+			}
+			if nestedExcluded(path, chain) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 		if !d.Type().IsRegular() {
+			if special := specialFileType(d.Type()); special != "" && accept(display, match, opts, res) {
+				res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipSpecial, Detail: special})
+			}
 			return nil
 		}
-		return scanFile(path, cwd, opts, visited, res)
+		return scanFile(path, display, match, opts, visited, configs, nested, pool, res)
 	})
 }
 
-func scanFile(path, cwd string, opts Options, visited map[string]struct{}, res *Result) error {
-	abs, err := filepath.Abs(path)
+// scanFile scans the file at the absolute path abs unless it was already
+// scanned, possibly through another path such as a hard link. display is
+// the path reported to users and match the path patterns are matched
+// against; see Options.Root. The file is read and scanned by pool, into
+// res or a Result of its own.
+func scanFile(abs, display, match string, opts Options, visited map[fileKey]struct{}, configs editorConfigs, nested nestedConfigs, pool *filePool, res *Result) error {
+	if pool.stopped() {
+		return errPoolStopped
+	}
+	chain, err := nested.chain(filepath.Dir(abs), opts)
 	if err != nil {
 		return err
 	}
-	if _, ok := visited[abs]; ok {
+	if nestedExcluded(abs, chain) || !accept(display, match, opts, res) {
 		return nil
 	}
-	visited[abs] = struct{}{}
+	opts.AllowRunes = nestedAllowRunes(opts, chain)
 
-	display := displayPath(cwd, abs)
-	if !isIncluded(display, opts.Include) {
-		return nil
-	}
-	if isExcluded(display, opts.Exclude) {
-		return nil
-	}
-	if isAllowedFile(display, opts.AllowFilePatterns) {
-		res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: "allowed by file pattern"})
-		return nil
+	f, err := os.Open(abs)
+	if err != nil {
+		return skipUnreadable(display, fmt.Errorf("read %s: %w", display, err), opts, res)
 	}
-
-	data, err := os.ReadFile(abs)
+	info, err := f.Stat()
 	if err != nil {
-		return fmt.Errorf("read %s: %w", display, err)
+		_ = f.Close()
+		return skipUnreadable(display, fmt.Errorf("read %s: %w", display, err), opts, res)
 	}
-	if isBinary(data) {
-		res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: "binary file"})
+	key := fileKeyOf(abs, info)
+	if _, ok := visited[key]; ok {
+		_ = f.Close()
 		return nil
 	}
+	visited[key] = struct{}{}
 
-	res.ScannedFiles = append(res.ScannedFiles, display)
-	findings := scanContent(display, data, syntaxForPath(display), opts)
-	if len(findings) > 0 {
-		res.Findings = append(res.Findings, findings...)
+	charset := ""
+	if opts.EditorConfig || opts.CheckCharset {
+		charset, err = configs.charset(abs)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
 	}
-	return nil
+
+	return pool.submit(func(res *Result) error {
+		defer func() { _ = f.Close() }()
+		var source io.Reader = f
+		if opts.MmapThreshold > 0 {
+			data, release, err := mapLargeFile(f, info, opts.MmapThreshold)
+			if err == nil && data != nil {
+				defer func() { _ = release() }()
+				source = bytes.NewReader(data)
+			}
+		}
+		err := scanSource(display, match, source, charset, opts, res)
+		var internal *InternalError
+		if err != nil && !errors.As(err, &internal) {
+			return skipUnreadable(display, err, opts, res)
+		}
+		return err
+	})
 }
 
-func isIncluded(path string, include []string) bool {
-	if len(include) == 0 {
-		return true
+// specialFileType describes a file mode that is neither a regular file, a
+// directory, nor a symbolic link, such as "named pipe", or returns "".
+func specialFileType(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&fs.ModeCharDevice != 0:
+		return "character device"
+	case mode&fs.ModeDevice != 0:
+		return "block device"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeIrregular != 0:
+		return "irregular file"
 	}
-	return matches(path, include)
+	return ""
 }
 
-func isExcluded(path string, exclude []string) bool {
-	if len(exclude) == 0 {
-		return false
+// skipUnreadable records display, which could not be read, as skipped
+// unless opts.ErrorPolicy aborts the scan with err.
+func skipUnreadable(display string, err error, opts Options, res *Result) error {
+	if opts.ErrorPolicy == ErrorPolicyAbort {
+		return err
 	}
-	if matches(path, exclude) {
-		return true
+	// The path is already in the SkippedFile, so keep only the cause.
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
 	}
-	return matches(path+"/", exclude)
+	res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipError, Detail: "read error: " + err.Error()})
+	return nil
 }
 
-func isAllowedFile(path string, patterns []string) bool {
-	if len(patterns) == 0 {
+// accept applies the include, exclude, and allow_file_patterns rules to a
+// display path, recording files skipped by allow_file_patterns and
+// translated locale resources.
+func accept(display, match string, opts Options, res *Result) bool {
+	if !isIncluded(match, opts.Include) {
 		return false
 	}
-	return matches(path, patterns)
-}
-
-func matches(path string, patterns []string) bool {
-	norm := filepath.ToSlash(path)
-	base := filepath.Base(norm)
-	for _, p := range patterns {
-		p = strings.TrimSpace(p)
-		if p == "" {
-			continue
-		}
-		if match.Match(p, norm) || match.Match(p, base) {
-			return true
-		}
-		p = filepath.ToSlash(p)
-		if strings.HasSuffix(p, "/**") {
-			prefix := strings.TrimSuffix(p, "/**")
-			if norm == prefix || strings.HasPrefix(norm, prefix+"/") {
-				return true
-			}
-		}
+	if isExcluded(match, opts.Exclude) {
+		return false
 	}
-	return false
+	if isAllowedFile(match, opts.AllowFilePatterns) {
+		res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipAllowedPattern})
+		return false
+	}
+	if _, ok := translatedLocale(display); ok {
+		res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipSuppressed, Detail: "translated locale resource"})
+		return false
+	}
+	return true
 }
 
-func displayPath(cwd, path string) string {
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		return filepath.ToSlash(path)
+// scanSource scans the content of one accepted file, decoding it from
+// charset, an .editorconfig charset, when one is given.
+func scanSource(display, match string, source io.Reader, charset string, opts Options, res *Result) error {
+	opts.Severity = pathSeverity(match, opts)
+	start := time.Now()
+	counted := &countingReader{r: source}
+	source = counted
+	allowSums := allowFileSums(match, opts.AllowFiles)
+	var hasher hash.Hash
+	if opts.DedupeContent || len(allowSums) > 0 {
+		hasher = sha256.New()
+		source = io.TeeReader(source, hasher)
 	}
-	rel, err := filepath.Rel(cwd, abs)
-	if err != nil || strings.HasPrefix(rel, "..") {
-		return filepath.ToSlash(abs)
+	hashed := source
+	var mismatch string
+	if charset != "" {
+		source, mismatch = decodeCharset(bufio.NewReaderSize(source, readBufferSize), charset)
 	}
-	if rel == "." {
-		return rel
+	var head *headReader
+	if limit, ok := headLimit(match, opts.HeadLimits); ok {
+		head = newHeadReader(source, limit)
+		source = head
 	}
-	return filepath.ToSlash(rel)
-}
-
-func isBinary(data []byte) bool {
-	if len(data) == 0 {
-		return false
+	in, binary, err := sniff(source)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", display, err)
 	}
-	sample := data
-	if len(sample) > 8192 {
-		sample = sample[:8192]
+	if binary != "" {
+		res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipBinary, Detail: binary})
+		return nil
 	}
-	if bytes.IndexByte(sample, 0) >= 0 {
-		return true
+
+	content, err := scanIsolated(display, match, in, opts)
+	var internal *InternalError
+	switch {
+	case errors.As(err, &internal) && opts.Strict:
+		return err
+	case internal != nil:
+		res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipError, Detail: "internal error: " + fmt.Sprint(internal.Value)})
+		return nil
+	case err != nil:
+		return fmt.Errorf("read %s: %w", display, err)
 	}
-	control := 0
-	for _, b := range sample {
-		if b == '\n' || b == '\r' || b == '\t' {
-			continue
+	truncated := head != nil && head.truncated
+	if len(allowSums) > 0 {
+		// Hash the rest of a file that scan_head_only cut short.
+		if _, err := io.Copy(io.Discard, hashed); err != nil {
+			return fmt.Errorf("read %s: %w", display, err)
 		}
-		if b < 0x20 || b == 0x7f {
-			control++
+		if slices.Contains(allowSums, hex.EncodeToString(hasher.Sum(nil))) {
+			res.SkippedFiles = append(res.SkippedFiles, SkippedFile{Path: display, Reason: SkipAllowedHash})
+			return nil
 		}
 	}
-	return float64(control)/float64(len(sample)) > 0.30
+	res.ScannedFiles = append(res.ScannedFiles, display)
+	if truncated {
+		res.TruncatedFiles = append(res.TruncatedFiles, TruncatedFile{Path: display, Bytes: head.n, Lines: head.scannedLines()})
+	}
+	// The hash of a truncated file covers only part of its content.
+	if hasher != nil && !truncated {
+		if res.contents == nil {
+			res.contents = make(map[string][]string)
+		}
+		sum := hex.EncodeToString(hasher.Sum(nil))
+		res.contents[sum] = append(res.contents[sum], display)
+	}
+	if opts.Timing {
+		res.Timings = append(res.Timings, FileTiming{Path: display, Bytes: counted.n, Duration: time.Since(start), EOL: content.eol})
+	}
+	if mismatch != "" && opts.CheckCharset {
+		res.Findings = append(res.Findings, Finding{
+			Path:       display,
+			Line:       1,
+			Column:     1,
+			Character:  "?",
+			CodePoint:  "charset",
+			Category:   "Charset Mismatch",
+			Confidence: ConfidenceHigh,
+			Severity:   opts.Severity,
+			Message:    fmt.Sprintf("File content %s but .editorconfig declares charset %s", mismatch, charset),
+		})
+	}
+	if len(content.findings) > 0 {
+		res.Findings = append(res.Findings, content.findings...)
+	}
+	if content.omitted > 0 {
+		res.LimitedFiles = append(res.LimitedFiles, LimitedFile{Path: display, Reported: len(content.findings), Omitted: content.omitted})
+	}
+	return nil
 }
 
-type syntaxRules struct {
-	lineComments []string
-	blockStart   string
-	blockEnd     string
-	strings      bool
-	backtick     bool
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
 }
 
-func syntaxForPath(path string) syntaxRules {
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// InternalError is a panic recovered while scanning one file, such as a
+// parser bug or a panicking Categorizer.
+type InternalError struct {
+	Path  string
+	Value any
+}
+
+func (e *InternalError) Error() string {
+	return fmt.Sprintf("internal error in %s: %v", e.Path, e.Value)
+}
+
+// scanIsolated scans one file, recovering a panic as an InternalError so a
+// single pathological file cannot end the whole run.
+func scanIsolated(display, match string, in *bufio.Reader, opts Options) (content contentResult, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &InternalError{Path: display, Value: v}
+		}
+	}()
+	return scanReader(display, match, in, syntaxForFile(display, in), opts)
+}
+
+// mapLargeFile memory-maps f when it is at least threshold bytes. It returns
+// nil data for smaller files or when mapping fails, so callers can stream.
+func mapLargeFile(f *os.File, info os.FileInfo, threshold int64) ([]byte, func() error, error) {
+	if !info.Mode().IsRegular() || info.Size() < threshold || info.Size() == 0 {
+		return nil, nil, nil
+	}
+	return mapFile(f, info.Size())
+}
+
+// sniff wraps r in a buffered reader and returns the binaryEvidence of the
+// leading bytes without consuming them.
+func sniff(r io.Reader) (*bufio.Reader, string, error) {
+	in := bufio.NewReaderSize(r, readBufferSize)
+	head, err := in.Peek(binarySniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", err
+	}
+	return in, binaryEvidence(head), nil
+}
+
+// Explanation describes which rule decides whether a file is scanned.
+type Explanation struct {
+	Path    string `json:"path"`
+	Scanned bool   `json:"scanned"`
+	Rule    string `json:"rule"`
+	Pattern string `json:"pattern,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// Explain reports whether path would be scanned with opts and which
+// include, exclude, allow_file_patterns, or allow_files entry made the
+// decision.
+func Explain(path string, opts Options) (Explanation, error) {
+	opts = normalizeOptions(opts)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Explanation{}, err
+	}
+	root, err := patternRoot(opts.Root, cwd)
+	if err != nil {
+		return Explanation{}, err
+	}
+	base, err := displayBase(opts, cwd)
+	if err != nil {
+		return Explanation{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	display := displayPath(base, path)
+	match := displayPath(root, path)
+	out := Explanation{Path: display}
+	if info.IsDir() {
+		if pattern, ok := excludePattern(match, opts.Exclude); ok && match != "." {
+			out.Rule = "exclude"
+			out.Pattern = pattern
+			out.Reason = "directory is excluded and will not be walked"
+			return out, nil
+		}
+		out.Scanned = true
+		out.Rule = "directory"
+		out.Reason = "directory is walked; run --why on a file for per-file rules"
+		return out, nil
+	}
+
+	for dir := filepath.ToSlash(filepath.Dir(match)); dir != "." && dir != "/" && !strings.HasSuffix(dir, ":/"); dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if pattern, ok := excludePattern(dir, opts.Exclude); ok {
+			out.Rule = "exclude"
+			out.Pattern = pattern
+			out.Reason = fmt.Sprintf("parent directory %s is excluded and is not walked", dir)
+			return out, nil
+		}
+	}
+	if len(opts.Include) > 0 {
+		pattern, ok := matchingPattern(match, opts.Include)
+		if !ok {
+			out.Rule = "include"
+			out.Reason = "no include pattern matches"
+			return out, nil
+		}
+		out.Pattern = pattern
+	}
+	if pattern, ok := excludePattern(match, opts.Exclude); ok {
+		out.Rule = "exclude"
+		out.Pattern = pattern
+		out.Reason = "file matches an exclude pattern"
+		return out, nil
+	}
+	if pattern, ok := matchingPattern(match, opts.AllowFilePatterns); ok {
+		out.Rule = "allow_file_patterns"
+		out.Pattern = pattern
+		out.Reason = "file is allowed and skipped without scanning"
+		return out, nil
+	}
+	if locale, ok := translatedLocale(display); ok {
+		out.Rule = "locale"
+		out.Pattern = ""
+		out.Reason = fmt.Sprintf("file is a %s translation resource and is skipped without scanning", locale)
+		return out, nil
+	}
+	if !info.Mode().IsRegular() {
+		out.Rule = "file type"
+		out.Pattern = ""
+		out.Reason = "not a regular file"
+		if special := specialFileType(info.Mode()); special != "" {
+			out.Reason = "file is a " + special + "; pass it explicitly with --read-special to read it"
+		}
+		return out, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Explanation{}, fmt.Errorf("read %s: %w", display, err)
+	}
+	defer func() { _ = f.Close() }()
+	_, binary, err := sniff(f)
+	if err != nil {
+		return Explanation{}, fmt.Errorf("read %s: %w", display, err)
+	}
+	if binary != "" {
+		out.Rule = "binary"
+		out.Pattern = ""
+		out.Reason = "file content looks binary: " + binary
+		return out, nil
+	}
+
+	if sums := allowFileSums(match, opts.AllowFiles); len(sums) > 0 {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return Explanation{}, fmt.Errorf("read %s: %w", display, err)
+		}
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, f); err != nil {
+			return Explanation{}, fmt.Errorf("read %s: %w", display, err)
+		}
+		sum := hex.EncodeToString(hasher.Sum(nil))
+		out.Rule = "allow_files"
+		out.Pattern = ""
+		if slices.Contains(sums, sum) {
+			out.Reason = "file content matches its allow_files sha256 and is skipped"
+			return out, nil
+		}
+		out.Scanned = true
+		out.Reason = fmt.Sprintf("file changed since it was allowed in allow_files and is scanned; its sha256 is now %s", sum)
+		return out, nil
+	}
+
+	out.Scanned = true
+	out.Rule = "include"
+	if out.Pattern == "" {
+		out.Reason = "no include patterns are configured, so every file is included"
+	} else {
+		out.Reason = "file matches an include pattern"
+	}
+	return out, nil
+}
+
+// pathSeverity returns the default severity of path: that of the last
+// matching PathSeverities entry, or Options.Severity.
+func pathSeverity(path string, opts Options) Severity {
+	for i := len(opts.PathSeverities) - 1; i >= 0; i-- {
+		if matches(path, []string{opts.PathSeverities[i].Pattern}) {
+			return opts.PathSeverities[i].Severity
+		}
+	}
+	return opts.Severity
+}
+
+func isIncluded(path string, include []string) bool {
+	if len(include) == 0 {
+		return true
+	}
+	return matches(path, include)
+}
+
+func isExcluded(path string, exclude []string) bool {
+	_, ok := excludePattern(path, exclude)
+	return ok
+}
+
+func excludePattern(path string, exclude []string) (string, bool) {
+	if len(exclude) == 0 {
+		return "", false
+	}
+	if pattern, ok := matchingPattern(path, exclude); ok {
+		return pattern, true
+	}
+	return matchingPattern(path+"/", exclude)
+}
+
+func isAllowedFile(path string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	return matches(path, patterns)
+}
+
+// allowFileSums returns the digests of the AllowFiles entries for match.
+func allowFileSums(match string, files []AllowFile) []string {
+	var sums []string
+	for _, f := range files {
+		if filepath.Clean(filepath.FromSlash(strings.TrimSpace(f.Path))) == filepath.Clean(match) {
+			sums = append(sums, f.SHA256)
+		}
+	}
+	return sums
+}
+
+func matches(path string, patterns []string) bool {
+	_, ok := matchingPattern(path, patterns)
+	return ok
+}
+
+// matchingPattern returns the first pattern that matches path.
+func matchingPattern(path string, patterns []string) (string, bool) {
+	norm := filepath.ToSlash(path)
+	base := filepath.Base(norm)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if match.Match(pattern, norm) || match.Match(pattern, base) {
+			return pattern, true
+		}
+		p := filepath.ToSlash(pattern)
+		if strings.HasSuffix(p, "/**") {
+			prefix := strings.TrimSuffix(p, "/**")
+			if norm == prefix || strings.HasPrefix(norm, prefix+"/") {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// displayPath returns path relative to base with forward slashes, or
+// absolute when base is empty or path is outside it.
+func displayPath(base, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if base == "" {
+		return filepath.ToSlash(abs)
+	}
+	rel, err := filepath.Rel(base, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(abs)
+	}
+	if rel == "." {
+		return rel
+	}
+	return filepath.ToSlash(rel)
+}
+
+// binaryEvidence returns why the start of data looks binary, such as
+// "NUL byte at offset 12", or "" when it looks like text.
+func binaryEvidence(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sample := data
+	if len(sample) > binarySniffSize {
+		sample = sample[:binarySniffSize]
+	}
+	if i := bytes.IndexByte(sample, 0); i >= 0 {
+		return fmt.Sprintf("NUL byte at offset %d", i)
+	}
+	control, first := 0, -1
+	for i, b := range sample {
+		if b == '\n' || b == '\r' || b == '\t' {
+			continue
+		}
+		if b < 0x20 || b == 0x7f {
+			if first < 0 {
+				first = i
+			}
+			control++
+		}
+	}
+	if float64(control)/float64(len(sample)) <= 0.30 {
+		return ""
+	}
+	return fmt.Sprintf("%d of the first %d bytes are control bytes, the first 0x%02X at offset %d", control, len(sample), sample[first], first)
+}
+
+type syntaxRules struct {
+	lineComments []string
+	blockStart   string
+	blockEnd     string
+	strings      bool
+	backtick     bool
+	// entities marks markup where HTML character references render.
+	entities bool
+	// tripleQuote enables """ block strings.
+	tripleQuote bool
+	// heredoc enables <<EOT and <<-EOT heredocs.
+	heredoc bool
+	// svg limits scanning to SVG text elements and comments; see svgStep.
+	svg bool
+	// templates lists the template engine constructs; see templateStep.
+	templates []templateDelim
+	// latex enables LaTeX control symbols, math, and verbatim text; see
+	// latexStep.
+	latex bool
+	// escapes marks formats that store text as \uXXXX escapes, such as Java
+	// .properties files, so they are decoded outside comments.
+	escapes bool
+	// markup selects the code block and comment rules of documentation
+	// formats.
+	markup markupKind
+	// nestedComments lets block comments nest, as in Rust, so each
+	// blockStart inside a comment needs its own blockEnd.
+	nestedComments bool
+	// goLiterals marks Go, where ' starts a rune literal and backtick
+	// strings are raw, without escape sequences.
+	goLiterals bool
+}
+
+func syntaxForPath(path string) syntaxRules {
 	ext := strings.ToLower(filepath.Ext(path))
 	base := strings.ToLower(filepath.Base(path))
 
 	switch ext {
-	case ".go", ".js", ".jsx", ".ts", ".tsx", ".java", ".c", ".cc", ".cpp", ".h", ".hpp", ".cs", ".swift", ".kt", ".kts", ".rs", ".php":
+	case ".go":
+		return syntaxRules{lineComments: []string{"//"}, blockStart: "/*", blockEnd: "*/", strings: true, backtick: true, goLiterals: true}
+	case ".swift", ".kt", ".kts", ".rs":
+		return syntaxRules{lineComments: []string{"//"}, blockStart: "/*", blockEnd: "*/", strings: true, backtick: true, nestedComments: true}
+	case ".js", ".jsx", ".ts", ".tsx", ".java", ".c", ".cc", ".cpp", ".h", ".hpp", ".cs", ".php":
 		return syntaxRules{lineComments: []string{"//"}, blockStart: "/*", blockEnd: "*/", strings: true, backtick: true}
-	case ".py", ".rb", ".sh", ".bash", ".zsh", ".yaml", ".yml", ".toml", ".ini", ".conf", ".properties":
+	case ".hs":
+		// ' is also a character in identifiers such as foldl', so string
+		// literals are not tracked.
+		return syntaxRules{lineComments: []string{"--"}, blockStart: "{-", blockEnd: "-}", nestedComments: true}
+	case ".py", ".rb", ".sh", ".bash", ".zsh", ".yaml", ".yml", ".toml", ".ini", ".conf":
 		return syntaxRules{lineComments: []string{"#"}, strings: true}
+	case ".properties":
+		return syntaxRules{lineComments: []string{"#"}, strings: true, escapes: true}
 	case ".sql":
 		return syntaxRules{lineComments: []string{"--"}, blockStart: "/*", blockEnd: "*/", strings: true}
 	case ".lua":
 		return syntaxRules{lineComments: []string{"--"}, strings: true}
+	case ".tf", ".tfvars", ".hcl":
+		return syntaxRules{lineComments: []string{"#", "//"}, blockStart: "/*", blockEnd: "*/", strings: true, heredoc: true}
+	case ".graphql", ".graphqls", ".gql":
+		return syntaxRules{lineComments: []string{"#"}, strings: true, tripleQuote: true}
+	case ".proto":
+		return syntaxRules{lineComments: []string{"//"}, blockStart: "/*", blockEnd: "*/", strings: true}
+	case ".md", ".markdown":
+		return syntaxRules{entities: true, markup: markupMarkdown}
+	case ".html", ".htm", ".xhtml":
+		return syntaxRules{entities: true}
+	case ".rst":
+		return syntaxRules{markup: markupRST}
+	case ".adoc", ".asciidoc":
+		return syntaxRules{markup: markupAsciiDoc}
+	case ".po", ".pot":
+		return syntaxRules{markup: markupGettext}
+	case ".svg":
+		return syntaxRules{entities: true, svg: true}
+	case ".tex", ".ltx", ".sty", ".cls", ".bib":
+		return syntaxRules{lineComments: []string{"%"}, latex: true}
+	case ".vue", ".svelte":
+		return componentTemplate
+	case ".tmpl", ".gotmpl":
+		return templateText(goTemplateDelims)
+	case ".j2", ".jinja", ".jinja2":
+		return templateText(jinjaDelims)
+	case ".erb":
+		return templateText(erbDelims)
+	case ".hbs", ".handlebars", ".mustache":
+		return templateText(handlebarsDelims)
 	default:
 		if base == "dockerfile" || strings.HasSuffix(base, ".dockerfile") {
 			return syntaxRules{lineComments: []string{"#"}, strings: true}
@@ -320,6 +1452,55 @@ func syntaxForPath(path string) syntaxRules {
 	}
 }
 
+// CommentSyntax returns the text that opens and closes a comment on its own
+// line in path, preferring line comments. ok is false for file types with no
+// known comment syntax.
+func CommentSyntax(path string) (start, end string, ok bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".html", ".htm", ".xml":
+		return "<!-- ", " -->", true
+	case ".rst":
+		return ".. ", "", true
+	case ".adoc", ".asciidoc":
+		return "// ", "", true
+	case ".po", ".pot":
+		return "# ", "", true
+	case ".svg":
+		return "<!-- ", " -->", true
+	case ".vue", ".svelte":
+		// The comment syntax depends on the region of the line.
+		return "", "", false
+	case ".tmpl", ".gotmpl":
+		return "{{/* ", " */}}", true
+	case ".j2", ".jinja", ".jinja2":
+		return "{# ", " #}", true
+	case ".erb":
+		return "<%# ", " %>", true
+	case ".hbs", ".handlebars", ".mustache":
+		return "{{!-- ", " --}}", true
+	}
+	rules := syntaxForPath(path)
+	switch {
+	case len(rules.lineComments) > 0:
+		return rules.lineComments[0] + " ", "", true
+	case rules.blockStart != "":
+		return rules.blockStart + " ", " " + rules.blockEnd, true
+	default:
+		return "", "", false
+	}
+}
+
+// CodeLines reports, for each 1-based line of data, whether the line
+// starts outside strings, comments, and code blocks in the file at path, so
+// that a comment inserted above it keeps its own meaning. Index 0 is unused.
+func CodeLines(path string, data []byte) []bool {
+	in := bufio.NewReaderSize(bytes.NewReader(data), readBufferSize)
+	c := newContentScanner(path, in, syntaxForFile(path, in), Options{})
+	c.lineStarts = []bool{false}
+	_ = c.run()
+	return c.lineStarts
+}
+
 type scanState int
 
 const (
@@ -329,166 +1510,895 @@ const (
 	stateSingleString
 	stateDoubleString
 	stateBacktickString
+	// stateRune is a Go rune literal. Its findings are in the "rune"
+	// region, and IgnoreStrings does not skip it.
+	stateRune
+	// stateTripleString is a """ block string, such as a GraphQL description.
+	stateTripleString
+	// stateHeredoc is heredoc text up to the line holding only
+	// contentScanner.heredoc, as in HCL.
+	stateHeredoc
+	// stateCodeBlock is a code block in a documentation file; see
+	// startDocLine.
+	stateCodeBlock
+	// stateSkipped is text that is never inspected, such as a gettext
+	// msgstr translation or SVG markup outside text elements.
+	stateSkipped
+	// stateMath is LaTeX math, where math symbols are not reported.
+	stateMath
+)
+
+const (
+	readBufferSize  = 64 * 1024
+	binarySniffSize = 8192
+	maxExcerptBytes = 160
+	lookaheadBytes  = 16
 )
 
+// contentScanner walks a file as a rune stream. Only the leading bytes of the
+// current line are buffered for excerpts, so memory stays bounded no matter
+// how large the file is.
+type contentScanner struct {
+	path     string
+	in       *bufio.Reader
+	syntax   syntaxRules
+	opts     Options
+	line     int
+	col      int
+	state    scanState
+	escaped  bool
+	lineHead []byte
+	lineLong bool
+	pending  []Finding
+	findings []Finding
+	omitted  int
+	// seen counts fingerprint keys so repeated identical findings in one
+	// file still get distinct fingerprints.
+	seen map[string]int
+	// directive is how many bytes of IgnoreDirective the current line has
+	// matched so far; lineIgnored is set once it matched completely, and
+	// ignoreLine suppresses the findings of the line after it.
+	directive   int
+	lineIgnored bool
+	ignoreLine  bool
+	// lineText holds the current line for IgnoreLinePatterns and
+	// AllowPatterns.
+	lineText []byte
+	// policies holds the policies matching path, and levels caches their
+	// merged category levels per region.
+	policies []Policy
+	levels   map[string]map[string]Severity
+	// region is the current single-file component or template region, if
+	// any.
+	region string
+	// word holds the leading bytes of the current whitespace-delimited word
+	// and wordCol its column, so IgnoreURLs and IgnoreBlobs can drop the
+	// findings of a word that turns out to be a URL, email address, or blob.
+	word    []byte
+	wordCol int
+	// The doc fields track code blocks and comments in documentation files:
+	// docFence is the delimiter closing the open fenced block, docIndent the
+	// indentation an indented block's lines must exceed (-1 when none), and
+	// docState the state of either block. docLineStarted, docLineIndent,
+	// docDirective, and docTail describe the current line.
+	docFence       string
+	docIndent      int
+	docState       scanState
+	docLineStarted bool
+	docLineIndent  int
+	docDirective   bool
+	docTail        [2]byte
+	// The svg fields track SVG markup: svgDepth counts the open text
+	// elements, svgTag is set inside a tag, svgQuote holds the quote of the
+	// attribute value being read, svgOpen marks a tag opening a text element,
+	// and svgPrev is the previous byte of the tag.
+	svgDepth int
+	svgTag   bool
+	svgQuote byte
+	svgOpen  bool
+	svgPrev  byte
+	// heredoc is the delimiter closing the open heredoc.
+	heredoc string
+	// commentDepth counts the open block comments when they nest.
+	commentDepth int
+	// eol records the line break styles seen so far, and lineCR is set
+	// when the last byte consumed was \r.
+	eol    eolStyle
+	lineCR bool
+	// templateClose is the delimiter closing the open template construct.
+	templateClose string
+	// latexEnd closes the open LaTeX math, verbatim, or comment text.
+	latexEnd string
+	// lineStarts, when not nil, records whether each line started in
+	// stateCode; see CodeLines.
+	lineStarts []bool
+}
+
+// contentResult is what scanning a single file produces.
+type contentResult struct {
+	findings []Finding
+	omitted  int
+	eol      string
+}
+
 func scanContent(path string, data []byte, syntax syntaxRules, opts Options) []Finding {
-	text := string(data)
-	lines := strings.Split(text, "\n")
-	findings := make([]Finding, 0)
-	line := 1
-	col := 1
-	state := stateCode
-	escaped := false
+	content, _ := scanReader(path, path, bufio.NewReaderSize(bytes.NewReader(data), readBufferSize), syntax, opts)
+	return content.findings
+}
 
-	for i := 0; i < len(text); {
-		switch state {
+// scanReader scans in, reporting findings at path and selecting the policies
+// whose paths match match.
+func scanReader(path, match string, in *bufio.Reader, syntax syntaxRules, opts Options) (contentResult, error) {
+	c := newContentScanner(path, in, syntax, opts)
+	for _, policy := range opts.Policies {
+		if matches(match, policy.Paths) {
+			c.policies = append(c.policies, policy)
+		}
+	}
+	if err := c.run(); err != nil {
+		return contentResult{}, err
+	}
+	return contentResult{findings: c.findings, omitted: c.omitted, eol: c.eol.String()}, nil
+}
+
+func newContentScanner(path string, in *bufio.Reader, syntax syntaxRules, opts Options) *contentScanner {
+	c := &contentScanner{
+		path:      path,
+		in:        in,
+		syntax:    syntax,
+		opts:      opts,
+		line:      1,
+		col:       1,
+		state:     stateCode,
+		lineHead:  make([]byte, 0, maxExcerptBytes+2),
+		findings:  make([]Finding, 0),
+		docIndent: -1,
+	}
+	if syntax.markup == markupComponent {
+		c.region = "template"
+	}
+	if syntax.templates != nil {
+		c.region = "text"
+	}
+	return c
+}
+
+func (c *contentScanner) run() error {
+	for {
+		syntax := c.syntax
+		// startDocLine peeks further ahead, which may move the buffered
+		// bytes, so it runs before head is taken.
+		if syntax.markup != markupNone && !c.docLineStarted {
+			c.docLineStarted = true
+			c.startDocLine()
+			syntax = c.syntax
+		}
+		for c.lineStarts != nil && len(c.lineStarts) <= c.line {
+			c.lineStarts = append(c.lineStarts, c.state == stateCode)
+		}
+		if c.state == stateHeredoc && c.col == 1 && c.endHeredoc() {
+			continue
+		}
+		if syntax.latex && c.latexStep() {
+			continue
+		}
+		head, err := c.in.Peek(lookaheadBytes)
+		if len(head) == 0 {
+			if err != nil && err != io.EOF {
+				return err
+			}
+			break
+		}
+		if syntax.templates != nil && c.templateStep(head) {
+			continue
+		}
+
+		switch c.state {
 		case stateCode:
-			if syntax.blockStart != "" && strings.HasPrefix(text[i:], syntax.blockStart) {
-				i, line, col = advanceByToken(i, line, col, syntax.blockStart)
-				state = stateBlockComment
-				escaped = false
+			window := string(head)
+			if syntax.blockStart != "" && strings.HasPrefix(window, syntax.blockStart) {
+				c.skipToken(syntax.blockStart)
+				c.state = stateBlockComment
+				c.commentDepth = 1
+				c.escaped = false
 				continue
 			}
-			if token, ok := matchPrefix(text[i:], syntax.lineComments); ok {
-				i, line, col = advanceByToken(i, line, col, token)
-				state = stateLineComment
-				escaped = false
+			if token, ok := matchPrefix(window, syntax.lineComments); ok {
+				c.skipToken(token)
+				c.state = stateLineComment
+				c.escaped = false
+				continue
+			}
+			if syntax.tripleQuote && strings.HasPrefix(window, `"""`) {
+				c.skipToken(`"""`)
+				c.state = stateTripleString
+				c.escaped = false
+				continue
+			}
+			if syntax.heredoc && strings.HasPrefix(window, "<<") && c.startHeredoc() {
 				continue
 			}
 			if syntax.strings {
-				switch text[i] {
+				switch head[0] {
 				case '\'':
-					i++
-					col++
-					state = stateSingleString
-					escaped = false
+					c.skipToken("'")
+					c.state = stateSingleString
+					if syntax.goLiterals {
+						c.state = stateRune
+					}
+					c.escaped = false
 					continue
 				case '"':
-					i++
-					col++
-					state = stateDoubleString
-					escaped = false
+					c.skipToken("\"")
+					c.state = stateDoubleString
+					c.escaped = false
 					continue
 				case '`':
 					if syntax.backtick {
-						i++
-						col++
-						state = stateBacktickString
-						escaped = false
+						c.skipToken("`")
+						c.state = stateBacktickString
+						c.escaped = false
 						continue
 					}
 				}
 			}
 		case stateLineComment:
-			if text[i] == '\n' {
-				i++
-				line++
-				col = 1
-				state = stateCode
-				escaped = false
+			if isLineEnd(head) {
+				c.endLine()
+				c.state = stateCode
+				c.escaped = false
 				continue
 			}
 		case stateBlockComment:
-			if syntax.blockEnd != "" && strings.HasPrefix(text[i:], syntax.blockEnd) {
-				i, line, col = advanceByToken(i, line, col, syntax.blockEnd)
-				state = stateCode
-				escaped = false
+			if syntax.blockEnd != "" && bytes.HasPrefix(head, []byte(syntax.blockEnd)) {
+				c.skipToken(syntax.blockEnd)
+				if c.commentDepth--; syntax.nestedComments && c.commentDepth > 0 {
+					continue
+				}
+				c.state = stateCode
+				c.escaped = false
+				continue
+			}
+			if syntax.nestedComments && bytes.HasPrefix(head, []byte(syntax.blockStart)) {
+				c.skipToken(syntax.blockStart)
+				c.commentDepth++
 				continue
 			}
-		case stateSingleString:
-			if !escaped {
-				if text[i] == '\\' {
-					i++
-					col++
-					escaped = true
+		}
+		if syntax.svg && c.svgStep(head) {
+			continue
+		}
+		if syntax.escapes && c.state == stateCode && bytes.HasPrefix(head, []byte(`\\`)) {
+			c.skipToken(`\\`)
+			continue
+		}
+		if c.decodesEscapes() && !c.escaped {
+			if r, n, ok := decodeEscape(head); ok && (!syntax.escapes || head[1] == 'u') {
+				c.skipDecoded(r, head[:n], false)
+				continue
+			}
+		}
+		if c.opts.CheckEntities && syntax.entities && c.state != stateCodeBlock && head[0] == '&' {
+			if r, n, ok := decodeEntity(head); ok {
+				c.skipDecoded(r, head[:n], true)
+				continue
+			}
+		}
+		switch c.state {
+		case stateSingleString, stateRune:
+			if !c.escaped {
+				if head[0] == '\\' {
+					c.skipToken("\\")
+					c.escaped = true
 					continue
 				}
-				if text[i] == '\'' {
-					i++
-					col++
-					state = stateCode
+				if head[0] == '\'' {
+					c.skipToken("'")
+					c.state = stateCode
 					continue
 				}
 			}
 		case stateDoubleString:
-			if !escaped {
-				if text[i] == '\\' {
-					i++
-					col++
-					escaped = true
+			if !c.escaped {
+				if head[0] == '\\' {
+					c.skipToken("\\")
+					c.escaped = true
 					continue
 				}
-				if text[i] == '"' {
-					i++
-					col++
-					state = stateCode
+				if head[0] == '"' {
+					c.skipToken("\"")
+					c.state = stateCode
 					continue
 				}
 			}
 		case stateBacktickString:
-			if text[i] == '`' {
-				i++
-				col++
-				state = stateCode
+			if head[0] == '`' {
+				c.skipToken("`")
+				c.state = stateCode
+				continue
+			}
+		case stateTripleString:
+			if bytes.HasPrefix(head, []byte(`\"""`)) {
+				c.skipToken(`\"""`)
+				continue
+			}
+			if bytes.HasPrefix(head, []byte(`"""`)) {
+				c.skipToken(`"""`)
+				c.state = stateCode
 				continue
 			}
 		}
 
-		r, size := utf8.DecodeRuneInString(text[i:])
+		r, size := utf8.DecodeRune(head)
 		if r == utf8.RuneError && size == 1 {
-			if shouldInspect(state, opts) {
-				findings = append(findings, Finding{
-					Path:      path,
-					Line:      line,
-					Column:    col,
-					Character: "?",
-					CodePoint: "invalid-utf8",
-					Category:  "Invalid UTF-8",
-					Severity:  opts.Severity,
-					Message:   "Detected invalid UTF-8 byte sequence",
-					Excerpt:   lineExcerpt(lines, line),
+			if shouldInspect(c.state, c.opts) {
+				c.pending = append(c.pending, Finding{
+					Path:       c.path,
+					Line:       c.line,
+					Column:     c.col,
+					Character:  "?",
+					CodePoint:  "invalid-utf8",
+					Category:   "Invalid UTF-8",
+					Confidence: ConfidenceHigh,
+					Severity:   c.opts.Severity,
+					Message:    "Detected invalid UTF-8 byte sequence",
+					Region:     c.region,
 				})
 			}
-			i++
-			col++
-			if escaped {
-				escaped = false
-			}
+			c.consume(1)
+			c.col++
+			c.escaped = false
 			continue
 		}
 
-		if shouldInspect(state, opts) && !isAllowedRune(r, opts.AllowRunes) {
-			category := categoryForRune(r)
-			codePoint := fmt.Sprintf("U+%04X", r)
-			findings = append(findings, Finding{
-				Path:      path,
-				Line:      line,
-				Column:    col,
-				Character: string(r),
-				CodePoint: codePoint,
-				Category:  category,
-				Severity:  opts.Severity,
-				Message:   fmt.Sprintf("Detected %s character %q (%s)", category, string(r), codePoint),
-				Excerpt:   lineExcerpt(lines, line),
-			})
+		if finding, ok := c.runeFinding(r); ok {
+			c.pending = append(c.pending, finding)
 		}
 
-		i += size
-		if r == '\n' {
-			line++
-			col = 1
-			if state == stateLineComment {
-				state = stateCode
+		if isLineEnd(head) {
+			c.endLine()
+			if c.state == stateLineComment {
+				c.state = stateCode
 			}
 		} else {
-			col++
+			c.consume(size)
+			c.col++
+		}
+		c.escaped = false
+	}
+	c.flushLine()
+	return nil
+}
+
+// decodesEscapes reports whether escape sequences are decoded in the current
+// state: inside string literals with DecodeEscapes, and everywhere outside
+// comments in files whose syntax stores text escaped, such as .properties.
+func (c *contentScanner) decodesEscapes() bool {
+	if c.syntax.escapes {
+		return c.state == stateCode || isString(c.state)
+	}
+	if c.syntax.goLiterals && c.state == stateBacktickString {
+		return false
+	}
+	return c.opts.DecodeEscapes && isString(c.state)
+}
+
+// skipDecoded reports the non-ASCII character r encoded by the escape
+// sequence or HTML entity seq and consumes seq. suggestFix adds the ASCII
+// equivalent of r as the fix.
+func (c *contentScanner) skipDecoded(r rune, seq []byte, suggestFix bool) {
+	if r >= utf8.RuneSelf {
+		if finding, ok := c.runeFinding(r); ok {
+			finding.Escape = string(seq)
+			if suggestFix && finding.Fix == "" {
+				finding.Fix = asciiEquivalents[r]
+			}
+			c.pending = append(c.pending, finding)
+		}
+	}
+	n := len(seq)
+	c.consume(n)
+	c.col += n
+}
+
+// runeFinding returns the finding for r at the current position, if r is
+// reported there.
+func (c *contentScanner) runeFinding(r rune) (Finding, bool) {
+	if !shouldInspect(c.state, c.opts) || isAllowedRune(r, c.opts.AllowRunes) || unicode.In(r, c.opts.AllowScripts...) || (c.opts.AllowLatinExtended && isLatinExtended(r)) || (c.state == stateMath && isMathRune(r)) {
+		return Finding{}, false
+	}
+	finding := Finding{
+		Path:       c.path,
+		Line:       c.line,
+		Column:     c.col,
+		Character:  string(r),
+		CodePoint:  fmt.Sprintf("U+%04X", r),
+		Name:       uniname.Name(r),
+		Category:   categoryForRune(r),
+		Severity:   c.opts.Severity,
+		Confidence: confidenceFor(r, c.state, c.syntax),
+		Region:     c.region,
+	}
+	if c.state == stateRune {
+		finding.Region = "rune"
+	}
+	if ascii, ok := confusableASCII(r); ok {
+		finding.Fix = string(ascii)
+	}
+	if custom, ok := customCategory(r, c.opts.Categories); ok {
+		finding.Category = custom.Name
+		finding.Fix = custom.Fix
+		if custom.Severity != "" {
+			finding.Severity = custom.Severity
+		}
+	} else if c.opts.Categorizer != nil {
+		category, severity := c.opts.Categorizer.Categorize(r)
+		if severity == SeverityOff {
+			return Finding{}, false
+		}
+		if category != "" {
+			finding.Category = category
+		}
+		if severity != "" {
+			finding.Severity = severity
+		}
+	}
+	return finding, true
+}
+
+// consume discards n bytes from the input, keeping the leading bytes of the
+// current line for excerpts.
+func (c *contentScanner) consume(n int) {
+	raw, _ := c.in.Peek(n)
+	if len(raw) > 0 {
+		c.lineCR = raw[len(raw)-1] == '\r'
+	}
+	for _, b := range raw {
+		if c.lineIgnored {
+			break
+		}
+		switch {
+		case b == IgnoreDirective[c.directive]:
+			c.directive++
+		case b == IgnoreDirective[0]:
+			c.directive = 1
+		default:
+			c.directive = 0
+		}
+		c.lineIgnored = c.directive == len(IgnoreDirective)
+	}
+	if c.syntax.markup == markupRST {
+		for _, b := range raw {
+			if b != ' ' && b != '\t' && b != '\r' {
+				c.docTail = [2]byte{c.docTail[1], b}
+			}
+		}
+	}
+	if c.opts.IgnoreURLs || c.opts.IgnoreBlobs {
+		for _, b := range raw {
+			if isWordDelimiter(b) && !c.inDataURI(b) {
+				c.endWord()
+				continue
+			}
+			if len(c.word) == 0 {
+				c.wordCol = c.col
+			}
+			if len(c.word) < maxWordBytes {
+				c.word = append(c.word, b)
+			}
+		}
+	}
+	if len(c.opts.IgnoreLinePatterns) > 0 || len(c.opts.AllowPatterns) > 0 {
+		if room := maxLineMatchBytes - len(c.lineText); room > 0 {
+			c.lineText = append(c.lineText, raw[:min(len(raw), room)]...)
+		}
+	}
+	if room := cap(c.lineHead) - len(c.lineHead); room > 0 {
+		if len(raw) > room {
+			raw = raw[:room]
+		}
+		c.lineHead = append(c.lineHead, raw...)
+	}
+	if len(c.lineHead) == cap(c.lineHead) {
+		c.lineLong = true
+	}
+	_, _ = c.in.Discard(n)
+}
+
+// maxLineMatchBytes bounds how much of a line IgnoreLinePatterns are
+// matched against, so minified files do not buffer whole lines.
+const maxLineMatchBytes = 64 << 10
+
+// maxWordBytes bounds how much of a word endWord inspects; URL schemes,
+// email addresses, and blobs are recognized well before that.
+const maxWordBytes = 4096
+
+// minBlobBytes is the shortest base64 or hex token IgnoreBlobs skips.
+const minBlobBytes = 40
+
+// isWordDelimiter reports whether b ends a word that may hold a URL or email
+// address.
+func isWordDelimiter(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\r', '\f', '\v', '"', '\'', '`', '<', '>', '(', ')', '[', ']', '{', '}':
+		return true
+	}
+	return false
+}
+
+// inDataURI reports whether delimiter b continues a data URI rather than
+// ending the current word; data URIs end only at whitespace, quotes, or ")",
+// so inline markup such as "data:image/svg+xml,<svg>" stays in one word.
+func (c *contentScanner) inDataURI(b byte) bool {
+	switch b {
+	case '<', '>', '[', ']', '{', '}', '(':
+	default:
+		return false
+	}
+	if !c.opts.IgnoreBlobs {
+		return false
+	}
+	_, ok := dataURIStart(c.word)
+	return ok
+}
+
+// endWord drops the pending findings that fall inside the current word when
+// it is a URL, email address, or blob, then starts a new word.
+func (c *contentScanner) endWord() {
+	if len(c.word) == 0 {
+		return
+	}
+	if start, ok := c.skippedWordStart(); ok {
+		col := c.wordCol + utf8.RuneCount(c.word[:start])
+		kept := c.pending[:0]
+		for _, finding := range c.pending {
+			if finding.Line != c.line || finding.Column < col {
+				kept = append(kept, finding)
+			}
 		}
-		if escaped {
-			escaped = false
+		c.pending = kept
+	}
+	c.word = c.word[:0]
+}
+
+// skippedWordStart returns the byte offset from which the current word is
+// skipped under IgnoreURLs and IgnoreBlobs.
+func (c *contentScanner) skippedWordStart() (int, bool) {
+	if c.opts.IgnoreURLs {
+		if start, ok := urlStart(c.word); ok {
+			return start, true
+		}
+	}
+	if c.opts.IgnoreBlobs {
+		if start, ok := dataURIStart(c.word); ok {
+			return start, true
+		}
+		if isBlob(c.word) {
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// dataURIStart returns the byte offset of a "data:" URI in word.
+func dataURIStart(word []byte) (int, bool) {
+	for i := 0; i+5 <= len(word); i++ {
+		if (i == 0 || !isSchemeByte(word[i-1])) && strings.EqualFold(string(word[i:i+5]), "data:") {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// isBlob reports whether word looks like base64 or hex data: at least
+// minBlobBytes base64 alphabet bytes mixing letters and digits, with at most
+// one other byte in ten.
+func isBlob(word []byte) bool {
+	var alphabet, other int
+	var letters, digits bool
+	for _, b := range word {
+		switch {
+		case b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z':
+			letters = true
+			alphabet++
+		case b >= '0' && b <= '9':
+			digits = true
+			alphabet++
+		case b == '+' || b == '/' || b == '=' || b == '-' || b == '_':
+			alphabet++
+		default:
+			other++
 		}
 	}
+	return alphabet >= minBlobBytes && letters && digits && other*10 <= alphabet
+}
+
+// urlStart returns the byte offset at which word starts a URL ("scheme://",
+// "www.") or whether the whole word is an email address ("user@host.tld").
+func urlStart(word []byte) (int, bool) {
+	if i := bytes.Index(word, []byte("://")); i > 0 {
+		start := i
+		for start > 0 && isSchemeByte(word[start-1]) {
+			start--
+		}
+		if start < i {
+			return start, true
+		}
+	}
+	for i := 0; i+4 <= len(word); i++ {
+		if (i == 0 || !isSchemeByte(word[i-1])) && strings.EqualFold(string(word[i:i+4]), "www.") && i+4 < len(word) {
+			return i, true
+		}
+	}
+	if at := bytes.IndexByte(word, '@'); at > 0 {
+		domain := word[at+1:]
+		if dot := bytes.IndexByte(domain, '.'); dot > 0 && dot < len(domain)-1 {
+			return 0, true
+		}
+	}
+	return 0, false
+}
 
-	return findings
+func isSchemeByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '+' || b == '-' || b == '.'
+}
+
+// startHeredoc consumes a heredoc opener such as <<EOT or <<-EOT and enters
+// stateHeredoc. It reports false when "<<" is not followed by an identifier.
+func (c *contentScanner) startHeredoc() bool {
+	line, _ := c.in.Peek(docPeekBytes)
+	n := 2
+	if n < len(line) && line[n] == '-' {
+		n++
+	}
+	start := n
+	for n < len(line) && (line[n] == '_' || line[n] >= 'a' && line[n] <= 'z' || line[n] >= 'A' && line[n] <= 'Z' || n > start && line[n] >= '0' && line[n] <= '9') {
+		n++
+	}
+	if n == start {
+		return false
+	}
+	c.heredoc = string(line[start:n])
+	c.skipToken(string(line[:n]))
+	c.state = stateHeredoc
+	return true
+}
+
+// endHeredoc consumes the closing delimiter line of the open heredoc and
+// returns to code. Leading whitespace is allowed before the delimiter.
+func (c *contentScanner) endHeredoc() bool {
+	line, _ := c.in.Peek(docPeekBytes)
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	trimmed := bytes.TrimLeft(line, " \t")
+	if string(bytes.TrimRight(trimmed, " \t\r")) != c.heredoc {
+		return false
+	}
+	c.skipToken(string(line[:len(line)-len(trimmed)+len(c.heredoc)]))
+	c.state = stateCode
+	c.heredoc = ""
+	return true
+}
+
+// skipToken consumes a single-line syntax token such as "//" or a quote.
+func (c *contentScanner) skipToken(token string) {
+	c.consume(len(token))
+	c.col += utf8.RuneCountInString(token)
+}
+
+// isLineEnd reports whether head starts with a line break: \n, or \r not
+// followed by \n, which editors also treat as one. The \r of \r\n is part
+// of the line, so columns before it are unaffected and excerpts drop it.
+func isLineEnd(head []byte) bool {
+	return head[0] == '\n' || (head[0] == '\r' && (len(head) == 1 || head[1] != '\n'))
+}
+
+// endLine consumes the line break byte, records its style, and starts the
+// next line.
+func (c *contentScanner) endLine() {
+	switch head, _ := c.in.Peek(1); {
+	case head[0] == '\r':
+		c.eol |= eolCR
+	case c.lineCR:
+		c.eol |= eolCRLF
+	default:
+		c.eol |= eolLF
+	}
+	c.lineCR = false
+	_, _ = c.in.Discard(1)
+	c.flushLine()
+	if c.syntax.markup != markupNone {
+		c.endDocLine()
+		c.docLineStarted = false
+	}
+	c.ignoreLine = c.lineIgnored
+	c.lineIgnored = false
+	c.directive = 0
+	c.line++
+	c.col = 1
+	c.lineHead = c.lineHead[:0]
+	c.lineText = c.lineText[:0]
+	c.lineLong = false
+}
+
+// flushLine attaches the line excerpt to the findings collected on the
+// current line and records them, honoring MaxFindingsPerFile.
+func (c *contentScanner) flushLine() {
+	c.endWord()
+	if len(c.pending) == 0 {
+		return
+	}
+	if c.ignoreLine || c.lineMatchesIgnorePattern() {
+		c.pending = c.pending[:0]
+		return
+	}
+	excerpt := ""
+	switch c.opts.Excerpts {
+	case ExcerptOmit:
+	case ExcerptRedact:
+		excerpt = redact(lineExcerpt(c.lineHead, c.lineLong))
+	default:
+		excerpt = lineExcerpt(c.lineHead, c.lineLong)
+	}
+	text := strings.TrimSpace(string(c.lineHead))
+	allowed := c.allowedSpans()
+	for _, finding := range c.pending {
+		if allowed.allows(finding) {
+			continue
+		}
+		if c.opts.MinConfidence != "" && !finding.Confidence.AtLeast(c.opts.MinConfidence) {
+			continue
+		}
+		if level, ok := c.level(finding.Category, finding.Region); ok {
+			finding.Severity = level
+		}
+		if finding.Severity == SeverityOff {
+			continue
+		}
+		key := finding.CodePoint + "\x00" + text
+		if c.seen == nil {
+			c.seen = make(map[string]int)
+		}
+		finding.Fingerprint = Fingerprint(finding.Path, key, c.seen[key])
+		c.seen[key]++
+		if c.opts.MaxFindingsPerFile > 0 && len(c.findings) >= c.opts.MaxFindingsPerFile {
+			c.omitted++
+			continue
+		}
+		finding.Excerpt = excerpt
+		if c.opts.Excerpts == ExcerptRedact {
+			finding.Character = redact(finding.Character)
+		}
+		if finding.Message == "" && strings.HasPrefix(finding.Escape, "&") && c.syntax.entities {
+			finding.Message = fmt.Sprintf("HTML entity %s renders as %s character %q (%s)", finding.Escape, finding.Category, finding.Character, finding.CodePoint)
+		}
+		if finding.Message == "" && finding.Escape != "" {
+			finding.Message = fmt.Sprintf("Escape sequence %s encodes %s character %q (%s)", finding.Escape, finding.Category, finding.Character, finding.CodePoint)
+		}
+		if finding.Message == "" {
+			finding.Message = categoryMessage(finding.Category, finding.CodePoint)
+		}
+		if finding.Message == "" {
+			finding.Message = fmt.Sprintf("Detected %s character %q (%s)", finding.Category, finding.Character, finding.CodePoint)
+		}
+		if template, ok := c.messageTemplate(finding.Category); ok {
+			finding.Message = expandMessage(template, finding)
+		}
+		c.findings = append(c.findings, finding)
+	}
+	c.pending = c.pending[:0]
+}
+
+// lineMatchesIgnorePattern reports whether one of IgnoreLinePatterns
+// matches the current line.
+func (c *contentScanner) lineMatchesIgnorePattern() bool {
+	line := bytes.TrimSuffix(c.lineText, []byte("\r"))
+	for _, pattern := range c.opts.IgnoreLinePatterns {
+		if pattern.Match(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedSpan is the columns [start, end) of an AllowPatterns match.
+type allowedSpan struct {
+	start, end int
+	categories []string
+}
+
+type allowedSpans []allowedSpan
+
+// allowedSpans returns the column spans of the AllowPatterns matches on the
+// current line.
+func (c *contentScanner) allowedSpans() allowedSpans {
+	if len(c.opts.AllowPatterns) == 0 {
+		return nil
+	}
+	line := bytes.TrimSuffix(c.lineText, []byte("\r"))
+	var spans allowedSpans
+	for _, pattern := range c.opts.AllowPatterns {
+		for _, m := range pattern.Pattern.FindAllIndex(line, -1) {
+			start := 1 + utf8.RuneCount(line[:m[0]])
+			end := start + utf8.RuneCount(line[m[0]:m[1]])
+			spans = append(spans, allowedSpan{start: start, end: end, categories: pattern.Categories})
+		}
+	}
+	return spans
+}
+
+// allows reports whether finding lies inside a span that covers its
+// category.
+func (spans allowedSpans) allows(finding Finding) bool {
+	for _, span := range spans {
+		if finding.Column < span.start || finding.Column >= span.end {
+			continue
+		}
+		if len(span.categories) == 0 || slices.ContainsFunc(span.categories, func(category string) bool {
+			return InCategory(finding.Category, category)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// level returns the policy level for category in region, falling back to
+// its umbrella category and then "*".
+func (c *contentScanner) level(category, region string) (Severity, bool) {
+	if len(c.policies) == 0 {
+		return "", false
+	}
+	levels, ok := c.levels[region]
+	if !ok {
+		levels = make(map[string]Severity)
+		for _, policy := range c.policies {
+			if len(policy.Regions) > 0 && !slices.Contains(policy.Regions, region) {
+				continue
+			}
+			for category, level := range policy.Levels {
+				levels[category] = level
+			}
+		}
+		if c.levels == nil {
+			c.levels = make(map[string]map[string]Severity)
+		}
+		c.levels[region] = levels
+	}
+	return categoryEntry(levels, category)
+}
+
+func (c *contentScanner) messageTemplate(category string) (string, bool) {
+	if len(c.opts.MessageTemplates) == 0 {
+		return "", false
+	}
+	return categoryEntry(c.opts.MessageTemplates, category)
+}
+
+// expandMessage fills the {character}, {codepoint}, {name}, {category},
+// {path}, {line}, {column}, and {message} placeholders of a message template.
+// {message} is the default message. Unknown placeholders are kept as-is.
+func expandMessage(template string, f Finding) string {
+	return strings.NewReplacer(
+		"{character}", f.Character,
+		"{codepoint}", f.CodePoint,
+		"{name}", f.Name,
+		"{category}", f.Category,
+		"{path}", filepath.ToSlash(f.Path),
+		"{line}", strconv.Itoa(f.Line),
+		"{column}", strconv.Itoa(f.Column),
+		"{message}", f.Message,
+	).Replace(template)
+}
+
+// Fingerprint hashes the file path, a key such as the code point with its
+// line text, and the occurrence index of that key in the file. Line numbers
+// are left out so findings keep their fingerprint when unrelated lines are
+// added above.
+func Fingerprint(path, key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", filepath.ToSlash(path), key, occurrence)))
+	return hex.EncodeToString(sum[:8])
 }
 
 func matchPrefix(input string, prefixes []string) (string, bool) {
@@ -503,30 +2413,96 @@ func matchPrefix(input string, prefixes []string) (string, bool) {
 	return "", false
 }
 
-func advanceByToken(i, line, col int, token string) (int, int, int) {
-	for _, r := range token {
-		i += utf8.RuneLen(r)
-		if r == '\n' {
-			line++
-			col = 1
-			continue
-		}
-		col++
-	}
-	return i, line, col
-}
-
 func shouldInspect(state scanState, opts Options) bool {
 	switch state {
 	case stateLineComment, stateBlockComment:
 		return !opts.IgnoreComments
-	case stateSingleString, stateDoubleString, stateBacktickString:
+	case stateSingleString, stateDoubleString, stateBacktickString, stateTripleString, stateHeredoc:
 		return !opts.IgnoreStrings
+	case stateCodeBlock:
+		return !opts.IgnoreCodeBlocks
+	case stateSkipped:
+		return false
 	default:
 		return true
 	}
 }
 
+func isString(state scanState) bool {
+	switch state {
+	case stateSingleString, stateDoubleString, stateBacktickString, stateRune, stateTripleString, stateHeredoc:
+		return true
+	}
+	return false
+}
+
+// decodeEscape decodes the escape sequence at the start of head: \uXXXX,
+// \u{X...}, \UXXXXXXXX, \x{X...}, &#xX...;, or &#D...;, joining a \uXXXX
+// surrogate pair into one rune. It returns the decoded rune and the length
+// of the sequence.
+func decodeEscape(head []byte) (rune, int, bool) {
+	switch {
+	case bytes.HasPrefix(head, []byte(`\u{`)), bytes.HasPrefix(head, []byte(`\x{`)):
+		return decodeCodePoint(head, 3, "}", 16, 6)
+	case bytes.HasPrefix(head, []byte(`\u`)):
+		r, n, ok := decodeCodePoint(head, 2, "", 16, 4)
+		if ok && utf16.IsSurrogate(r) && bytes.HasPrefix(head[n:], []byte(`\u`)) {
+			if low, m, ok := decodeCodePoint(head[n:], 2, "", 16, 4); ok {
+				if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+					return pair, n + m, true
+				}
+			}
+		}
+		return r, n, ok
+	case bytes.HasPrefix(head, []byte(`\U`)):
+		return decodeCodePoint(head, 2, "", 16, 8)
+	case bytes.HasPrefix(head, []byte("&#x")), bytes.HasPrefix(head, []byte("&#X")):
+		return decodeCodePoint(head, 3, ";", 16, 6)
+	case bytes.HasPrefix(head, []byte("&#")):
+		return decodeCodePoint(head, 2, ";", 10, 7)
+	}
+	return 0, 0, false
+}
+
+// decodeCodePoint parses the digits of an escape starting at offset start.
+// With a terminator the digits are variable-length up to maxDigits and must
+// be followed by it; without one exactly maxDigits digits are required.
+func decodeCodePoint(head []byte, start int, terminator string, base, maxDigits int) (rune, int, bool) {
+	end := start
+	for end < len(head) && end-start < maxDigits && isDigitIn(head[end], base) {
+		end++
+	}
+	if end == start {
+		return 0, 0, false
+	}
+	n := end
+	if terminator == "" {
+		if end-start != maxDigits {
+			return 0, 0, false
+		}
+	} else {
+		if !bytes.HasPrefix(head[end:], []byte(terminator)) {
+			return 0, 0, false
+		}
+		n += len(terminator)
+	}
+	value, err := strconv.ParseUint(string(head[start:end]), base, 32)
+	if err != nil || value > unicode.MaxRune {
+		return 0, 0, false
+	}
+	return rune(value), n, true
+}
+
+func isDigitIn(b byte, base int) bool {
+	switch {
+	case b >= '0' && b <= '9':
+		return true
+	case base == 16:
+		return b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+	}
+	return false
+}
+
 func isAllowedRune(r rune, allow map[rune]struct{}) bool {
 	if r == '\n' || r == '\r' || r == '\t' {
 		return true
@@ -538,21 +2514,98 @@ func isAllowedRune(r rune, allow map[rune]struct{}) bool {
 	return ok
 }
 
-func lineExcerpt(lines []string, line int) string {
-	if line < 1 || line > len(lines) {
-		return ""
+// isLatinExtended reports whether r is a non-ASCII Latin letter, such as é,
+// ü, or ß, or a combining diacritical mark used to spell one in decomposed
+// form. Fullwidth Latin letters are excluded since they are rarely intended
+// in source code.
+func isLatinExtended(r rune) bool {
+	if r >= 0xFF21 && r <= 0xFF5A {
+		return false
+	}
+	return unicode.In(r, unicode.Latin) || (r >= 0x0300 && r <= 0x036F)
+}
+
+// redact replaces every non-ASCII rune in text with a code point placeholder
+// such as <U+3042>, and invalid bytes with their hex value.
+func redact(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, "<0x%02X>", text[i])
+		case r > unicode.MaxASCII:
+			fmt.Fprintf(&b, "<U+%04X>", r)
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// lineExcerpt renders the buffered head of a line. long reports that the line
+// continued past the buffer, in which case the excerpt is always truncated.
+func lineExcerpt(head []byte, long bool) string {
+	if !long {
+		head = bytes.TrimRight(head, "\r")
 	}
-	excerpt := strings.TrimRight(lines[line-1], "\r")
-	if len(excerpt) > 160 {
-		return excerpt[:160] + "..."
+	if len(head) > maxExcerptBytes {
+		return string(head[:maxExcerptBytes]) + "..."
 	}
-	return excerpt
+	return string(head)
+}
+
+// confidenceFor rates a finding for r found in state. Outside comments and
+// strings of a known language the character is part of the code itself,
+// such as a confusable in an identifier, which is almost always a mistake.
+// Elsewhere letters suggest untranslated text, and format characters
+// break comparisons of the text, while a lone symbol in prose is often
+// intentional.
+func confidenceFor(r rune, state scanState, syntax syntaxRules) Confidence {
+	known := len(syntax.lineComments) > 0 || syntax.blockStart != "" || syntax.strings
+	switch {
+	case known && state == stateCode, isTagRune(r):
+		return ConfidenceHigh
+	case unicode.IsLetter(r) || unicode.IsMark(r) || IsFormatCharacter(r):
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// customCategory returns the first category with a range containing r.
+func customCategory(r rune, categories []Category) (Category, bool) {
+	for _, category := range categories {
+		for _, rr := range category.Ranges {
+			if r >= rr.Lo && r <= rr.Hi {
+				return category, true
+			}
+		}
+	}
+	return Category{}, false
 }
 
 func categoryForRune(r rune) string {
 	switch {
-	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
-		return "CJK"
+	case isVariationSelector(r) || isTagRune(r):
+		return categoryInvisible
+	case IsFormatCharacter(r):
+		return categoryFormat
+	case unicode.Is(unicode.Co, r):
+		return categoryPrivateUse
+	case isConfusableLatin(r):
+		return categoryConfusableLatin
+	case unicode.Is(unicode.Sc, r):
+		return categoryCurrency
+	case unicode.Is(measurementSymbols, r):
+		return categoryMeasurement
+	case unicode.In(r, unicode.Han):
+		return "Han"
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return "Kana"
+	case unicode.In(r, unicode.Hangul):
+		return "Hangul"
 	case unicode.In(r, unicode.Cyrillic):
 		return "Cyrillic"
 	case unicode.In(r, unicode.Arabic):
@@ -567,9 +2620,12 @@ func categoryForRune(r rune) string {
 		return "Greek"
 	case unicode.In(r, unicode.Latin):
 		return "Latin Extended"
-	case unicode.IsPunct(r) || unicode.IsSymbol(r):
+	}
+	if name, ok := scriptCategory(r); ok {
+		return name
+	}
+	if unicode.IsPunct(r) || unicode.IsSymbol(r) {
 		return "Unicode Symbol"
-	default:
-		return "Other Unicode"
 	}
+	return "Other Unicode"
 }
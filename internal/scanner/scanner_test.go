@@ -1,18 +1,33 @@
 package scanner
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 )
 
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failure")
+}
+
 func TestScanDetectsUnicodeCategories(t *testing.T) {
 	tests := []struct {
 		name         string
 		file         string
 		wantCategory string
 	}{
-		{name: "cjk", file: "japanese.go", wantCategory: "CJK"},
+		{name: "cjk", file: "japanese.go", wantCategory: "Kana"},
 		{name: "cyrillic", file: "cyrillic.txt", wantCategory: "Cyrillic"},
 		{name: "arabic", file: "arabic.txt", wantCategory: "Arabic"},
 		{name: "thai", file: "thai.txt", wantCategory: "Thai"},
@@ -38,6 +53,45 @@ func TestScanDetectsUnicodeCategories(t *testing.T) {
 	}
 }
 
+func TestResultMergeSummary(t *testing.T) {
+	var res Result
+	res.Merge(nil)
+	if res.Summary.FindingsByCategory == nil || res.Summary.FindingsBySeverity == nil {
+		t.Fatalf("expected empty maps without findings, got %+v", res.Summary)
+	}
+	res.Merge([]Finding{
+		{Path: "a.go", Category: "CJK", Severity: SeverityError},
+		{Path: "a.go", Category: "CJK", Severity: SeverityWarning},
+		{Path: "b.go", Category: "Cyrillic", Severity: SeverityError},
+	})
+	if want := map[string]int{"CJK": 2, "Cyrillic": 1}; !reflect.DeepEqual(res.Summary.FindingsByCategory, want) {
+		t.Fatalf("findingsByCategory = %v, want %v", res.Summary.FindingsByCategory, want)
+	}
+	if want := map[Severity]int{SeverityError: 2, SeverityWarning: 1}; !reflect.DeepEqual(res.Summary.FindingsBySeverity, want) {
+		t.Fatalf("findingsBySeverity = %v, want %v", res.Summary.FindingsBySeverity, want)
+	}
+}
+
+func TestResultLimit(t *testing.T) {
+	res := Result{Findings: []Finding{
+		{Path: "a.go", Line: 3},
+		{Path: "b.go", Line: 1},
+		{Path: "a.go", Line: 1},
+		{Path: "a.go", Line: 2},
+	}}
+	res.Limit(2)
+	want := []Finding{{Path: "a.go", Line: 1}, {Path: "a.go", Line: 2}, {Path: "b.go", Line: 1}}
+	if !reflect.DeepEqual(res.Findings, want) {
+		t.Fatalf("findings = %+v, want %+v", res.Findings, want)
+	}
+	if want := []LimitedFile{{Path: "a.go", Reported: 2, Omitted: 1}}; !reflect.DeepEqual(res.LimitedFiles, want) {
+		t.Fatalf("limitedFiles = %+v, want %+v", res.LimitedFiles, want)
+	}
+	if res.Summary.Findings != 3 || res.Summary.FindingsOmitted != 1 {
+		t.Fatalf("unexpected summary: %+v", res.Summary)
+	}
+}
+
 func TestScanIgnoreCommentsAndStrings(t *testing.T) {
 	path := filepath.Join("testdata", "fixtures", "string_comment.go")
 
@@ -63,6 +117,211 @@ func TestScanIgnoreCommentsAndStrings(t *testing.T) {
 	}
 }
 
+func TestScanDedupeContent(t *testing.T) {
+	tmp := t.TempDir()
+	for name, content := range map[string]string{"b.go": "// 日 本\n", "a.go": "// 日 本\n", "c.go": "// 日\n"} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	opts := Options{Root: tmp, DisplayRoot: tmp, Include: []string{"**/*.go"}, MaxFindingsPerFile: 1, DedupeContent: true}
+	res, err := Scan([]string{tmp}, opts)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	var got []string
+	for _, f := range res.Findings {
+		got = append(got, f.Path+":"+strings.Join(f.Duplicates, ","))
+	}
+	if want := []string{"a.go:b.go", "c.go:"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("findings = %q, want %q", got, want)
+	}
+	if len(res.LimitedFiles) != 1 || res.LimitedFiles[0].Path != "a.go" || res.Summary.FilesScanned != 3 {
+		t.Fatalf("unexpected limited files %+v or summary %+v", res.LimitedFiles, res.Summary)
+	}
+}
+
+func TestScanHelpURIs(t *testing.T) {
+	tmp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmp, "a.go"), []byte("// 日 ж\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tests := []struct {
+		name string
+		uris map[string]string
+		want []string
+	}{
+		{name: "none", want: []string{"", ""}},
+		{name: "per category", uris: map[string]string{"cjk": "https://wiki.example.com/cjk"}, want: []string{"https://wiki.example.com/cjk", ""}},
+		{name: "fallback", uris: map[string]string{"cjk": "https://wiki.example.com/cjk", "*": "https://wiki.example.com/"}, want: []string{"https://wiki.example.com/cjk", "https://wiki.example.com/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Scan([]string{tmp}, Options{Root: tmp, Include: []string{"**/*.go"}, HelpURIs: tt.uris})
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			var got []string
+			for _, f := range res.Findings {
+				got = append(got, f.HelpURI)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("help URIs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanHeadLimits(t *testing.T) {
+	tmp := t.TempDir()
+	content := "-- 日\n-- 本\n-- 語\n"
+	for _, name := range []string{"seed.sql", "a.go"} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	tests := []struct {
+		name      string
+		limit     HeadLimit
+		want      []string
+		truncated []TruncatedFile
+	}{
+		{name: "lines", limit: HeadLimit{Lines: 2}, want: []string{"a.go:日", "a.go:本", "a.go:語", "seed.sql:日", "seed.sql:本"}, truncated: []TruncatedFile{{Path: "seed.sql", Bytes: 14, Lines: 2}}},
+		{name: "bytes inside a character", limit: HeadLimit{Bytes: 12}, want: []string{"a.go:日", "a.go:本", "a.go:語", "seed.sql:日"}, truncated: []TruncatedFile{{Path: "seed.sql", Bytes: 10, Lines: 2}}},
+		{name: "both", limit: HeadLimit{Bytes: 100, Lines: 1}, want: []string{"a.go:日", "a.go:本", "a.go:語", "seed.sql:日"}, truncated: []TruncatedFile{{Path: "seed.sql", Bytes: 7, Lines: 1}}},
+		{name: "whole file", limit: HeadLimit{Lines: 3}, want: []string{"a.go:日", "a.go:本", "a.go:語", "seed.sql:日", "seed.sql:本", "seed.sql:語"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.limit.Pattern = "**/*.sql"
+			res, err := Scan([]string{tmp}, Options{Root: tmp, DisplayRoot: tmp, HeadLimits: []HeadLimit{tt.limit}})
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			var got []string
+			for _, f := range res.Findings {
+				got = append(got, f.Path+":"+f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(res.TruncatedFiles, tt.truncated) {
+				t.Fatalf("findings = %q, truncated = %+v; want %q, %+v", got, res.TruncatedFiles, tt.want, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestScanAllowFiles(t *testing.T) {
+	tmp := t.TempDir()
+	content := "// 日\n// 本\n"
+	if err := os.MkdirAll(filepath.Join(tmp, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"docs/intro_ja.md", "a.md"} {
+		if err := os.WriteFile(filepath.Join(tmp, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	sum := "dadf35ac5530ea68f8a377bb7e76a5eeb04f533a241f0fd3c4dd2f7fb5104bdc"
+	tests := []struct {
+		name    string
+		files   []AllowFile
+		heads   []HeadLimit
+		want    []string
+		skipped []string
+	}{
+		{name: "none", want: []string{"a.md:日", "a.md:本", "docs/intro_ja.md:日", "docs/intro_ja.md:本"}},
+		{name: "unchanged", files: []AllowFile{{Path: "docs/intro_ja.md", SHA256: sum}}, want: []string{"a.md:日", "a.md:本"}, skipped: []string{"docs/intro_ja.md:allowed-hash"}},
+		{name: "slash path", files: []AllowFile{{Path: "./docs/intro_ja.md", SHA256: sum}}, want: []string{"a.md:日", "a.md:本"}, skipped: []string{"docs/intro_ja.md:allowed-hash"}},
+		{name: "changed", files: []AllowFile{{Path: "docs/intro_ja.md", SHA256: strings.Repeat("0", 64)}}, want: []string{"a.md:日", "a.md:本", "docs/intro_ja.md:日", "docs/intro_ja.md:本"}},
+		{name: "one of several", files: []AllowFile{{Path: "docs/intro_ja.md", SHA256: strings.Repeat("0", 64)}, {Path: "docs/intro_ja.md", SHA256: sum}}, want: []string{"a.md:日", "a.md:本"}, skipped: []string{"docs/intro_ja.md:allowed-hash"}},
+		{name: "head limited", files: []AllowFile{{Path: "docs/intro_ja.md", SHA256: sum}}, heads: []HeadLimit{{Pattern: "**/*.md", Lines: 1}}, want: []string{"a.md:日"}, skipped: []string{"docs/intro_ja.md:allowed-hash"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Scan([]string{tmp}, Options{Root: tmp, DisplayRoot: tmp, Include: []string{"**/*.md"}, AllowFiles: tt.files, HeadLimits: tt.heads})
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			var got []string
+			for _, f := range res.Findings {
+				got = append(got, filepath.ToSlash(f.Path)+":"+f.Character)
+			}
+			var skipped []string
+			for _, s := range res.SkippedFiles {
+				skipped = append(skipped, filepath.ToSlash(s.Path)+":"+string(s.Reason))
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Fatalf("findings = %q, skipped = %q; want %q, %q", got, skipped, tt.want, tt.skipped)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		sum     string
+		scanned bool
+		reason  string
+	}{
+		{sum: sum, reason: "file content matches its allow_files sha256 and is skipped"},
+		{sum: strings.Repeat("0", 64), scanned: true, reason: "file changed since it was allowed in allow_files and is scanned; its sha256 is now " + sum},
+	} {
+		got, err := Explain(filepath.Join(tmp, "docs", "intro_ja.md"), Options{Root: tmp, Include: []string{"**/*.md"}, AllowFiles: []AllowFile{{Path: "docs/intro_ja.md", SHA256: tt.sum}}})
+		if err != nil {
+			t.Fatalf("Explain: %v", err)
+		}
+		if got.Rule != "allow_files" || got.Scanned != tt.scanned || got.Reason != tt.reason {
+			t.Fatalf("unexpected explanation %+v", got)
+		}
+	}
+}
+
+func TestScanRootSummaries(t *testing.T) {
+	tmp := t.TempDir()
+	files := map[string]string{
+		"services/api/a.go":    "// 日本\n",
+		"services/api/b.go":    "// é\n",
+		"services/web/c.go":    "// ß\n",
+		"services/web/d.bin":   "\x00\x01",
+		"services/webapp/e.go": "// ü\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmp, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	root := func(name string) string { return filepath.Join(tmp, "services", name) }
+
+	// web/c.go is reached through web and again through services; it is
+	// scanned once and counted toward web.
+	res, err := Scan([]string{root("web"), root("api"), filepath.Join(tmp, "services")}, Options{Root: tmp, DisplayRoot: tmp})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	want := []RootSummary{
+		{Root: "services/web", Summary: Summary{FilesScanned: 1, FilesSkipped: 1, Findings: 1, FindingsByCategory: map[string]int{"Latin Extended": 1}, FindingsBySeverity: map[Severity]int{SeverityError: 1}}},
+		{Root: "services/api", Summary: Summary{FilesScanned: 2, Findings: 3, FindingsByCategory: map[string]int{"Han": 2, "Latin Extended": 1}, FindingsBySeverity: map[Severity]int{SeverityError: 3}}},
+		{Root: "services", Summary: Summary{FilesScanned: 1, Findings: 1, FindingsByCategory: map[string]int{"Latin Extended": 1}, FindingsBySeverity: map[Severity]int{SeverityError: 1}}},
+	}
+	if !reflect.DeepEqual(res.Roots, want) {
+		t.Fatalf("roots = %+v, want %+v", res.Roots, want)
+	}
+	if res.Summary.FilesScanned != 4 || res.Summary.Findings != 5 {
+		t.Fatalf("unexpected summary: %+v", res.Summary)
+	}
+
+	res.Merge([]Finding{{Path: "services/webapp/e.go", Category: "Plugin", Severity: SeverityWarning}})
+	if got := res.Roots[2].Summary; got.Findings != 2 || got.FindingsByCategory["Plugin"] != 1 {
+		t.Fatalf("expected the merged finding under services, got %+v", got)
+	}
+
+	res, err = Scan([]string{root("api")}, Options{Root: tmp, DisplayRoot: tmp})
+	if err != nil || res.Roots != nil {
+		t.Fatalf("expected no root summaries for one path, got %+v (%v)", res.Roots, err)
+	}
+}
+
 func TestScanAllowRunes(t *testing.T) {
 	tmp := t.TempDir()
 	path := filepath.Join(tmp, "a.go")
@@ -143,7 +402,7 @@ func TestScanAllowedFilePattern(t *testing.T) {
 	if len(res.Findings) != 0 {
 		t.Fatalf("expected no findings for allowed file pattern")
 	}
-	if len(res.SkippedFiles) != 1 || res.SkippedFiles[0].Reason != "allowed by file pattern" {
+	if len(res.SkippedFiles) != 1 || res.SkippedFiles[0].Reason != SkipAllowedPattern {
 		t.Fatalf("unexpected skipped files: %+v", res.SkippedFiles)
 	}
 }
@@ -240,9 +499,9 @@ func TestHelperFunctions(t *testing.T) {
 		if _, ok := matchPrefix("abc", []string{"//"}); ok {
 			t.Fatalf("unexpected token match")
 		}
-		i, line, col := advanceByToken(0, 1, 1, "/*")
-		if i != 2 || line != 1 || col != 3 {
-			t.Fatalf("unexpected advance result: %d %d %d", i, line, col)
+		findings := scanContent("a.go", []byte("/*é*/ \"ü\""), syntaxForPath("a.go"), Options{})
+		if len(findings) != 2 || findings[0].Column != 3 || findings[1].Column != 8 {
+			t.Fatalf("unexpected token columns: %+v", findings)
 		}
 		if !shouldInspect(stateCode, Options{}) {
 			t.Fatalf("code should be inspected")
@@ -267,15 +526,42 @@ func TestHelperFunctions(t *testing.T) {
 		}
 
 		cases := map[rune]string{
-			'あ': "CJK",
-			'Я': "Cyrillic",
-			'ع': "Arabic",
-			'ไ': "Thai",
-			'अ': "Devanagari",
-			'א': "Hebrew",
-			'Ω': "Greek",
-			'é': "Latin Extended",
-			'→': "Unicode Symbol",
+			'あ':      "Kana",
+			'カ':      "Kana",
+			'中':      "Han",
+			'한':      "Hangul",
+			'Я':      "Cyrillic",
+			'ع':      "Arabic",
+			'ไ':      "Thai",
+			'अ':      "Devanagari",
+			'א':      "Hebrew",
+			'Ω':      "Greek",
+			'é':      "Latin Extended",
+			'Ա':      "Armenian",
+			'ა':      "Georgian",
+			'አ':      "Ethiopic",
+			'ក':      "Khmer",
+			'ກ':      "Lao",
+			'က':      "Myanmar",
+			'অ':      "Bengali",
+			'த':      "Tamil",
+			'Ꮳ':      "Cherokee",
+			'ᐁ':      "Canadian Syllabics",
+			'։':      "Armenian",
+			'→':      "Unicode Symbol",
+			'€':      "Currency",
+			'₹':      "Currency",
+			'฿':      "Currency",
+			'°':      "Measurement",
+			'µ':      "Measurement",
+			'㎏':      "Measurement",
+			'㍺':      "Measurement",
+			'㏟':      "Measurement",
+			'㍻':      "Unicode Symbol",
+			'㍿':      "Unicode Symbol",
+			'㏂':      "Unicode Symbol",
+			'㏘':      "Unicode Symbol",
+			'\u0378': "Other Unicode",
 		}
 		for r, want := range cases {
 			if got := categoryForRune(r); got != want {
@@ -285,25 +571,28 @@ func TestHelperFunctions(t *testing.T) {
 	})
 
 	t.Run("line excerpt and binary", func(t *testing.T) {
-		if got := lineExcerpt([]string{"a", "b"}, 2); got != "b" {
+		if got := lineExcerpt([]byte("b\r"), false); got != "b" {
 			t.Fatalf("unexpected excerpt: %q", got)
 		}
-		if got := lineExcerpt([]string{"a"}, 10); got != "" {
-			t.Fatalf("expected empty excerpt for out of range")
+		if got := lineExcerpt(nil, false); got != "" {
+			t.Fatalf("expected empty excerpt for empty line")
 		}
 		long := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
-		if got := lineExcerpt([]string{long}, 1); len(got) <= 160 {
+		if got := lineExcerpt([]byte(long), false); len(got) <= 160 {
 			t.Fatalf("expected truncated excerpt")
 		}
+		if got := lineExcerpt([]byte("short"), true); got != "short" {
+			t.Fatalf("unexpected excerpt for long flag: %q", got)
+		}
 
-		if isBinary([]byte{}) {
-			t.Fatalf("empty data should not be binary")
+		if got := binaryEvidence([]byte{}); got != "" {
+			t.Fatalf("empty data should not be binary, got %q", got)
 		}
-		if !isBinary([]byte{0x00, 0x01}) {
-			t.Fatalf("nul bytes should be binary")
+		if got := binaryEvidence([]byte{'a', 0x00, 0x01}); got != "NUL byte at offset 1" {
+			t.Fatalf("nul bytes should be binary, got %q", got)
 		}
-		if isBinary([]byte("hello\nworld\n")) {
-			t.Fatalf("plain text should not be binary")
+		if got := binaryEvidence([]byte("hello\nworld\n")); got != "" {
+			t.Fatalf("plain text should not be binary, got %q", got)
 		}
 	})
 }
@@ -417,9 +706,16 @@ func TestScanFilesystemBranches(t *testing.T) {
 			t.Fatalf("chmod: %v", err)
 		}
 		defer os.Chmod(file, 0o644)
-		if _, err := Scan([]string{file}, Options{Include: []string{"**/*.go"}}); err == nil {
+		if _, err := Scan([]string{file}, Options{Include: []string{"**/*.go"}, ErrorPolicy: ErrorPolicyAbort}); err == nil {
 			t.Fatalf("expected read error")
 		}
+		res, err := Scan([]string{file}, Options{Include: []string{"**/*.go"}})
+		if err != nil {
+			t.Fatalf("expected unreadable file to be skipped, got %v", err)
+		}
+		if len(res.SkippedFiles) != 1 || res.SkippedFiles[0].Describe() != "error: read error: permission denied" {
+			t.Fatalf("unexpected skipped files: %+v", res.SkippedFiles)
+		}
 	})
 
 	t.Run("walk error on unreadable directory", func(t *testing.T) {
@@ -432,9 +728,16 @@ func TestScanFilesystemBranches(t *testing.T) {
 			t.Fatalf("chmod: %v", err)
 		}
 		defer os.Chmod(dir, 0o700)
-		if _, err := Scan([]string{dir}, Options{Include: []string{"**/*"}}); err == nil {
+		if _, err := Scan([]string{dir}, Options{Include: []string{"**/*"}, ErrorPolicy: ErrorPolicyAbort}); err == nil {
 			t.Fatalf("expected walk error")
 		}
+		res, err := Scan([]string{tmp}, Options{Include: []string{"**/*"}, ErrorPolicy: ErrorPolicyWarn})
+		if err != nil {
+			t.Fatalf("expected unreadable directory to be skipped, got %v", err)
+		}
+		if len(res.SkippedFiles) != 1 || res.SkippedFiles[0].Reason != SkipError {
+			t.Fatalf("unexpected skipped files: %+v", res.SkippedFiles)
+		}
 	})
 
 	t.Run("skip non-regular files", func(t *testing.T) {
@@ -458,17 +761,31 @@ func TestScanFilesystemBranches(t *testing.T) {
 }
 
 func TestAdditionalHelpers(t *testing.T) {
-	t.Run("advance token newline and rune len", func(t *testing.T) {
-		i, line, col := advanceByToken(0, 1, 1, "a\nβ")
-		if i <= 0 || line != 2 || col <= 1 {
-			t.Fatalf("unexpected advance result: %d %d %d", i, line, col)
+	t.Run("excerpt of line longer than buffer", func(t *testing.T) {
+		line := strings.Repeat("x", 100000) + "é\r\nnext ü\r\n"
+		findings := scanContent("a.txt", []byte(line), syntaxRules{}, Options{})
+		if len(findings) != 2 {
+			t.Fatalf("expected two findings, got %d", len(findings))
+		}
+		if findings[0].Column != 100001 || len(findings[0].Excerpt) != 163 || !strings.HasSuffix(findings[0].Excerpt, "...") {
+			t.Fatalf("unexpected long line finding: col=%d excerpt len=%d", findings[0].Column, len(findings[0].Excerpt))
+		}
+		if findings[1].Line != 2 || findings[1].Excerpt != "next ü" {
+			t.Fatalf("unexpected second line finding: %+v", findings[1])
+		}
+	})
+
+	t.Run("read error is returned", func(t *testing.T) {
+		in := bufio.NewReader(io.MultiReader(strings.NewReader("é"), errReader{}))
+		if _, err := scanReader("a.txt", "a.txt", in, syntaxRules{}, Options{}); err == nil {
+			t.Fatalf("expected read error")
 		}
 	})
 
 	t.Run("binary ratio branch", func(t *testing.T) {
-		data := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
-		if !isBinary(data) {
-			t.Fatalf("expected control-heavy bytes to be binary")
+		data := []byte{'a', '\n', 2, 3, 4, 5, 6, 7, 8, 11}
+		if got := binaryEvidence(data); got != "8 of the first 10 bytes are control bytes, the first 0x02 at offset 2" {
+			t.Fatalf("expected control-heavy bytes to be binary, got %q", got)
 		}
 	})
 
@@ -524,3 +841,1433 @@ func TestScanGetwdError(t *testing.T) {
 		t.Skip("platform kept working directory resolvable after removal")
 	}
 }
+
+func TestExplain(t *testing.T) {
+	tmp := t.TempDir()
+	for name, content := range map[string]string{
+		"src/a.go":      "package p\n",
+		"src/a.md":      "# a\n",
+		"vendor/b.go":   "package b\n",
+		"docs/c.go":     "package c\n",
+		"src/gen.go":    "package p\n",
+		"src/image.go":  "\x00\x01",
+		"src/other.txt": "x\n",
+	} {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	opts := Options{
+		Include:           []string{"**/*.go", "**/*.md"},
+		Exclude:           []string{"**/vendor/**", "**/gen.go"},
+		AllowFilePatterns: []string{"**/docs/**"},
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		opts        Options
+		wantScanned bool
+		wantRule    string
+		wantPattern string
+	}{
+		{name: "included", path: "src/a.go", opts: opts, wantScanned: true, wantRule: "include", wantPattern: "**/*.go"},
+		{name: "second include", path: "src/a.md", opts: opts, wantScanned: true, wantRule: "include", wantPattern: "**/*.md"},
+		{name: "not included", path: "src/other.txt", opts: opts, wantRule: "include"},
+		{name: "excluded parent", path: "vendor/b.go", opts: opts, wantRule: "exclude", wantPattern: "**/vendor/**"},
+		{name: "excluded file", path: "src/gen.go", opts: opts, wantRule: "exclude", wantPattern: "**/gen.go"},
+		{name: "allowed file", path: "docs/c.go", opts: opts, wantRule: "allow_file_patterns", wantPattern: "**/docs/**"},
+		{name: "binary", path: "src/image.go", opts: opts, wantRule: "binary"},
+		{name: "no include patterns", path: "src/other.txt", opts: Options{}, wantScanned: true, wantRule: "include"},
+		{name: "directory", path: "src", opts: opts, wantScanned: true, wantRule: "directory"},
+		{name: "excluded directory", path: "vendor", opts: opts, wantRule: "exclude", wantPattern: "**/vendor/**"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Explain(filepath.Join(tmp, filepath.FromSlash(tt.path)), tt.opts)
+			if err != nil {
+				t.Fatalf("Explain error: %v", err)
+			}
+			if got.Scanned != tt.wantScanned || got.Rule != tt.wantRule || got.Pattern != tt.wantPattern {
+				t.Fatalf("unexpected explanation: %+v", got)
+			}
+			if got.Reason == "" {
+				t.Fatalf("expected reason")
+			}
+		})
+	}
+
+	if _, err := Explain(filepath.Join(tmp, "missing.go"), opts); err == nil {
+		t.Fatalf("expected stat error")
+	}
+}
+
+func TestScanMmapThreshold(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "a.go")
+	if err := os.WriteFile(path, []byte("package p\nvar _ = \"こんにちは\"\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	empty := filepath.Join(tmp, "empty.go")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	streamed, err := Scan([]string{path}, Options{Include: []string{"**/*.go"}})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	mapped, err := Scan([]string{path, empty}, Options{Include: []string{"**/*.go"}, MmapThreshold: 1})
+	if err != nil {
+		t.Fatalf("mmap scan error: %v", err)
+	}
+	if !reflect.DeepEqual(streamed.Findings, mapped.Findings) {
+		t.Fatalf("mmap findings differ: %+v vs %+v", streamed.Findings, mapped.Findings)
+	}
+	if mapped.Summary.FilesScanned != 2 {
+		t.Fatalf("expected both files scanned, got %d", mapped.Summary.FilesScanned)
+	}
+}
+
+func TestScanMaxFindingsPerFile(t *testing.T) {
+	tmp := t.TempDir()
+	noisy := filepath.Join(tmp, "dump.txt")
+	quiet := filepath.Join(tmp, "note.txt")
+	if err := os.WriteFile(noisy, []byte("あいう\nえお\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(quiet, []byte("é\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	res, err := Scan([]string{noisy, quiet}, Options{MaxFindingsPerFile: 2})
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if res.Summary.Findings != 3 || res.Summary.FindingsOmitted != 3 {
+		t.Fatalf("unexpected summary: %+v", res.Summary)
+	}
+	if len(res.LimitedFiles) != 1 || res.LimitedFiles[0].Reported != 2 || res.LimitedFiles[0].Omitted != 3 {
+		t.Fatalf("unexpected limited files: %+v", res.LimitedFiles)
+	}
+	if res.Findings[1].Excerpt != "あいう" {
+		t.Fatalf("expected excerpt on limited findings, got %q", res.Findings[1].Excerpt)
+	}
+}
+
+func TestScanExcerptModes(t *testing.T) {
+	text := []byte("var s = \"秘密\" // \xff\n")
+	tests := []struct {
+		name          string
+		mode          ExcerptMode
+		wantExcerpt   string
+		wantCharacter string
+	}{
+		{name: "full", mode: ExcerptFull, wantExcerpt: "var s = \"秘密\" // \xff", wantCharacter: "秘"},
+		{name: "default", mode: "", wantExcerpt: "var s = \"秘密\" // \xff", wantCharacter: "秘"},
+		{name: "omit", mode: ExcerptOmit, wantExcerpt: "", wantCharacter: "秘"},
+		{name: "redact", mode: ExcerptRedact, wantExcerpt: "var s = \"<U+79D8><U+5BC6>\" // <0xFF>", wantCharacter: "<U+79D8>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := scanContent("a.go", text, syntaxForPath("a.go"), Options{Excerpts: tt.mode})
+			if len(findings) != 3 {
+				t.Fatalf("expected three findings, got %d", len(findings))
+			}
+			if findings[0].Excerpt != tt.wantExcerpt || findings[0].Character != tt.wantCharacter {
+				t.Fatalf("unexpected finding: %+v", findings[0])
+			}
+			if tt.mode == ExcerptRedact && strings.Contains(findings[0].Message, "秘") {
+				t.Fatalf("expected redacted message, got %q", findings[0].Message)
+			}
+		})
+	}
+}
+
+func TestScanAllowLatinExtended(t *testing.T) {
+	text := []byte("// café naïve Straße ǅ e\u0301 Ａ 中文 ж\n")
+	tests := []struct {
+		name  string
+		allow bool
+		want  []string
+	}{
+		{name: "off", want: []string{"é", "ï", "ß", "ǅ", "\u0301", "Ａ", "中", "文", "ж"}},
+		{name: "on", allow: true, want: []string{"Ａ", "中", "文", "ж"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent("a.go", text, syntaxForPath("a.go"), Options{AllowLatinExtended: tt.allow}) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanIgnoreURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		want []string
+	}{
+		{name: "url", path: "a.md", text: "见 https://例え.jp/パス 文\n", want: []string{"见", "文"}},
+		{name: "markdown link", path: "a.md", text: "[日](https://例え.jp)\n", want: []string{"日"}},
+		{name: "prose before scheme", path: "a.md", text: "参照http://例.jp\n", want: []string{"参", "照"}},
+		{name: "www", path: "a.md", text: "www.例え.jp 中\n", want: []string{"中"}},
+		{name: "email", path: "a.md", text: "mail 用户@例子.广告 or 中\n", want: []string{"中"}},
+		{name: "handle", path: "a.md", text: "@用户 hi\n", want: []string{"用", "户"}},
+		{name: "string literal", path: "a.go", text: "u := \"https://例.jp\" + \"中\"\n", want: []string{"中"}},
+		{name: "no scheme", path: "a.md", text: "://中\n", want: []string{"中"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), Options{IgnoreURLs: true}) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanIgnoreLinePatterns(t *testing.T) {
+	patterns := []*regexp.Regexp{regexp.MustCompile(`https?://\S+`), regexp.MustCompile(`^Co-authored-by:.*`)}
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "url line", text: "见 https://例え.jp\n中\n", want: []string{"中"}},
+		{name: "trailer", text: "Co-authored-by: 山田 <a@b.c>\n", want: nil},
+		{name: "trailer not at start", text: "x Co-authored-by: 山\n", want: []string{"山"}},
+		{name: "crlf", text: "Co-authored-by: 山\r\n中\r\n", want: []string{"中"}},
+		{name: "last line", text: "中\nsee http://x 文", want: []string{"中"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent("a.txt", []byte(tt.text), syntaxForPath("a.txt"), Options{IgnoreLinePatterns: patterns}) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanAllowPatterns(t *testing.T) {
+	translation := regexp.MustCompile(`t\("[^"]*"\)`)
+	tests := []struct {
+		name     string
+		text     string
+		patterns []AllowPattern
+		want     []string
+	}{
+		{name: "inside call", text: "x := t(\"日本\") + \"中\"\n", patterns: []AllowPattern{{Pattern: translation}}, want: []string{"中"}},
+		{name: "two calls", text: "t(\"日\"); t(\"本\"); \"中\"\n", patterns: []AllowPattern{{Pattern: translation}}, want: []string{"中"}},
+		{name: "category match", text: "t(\"日é\")\n", patterns: []AllowPattern{{Pattern: translation, Categories: []string{"cjk"}}}, want: []string{"é"}},
+		{name: "no match", text: "tr(\"日\")\n", patterns: []AllowPattern{{Pattern: translation}}, want: []string{"日"}},
+		{name: "columns after wide text", text: "\"中\" t(\"日\")\n", patterns: []AllowPattern{{Pattern: translation}}, want: []string{"中"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent("a.go", []byte(tt.text), syntaxForPath("a.go"), Options{AllowPatterns: tt.patterns}) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanIgnoreBlobs(t *testing.T) {
+	blob := "QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo0MjQyNDI0Mg=="
+	tests := []struct {
+		name string
+		path string
+		text string
+		want []string
+	}{
+		{name: "base64 with invalid bytes", path: "a.txt", text: blob + "\xff\xfe" + blob + " 中\n", want: []string{"中"}},
+		{name: "hex", path: "a.txt", text: "0123456789abcdef0123456789abcdef01234567\x80 中\n", want: []string{"中"}},
+		{name: "short token", path: "a.txt", text: "abc123\xff 中\n", want: []string{"?", "中"}},
+		{name: "long identifier", path: "a.txt", text: "ThisIsAVeryLongIdentifierWithoutAnyDigitsé 中\n", want: []string{"é", "中"}},
+		{name: "html data uri", path: "a.html", text: "<img src=\"data:image/svg+xml,<svg>日</svg>\"> 中\n", want: []string{"中"}},
+		{name: "css data uri", path: "a.css", text: "a { background: url(data:text/plain,日本); } /* 中 */\n", want: []string{"中"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), Options{IgnoreBlobs: true}) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanDecodeEscapes(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "unicode escapes", path: "a.js", text: `s = "\u4e2d\u6587"` + "\n", want: []string{`中|\u4e2d|6`, `文|\u6587|12`}},
+		{name: "braced escapes", path: "a.js", text: `s = '\u{1F600} \x{416}'` + "\n", want: []string{`😀|\u{1F600}|6`, `Ж|\x{416}|16`}},
+		{name: "long escape", path: "a.py", text: `s = "\U0001F600"` + "\n", want: []string{`😀|\U0001F600|6`}},
+		{name: "surrogate pair", path: "a.js", text: `s = "\ud83d\ude00"` + "\n", want: []string{`😀|\ud83d\ude00|6`}},
+		{name: "html entities", path: "a.js", text: "s = `&#x4E2D; &#25991;`\n", want: []string{"中|&#x4E2D;|6", "文|&#25991;|15"}},
+		{name: "ascii escape", path: "a.js", text: `s = "\u0041\x{41}&#65;"` + "\n", want: nil},
+		{name: "escaped backslash", path: "a.js", text: `s = "\\u4e2d"` + "\n", want: nil},
+		{name: "outside string", path: "a.js", text: `// \u4e2d` + "\n", want: nil},
+		{name: "literal kept", path: "a.js", text: `s = "中\u6587"` + "\n", want: []string{"中||6", `文|\u6587|7`}},
+		{name: "allowed", path: "a.js", text: `s = "\u00a9"` + "\n", opts: Options{AllowRunes: map[rune]struct{}{'©': {}}}, want: nil},
+		{name: "ignore strings", path: "a.js", text: `s = "\u4e2d"` + "\n", opts: Options{IgnoreStrings: true}, want: nil},
+		{name: "invalid", path: "a.js", text: `s = "\u4e2 &#x; \x{110000}"` + "\n", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.DecodeEscapes = true
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), opts) {
+				got = append(got, fmt.Sprintf("%s|%s|%d", f.Character, f.Escape, f.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanPropertiesEscapes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "value", text: `greeting=\u3053\u3093 ok` + "\n", want: []string{`こ|\u3053|10`, `ん|\u3093|16`}},
+		{name: "key and literal", text: `k\u00e9y=日` + "\n", want: []string{`é|\u00e9|2`, "日||10"}},
+		{name: "escaped backslash", text: `path=C:\\u4e2d` + "\n", want: nil},
+		{name: "comment", text: `# \u4e2d` + "\n", want: nil},
+		{name: "ascii", text: `k=\u0041` + "\n", want: nil},
+		{name: "other escapes", text: `k=\x{4e2d}` + "\n", want: nil},
+		{name: "ignore strings", text: `k='\u4e2d'` + "\n", opts: Options{IgnoreStrings: true}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent("messages.properties", []byte(tt.text), syntaxForPath("messages.properties"), tt.opts) {
+				got = append(got, fmt.Sprintf("%s|%s|%d", f.Character, f.Escape, f.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanGettextCatalog(t *testing.T) {
+	catalog := `# 译者注释
+#. 提取
+#: src/main.c:1
+#, fuzzy
+msgctxt "菜单"
+msgid ""
+"Hello 世"
+msgstr ""
+"你好"
+msgid_plural "界"
+msgstr[0] "世界"
+#~ msgid "旧"
+#~ msgstr "舊"
+`
+	tests := []struct {
+		name string
+		path string
+		opts Options
+		want []string
+	}{
+		{name: "po", path: "zh.po", want: []string{"提", "取", "菜", "单", "世", "界", "旧"}},
+		{name: "pot ignore comments", path: "messages.pot", opts: Options{IgnoreComments: true}, want: []string{"菜", "单", "世", "界"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(catalog), syntaxForPath(tt.path), tt.opts) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanSVG(t *testing.T) {
+	svg := `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" aria-label="图">
+  <title>图标</title>
+  <desc lang="zh">说明 <tspan font-family="宋体">明</tspan></desc>
+  <path d="M0 0 L10 10" data-name="路径"/>
+  <g>装饰</g>
+  <!-- 注释 > -->
+  <text x="1" y="2">文<tspan>字</tspan></text>
+  <text/>外
+  <svg:title>題</svg:title>
+</svg>
+`
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "text elements and comments", want: []string{"图", "标", "说", "明", "明", "注", "释", "文", "字", "題"}},
+		{name: "ignore comments", opts: Options{IgnoreComments: true}, want: []string{"图", "标", "说", "明", "明", "文", "字", "題"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent("icon.svg", []byte(svg), syntaxForPath("icon.svg"), tt.opts) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanInfraSyntax(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		want []string
+	}{
+		{name: "hcl", path: "main.tf", text: "# 甲\n// 乙\n/* 丙 */\nname = \"丁\"\npolicy = <<-EOT\n  戊\n  EOT\n己 = 1\n", want: []string{"甲|comment", "乙|comment", "丙|comment", "丁|string", "戊|string", "己|code"}},
+		{name: "hcl heredoc not closed by prefix", path: "a.hcl", text: "x = <<EOF\nEOFX 甲\nEOF\n乙\n", want: []string{"甲|string", "乙|code"}},
+		{name: "graphql", path: "schema.graphql", text: "# 甲\n\"\"\"\n乙 \"quoted\" 丙 \\\"\"\" 丁\n\"\"\"\ntype 戊 { f: String @deprecated(reason: \"己\") }\n", want: []string{"甲|comment", "乙|string", "丙|string", "丁|string", "戊|code", "己|string"}},
+		{name: "proto", path: "api.proto", text: "// 甲\n/* 乙 */\noption (x) = \"丙\";\nmessage 丁 {}\n", want: []string{"甲|comment", "乙|comment", "丙|string", "丁|code"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			all := scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), Options{})
+			noComments := scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), Options{IgnoreComments: true})
+			noStrings := scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), Options{IgnoreStrings: true})
+			for _, f := range all {
+				kind := "code"
+				if !containsCharacter(noComments, f.Character) {
+					kind = "comment"
+				} else if !containsCharacter(noStrings, f.Character) {
+					kind = "string"
+				}
+				got = append(got, f.Character+"|"+kind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func containsCharacter(findings []Finding, character string) bool {
+	for _, f := range findings {
+		if f.Character == character {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScanCheckEntities(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "nbsp", path: "a.md", text: "a&nbsp;b\n", want: []string{`U+00A0|&nbsp;|" "|2`}},
+		{name: "numeric", path: "a.html", text: "<p>&#x4e2d;&#25991;</p>\n", want: []string{`U+4E2D|&#x4e2d;|""|4`, `U+6587|&#25991;|""|12`}},
+		{name: "invisible", path: "a.md", text: "a&zwj;b\n", want: []string{`U+200D|&zwj;|""|2`}},
+		{name: "dash", path: "a.md", text: "a &mdash; b\n", want: []string{`U+2014|&mdash;|"--"|3`}},
+		{name: "ascii and unknown", path: "a.md", text: "&amp; &lt; &#65; &bogus; &\n", want: nil},
+		{name: "allowed", path: "a.md", text: "&copy;\n", opts: Options{AllowRunes: map[rune]struct{}{'©': {}}}, want: nil},
+		{name: "other file types", path: "a.go", text: "// &nbsp;\n", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.CheckEntities = true
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), opts) {
+				got = append(got, fmt.Sprintf("%s|%s|%q|%d", f.CodePoint, f.Escape, f.Fix, f.Column))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanDocumentationBlocks(t *testing.T) {
+	markdown := "日\n````md\n```go\ns := \"中\"\n```\n````\n文\n~~~\n本\n~~~\n語 ```\n"
+	rst := "日\n\nExample::\n\n    中\n\n    本\n文\n\n.. code-block:: python\n\n   語\n.. note:: 注\n.. 評\n   論\n.. _目: https://example.com\n"
+	adoc := "日\n[source]\n----\n中\n-----\n本\n----\n文\n////\n評\n////\n// 論\n/// 注\n....\n語\n....\n"
+	tests := []struct {
+		name string
+		path string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "markdown default", path: "a.md", text: markdown, want: []string{"日", "中", "文", "本", "語"}},
+		{name: "markdown code blocks", path: "a.md", text: markdown, opts: Options{IgnoreCodeBlocks: true}, want: []string{"日", "文", "語"}},
+		{name: "rst code blocks", path: "a.rst", text: rst, opts: Options{IgnoreCodeBlocks: true}, want: []string{"日", "文", "注", "評", "論", "目"}},
+		{name: "rst comments", path: "a.rst", text: rst, opts: Options{IgnoreComments: true}, want: []string{"日", "中", "本", "文", "語", "注", "目"}},
+		{name: "adoc code blocks", path: "a.adoc", text: adoc, opts: Options{IgnoreCodeBlocks: true}, want: []string{"日", "文", "評", "論", "注"}},
+		{name: "adoc comments", path: "a.asciidoc", text: adoc, opts: Options{IgnoreComments: true}, want: []string{"日", "中", "本", "文", "注", "語"}},
+		{name: "other files", path: "a.txt", text: markdown, opts: Options{IgnoreCodeBlocks: true}, want: []string{"日", "中", "文", "本", "語"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), tt.opts) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanConfidence(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		min  Confidence
+		want []string
+	}{
+		{name: "identifier", path: "a.go", text: "vаr := 1\n", want: []string{"а|high"}},
+		{name: "string", path: "a.go", text: "s := \"™ 日\"\n", want: []string{"™|low", "日|medium"}},
+		{name: "comment", path: "a.py", text: "# café →\n", want: []string{"é|medium", "→|low"}},
+		{name: "unknown syntax", path: "a.txt", text: "café →\n", want: []string{"é|medium", "→|low"}},
+		{name: "invalid utf-8", path: "a.go", text: "// \xff\n", want: []string{"?|high"}},
+		{name: "min medium", path: "a.go", text: "s := \"™ 日\" + ж\n", min: ConfidenceMedium, want: []string{"日|medium", "ж|high"}},
+		{name: "min high", path: "a.go", text: "s := \"™ 日\" + ж\n", min: ConfidenceHigh, want: []string{"ж|high"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), Options{MinConfidence: tt.min}) {
+				got = append(got, f.Character+"|"+string(f.Confidence))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !Confidence("").AtLeast(ConfidenceHigh) || ConfidenceLow.AtLeast(ConfidenceMedium) || !ConfidenceHigh.AtLeast(ConfidenceLow) {
+		t.Fatalf("unexpected AtLeast results")
+	}
+}
+
+func TestScanPolicies(t *testing.T) {
+	policies := []Policy{
+		{Paths: []string{"**/*.md"}, Levels: map[string]Severity{"unicode symbol": SeverityOff, "latin extended": SeverityOff}},
+		{Paths: []string{"docs/**"}, Levels: map[string]Severity{"*": SeverityWarning}},
+		{Paths: []string{"docs/legal/**"}, Levels: map[string]Severity{"cjk": SeverityError}},
+	}
+	text := []byte("© café 日 ж\n")
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "a.go", want: []string{"©|error", "é|error", "日|error", "ж|error"}},
+		{path: "README.md", want: []string{"日|error", "ж|error"}},
+		{path: "docs/a.md", want: []string{"日|warning", "ж|warning"}},
+		{path: "docs/legal/a.txt", want: []string{"©|warning", "é|warning", "日|error", "ж|warning"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, text, syntaxForPath(tt.path), Options{Severity: SeverityError, Policies: policies, MaxFindingsPerFile: 4}) {
+				got = append(got, f.Character+"|"+string(f.Severity))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanComponentRegions(t *testing.T) {
+	component := `<template>
+  <!-- 甲 -->
+  <p title="乙">丙</p>
+</template>
+<script setup lang="ts">
+// 丁
+const s = "戊" /* 己
+庚 */
+</script>
+<style lang="scss">
+// 辛
+a::after { content: "壬"; }
+</style>
+<p>癸</p>
+`
+	tests := []struct {
+		name string
+		path string
+		opts Options
+		want []string
+	}{
+		{name: "regions", path: "App.vue", want: []string{"甲|template", "乙|template", "丙|template", "丁|script", "戊|script", "己|script", "庚|script", "辛|style", "壬|style", "癸|template"}},
+		{name: "ignore comments", path: "App.svelte", opts: Options{IgnoreComments: true}, want: []string{"乙|template", "丙|template", "戊|script", "壬|style", "癸|template"}},
+		{name: "ignore strings", path: "App.vue", opts: Options{IgnoreStrings: true}, want: []string{"甲|template", "乙|template", "丙|template", "丁|script", "己|script", "庚|script", "辛|style", "癸|template"}},
+		{name: "region policies", path: "App.vue", opts: Options{Policies: []Policy{
+			{Paths: []string{"**/*.vue"}, Levels: map[string]Severity{"*": SeverityWarning}},
+			{Paths: []string{"**/*.vue"}, Regions: []string{"template", "style"}, Levels: map[string]Severity{"cjk": SeverityOff}},
+		}}, want: []string{"丁|script", "戊|script", "己|script", "庚|script"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(component), syntaxForPath(tt.path), tt.opts) {
+				got = append(got, f.Character+"|"+f.Region)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanTemplateRegions(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "go", path: "page.html.tmpl", text: "<p>甲 {{ .T \"乙\" }}</p>{{/* 丙 */}}{{- /* 丁 */ -}}\n{{ 戊 }}己\n", want: []string{"甲|text", "乙|expression", "丙|expression", "丁|expression", "戊|expression", "己|text"}},
+		{name: "jinja", path: "page.j2", text: "甲{# 乙 #}{% if x == '丙' %}丁{{ 戊 }}\n", want: []string{"甲|text", "乙|expression", "丙|expression", "丁|text", "戊|expression"}},
+		{name: "erb", path: "page.html.erb", text: "甲<%# 乙 %><%= t(\"丙\") %>丁\n", want: []string{"甲|text", "乙|expression", "丙|expression", "丁|text"}},
+		{name: "handlebars", path: "page.hbs", text: "甲{{!-- 乙 }} --}}{{! 丙 }}{{{ 丁 }}}戊\n", want: []string{"甲|text", "乙|expression", "丙|expression", "丁|expression", "戊|text"}},
+		{name: "ignore comments", path: "page.j2", text: "甲{# 乙 #}{{ 丙 }}\n", opts: Options{IgnoreComments: true}, want: []string{"甲|text", "丙|expression"}},
+		{name: "ignore strings", path: "page.erb", text: "<%= t('甲') %>乙'丙'\n", opts: Options{IgnoreStrings: true}, want: []string{"乙|text", "丙|text"}},
+		{name: "region policies", path: "page.j2", text: "甲{{ 乙 }}\n", opts: Options{Policies: []Policy{
+			{Paths: []string{"**/*.j2"}, Regions: []string{"text"}, Levels: map[string]Severity{"cjk": SeverityOff}},
+		}}, want: []string{"乙|expression"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), tt.opts) {
+				got = append(got, f.Character+"|"+f.Region)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanLineEndings(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+		eol  string
+	}{
+		{name: "lf", text: "a := 1\n// é\n", want: []string{"2:4:// é"}, eol: EOLLF},
+		{name: "crlf", text: "a := 1\r\n// é x\r\nb := \"ü\"\r\n", want: []string{"2:4:// é x", "3:7:b := \"ü\""}, eol: EOLCRLF},
+		{name: "cr", text: "// é\rb := \"ü\"\r", want: []string{"1:4:// é", "2:7:b := \"ü\""}, eol: EOLCR},
+		{name: "mixed", text: "a\r\nb\n// é", want: []string{"3:4:// é"}, eol: EOLMixed},
+		{name: "none", text: "// é", want: []string{"1:4:// é"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := scanReader("a.go", "a.go", bufio.NewReader(strings.NewReader(tt.text)), syntaxForPath("a.go"), Options{})
+			if err != nil {
+				t.Fatalf("scanReader: %v", err)
+			}
+			var got []string
+			for _, f := range content.findings {
+				got = append(got, fmt.Sprintf("%d:%d:%s", f.Line, f.Column, f.Excerpt))
+			}
+			if !reflect.DeepEqual(got, tt.want) || content.eol != tt.eol {
+				t.Fatalf("findings = %q, eol %q, want %q, eol %q", got, content.eol, tt.want, tt.eol)
+			}
+		})
+	}
+}
+
+func TestScanNestedComments(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "rust", path: "lib.rs", text: "/* 甲 /* 乙 */ 丙 */ let s = \"丁\";\n", opts: Options{IgnoreComments: true}, want: []string{"丁"}},
+		{name: "swift multiline", path: "main.swift", text: "/*\n/* 甲 */\n乙\n*/\n丙\n", opts: Options{IgnoreComments: true}, want: []string{"丙"}},
+		{name: "haskell", path: "Main.hs", text: "{- 甲 {- 乙 -} 丙 -}\nfoldl' f z -- 丁\n戊\n", opts: Options{IgnoreComments: true}, want: []string{"戊"}},
+		{name: "c does not nest", path: "main.c", text: "/* /* 甲 */ 乙 */\n", opts: Options{IgnoreComments: true}, want: []string{"乙"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), tt.opts) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanGoLiterals(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "rune region", text: "r := '甲'\ns := \"乙\"\n", want: []string{"甲|rune", "乙|"}},
+		{name: "escaped quote", text: "r := '\\''; s := \"甲\"\n", want: []string{"甲|"}},
+		{name: "ignore strings keeps runes", text: "r := '甲'\ns := \"乙\" + `丙`\n", opts: Options{IgnoreStrings: true}, want: []string{"甲|rune"}},
+		{name: "decoded rune escape", text: `r := '\u3042'` + "\n", opts: Options{DecodeEscapes: true}, want: []string{`あ|rune|\u3042`}},
+		{name: "raw string backslash", text: "s := `C:\\`; t := \"甲\"\n", want: []string{"甲|"}},
+		{name: "raw string escape", text: "s := `\\u3042 乙`\n", opts: Options{DecodeEscapes: true}, want: []string{"乙|"}},
+		{name: "rune policy", text: "r := '甲'\ns := \"乙\"\n", opts: Options{Policies: []Policy{
+			{Paths: []string{"**/*.go"}, Levels: map[string]Severity{"*": SeverityOff}},
+			{Paths: []string{"**/*.go"}, Regions: []string{"rune"}, Levels: map[string]Severity{"*": SeverityError}},
+		}}, want: []string{"甲|rune"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent("main.go", []byte(tt.text), syntaxForPath("main.go"), tt.opts) {
+				entry := f.Character + "|" + f.Region
+				if f.Escape != "" {
+					entry += "|" + f.Escape
+				}
+				got = append(got, entry)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanLaTeX(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		text string
+		opts Options
+		want []string
+	}{
+		{name: "prose and comments", path: "paper.tex", text: "\\section{甲} 50\\% 乙 % 丙\n", want: []string{"甲", "乙", "丙"}},
+		{name: "inline math", path: "paper.tex", text: "$\\alpha ≤ β$ 甲 \\(→\\) $$∑ \\text{乙}$$ \\$ ≥\n", want: []string{"甲", "乙", "≥"}},
+		{name: "math environment", path: "paper.tex", text: "\\begin{align*}\nx ≠ 𝐱 % 甲\n\\end{align*} ×\n", want: []string{"甲", "×"}},
+		{name: "backslash before multibyte rune", path: "paper.tex", text: "Caf\\é and \\中\n", want: []string{"é", "中"}},
+		{name: "verbatim", path: "paper.tex", text: "\\verb|甲| \\begin{verbatim}\n乙\n\\end{verbatim}\n丙\n", opts: Options{IgnoreCodeBlocks: true}, want: []string{"丙"}},
+		{name: "comment environment", path: "paper.tex", text: "\\begin{comment}\n甲\n\\end{comment}\n", opts: Options{IgnoreComments: true}},
+		{name: "bibliography", path: "refs.bib", text: "% 甲\n@article{k, title = {乙 $\\gamma$}}\n", opts: Options{IgnoreComments: true}, want: []string{"乙"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, []byte(tt.text), syntaxForPath(tt.path), tt.opts) {
+				got = append(got, f.Character)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanCustomCategories(t *testing.T) {
+	categories := []Category{
+		{Name: "Box Drawing", Ranges: []RuneRange{{Lo: 0x2500, Hi: 0x257F}}, Severity: SeverityWarning, Fix: "-"},
+		{Name: "Shadowed", Ranges: []RuneRange{{Lo: 0x2550, Hi: 0x2550}}},
+		{Name: "Kana", Ranges: []RuneRange{{Lo: 0x3040, Hi: 0x30FF}}},
+	}
+	text := []byte("// ─═ あ 日\n")
+	findings := scanContent("a.go", text, syntaxForPath("a.go"), Options{Severity: SeverityError, Categories: categories, AllowRunes: map[rune]struct{}{'═': {}}})
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%s|%s|%s|%s", f.Character, f.Category, f.Severity, f.Fix))
+	}
+	want := []string{"─|Box Drawing|warning|-", "あ|Kana|error|", "日|Han|error|"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	if findings[0].Message != `Detected Box Drawing character "─" (U+2500)` {
+		t.Fatalf("unexpected message %q", findings[0].Message)
+	}
+}
+
+func TestScanMutedCategory(t *testing.T) {
+	opts := Options{
+		Severity:   SeverityError,
+		Categories: []Category{{Name: "Box Drawing", Ranges: []RuneRange{{Lo: 0x2500, Hi: 0x257F}}, Severity: SeverityOff}},
+		Policies:   []Policy{{Paths: []string{"docs/**"}, Levels: map[string]Severity{"box drawing": SeverityWarning}}},
+	}
+	text := []byte("// ─ 日\n")
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "src/a.go", want: []string{"日|Han|error"}},
+		{path: "docs/a.go", want: []string{"─|Box Drawing|warning", "日|Han|error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(tt.path, text, syntaxForPath(tt.path), opts) {
+				got = append(got, fmt.Sprintf("%s|%s|%s", f.Character, f.Category, f.Severity))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInCategory(t *testing.T) {
+	tests := []struct {
+		category string
+		name     string
+		want     bool
+	}{
+		{"Han", "han", true},
+		{"Han", "CJK", true},
+		{"Kana", "cjk", true},
+		{"Hangul", "CJK", true},
+		{"Han", "Kana", false},
+		{"Cyrillic", "CJK", false},
+		{"CJK", "Han", false},
+	}
+	for _, tt := range tests {
+		if got := InCategory(tt.category, tt.name); got != tt.want {
+			t.Errorf("InCategory(%q, %q) = %v, want %v", tt.category, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanMessageTemplates(t *testing.T) {
+	text := []byte("a := \"日\"\nb := \"ж\"\nc := \"\xff\"\n")
+	tests := []struct {
+		name      string
+		templates map[string]string
+		want      []string
+	}{
+		{name: "default", want: []string{
+			"Detected Han character \"日\" (U+65E5)",
+			"Detected Cyrillic character \"ж\" (U+0436)",
+			"Detected invalid UTF-8 byte sequence",
+		}},
+		{name: "per category with fallback", templates: map[string]string{
+			"cjk": "{category} {character} {codepoint} {name} at {path}:{line}:{column}; see https://wiki.example.com/i18n",
+			"*":   "{message} [{unknown}]",
+		}, want: []string{
+			"Han 日 U+65E5 CJK UNIFIED IDEOGRAPH-65E5 at dir/a.go:1:7; see https://wiki.example.com/i18n",
+			"Detected Cyrillic character \"ж\" (U+0436) [{unknown}]",
+			"Detected invalid UTF-8 byte sequence [{unknown}]",
+		}},
+		{name: "own category over umbrella", templates: map[string]string{"han": "{category}", "cjk": "umbrella"}, want: []string{
+			"Han",
+			"Detected Cyrillic character \"ж\" (U+0436)",
+			"Detected invalid UTF-8 byte sequence",
+		}},
+		{name: "invalid utf-8 category only", templates: map[string]string{"invalid utf-8": "fix encoding"}, want: []string{
+			"Detected Han character \"日\" (U+65E5)",
+			"Detected Cyrillic character \"ж\" (U+0436)",
+			"fix encoding",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range scanContent(filepath.Join("dir", "a.go"), text, syntaxForPath("a.go"), Options{MessageTemplates: tt.templates}) {
+				got = append(got, f.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindingFingerprints(t *testing.T) {
+	original := scanContent("a.go", []byte("x := \"é\"\ny := \"é é\"\n"), syntaxForPath("a.go"), Options{})
+	shifted := scanContent("a.go", []byte("// header\n\nx := \"é\"\ny := \"é é\"\n"), syntaxForPath("a.go"), Options{})
+	redacted := scanContent("a.go", []byte("x := \"é\"\ny := \"é é\"\n"), syntaxForPath("a.go"), Options{Excerpts: ExcerptRedact})
+	otherPath := scanContent("b.go", []byte("x := \"é\"\n"), syntaxForPath("b.go"), Options{})
+	if len(original) != 3 || len(shifted) != 3 || len(redacted) != 3 {
+		t.Fatalf("unexpected finding counts: %d %d %d", len(original), len(shifted), len(redacted))
+	}
+
+	seen := make(map[string]bool)
+	for i := range original {
+		fp := original[i].Fingerprint
+		if len(fp) != 16 {
+			t.Fatalf("unexpected fingerprint %q", fp)
+		}
+		if seen[fp] {
+			t.Fatalf("duplicate fingerprint %q", fp)
+		}
+		seen[fp] = true
+		if shifted[i].Fingerprint != fp || redacted[i].Fingerprint != fp {
+			t.Fatalf("fingerprint %d changed: %q %q %q", i, fp, shifted[i].Fingerprint, redacted[i].Fingerprint)
+		}
+	}
+	if otherPath[0].Fingerprint == original[0].Fingerprint {
+		t.Fatalf("expected path to affect fingerprint")
+	}
+}
+
+func TestScanReader(t *testing.T) {
+	opts := Options{Include: []string{"**/*.go"}, Exclude: []string{"vendor/**"}, AllowFilePatterns: []string{"i18n/**"}}
+	tests := []struct {
+		name         string
+		path         string
+		content      string
+		wantScanned  int
+		wantSkipped  int
+		wantFindings int
+	}{
+		{name: "scanned", path: "src/a.go", content: "var s = \"日本\"\n", wantScanned: 1, wantFindings: 2},
+		{name: "not included", path: "README.md", content: "日本\n"},
+		{name: "excluded", path: "vendor/x.go", content: "日本\n"},
+		{name: "allowed file", path: "i18n/ja.go", content: "日本\n", wantSkipped: 1},
+		{name: "binary", path: "b.go", content: "\x00\x01", wantSkipped: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ScanReader(tt.path, strings.NewReader(tt.content), opts)
+			if err != nil {
+				t.Fatalf("ScanReader: %v", err)
+			}
+			if res.Summary.FilesScanned != tt.wantScanned || res.Summary.FilesSkipped != tt.wantSkipped || res.Summary.Findings != tt.wantFindings {
+				t.Fatalf("unexpected summary: %+v", res.Summary)
+			}
+		})
+	}
+	if _, err := ScanReader("a.go", errReader{}, Options{}); err == nil {
+		t.Fatalf("expected read error")
+	}
+}
+
+func TestScanPathSeverities(t *testing.T) {
+	opts := Options{
+		Include:    []string{"**/*"},
+		Severity:   SeverityError,
+		Categories: []Category{{Name: "Box Drawing", Ranges: []RuneRange{{Lo: 0x2500, Hi: 0x257F}}, Severity: SeverityError}},
+		PathSeverities: []PathSeverity{
+			{Pattern: "docs/**", Severity: SeverityWarning},
+			{Pattern: "docs/api/**", Severity: SeverityError},
+		},
+		Policies: []Policy{{Paths: []string{"docs/**"}, Levels: map[string]Severity{"cyrillic": SeverityError}}},
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "src/a.go", want: []string{"日|error", "д|error", "─|error"}},
+		{path: "docs/guide.md", want: []string{"日|warning", "д|error", "─|error"}},
+		{path: "docs/api/ref.md", want: []string{"日|error", "д|error", "─|error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res, err := ScanReader(tt.path, strings.NewReader("日 д ─\n"), opts)
+			if err != nil {
+				t.Fatalf("ScanReader: %v", err)
+			}
+			var got []string
+			for _, f := range res.Findings {
+				got = append(got, f.Character+"|"+string(f.Severity))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanIgnoreDirective(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		content   string
+		wantLines []int
+	}{
+		{name: "next line", path: "a.go", content: "// englint:ignore TODO: legacy\nx := \"日\"\ny := \"本\"\n", wantLines: []int{3}},
+		{name: "same line is not suppressed", path: "a.go", content: "x := \"日\" // englint:ignore\ny := \"本\"\n", wantLines: []int{1}},
+		{name: "restarted match", path: "a.py", content: "# eenglint:ignore\nx = 'é'\n", wantLines: nil},
+		{name: "partial directive", path: "a.py", content: "# englint:ign\nx = 'é'\n", wantLines: []int{2}},
+		{name: "split across lines", path: "a.py", content: "# englint:\nignore\nx = 'é'\n", wantLines: []int{3}},
+		{name: "block comment", path: "a.go", content: "/* englint:ignore */\nx := \"é\"\n", wantLines: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []int
+			for _, f := range scanContent(tt.path, []byte(tt.content), syntaxForPath(tt.path), Options{}) {
+				lines = append(lines, f.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Fatalf("got finding lines %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestScanInvisibleCharacters(t *testing.T) {
+	data := []byte("// ❤️ ok\U000E0101\nx := 1 // \U000E0001\U000E0068\U000E0069\n")
+	findings := scanContent("a.go", data, syntaxRules{lineComments: []string{"//"}}, Options{Severity: SeverityError})
+	want := []struct {
+		codePoint, category, message string
+		confidence                   Confidence
+	}{
+		{"U+2764", "Unicode Symbol", "", ConfidenceLow},
+		{"U+FE0F", categoryInvisible, "Detected invisible variation selector (U+FE0F)", ConfidenceMedium},
+		{"U+E0101", categoryInvisible, "Detected invisible variation selector (U+E0101)", ConfidenceMedium},
+		{"U+E0001", categoryInvisible, "Detected invisible tag character (U+E0001)", ConfidenceHigh},
+		{"U+E0068", categoryInvisible, `Detected invisible tag character (U+E0068) hiding 'h'`, ConfidenceHigh},
+		{"U+E0069", categoryInvisible, `Detected invisible tag character (U+E0069) hiding 'i'`, ConfidenceHigh},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.CodePoint != w.codePoint || f.Category != w.category || f.Confidence != w.confidence || (w.message != "" && f.Message != w.message) {
+			t.Fatalf("finding %d = %+v, want %+v", i, f, w)
+		}
+	}
+
+	redacted := scanContent("a.go", []byte("// \U000E0041\n"), syntaxRules{lineComments: []string{"//"}}, Options{Severity: SeverityError, Excerpts: ExcerptRedact})
+	if len(redacted) != 1 || !strings.HasSuffix(redacted[0].Message, "hiding 'A'") {
+		t.Fatalf("expected redacted tag message, got %+v", redacted)
+	}
+}
+
+func TestScanFormatCharacters(t *testing.T) {
+	data := []byte("x := \"co\u00adop\" // a\u200bb\u2060c\ufeff\u00a0\n")
+	findings := scanContent("a.go", data, syntaxRules{lineComments: []string{"//"}, strings: true}, Options{Severity: SeverityError})
+	want := []struct {
+		codePoint, category, message string
+		confidence                   Confidence
+	}{
+		{"U+00AD", categoryFormat, "Detected invisible soft hyphen (U+00AD), which breaks string comparisons", ConfidenceMedium},
+		{"U+200B", categoryFormat, "Detected invisible zero width space (U+200B), which breaks string comparisons", ConfidenceMedium},
+		{"U+2060", categoryFormat, "Detected invisible word joiner (U+2060), which breaks string comparisons", ConfidenceMedium},
+		{"U+FEFF", categoryFormat, "Detected invisible zero width no-break space (U+FEFF), which breaks string comparisons", ConfidenceMedium},
+		{"U+00A0", "Other Unicode", "", ConfidenceLow},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.CodePoint != w.codePoint || f.Category != w.category || f.Confidence != w.confidence || (w.message != "" && f.Message != w.message) {
+			t.Fatalf("finding %d = %+v, want %+v", i, f, w)
+		}
+	}
+}
+
+func TestScanPrivateUse(t *testing.T) {
+	data := []byte("// \uE0A0 \U000F0001 \U0010FFFD\n")
+	findings := scanContent("a.go", data, syntaxRules{lineComments: []string{"//"}}, Options{Severity: SeverityError})
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", findings)
+	}
+	for _, f := range findings {
+		if f.Category != categoryPrivateUse || !strings.HasPrefix(f.Message, "Detected Private Use character ("+f.CodePoint+"), likely an icon-font glyph") {
+			t.Fatalf("unexpected finding: %+v", f)
+		}
+	}
+	custom := scanContent("a.go", []byte("// \uE0A0\n"), syntaxRules{lineComments: []string{"//"}}, Options{
+		Severity:   SeverityError,
+		Categories: []Category{{Name: "Powerline", Ranges: []RuneRange{{Lo: 0xE0A0, Hi: 0xE0D4}}}},
+	})
+	if len(custom) != 1 || custom[0].Category != "Powerline" || custom[0].Message != `Detected Powerline character "\ue0a0" (U+E0A0)` {
+		t.Fatalf("expected custom category to win, got %+v", custom)
+	}
+}
+
+func TestConfusableASCII(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want rune
+		ok   bool
+	}{
+		{r: '𝐀', want: 'A', ok: true},
+		{r: '𝐛', want: 'b', ok: true},
+		{r: '𝘪', want: 'i', ok: true},
+		{r: '𝚣', want: 'z', ok: true},
+		{r: '𝚤', want: 'i', ok: true},
+		{r: 'ℎ', want: 'h', ok: true},
+		{r: 'ℝ', want: 'R', ok: true},
+		{r: '𝟎', want: '0', ok: true},
+		{r: '𝟿', want: '9', ok: true},
+		{r: '𝛂'},
+		{r: 'é'},
+	}
+	for _, tt := range tests {
+		got, ok := confusableASCII(tt.r)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("confusableASCII(%q) = %q, %v, want %q, %v", tt.r, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestScanConfusableLatin(t *testing.T) {
+	findings := scanContent("a.md", []byte("𝐛𝐨𝐥𝐝 𝟏\n"), syntaxRules{}, Options{Severity: SeverityError})
+	if len(findings) != 5 {
+		t.Fatalf("expected 5 findings, got %+v", findings)
+	}
+	var fixed strings.Builder
+	for _, f := range findings {
+		if f.Category != categoryConfusableLatin {
+			t.Fatalf("unexpected category: %+v", f)
+		}
+		fixed.WriteString(f.Fix)
+	}
+	if fixed.String() != "bold1" {
+		t.Fatalf("unexpected fixes: %q", fixed.String())
+	}
+	if want := `Detected mathematical symbol (U+1D41B) styled to look like Latin 'b'`; findings[0].Message != want {
+		t.Fatalf("message = %q, want %q", findings[0].Message, want)
+	}
+}
+
+func TestScanDeduplicatesFiles(t *testing.T) {
+	tmp := t.TempDir()
+	original := filepath.Join(tmp, "a.go")
+	if err := os.WriteFile(original, []byte("// é\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Link(original, filepath.Join(tmp, "b.go")); err != nil {
+		t.Skipf("hard links unsupported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "docs.md"), []byte("é\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	opts := Options{Include: []string{"**/*.go", "**/*.md"}, AllowFilePatterns: []string{"**/*.md"}, Severity: SeverityError}
+	res, err := Scan([]string{tmp, original, tmp}, opts)
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(res.ScannedFiles) != 1 || len(res.Findings) != 1 {
+		t.Fatalf("expected hard links to be scanned once, got %v %+v", res.ScannedFiles, res.Findings)
+	}
+	if len(res.SkippedFiles) != 1 {
+		t.Fatalf("expected one skipped file, got %+v", res.SkippedFiles)
+	}
+}
+
+func TestScanCategorizer(t *testing.T) {
+	categorizer := CategorizerFunc(func(r rune) (string, Severity) {
+		switch {
+		case unicode.In(r, unicode.Greek):
+			return "", SeverityOff
+		case unicode.In(r, unicode.Cyrillic):
+			return "Homoglyph", SeverityWarning
+		default:
+			return "", ""
+		}
+	})
+	opts := Options{
+		Severity:    SeverityError,
+		Categorizer: categorizer,
+		Categories:  []Category{{Name: "Cyrillic Ya", Ranges: []RuneRange{{Lo: 'я', Hi: 'я'}}}},
+	}
+	findings := scanContent("a.txt", []byte("α β а я 中\n"), syntaxRules{}, opts)
+	want := []struct {
+		category string
+		severity Severity
+	}{
+		{"Homoglyph", SeverityWarning},
+		{"Cyrillic Ya", SeverityError},
+		{"Han", SeverityError},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), findings)
+	}
+	for i, w := range want {
+		if findings[i].Category != w.category || findings[i].Severity != w.severity {
+			t.Fatalf("finding %d = %+v, want %+v", i, findings[i], w)
+		}
+	}
+}
+
+func TestScanRecoversPanics(t *testing.T) {
+	tmp := t.TempDir()
+	for name, content := range map[string]string{"bad.go": "// 中\n", "good.go": "// é\n"} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	opts := Options{
+		Include:  []string{"**/*.go"},
+		Severity: SeverityError,
+		Categorizer: CategorizerFunc(func(r rune) (string, Severity) {
+			if r == '中' {
+				panic("boom")
+			}
+			return "", ""
+		}),
+	}
+	res, err := Scan([]string{tmp}, opts)
+	if err != nil {
+		t.Fatalf("scan error: %v", err)
+	}
+	if len(res.Findings) != 1 || len(res.ScannedFiles) != 1 {
+		t.Fatalf("expected the good file to be scanned, got %+v", res)
+	}
+	if len(res.SkippedFiles) != 1 || !strings.HasSuffix(res.SkippedFiles[0].Path, "bad.go") || res.SkippedFiles[0].Detail != "internal error: boom" {
+		t.Fatalf("expected bad file to be skipped, got %+v", res.SkippedFiles)
+	}
+
+	opts.Strict = true
+	_, err = Scan([]string{tmp}, opts)
+	var internal *InternalError
+	if !errors.As(err, &internal) || internal.Value != "boom" || !strings.Contains(err.Error(), "internal error in ") {
+		t.Fatalf("expected strict internal error, got %v", err)
+	}
+}
+
+func TestScanThreads(t *testing.T) {
+	tmp := t.TempDir()
+	for i := 0; i < 40; i++ {
+		dir := filepath.Join(tmp, fmt.Sprintf("d%d", i%4))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		content := fmt.Sprintf("// file %d é\nx := \"日本\"\n", i)
+		if i%5 == 0 {
+			content = "package p\n"
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%02d.go", i)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		// Copies share content, which DedupeContent collapses.
+		if i%10 == 1 {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("copy%02d.go", i)), []byte(content), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "d0", "data.bin"), []byte{0, 1, 2}, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	scan := func(threads int) Result {
+		t.Helper()
+		res, err := Scan([]string{filepath.Join(tmp, "d0"), tmp}, Options{
+			Include:            []string{"**/*.go", "**/*.bin"},
+			Severity:           SeverityError,
+			MaxFindingsPerFile: 2,
+			DedupeContent:      true,
+			Threads:            threads,
+		})
+		if err != nil {
+			t.Fatalf("scan with %d threads: %v", threads, err)
+		}
+		return res
+	}
+	want := scan(1)
+	if len(want.Findings) == 0 || len(want.SkippedFiles) != 1 || len(want.LimitedFiles) == 0 || len(want.Roots) != 2 {
+		t.Fatalf("unexpected sequential result: %+v", want)
+	}
+	for _, threads := range []int{0, 2, 8} {
+		if got := scan(threads); !reflect.DeepEqual(got, want) {
+			t.Fatalf("result with %d threads differs:\n%+v\nwant:\n%+v", threads, got, want)
+		}
+	}
+
+	// A failing file ends a parallel scan with its error.
+	_, err := Scan([]string{tmp}, Options{
+		Include:  []string{"**/*.go"},
+		Severity: SeverityError,
+		Strict:   true,
+		Threads:  4,
+		Categorizer: CategorizerFunc(func(r rune) (string, Severity) {
+			if r == '本' {
+				panic("boom")
+			}
+			return "", ""
+		}),
+	})
+	var internal *InternalError
+	if !errors.As(err, &internal) || internal.Value != "boom" {
+		t.Fatalf("expected internal error, got %v", err)
+	}
+}
+
+func TestScanDisplayPaths(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "src", "a.go")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(file, []byte("// é\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{name: "relative to root", opts: Options{DisplayRoot: tmp}, want: "src/a.go"},
+		{name: "absolute", opts: Options{AbsolutePaths: true, DisplayRoot: tmp}, want: filepath.ToSlash(file)},
+		{name: "outside root", opts: Options{DisplayRoot: filepath.Join(tmp, "docs")}, want: filepath.ToSlash(file)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Severity = SeverityError
+			res, err := Scan([]string{filepath.Join(tmp, "src")}, tt.opts)
+			if err != nil {
+				t.Fatalf("scan error: %v", err)
+			}
+			if len(res.Findings) != 1 || res.Findings[0].Path != tt.want || res.ScannedFiles[0] != tt.want {
+				t.Fatalf("expected path %q, got %+v", tt.want, res)
+			}
+			explanation, err := Explain(file, tt.opts)
+			if err != nil || explanation.Path != tt.want {
+				t.Fatalf("expected explained path %q, got %+v, %v", tt.want, explanation, err)
+			}
+		})
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	tests := []struct {
+		path string
+		line int
+		want string
+	}{
+		{path: "/src/app.go", line: 12, want: "file:///src/app.go#L12"},
+		{path: "/my docs/日本.md", line: 1, want: "file:///my%20docs/%E6%97%A5%E6%9C%AC.md#L1"},
+		{path: "C:/src/app.go", line: 3, want: "file:///C:/src/app.go#L3"},
+	}
+	for _, tt := range tests {
+		if got := FileURI(tt.path, tt.line); got != tt.want {
+			t.Fatalf("FileURI(%q, %d) = %q, want %q", tt.path, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSkippedFileDescribe(t *testing.T) {
+	tests := []struct {
+		skipped SkippedFile
+		want    string
+	}{
+		{skipped: SkippedFile{Reason: SkipBinary}, want: "binary"},
+		{skipped: SkippedFile{Reason: SkipError, Detail: "read error: permission denied"}, want: "error: read error: permission denied"},
+	}
+	for _, tt := range tests {
+		if got := tt.skipped.Describe(); got != tt.want {
+			t.Fatalf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestScanTiming(t *testing.T) {
+	dir := t.TempDir()
+	content := "package main\n// é\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.bin"), []byte{0, 1, 2}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Scan([]string{dir}, Options{Include: []string{"**/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Timings) != 0 {
+		t.Fatalf("expected no timings without Timing, got %+v", res.Timings)
+	}
+
+	res, err = Scan([]string{dir}, Options{Include: []string{"**/*"}, Timing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Timings) != 1 || !strings.HasSuffix(res.Timings[0].Path, "a.go") || res.Timings[0].Bytes != int64(len(content)) {
+		t.Fatalf("expected a timing for a.go only, got %+v", res.Timings)
+	}
+}
+
+func TestResultSlowest(t *testing.T) {
+	res := Result{Timings: []FileTiming{
+		{Path: "a", Duration: 2 * time.Millisecond},
+		{Path: "b", Duration: 5 * time.Millisecond},
+		{Path: "c", Duration: time.Millisecond},
+	}}
+	var got []string
+	for _, timing := range res.Slowest(2) {
+		got = append(got, timing.Path)
+	}
+	if !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Fatalf("Slowest(2) = %v", got)
+	}
+	if res.Timings[0].Path != "a" {
+		t.Fatalf("Slowest reordered the result: %+v", res.Timings)
+	}
+	if len(res.Slowest(10)) != 3 {
+		t.Fatalf("expected every file when n exceeds the count")
+	}
+}
+
+func TestCodeLines(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want []bool
+	}{
+		{path: "a.go", data: "a := `x\ny`\n/* z\n*/\nb := 1", want: []bool{false, true, false, true, false, true}},
+		{path: "a.py", data: "s = \"\"\"\nx\n\"\"\"\n# y\nz\n", want: []bool{false, true, false, false, true, true, true}},
+		{path: "a.md", data: "text\n```\ncode\n```\n", want: []bool{false, true, false, false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := CodeLines(tt.path, []byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CodeLines(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestScanSpecialFiles(t *testing.T) {
+	tmp := t.TempDir()
+	pipe := filepath.Join(tmp, "pipe.go")
+	if err := syscall.Mkfifo(pipe, 0o644); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "a.go"), []byte("// ok\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	opts := Options{Root: tmp, DisplayRoot: tmp, Include: []string{"**/*.go"}}
+	res, err := Scan([]string{tmp}, opts)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	want := []SkippedFile{{Path: "pipe.go", Reason: SkipSpecial, Detail: "named pipe"}}
+	if !reflect.DeepEqual(res.SkippedFiles, want) || !reflect.DeepEqual(res.ScannedFiles, []string{"a.go"}) {
+		t.Fatalf("walked: skipped %+v, scanned %q", res.SkippedFiles, res.ScannedFiles)
+	}
+
+	res, err = Scan([]string{pipe}, opts)
+	if err != nil || !reflect.DeepEqual(res.SkippedFiles, want) {
+		t.Fatalf("explicit without ReadSpecialFiles: skipped %+v (%v)", res.SkippedFiles, err)
+	}
+
+	go func() {
+		if f, err := os.OpenFile(pipe, os.O_WRONLY, 0); err == nil {
+			_, _ = f.WriteString("s := \"日\"\n")
+			_ = f.Close()
+		}
+	}()
+	opts.Include = []string{"**/*.md"}
+	opts.ReadSpecialFiles = true
+	res, err = Scan([]string{pipe}, opts)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].Path != "pipe.go" || res.Findings[0].Character != "日" || len(res.SkippedFiles) != 0 {
+		t.Fatalf("explicit with ReadSpecialFiles: findings %+v, skipped %+v", res.Findings, res.SkippedFiles)
+	}
+}
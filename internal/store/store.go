@@ -0,0 +1,157 @@
+// Package store appends scan results to a SQLite database using the sqlite3
+// command-line shell, so englint itself stays free of cgo and dependencies.
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// command is the sqlite3 executable; tests may override it.
+var command = "sqlite3"
+
+const schema = `CREATE TABLE IF NOT EXISTS scans (
+  id INTEGER PRIMARY KEY,
+  scanned_at TEXT NOT NULL,
+  git_sha TEXT NOT NULL,
+  files_scanned INTEGER NOT NULL,
+  files_skipped INTEGER NOT NULL,
+  findings INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS findings (
+  scan_id INTEGER NOT NULL REFERENCES scans(id),
+  path TEXT NOT NULL,
+  line INTEGER NOT NULL,
+  col INTEGER NOT NULL,
+  code_point TEXT NOT NULL,
+  category TEXT NOT NULL,
+  severity TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS findings_scan_id ON findings(scan_id);
+`
+
+// Record is one scan to append.
+type Record struct {
+	Time   time.Time
+	GitSHA string
+	Result scanner.Result
+}
+
+// Scan is a stored scan with its findings counted per category.
+type Scan struct {
+	ID           int            `json:"id"`
+	ScannedAt    string         `json:"scannedAt"`
+	GitSHA       string         `json:"gitSha,omitempty"`
+	FilesScanned int            `json:"filesScanned"`
+	FilesSkipped int            `json:"filesSkipped"`
+	Findings     int            `json:"findings"`
+	ByCategory   map[string]int `json:"byCategory,omitempty"`
+}
+
+// Store is a SQLite database file.
+type Store struct {
+	Path string
+}
+
+// Append inserts rec and its findings in a single transaction.
+func (s Store) Append(rec Record) error {
+	var b strings.Builder
+	b.WriteString(schema)
+	b.WriteString("BEGIN;\n")
+	summary := rec.Result.Summary
+	fmt.Fprintf(&b, "INSERT INTO scans (scanned_at, git_sha, files_scanned, files_skipped, findings) VALUES (%s, %s, %d, %d, %d);\n",
+		quote(rec.Time.UTC().Format(time.RFC3339)), quote(rec.GitSHA), summary.FilesScanned, summary.FilesSkipped, summary.Findings)
+	for _, f := range rec.Result.Findings {
+		fmt.Fprintf(&b, "INSERT INTO findings (scan_id, path, line, col, code_point, category, severity) VALUES ((SELECT max(id) FROM scans), %s, %d, %d, %s, %s, %s);\n",
+			quote(f.Path), f.Line, f.Column, quote(f.CodePoint), quote(f.Category), quote(string(f.Severity)))
+	}
+	b.WriteString("COMMIT;\n")
+	_, err := s.exec(b.String())
+	return err
+}
+
+// History returns the most recent scans, newest first. A limit of zero
+// returns every scan.
+func (s Store) History(limit int) ([]Scan, error) {
+	if _, err := os.Stat(s.Path); err != nil {
+		return nil, err
+	}
+	query := schema + `SELECT s.id, s.scanned_at, s.git_sha, s.files_scanned, s.files_skipped, s.findings,
+  coalesce((SELECT group_concat(category || '=' || n, ',') FROM
+    (SELECT category, count(*) AS n FROM findings WHERE scan_id = s.id GROUP BY category ORDER BY category)), '')
+FROM scans s ORDER BY s.id DESC`
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+	out, err := s.exec(query + ";\n")
+	if err != nil {
+		return nil, err
+	}
+	return parseHistory(out)
+}
+
+// parseHistory decodes the tab-separated rows produced by History.
+func parseHistory(out string) ([]Scan, error) {
+	var scans []Scan
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		if len(cols) != 7 {
+			return nil, fmt.Errorf("unexpected history row %q", line)
+		}
+		scan := Scan{ScannedAt: cols[1], GitSHA: cols[2]}
+		for i, dst := range []*int{&scan.ID, nil, nil, &scan.FilesScanned, &scan.FilesSkipped, &scan.Findings} {
+			if dst == nil {
+				continue
+			}
+			n, err := strconv.Atoi(cols[i])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected history row %q", line)
+			}
+			*dst = n
+		}
+		if cols[6] != "" {
+			scan.ByCategory = make(map[string]int)
+			for _, pair := range strings.Split(cols[6], ",") {
+				category, count, ok := strings.Cut(pair, "=")
+				n, err := strconv.Atoi(count)
+				if !ok || err != nil {
+					return nil, fmt.Errorf("unexpected category count %q", pair)
+				}
+				scan.ByCategory[category] = n
+			}
+		}
+		scans = append(scans, scan)
+	}
+	return scans, nil
+}
+
+func (s Store) exec(script string) (string, error) {
+	cmd := exec.Command(command, "-batch", "-noheader", "-separator", "\t", s.Path)
+	cmd.Stdin = strings.NewReader(script)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %s", command, s.Path, msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", command, s.Path, err)
+	}
+	return stdout.String(), nil
+}
+
+// quote renders value as an SQL string literal.
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
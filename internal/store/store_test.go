@@ -0,0 +1,96 @@
+package store
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestQuote(t *testing.T) {
+	if got := quote("it's"); got != "'it''s'" {
+		t.Fatalf("unexpected quote: %s", got)
+	}
+}
+
+func TestParseHistory(t *testing.T) {
+	out := "2\t2024-02-01T00:00:00Z\tabc\t10\t1\t3\tCJK=2,Cyrillic=1\n1\t2024-01-01T00:00:00Z\t\t9\t0\t0\t\n"
+	got, err := parseHistory(out)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []Scan{
+		{ID: 2, ScannedAt: "2024-02-01T00:00:00Z", GitSHA: "abc", FilesScanned: 10, FilesSkipped: 1, Findings: 3, ByCategory: map[string]int{"CJK": 2, "Cyrillic": 1}},
+		{ID: 1, ScannedAt: "2024-01-01T00:00:00Z", FilesScanned: 9},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"1\t2\t3", "x\tt\ts\t1\t1\t1\t", "1\tt\ts\t1\t1\t1\tCJK"} {
+		if _, err := parseHistory(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestAppendAndHistory(t *testing.T) {
+	if _, err := exec.LookPath(command); err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+	s := Store{Path: filepath.Join(t.TempDir(), "results.db")}
+	if _, err := s.History(0); !os.IsNotExist(err) {
+		t.Fatalf("expected missing database error, got %v", err)
+	}
+
+	first := Record{
+		Time:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		GitSHA: "abc",
+		Result: scanner.Result{
+			Summary: scanner.Summary{FilesScanned: 2, Findings: 3},
+			Findings: []scanner.Finding{
+				{Path: "it's.go", Line: 1, Column: 1, CodePoint: "U+65E5", Category: "CJK", Severity: scanner.SeverityError},
+				{Path: "a.go", Line: 2, Column: 1, CodePoint: "U+672C", Category: "CJK", Severity: scanner.SeverityError},
+				{Path: "a.go", Line: 3, Column: 1, CodePoint: "U+0436", Category: "Cyrillic", Severity: scanner.SeverityWarning},
+			},
+		},
+	}
+	second := Record{Time: first.Time.Add(time.Hour), Result: scanner.Result{Summary: scanner.Summary{FilesScanned: 2, FilesSkipped: 1}}}
+	for _, rec := range []Record{first, second} {
+		if err := s.Append(rec); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	scans, err := s.History(0)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(scans) != 2 || scans[0].ID != 2 || scans[0].FilesSkipped != 1 || scans[0].ByCategory != nil {
+		t.Fatalf("unexpected latest scan: %+v", scans)
+	}
+	if scans[1].GitSHA != "abc" || !reflect.DeepEqual(scans[1].ByCategory, map[string]int{"CJK": 2, "Cyrillic": 1}) {
+		t.Fatalf("unexpected first scan: %+v", scans[1])
+	}
+	if limited, err := s.History(1); err != nil || len(limited) != 1 {
+		t.Fatalf("expected one scan with limit, got %+v (%v)", limited, err)
+	}
+
+	if err := (Store{Path: t.TempDir()}).Append(first); err == nil || !strings.Contains(err.Error(), command) {
+		t.Fatalf("expected sqlite3 error for directory path, got %v", err)
+	}
+}
+
+func TestMissingCommand(t *testing.T) {
+	orig := command
+	defer func() { command = orig }()
+	command = "englint-missing-sqlite3"
+	if err := (Store{Path: filepath.Join(t.TempDir(), "x.db")}).Append(Record{}); err == nil {
+		t.Fatalf("expected missing executable error")
+	}
+}
@@ -0,0 +1,73 @@
+// Package suggest proposes allow-list entries from scan findings.
+package suggest
+
+import (
+	"sort"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+// Default thresholds for suggesting a character.
+const (
+	DefaultMinCount = 5
+	DefaultMinFiles = 2
+)
+
+// Options sets how widespread a character must be before it is suggested.
+type Options struct {
+	MinCount int
+	MinFiles int
+}
+
+// Suggestion is a character that could be added to the allow list.
+type Suggestion struct {
+	Value     string `json:"value"`
+	CodePoint string `json:"codePoint"`
+	Category  string `json:"category"`
+	Count     int    `json:"count"`
+	Files     int    `json:"files"`
+}
+
+// Allow groups findings by character and returns the characters that occur
+// at least MinCount times in at least MinFiles files, most frequent first.
+// Invalid UTF-8 findings are never suggested since they cannot be allowed.
+func Allow(findings []scanner.Finding, opts Options) []Suggestion {
+	type group struct {
+		Suggestion
+		files map[string]struct{}
+	}
+	groups := make(map[string]*group)
+	for _, f := range findings {
+		if f.Category == "Invalid UTF-8" {
+			continue
+		}
+		g, ok := groups[f.Character]
+		if !ok {
+			g = &group{
+				Suggestion: Suggestion{Value: f.Character, CodePoint: f.CodePoint, Category: f.Category},
+				files:      make(map[string]struct{}),
+			}
+			groups[f.Character] = g
+		}
+		g.Count++
+		g.files[f.Path] = struct{}{}
+	}
+
+	out := []Suggestion{}
+	for _, g := range groups {
+		g.Files = len(g.files)
+		if g.Count >= opts.MinCount && g.Files >= opts.MinFiles {
+			out = append(out, g.Suggestion)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if out[i].Files != out[j].Files {
+			return out[i].Files > out[j].Files
+		}
+		return out[i].CodePoint < out[j].CodePoint
+	})
+	return out
+}
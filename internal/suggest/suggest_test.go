@@ -0,0 +1,55 @@
+package suggest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func TestAllow(t *testing.T) {
+	finding := func(path, char, cp, category string) scanner.Finding {
+		return scanner.Finding{Path: path, Character: char, CodePoint: cp, Category: category}
+	}
+	findings := []scanner.Finding{
+		finding("a.go", "©", "U+00A9", "Symbol"),
+		finding("a.go", "©", "U+00A9", "Symbol"),
+		finding("b.go", "©", "U+00A9", "Symbol"),
+		finding("a.go", "→", "U+2192", "Symbol"),
+		finding("b.go", "→", "U+2192", "Symbol"),
+		finding("c.go", "→", "U+2192", "Symbol"),
+		finding("a.go", "日", "U+65E5", "CJK"),
+		finding("a.go", "日", "U+65E5", "CJK"),
+		finding("a.go", "日", "U+65E5", "CJK"),
+		finding("a.go", "?", "invalid-utf8", "Invalid UTF-8"),
+		finding("b.go", "?", "invalid-utf8", "Invalid UTF-8"),
+		finding("c.go", "?", "invalid-utf8", "Invalid UTF-8"),
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "thresholds", opts: Options{MinCount: 3, MinFiles: 2}, want: []string{"→", "©"}},
+		{name: "ties prefer more files", opts: Options{MinCount: 3, MinFiles: 1}, want: []string{"→", "©", "日"}},
+		{name: "none", opts: Options{MinCount: 10, MinFiles: 1}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range Allow(findings, tt.opts) {
+				got = append(got, s.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got := Allow(findings, Options{MinCount: 1, MinFiles: 3})
+	want := []Suggestion{{Value: "→", CodePoint: "U+2192", Category: "Symbol", Count: 3, Files: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Allow() = %+v, want %+v", got, want)
+	}
+}
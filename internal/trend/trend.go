@@ -0,0 +1,142 @@
+// Package trend scans historical git trees to build a time series of
+// finding counts.
+package trend
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/TT-AIXion/englint/internal/git"
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+const (
+	StepDaily   = "daily"
+	StepWeekly  = "weekly"
+	StepMonthly = "monthly"
+)
+
+// Point is the finding count of the tree as of Date.
+type Point struct {
+	Date       string         `json:"date"`
+	Commit     string         `json:"commit"`
+	Files      int            `json:"filesScanned"`
+	Findings   int            `json:"findings"`
+	ByCategory map[string]int `json:"byCategory"`
+}
+
+// Options controls a trend run.
+type Options struct {
+	// Dir is the repository root; tree paths are matched relative to it.
+	Dir   string
+	Rev   string
+	Since time.Time
+	Until time.Time
+	Step  string
+	Scan  scanner.Options
+}
+
+// Dates returns the sample dates from since through until, inclusive.
+func Dates(since, until time.Time, step string) ([]time.Time, error) {
+	var next func(time.Time) time.Time
+	switch step {
+	case StepDaily:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case StepWeekly:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case StepMonthly:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, fmt.Errorf("step must be %q, %q, or %q", StepDaily, StepWeekly, StepMonthly)
+	}
+	if until.Before(since) {
+		return nil, fmt.Errorf("since (%s) is after until (%s)", since.Format(time.DateOnly), until.Format(time.DateOnly))
+	}
+	var dates []time.Time
+	for t := since; !t.After(until); t = next(t) {
+		dates = append(dates, t)
+	}
+	return dates, nil
+}
+
+// blobResult is the scan outcome of one blob at one path.
+type blobResult struct {
+	scanned    bool
+	byCategory map[string]int
+}
+
+// Run scans the tree at the last commit before the end of each sample date.
+// Dates before the first commit are left out. Blobs are read through git
+// plumbing, so the working tree is never touched, and each blob is scanned
+// once no matter how many sample points share it.
+func Run(opts Options) ([]Point, error) {
+	dates, err := Dates(opts.Since, opts.Until, opts.Step)
+	if err != nil {
+		return nil, err
+	}
+	rev := opts.Rev
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	cache := make(map[string]blobResult)
+	points := []Point{}
+	for _, date := range dates {
+		commit, err := git.CommitBefore(opts.Dir, rev, date.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		if commit == "" {
+			continue
+		}
+		entries, err := git.Files(opts.Dir, commit)
+		if err != nil {
+			return nil, err
+		}
+
+		var missing []string
+		paths := make(map[string][]string)
+		for _, e := range entries {
+			key := e.Blob + "\x00" + e.Path
+			if _, ok := cache[key]; ok {
+				continue
+			}
+			if len(paths[e.Blob]) == 0 {
+				missing = append(missing, e.Blob)
+			}
+			paths[e.Blob] = append(paths[e.Blob], e.Path)
+		}
+		err = git.ReadBlobs(opts.Dir, missing, func(blob string, data []byte) error {
+			for _, path := range paths[blob] {
+				res, err := scanner.ScanReader(path, bytes.NewReader(data), opts.Scan)
+				if err != nil {
+					return err
+				}
+				result := blobResult{scanned: len(res.ScannedFiles) > 0, byCategory: map[string]int{}}
+				for _, f := range res.Findings {
+					result.byCategory[f.Category]++
+				}
+				cache[blob+"\x00"+path] = result
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		point := Point{Date: date.Format(time.DateOnly), Commit: commit, ByCategory: map[string]int{}}
+		for _, e := range entries {
+			result := cache[e.Blob+"\x00"+e.Path]
+			if result.scanned {
+				point.Files++
+			}
+			for category, n := range result.byCategory {
+				point.ByCategory[category] += n
+				point.Findings += n
+			}
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
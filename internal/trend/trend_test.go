@@ -0,0 +1,133 @@
+package trend
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TT-AIXion/englint/internal/scanner"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestDates(t *testing.T) {
+	tests := []struct {
+		step    string
+		since   string
+		until   string
+		want    []string
+		wantErr string
+	}{
+		{step: StepDaily, since: "2024-01-30", until: "2024-02-01", want: []string{"2024-01-30", "2024-01-31", "2024-02-01"}},
+		{step: StepWeekly, since: "2024-01-01", until: "2024-01-20", want: []string{"2024-01-01", "2024-01-08", "2024-01-15"}},
+		{step: StepMonthly, since: "2024-01-15", until: "2024-03-15", want: []string{"2024-01-15", "2024-02-15", "2024-03-15"}},
+		{step: "yearly", since: "2024-01-01", until: "2024-01-01", wantErr: "step must be"},
+		{step: StepDaily, since: "2024-02-01", until: "2024-01-01", wantErr: "is after"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.step, func(t *testing.T) {
+			dates, err := Dates(day(tt.since), day(tt.until), tt.step)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			var got []string
+			for _, d := range dates {
+				got = append(got, d.Format(time.DateOnly))
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v (%v), want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func commitAt(t *testing.T, dir, date string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", date},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date+"T12:00:00Z", "GIT_COMMITTER_DATE="+date+"T12:00:00Z")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	commitAt(t, dir, "2024-01-10", map[string]string{
+		"a.go":          "package a\nvar s = \"日本\"\n",
+		"docs/guide.md": "Привет\n",
+		"image.png":     "\x00\x01",
+	})
+	commitAt(t, dir, "2024-02-10", map[string]string{"a.go": "package a\nvar s = \"hi\"\n"})
+
+	opts := Options{
+		Dir:   dir,
+		Since: day("2024-01-01"),
+		Until: day("2024-03-01"),
+		Step:  StepMonthly,
+		Scan:  scanner.Options{Include: []string{"**/*"}, Severity: scanner.SeverityError},
+	}
+	points, err := Run(opts)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points (no commit before 2024-01-01), got %+v", points)
+	}
+	if points[0].Date != "2024-02-01" || points[0].Findings != 8 || points[0].ByCategory["Han"] != 2 || points[0].ByCategory["Cyrillic"] != 6 {
+		t.Fatalf("unexpected first point: %+v", points[0])
+	}
+	if points[1].Date != "2024-03-01" || points[1].Findings != 6 || points[1].Files != 2 || len(points[1].Commit) != 40 {
+		t.Fatalf("unexpected second point: %+v", points[1])
+	}
+
+	opts.Scan.Exclude = []string{"docs/**"}
+	if points, err := Run(opts); err != nil || points[1].Findings != 0 {
+		t.Fatalf("expected excluded docs to be skipped: %+v (%v)", points, err)
+	}
+
+	opts.Step = "hourly"
+	if _, err := Run(opts); err == nil {
+		t.Fatalf("expected invalid step error")
+	}
+	opts.Step = StepMonthly
+	opts.Rev = "no-such-branch"
+	if _, err := Run(opts); err == nil {
+		t.Fatalf("expected unknown revision error")
+	}
+}
@@ -0,0 +1,85 @@
+//go:build ignore
+
+// Gen writes names.txt.gz from UnicodeData.txt, which is available from
+// https://www.unicode.org/Public/UCD/latest/ucd/UnicodeData.txt.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// rangePrefixes maps the labels of the First/Last ranges in UnicodeData.txt
+// to the prefix of their derived names. Hangul syllables are named in code,
+// and the other ranges have no names.
+var rangePrefixes = map[string]string{
+	"CJK Ideograph":    "CJK UNIFIED IDEOGRAPH-",
+	"Tangut Ideograph": "TANGUT IDEOGRAPH-",
+}
+
+func main() {
+	in := flag.String("in", "UnicodeData.txt", "UnicodeData.txt path")
+	out := flag.String("out", "names.txt.gz", "output path")
+	flag.Parse()
+
+	src, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer src.Close()
+	dst, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	zw, err := gzip.NewWriterLevel(dst, gzip.BestCompression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w := bufio.NewWriter(zw)
+
+	var first string
+	sc := bufio.NewScanner(src)
+	for sc.Scan() {
+		fields := strings.Split(sc.Text(), ";")
+		if len(fields) < 2 {
+			continue
+		}
+		cp, name := fields[0], fields[1]
+		if !strings.HasPrefix(name, "<") {
+			fmt.Fprintf(w, "%s;%s\n", cp, name)
+			continue
+		}
+		label, ok := strings.CutSuffix(strings.TrimPrefix(name, "<"), ", First>")
+		if ok {
+			first = cp
+			continue
+		}
+		label, ok = strings.CutSuffix(strings.TrimPrefix(name, "<"), ", Last>")
+		if !ok {
+			// Control characters are listed as <control> and have no name.
+			continue
+		}
+		for prefix, derived := range rangePrefixes {
+			if strings.HasPrefix(label, prefix) {
+				fmt.Fprintf(w, "%s..%s;%s\n", first, cp, derived)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
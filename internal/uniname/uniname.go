@@ -0,0 +1,103 @@
+// Package uniname looks up the names of Unicode characters, such as
+// "HIRAGANA LETTER A" for U+3042, from a table embedded in the binary.
+package uniname
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:generate go run gen.go -in UnicodeData.txt -out names.txt.gz
+
+// names.txt.gz lists one character per line as "<hex code point>;<name>",
+// and the ranges whose names are derived from the code point as
+// "<hex first>..<hex last>;<prefix>", from UnicodeData.txt.
+//
+//go:embed names.txt.gz
+var table []byte
+
+// nameRange is a range of characters named by a prefix and the code point,
+// such as "CJK UNIFIED IDEOGRAPH-4E2D".
+type nameRange struct {
+	lo, hi rune
+	prefix string
+}
+
+var (
+	loadOnce sync.Once
+	names    map[rune]string
+	ranges   []nameRange
+)
+
+// Hangul syllables are named after their jamo; see section 3.12 of the
+// Unicode Standard.
+const (
+	hangulBase  = 0xAC00
+	hangulCount = 11172
+	jamoVCount  = 21
+	jamoTCount  = 28
+)
+
+var (
+	jamoL = []string{"G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ", "C", "K", "T", "P", "H"}
+	jamoV = []string{"A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE", "YO", "U", "WEO", "WE", "WI", "YU", "EU", "YI", "I"}
+	jamoT = []string{"", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS", "LT", "LP", "LH", "M", "B", "BS", "S", "SS", "NG", "J", "C", "K", "T", "P", "H"}
+)
+
+// Name returns the Unicode name of r, or "" for characters without a name,
+// such as control characters, private use characters, and unassigned code
+// points.
+func Name(r rune) string {
+	if s := r - hangulBase; s >= 0 && s < hangulCount {
+		return "HANGUL SYLLABLE " + jamoL[s/(jamoVCount*jamoTCount)] + jamoV[s%(jamoVCount*jamoTCount)/jamoTCount] + jamoT[s%jamoTCount]
+	}
+	loadOnce.Do(load)
+	if name, ok := names[r]; ok {
+		return name
+	}
+	for _, rg := range ranges {
+		if r >= rg.lo && r <= rg.hi {
+			return fmt.Sprintf("%s%04X", rg.prefix, r)
+		}
+	}
+	return ""
+}
+
+// load parses the embedded table. It panics on a malformed table, which
+// the tests catch.
+func load() {
+	zr, err := gzip.NewReader(bytes.NewReader(table))
+	if err != nil {
+		panic("uniname: " + err.Error())
+	}
+	names = make(map[rune]string, 40000)
+	sc := bufio.NewScanner(zr)
+	for sc.Scan() {
+		cp, name, ok := strings.Cut(sc.Text(), ";")
+		if !ok {
+			panic("uniname: malformed line " + sc.Text())
+		}
+		if lo, hi, ok := strings.Cut(cp, ".."); ok {
+			ranges = append(ranges, nameRange{lo: parseRune(lo), hi: parseRune(hi), prefix: name})
+			continue
+		}
+		names[parseRune(cp)] = name
+	}
+	if err := sc.Err(); err != nil {
+		panic("uniname: " + err.Error())
+	}
+}
+
+func parseRune(s string) rune {
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		panic("uniname: " + err.Error())
+	}
+	return rune(n)
+}
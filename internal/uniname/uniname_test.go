@@ -0,0 +1,30 @@
+package uniname
+
+import "testing"
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want string
+	}{
+		{'a', "LATIN SMALL LETTER A"},
+		{'é', "LATIN SMALL LETTER E WITH ACUTE"},
+		{'あ', "HIRAGANA LETTER A"},
+		{'\u200b', "ZERO WIDTH SPACE"},
+		{'中', "CJK UNIFIED IDEOGRAPH-4E2D"},
+		{0x20000, "CJK UNIFIED IDEOGRAPH-20000"},
+		{0xF900, "CJK COMPATIBILITY IDEOGRAPH-F900"},
+		{'한', "HANGUL SYLLABLE HAN"},
+		{0xAC00, "HANGUL SYLLABLE GA"},
+		{0xD7A3, "HANGUL SYLLABLE HIH"},
+		{'😀', "GRINNING FACE"},
+		{'\n', ""},
+		{0xE000, ""},
+		{0x10FFFF, ""},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.r); got != tt.want {
+			t.Errorf("Name(%U) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}